@@ -67,6 +67,10 @@ const (
 	FaultSet
 	// MinerState is a types.MinerState instance of a miner
 	MinerState
+	// Addresses is a slice of address.Address
+	Addresses
+	// Merges is a slice of types.Merge
+	Merges
 )
 
 func (t Type) String() string {
@@ -117,6 +121,10 @@ func (t Type) String() string {
 		return "types.FaultSet"
 	case MinerState:
 		return "types.MinerState"
+	case Addresses:
+		return "[]address.Address"
+	case Merges:
+		return "[]types.Merge"
 	default:
 		return "<unknown type>"
 	}
@@ -176,6 +184,10 @@ func (av *Value) String() string {
 		return av.Val.(types.FaultSet).String()
 	case MinerState:
 		return fmt.Sprint(av.Val.(types.MinerState))
+	case Addresses:
+		return fmt.Sprint(av.Val.([]address.Address))
+	case Merges:
+		return fmt.Sprint(av.Val.([]types.Merge))
 	default:
 		return "<unknown type>"
 	}
@@ -344,6 +356,18 @@ func (av *Value) Serialize() ([]byte, error) {
 			return nil, &typeError{types.MinerState{}, av.Val}
 		}
 		return cbor.DumpObject(s)
+	case Addresses:
+		addrs, ok := av.Val.([]address.Address)
+		if !ok {
+			return nil, &typeError{[]address.Address{}, av.Val}
+		}
+		return cbor.DumpObject(addrs)
+	case Merges:
+		merges, ok := av.Val.([]types.Merge)
+		if !ok {
+			return nil, &typeError{[]types.Merge{}, av.Val}
+		}
+		return cbor.DumpObject(merges)
 	default:
 		return nil, fmt.Errorf("unrecognized Type: %d", av.Type)
 	}
@@ -403,6 +427,10 @@ func ToValues(i []interface{}) ([]*Value, error) {
 			out = append(out, &Value{Type: FaultSet, Val: v})
 		case types.MinerState:
 			out = append(out, &Value{Type: MinerState, Val: v})
+		case []address.Address:
+			out = append(out, &Value{Type: Addresses, Val: v})
+		case []types.Merge:
+			out = append(out, &Value{Type: Merges, Val: v})
 		default:
 			return nil, fmt.Errorf("unsupported type: %T", v)
 		}
@@ -583,6 +611,24 @@ func Deserialize(data []byte, t Type) (*Value, error) {
 			Type: t,
 			Val:  s,
 		}, nil
+	case Addresses:
+		var addrs []address.Address
+		if err := cbor.DecodeInto(data, &addrs); err != nil {
+			return nil, err
+		}
+		return &Value{
+			Type: t,
+			Val:  addrs,
+		}, nil
+	case Merges:
+		var merges []types.Merge
+		if err := cbor.DecodeInto(data, &merges); err != nil {
+			return nil, err
+		}
+		return &Value{
+			Type: t,
+			Val:  merges,
+		}, nil
 	case Invalid:
 		return nil, ErrInvalidType
 	default:
@@ -613,6 +659,8 @@ var typeTable = map[Type]reflect.Type{
 	MinerPoStStates: reflect.TypeOf(&map[string]uint64{}),
 	FaultSet:        reflect.TypeOf(types.FaultSet{}),
 	MinerState:      reflect.TypeOf(types.MinerState{}),
+	Addresses:       reflect.TypeOf([]address.Address{}),
+	Merges:          reflect.TypeOf([]types.Merge{}),
 }
 
 // TypeMatches returns whether or not 'val' is the go type expected for the given ABI type
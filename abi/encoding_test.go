@@ -40,6 +40,8 @@ func TestBasicEncodingRoundTrip(t *testing.T) {
 		"miner post states": {
 			&map[string]uint64{address.TestAddress.String(): 1, address.TestAddress2.String(): 2},
 		},
+		"addresses": {[]address.Address{addrGetter(), addrGetter()}},
+		"merges":    {[]types.Merge{{Lane: 1, Nonce: 2}, {Lane: 3, Nonce: 4}}},
 	}
 
 	for tname, tcase := range cases {
@@ -107,7 +107,7 @@ func NewMockActor(list exec.Exports) *MockActor {
 	}
 }
 
-func makeCtx(method string) exec.VMContext {
+func makeCtx(method types.MethodID) exec.VMContext {
 	addrGetter := address.NewForTestGetter()
 
 	vmCtxParams := vm.NewContextParams{
@@ -123,14 +123,15 @@ func TestMakeTypedExportSuccess(t *testing.T) {
 	tf.UnitTest(t)
 
 	t.Run("no return", func(t *testing.T) {
-		a := NewMockActor(map[string]*exec.FunctionSignature{
-			"two": {
+		a := NewMockActor(map[types.MethodID]*exec.FunctionSignature{
+			2: {
+				Name:   "two",
 				Params: nil,
 				Return: nil,
 			},
 		})
 
-		ret, exitCode, err := MakeTypedExport(a, "two")(makeCtx("two"))
+		ret, exitCode, err := MakeTypedExport(a, 2)(makeCtx(2))
 
 		assert.NoError(t, err)
 		assert.Equal(t, exitCode, uint8(0))
@@ -138,32 +139,34 @@ func TestMakeTypedExportSuccess(t *testing.T) {
 	})
 
 	t.Run("with return", func(t *testing.T) {
-		a := NewMockActor(map[string]*exec.FunctionSignature{
-			"four": {
+		a := NewMockActor(map[types.MethodID]*exec.FunctionSignature{
+			4: {
+				Name:   "four",
 				Params: nil,
 				Return: []abi.Type{abi.Bytes},
 			},
 		})
 
-		ret, exitCode, err := MakeTypedExport(a, "four")(makeCtx("four"))
+		ret, exitCode, err := MakeTypedExport(a, 4)(makeCtx(4))
 
 		assert.NoError(t, err)
 		assert.Equal(t, exitCode, uint8(0))
-		vv, err := abi.DecodeValues(ret, a.Exports()["four"].Return)
+		vv, err := abi.DecodeValues(ret, a.Exports()[4].Return)
 		assert.NoError(t, err)
 		assert.Equal(t, 1, len(vv))
 		assert.Equal(t, vv[0].Val, []byte("hello"))
 	})
 
 	t.Run("with error return", func(t *testing.T) {
-		a := NewMockActor(map[string]*exec.FunctionSignature{
-			"five": {
+		a := NewMockActor(map[types.MethodID]*exec.FunctionSignature{
+			5: {
+				Name:   "five",
 				Params: []abi.Type{},
 				Return: []abi.Type{abi.Bytes},
 			},
 		})
 
-		ret, exitCode, err := MakeTypedExport(a, "five")(makeCtx("five"))
+		ret, exitCode, err := MakeTypedExport(a, 5)(makeCtx(5))
 
 		assert.Contains(t, err.Error(), "fail5")
 		assert.Equal(t, exitCode, uint8(2))
@@ -171,16 +174,17 @@ func TestMakeTypedExportSuccess(t *testing.T) {
 	})
 
 	t.Run("with error that is not revert or fault", func(t *testing.T) {
-		a := NewMockActor(map[string]*exec.FunctionSignature{
-			"six": {
+		a := NewMockActor(map[types.MethodID]*exec.FunctionSignature{
+			6: {
+				Name:   "six",
 				Params: nil,
 				Return: nil,
 			},
 		})
 
-		exportedFunc := MakeTypedExport(a, "six")
+		exportedFunc := MakeTypedExport(a, 6)
 		assert.Panics(t, func() {
-			_, _, _ = exportedFunc(makeCtx("six"))
+			_, _, _ = exportedFunc(makeCtx(6))
 		})
 	})
 }
@@ -191,73 +195,79 @@ func TestMakeTypedExportFail(t *testing.T) {
 	testCases := []struct {
 		Name   string
 		Actor  *MockActor
-		Method string
+		Method types.MethodID
 		Error  string
 	}{
 		{
 			Name: "missing method on actor",
-			Actor: NewMockActor(map[string]*exec.FunctionSignature{
-				"one": {
+			Actor: NewMockActor(map[types.MethodID]*exec.FunctionSignature{
+				1: {
+					Name:   "one",
 					Params: nil,
 					Return: nil,
 				},
-				"other": {
+				7: {
+					Name:   "other",
 					Params: nil,
 					Return: nil,
 				},
 			}),
-			Method: "other",
+			Method: 7,
 			Error:  "MakeTypedExport could not find passed in method in actor: other",
 		},
 		{
 			Name:   "missing method on exports",
 			Actor:  NewMockActor(nil),
-			Error:  "MakeTypedExport could not find passed in method in exports: one",
-			Method: "one",
+			Error:  "MakeTypedExport could not find passed in method in exports: 1",
+			Method: 1,
 		},
 		{
 			Name: "too little params",
-			Actor: NewMockActor(map[string]*exec.FunctionSignature{
-				"one": {
+			Actor: NewMockActor(map[types.MethodID]*exec.FunctionSignature{
+				1: {
+					Name:   "one",
 					Params: nil,
 					Return: nil,
 				},
 			}),
 			Error:  "MakeTypedExport must receive a function with signature: func (Actor, exec.VMContext) (uint8, error), but got: func(*actor_test.MockActor) (uint8, error)",
-			Method: "one",
+			Method: 1,
 		},
 		{
 			Name: "too little return parameters",
-			Actor: NewMockActor(map[string]*exec.FunctionSignature{
-				"three": {
+			Actor: NewMockActor(map[types.MethodID]*exec.FunctionSignature{
+				3: {
+					Name:   "three",
 					Params: nil,
 					Return: nil,
 				},
 			}),
 			Error:  "MakeTypedExport must receive a function with signature: func (Actor, exec.VMContext) (uint8, error), but got: func(*actor_test.MockActor, exec.VMContext) error",
-			Method: "three",
+			Method: 3,
 		},
 		{
 			Name: "wrong return parameters",
-			Actor: NewMockActor(map[string]*exec.FunctionSignature{
-				"two": {
+			Actor: NewMockActor(map[types.MethodID]*exec.FunctionSignature{
+				2: {
+					Name:   "two",
 					Params: nil,
 					Return: []abi.Type{abi.Bytes},
 				},
 			}),
 			Error:  "MakeTypedExport must receive a function with signature: func (Actor, exec.VMContext) ([]byte, uint8, error), but got: func(*actor_test.MockActor, exec.VMContext) (uint8, error)",
-			Method: "two",
+			Method: 2,
 		},
 		{
 			Name: "multiple return parameters",
-			Actor: NewMockActor(map[string]*exec.FunctionSignature{
-				"two": {
+			Actor: NewMockActor(map[types.MethodID]*exec.FunctionSignature{
+				2: {
+					Name:   "two",
 					Params: nil,
 					Return: []abi.Type{abi.Bytes, abi.Bytes},
 				},
 			}),
 			Error:  "MakeTypedExport must receive a function with signature: func (Actor, exec.VMContext) ([]byte, []byte, uint8, error), but got: func(*actor_test.MockActor, exec.VMContext) (uint8, error)",
-			Method: "two",
+			Method: 2,
 		},
 	}
 
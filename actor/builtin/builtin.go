@@ -5,6 +5,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/actor/builtin/account"
 	"github.com/filecoin-project/go-filecoin/actor/builtin/initactor"
 	"github.com/filecoin-project/go-filecoin/actor/builtin/miner"
+	"github.com/filecoin-project/go-filecoin/actor/builtin/multisig"
 	"github.com/filecoin-project/go-filecoin/actor/builtin/paymentbroker"
 	"github.com/filecoin-project/go-filecoin/actor/builtin/storagemarket"
 	"github.com/filecoin-project/go-filecoin/exec"
@@ -24,4 +25,5 @@ func init() {
 	Actors[types.MinerActorCodeCid] = &miner.Actor{}
 	Actors[types.BootstrapMinerActorCodeCid] = &miner.Actor{Bootstrap: true}
 	Actors[types.InitActorCodeCid] = &initactor.Actor{}
+	Actors[types.MultisigActorCodeCid] = &multisig.Actor{}
 }
@@ -1,12 +1,16 @@
 package initactor
 
 import (
+	"math/big"
+
 	"github.com/filecoin-project/go-filecoin/types"
 	"github.com/ipfs/go-cid"
 	cbor "github.com/ipfs/go-ipld-cbor"
 
 	"github.com/filecoin-project/go-filecoin/abi"
 	"github.com/filecoin-project/go-filecoin/actor"
+	"github.com/filecoin-project/go-filecoin/actor/builtin/multisig"
+	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/exec"
 	"github.com/filecoin-project/go-filecoin/vm/errors"
 )
@@ -27,12 +31,24 @@ type State struct {
 // Ensure InitActor is an ExecutableActor at compile time.
 var _ exec.ExecutableActor = (*Actor)(nil)
 
+// Method ids of the init actor exports.
+const (
+	MethodGetNetwork     = types.MethodID(1)
+	MethodCreateMultisig = types.MethodID(2)
+)
+
 // initExports are the publicly (externally callable) methods of the AccountActor.
 var initExports = exec.Exports{
-	"getNetwork": &exec.FunctionSignature{
+	MethodGetNetwork: &exec.FunctionSignature{
+		Name:   "getNetwork",
 		Params: []abi.Type{},
 		Return: []abi.Type{abi.String},
 	},
+	MethodCreateMultisig: &exec.FunctionSignature{
+		Name:   "createMultisig",
+		Params: []abi.Type{abi.Addresses, abi.Integer, abi.BlockHeight},
+		Return: []abi.Type{abi.Address},
+	},
 }
 
 // Exports makes the available methods for this contract available.
@@ -79,3 +95,38 @@ func (sma *Actor) GetNetwork(vmctx exec.VMContext) (string, uint8, error) {
 
 	return state.Network, 0, nil
 }
+
+// CreateMultisig creates a new multisig wallet controlled by the given
+// signers, requiring `required` approvals per transaction. The value of the
+// message becomes the wallet's initial balance, which vests linearly over
+// unlockDuration blocks (a zero duration leaves it unlocked).
+func (ia *Actor) CreateMultisig(vmctx exec.VMContext, signers []address.Address, required *big.Int, unlockDuration *types.BlockHeight) (address.Address, uint8, error) {
+	if err := vmctx.Charge(actor.DefaultGasCost); err != nil {
+		return address.Undef, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	if !required.IsUint64() {
+		return address.Undef, multisig.ErrInvalidRequirement, multisig.Errors[multisig.ErrInvalidRequirement]
+	}
+
+	msState, err := multisig.NewState(signers, required.Uint64(), vmctx.Message().Value, vmctx.BlockHeight(), unlockDuration)
+	if err != nil {
+		return address.Undef, errors.CodeError(err), err
+	}
+
+	addr, err := vmctx.AddressForNewActor()
+	if err != nil {
+		return address.Undef, 1, errors.FaultErrorWrap(err, "could not get address for new actor")
+	}
+
+	if err := vmctx.CreateNewActor(addr, types.MultisigActorCodeCid, msState); err != nil {
+		return address.Undef, errors.CodeError(err), err
+	}
+
+	_, _, err = vmctx.Send(addr, "", vmctx.Message().Value, nil)
+	if err != nil {
+		return address.Undef, errors.CodeError(err), err
+	}
+
+	return addr, 0, nil
+}
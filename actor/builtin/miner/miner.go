@@ -68,6 +68,9 @@ const (
 	// ErrInvalidPieceInclusionProof indicates that the piece inclusion proof was
 	// malformed or did not succesfully verify.
 	ErrInvalidPieceInclusionProof = 46
+	// ErrInsufficientFunds indicates that a withdrawal exceeds the balance not
+	// required as pledge collateral.
+	ErrInsufficientFunds = 47
 )
 
 // Errors map error codes to revert errors this actor may return.
@@ -83,6 +86,7 @@ var Errors = map[uint8]error{
 	ErrGetProofsModeFailed:        errors.NewCodedRevertErrorf(ErrGetProofsModeFailed, "failed to get proofs mode"),
 	ErrInsufficientCollateral:     errors.NewCodedRevertErrorf(ErrInsufficientCollateral, "insufficient collateral"),
 	ErrInvalidPieceInclusionProof: errors.NewCodedRevertErrorf(ErrInvalidPieceInclusionProof, "piece inclusion proof did not validate"),
+	ErrInsufficientFunds:          errors.NewCodedRevertErrorf(ErrInsufficientFunds, "insufficient funds available for withdrawal"),
 }
 
 const (
@@ -160,54 +164,116 @@ func (ma *Actor) InitializeState(storage exec.Storage, initializerData interface
 
 var _ exec.ExecutableActor = (*Actor)(nil)
 
+// Method ids of the miner exports.
+const (
+	MethodAddAsk                   = types.MethodID(1)
+	MethodGetOwner                 = types.MethodID(2)
+	MethodCommitSector             = types.MethodID(3)
+	MethodGetWorker                = types.MethodID(4)
+	MethodGetPeerID                = types.MethodID(5)
+	MethodUpdatePeerID             = types.MethodID(6)
+	MethodGetPower                 = types.MethodID(7)
+	MethodSubmitPoSt               = types.MethodID(8)
+	MethodSlashStorageFault        = types.MethodID(9)
+	MethodTerminateSectors         = types.MethodID(10)
+	MethodWithdrawBalance          = types.MethodID(11)
+	MethodChangeWorker             = types.MethodID(12)
+	MethodProposeOwner             = types.MethodID(13)
+	MethodAcceptOwner              = types.MethodID(14)
+	MethodVerifyPieceInclusion     = types.MethodID(15)
+	MethodGetSectorSize            = types.MethodID(16)
+	MethodGetAsks                  = types.MethodID(17)
+	MethodGetAsk                   = types.MethodID(18)
+	MethodGetLastUsedSectorID      = types.MethodID(19)
+	MethodGetProvingSetCommitments = types.MethodID(20)
+	MethodIsBootstrapMiner         = types.MethodID(21)
+	MethodGetPoStState             = types.MethodID(22)
+	MethodGetProvingWindow         = types.MethodID(23)
+	MethodCalculateLateFee         = types.MethodID(24)
+	MethodGetActiveCollateral      = types.MethodID(25)
+	MethodGetState                 = types.MethodID(26)
+)
+
 var minerExports = exec.Exports{
 	// addAsk is not in the spec, but there's not yet another mechanism to discover asks.
-	"addAsk": &exec.FunctionSignature{
+	MethodAddAsk: &exec.FunctionSignature{
+		Name:   "addAsk",
 		Params: []abi.Type{abi.AttoFIL, abi.Integer},
 		Return: []abi.Type{abi.Integer},
 	},
-	"getOwner": &exec.FunctionSignature{
+	MethodGetOwner: &exec.FunctionSignature{
+		Name:   "getOwner",
 		Params: nil,
 		Return: []abi.Type{abi.Address},
 	},
-	"commitSector": &exec.FunctionSignature{
+	MethodCommitSector: &exec.FunctionSignature{
+		Name:   "commitSector",
 		Params: []abi.Type{abi.SectorID, abi.Bytes, abi.Bytes, abi.Bytes, abi.PoRepProof},
 		Return: []abi.Type{},
 	},
-	"getWorker": &exec.FunctionSignature{
+	MethodGetWorker: &exec.FunctionSignature{
+		Name:   "getWorker",
 		Params: []abi.Type{},
 		Return: []abi.Type{abi.Address},
 	},
-	"getPeerID": &exec.FunctionSignature{
+	MethodGetPeerID: &exec.FunctionSignature{
+		Name:   "getPeerID",
 		Params: []abi.Type{},
 		Return: []abi.Type{abi.PeerID},
 	},
-	"updatePeerID": &exec.FunctionSignature{
+	MethodUpdatePeerID: &exec.FunctionSignature{
+		Name:   "updatePeerID",
 		Params: []abi.Type{abi.PeerID},
 		Return: []abi.Type{},
 	},
-	"getPower": &exec.FunctionSignature{
+	MethodGetPower: &exec.FunctionSignature{
+		Name:   "getPower",
 		Params: []abi.Type{},
 		Return: []abi.Type{abi.BytesAmount},
 	},
-	"submitPoSt": &exec.FunctionSignature{
+	MethodSubmitPoSt: &exec.FunctionSignature{
+		Name:   "submitPoSt",
 		Params: []abi.Type{abi.PoStProof, abi.FaultSet, abi.IntSet},
 		Return: []abi.Type{},
 	},
-	"slashStorageFault": &exec.FunctionSignature{
+	MethodSlashStorageFault: &exec.FunctionSignature{
+		Name:   "slashStorageFault",
 		Params: []abi.Type{},
 		Return: []abi.Type{},
 	},
-	"changeWorker": &exec.FunctionSignature{
+	MethodTerminateSectors: &exec.FunctionSignature{
+		Name:   "terminateSectors",
+		Params: []abi.Type{abi.IntSet},
+		Return: []abi.Type{},
+	},
+	MethodWithdrawBalance: &exec.FunctionSignature{
+		Name:   "withdrawBalance",
+		Params: []abi.Type{abi.AttoFIL},
+		Return: []abi.Type{},
+	},
+	MethodChangeWorker: &exec.FunctionSignature{
+		Name:   "changeWorker",
+		Params: []abi.Type{abi.Address},
+		Return: []abi.Type{},
+	},
+	MethodProposeOwner: &exec.FunctionSignature{
+		Name:   "proposeOwner",
 		Params: []abi.Type{abi.Address},
 		Return: []abi.Type{},
 	},
+	MethodAcceptOwner: &exec.FunctionSignature{
+		Name:   "acceptOwner",
+		Params: []abi.Type{},
+		Return: []abi.Type{},
+	},
 	// verifyPieceInclusion is not in spec, but should be.
-	"verifyPieceInclusion": &exec.FunctionSignature{
+	MethodVerifyPieceInclusion: &exec.FunctionSignature{
+		Name:   "verifyPieceInclusion",
 		Params: []abi.Type{abi.Bytes, abi.BytesAmount, abi.SectorID, abi.Bytes},
 		Return: []abi.Type{},
 	},
-	"getSectorSize": &exec.FunctionSignature{
+	MethodGetSectorSize: &exec.FunctionSignature{
+		Name:   "getSectorSize",
 		Params: nil,
 		Return: []abi.Type{abi.BytesAmount},
 	},
@@ -217,43 +283,53 @@ var minerExports = exec.Exports{
 	// but are because we lack a mechanism to invoke actor methods without going through the
 	// queryMessage infrastructure. These should be removed when we have another way of invoking
 	// them from worker code. https://github.com/filecoin-project/go-filecoin/issues/2973
-	"getAsks": &exec.FunctionSignature{
+	MethodGetAsks: &exec.FunctionSignature{
+		Name:   "getAsks",
 		Params: nil,
 		Return: []abi.Type{abi.UintArray},
 	},
-	"getAsk": &exec.FunctionSignature{
+	MethodGetAsk: &exec.FunctionSignature{
+		Name:   "getAsk",
 		Params: []abi.Type{abi.Integer},
 		Return: []abi.Type{abi.Bytes},
 	},
-	"getLastUsedSectorID": &exec.FunctionSignature{
+	MethodGetLastUsedSectorID: &exec.FunctionSignature{
+		Name:   "getLastUsedSectorID",
 		Params: nil,
 		Return: []abi.Type{abi.SectorID},
 	},
-	"getProvingSetCommitments": &exec.FunctionSignature{
+	MethodGetProvingSetCommitments: &exec.FunctionSignature{
+		Name:   "getProvingSetCommitments",
 		Params: nil,
 		Return: []abi.Type{abi.CommitmentsMap},
 	},
-	"isBootstrapMiner": &exec.FunctionSignature{
+	MethodIsBootstrapMiner: &exec.FunctionSignature{
+		Name:   "isBootstrapMiner",
 		Params: nil,
 		Return: []abi.Type{abi.Boolean},
 	},
-	"getPoStState": &exec.FunctionSignature{
+	MethodGetPoStState: &exec.FunctionSignature{
+		Name:   "getPoStState",
 		Params: nil,
 		Return: []abi.Type{abi.Integer},
 	},
-	"getProvingWindow": &exec.FunctionSignature{
+	MethodGetProvingWindow: &exec.FunctionSignature{
+		Name:   "getProvingWindow",
 		Params: []abi.Type{},
 		Return: []abi.Type{abi.BlockHeight, abi.BlockHeight},
 	},
-	"calculateLateFee": &exec.FunctionSignature{
+	MethodCalculateLateFee: &exec.FunctionSignature{
+		Name:   "calculateLateFee",
 		Params: []abi.Type{abi.BlockHeight},
 		Return: []abi.Type{abi.AttoFIL},
 	},
-	"getActiveCollateral": &exec.FunctionSignature{
+	MethodGetActiveCollateral: &exec.FunctionSignature{
+		Name:   "getActiveCollateral",
 		Params: []abi.Type{},
 		Return: []abi.Type{abi.AttoFIL},
 	},
-	"getState": &exec.FunctionSignature{
+	MethodGetState: &exec.FunctionSignature{
+		Name:   "getState",
 		Params: nil,
 		Return: []abi.Type{abi.MinerState},
 	},
@@ -693,6 +769,60 @@ func (ma *Actor) ChangeWorker(ctx exec.VMContext, worker address.Address) (uint8
 	return 0, nil
 }
 
+// ProposeOwner starts transferring ownership of the miner to newOwner. The
+// transfer only takes effect once newOwner calls AcceptOwner. Proposing the
+// current owner withdraws any pending proposal.
+func (ma *Actor) ProposeOwner(ctx exec.VMContext, newOwner address.Address) (uint8, error) {
+	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
+		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	_, err := actor.WithState(ctx, &state, func() (interface{}, error) {
+		if ctx.Message().From != state.Owner {
+			return nil, Errors[ErrCallerUnauthorized]
+		}
+
+		if newOwner == state.Owner {
+			state.PendingOwner = address.Undef
+		} else {
+			state.PendingOwner = newOwner
+		}
+
+		return nil, nil
+	})
+	if err != nil {
+		return errors.CodeError(err), err
+	}
+
+	return 0, nil
+}
+
+// AcceptOwner completes an ownership transfer started by ProposeOwner. It must
+// be called by the proposed owner.
+func (ma *Actor) AcceptOwner(ctx exec.VMContext) (uint8, error) {
+	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
+		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	_, err := actor.WithState(ctx, &state, func() (interface{}, error) {
+		if state.PendingOwner.Empty() || ctx.Message().From != state.PendingOwner {
+			return nil, Errors[ErrCallerUnauthorized]
+		}
+
+		state.Owner = state.PendingOwner
+		state.PendingOwner = address.Undef
+
+		return nil, nil
+	})
+	if err != nil {
+		return errors.CodeError(err), err
+	}
+
+	return 0, nil
+}
+
 // GetWorker returns the worker address for this miner.
 func (ma *Actor) GetWorker(ctx exec.VMContext) (address.Address, uint8, error) {
 	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
@@ -941,6 +1071,16 @@ func (ma *Actor) SubmitPoSt(ctx exec.VMContext, poStProof types.PoStProof, fault
 			}
 		}
 
+		// Release payment for the storage deals in the sectors proven by this PoSt.
+		provenSectors := state.ProvingSet.Difference(faults.SectorIds)
+		_, ret, err := ctx.Send(address.StorageMarketAddress, "releaseDealPayments", types.ZeroAttoFIL, []interface{}{provenSectors})
+		if err != nil {
+			return nil, err
+		}
+		if ret != 0 {
+			return nil, Errors[ErrStoragemarketCallFailed]
+		}
+
 		// Update SectorSet, DoneSet and ProvingSet
 		if err = state.SectorCommitments.Drop(done.Values()); err != nil {
 			return nil, Errors[ErrInvalidSector]
@@ -1030,6 +1170,107 @@ func (ma *Actor) SlashStorageFault(ctx exec.VMContext) (uint8, error) {
 	return 0, nil
 }
 
+// TerminateSectors voluntarily retires committed sectors before they expire.
+// The sectors are removed from the sector commitments and proving set, any
+// power they contribute is removed from the storage market, and the pledge
+// collateral backing them is released.
+func (ma *Actor) TerminateSectors(ctx exec.VMContext, sectorIDs types.IntSet) (uint8, error) {
+	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
+		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	sender := ctx.Message().From
+	var state State
+	_, err := actor.WithState(ctx, &state, func() (interface{}, error) {
+		if sender != state.Owner && sender != state.Worker {
+			return nil, Errors[ErrCallerUnauthorized]
+		}
+
+		if sectorIDs.Size() == 0 {
+			return nil, errors.NewCodedRevertError(ErrInvalidSector, "no sectors to terminate")
+		}
+		for _, id := range sectorIDs.Values() {
+			if !state.SectorCommitments.Has(id) {
+				return nil, errors.NewCodedRevertErrorf(ErrInvalidSector, "sector %d is not committed", id)
+			}
+		}
+
+		// Only sectors in the proving set contribute power, and never more
+		// than the miner currently has (faulted sectors were not counted).
+		provingTerminated := state.ProvingSet.Intersection(sectorIDs)
+		removedPower := types.NewBytesAmount(uint64(provingTerminated.Size())).Mul(state.SectorSize)
+		if removedPower.GreaterThan(state.Power) {
+			removedPower = state.Power
+		}
+
+		if !removedPower.IsZero() {
+			powerDelta := types.ZeroBytes.Sub(removedPower) // negate bytes amount
+			_, ret, err := ctx.Send(address.StorageMarketAddress, "updateStorage", types.ZeroAttoFIL, []interface{}{powerDelta})
+			if err != nil {
+				return nil, err
+			}
+			if ret != 0 {
+				return nil, Errors[ErrStoragemarketCallFailed]
+			}
+			state.Power = state.Power.Sub(removedPower)
+		}
+
+		if err := state.SectorCommitments.Drop(sectorIDs.Values()); err != nil {
+			return nil, Errors[ErrInvalidSector]
+		}
+		state.ProvingSet = state.ProvingSet.Difference(sectorIDs)
+
+		// release the pledge collateral committed for these sectors
+		released := CollateralForSector(state.SectorSize).MulBigInt(big.NewInt(int64(sectorIDs.Size())))
+		if released.GreaterThan(state.ActiveCollateral) {
+			released = state.ActiveCollateral
+		}
+		state.ActiveCollateral = state.ActiveCollateral.Sub(released)
+
+		return nil, nil
+	})
+	if err != nil {
+		return errors.CodeError(err), err
+	}
+
+	return 0, nil
+}
+
+// WithdrawBalance sends amount from the miner's balance to its owner. Only
+// the balance in excess of the current pledge collateral requirement may be
+// withdrawn.
+func (ma *Actor) WithdrawBalance(ctx exec.VMContext, amount types.AttoFIL) (uint8, error) {
+	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
+		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	err := actor.ReadState(ctx, &state)
+	if err != nil {
+		return errors.CodeError(err), err
+	}
+
+	if ctx.Message().From != state.Owner {
+		return ErrCallerUnauthorized, Errors[ErrCallerUnauthorized]
+	}
+
+	if !amount.IsPositive() {
+		return ErrInsufficientFunds, errors.NewCodedRevertErrorf(ErrInsufficientFunds, "withdrawal amount must be positive, got %s", amount)
+	}
+
+	available := ctx.MyBalance().Sub(ma.getPledgeCollateralRequirement(state, ctx.BlockHeight()))
+	if amount.GreaterThan(available) {
+		return ErrInsufficientFunds, Errors[ErrInsufficientFunds]
+	}
+
+	_, _, err = ctx.Send(state.Owner, "", amount, []interface{}{})
+	if err != nil {
+		return errors.CodeError(err), err
+	}
+
+	return 0, nil
+}
+
 // GetProvingWindow returns the proving period start and proving period end
 func (ma *Actor) GetProvingWindow(ctx exec.VMContext) (*types.BlockHeight, *types.BlockHeight, uint8, error) {
 	var state State
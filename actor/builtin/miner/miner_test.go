@@ -42,13 +42,13 @@ func TestAskFunctions(t *testing.T) {
 
 	// make an ask, and then make sure it all looks good
 	pdata := actor.MustConvertParams(types.NewAttoFILFromFIL(5), big.NewInt(1500))
-	msg := types.NewMessage(address.TestAddress, minerAddr, 1, types.ZeroAttoFIL, "addAsk", pdata)
+	msg := types.NewMessage(address.TestAddress, minerAddr, 1, types.ZeroAttoFIL, MethodAddAsk, pdata)
 
 	_, err := th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(1))
 	assert.NoError(t, err)
 
 	pdata = actor.MustConvertParams(big.NewInt(0))
-	msg = types.NewMessage(address.TestAddress, minerAddr, 2, types.ZeroAttoFIL, "getAsk", pdata)
+	msg = types.NewMessage(address.TestAddress, minerAddr, 2, types.ZeroAttoFIL, MethodGetAsk, pdata)
 	result, err := th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(2))
 	assert.NoError(t, err)
 
@@ -67,20 +67,20 @@ func TestAskFunctions(t *testing.T) {
 
 	// Look for an ask that doesn't exist
 	pdata = actor.MustConvertParams(big.NewInt(3453))
-	msg = types.NewMessage(address.TestAddress, minerAddr, 2, types.ZeroAttoFIL, "getAsk", pdata)
+	msg = types.NewMessage(address.TestAddress, minerAddr, 2, types.ZeroAttoFIL, MethodGetAsk, pdata)
 	result, err = th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(2))
 	assert.NoError(t, err)
 	assert.Equal(t, Errors[ErrAskNotFound], result.ExecutionError)
 
 	// make another ask!
 	pdata = actor.MustConvertParams(types.NewAttoFILFromFIL(110), big.NewInt(200))
-	msg = types.NewMessage(address.TestAddress, minerAddr, 3, types.ZeroAttoFIL, "addAsk", pdata)
+	msg = types.NewMessage(address.TestAddress, minerAddr, 3, types.ZeroAttoFIL, MethodAddAsk, pdata)
 	result, err = th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(3))
 	assert.NoError(t, err)
 	assert.Equal(t, big.NewInt(1), big.NewInt(0).SetBytes(result.Receipt.Return[0]))
 
 	pdata = actor.MustConvertParams(big.NewInt(1))
-	msg = types.NewMessage(address.TestAddress, minerAddr, 4, types.ZeroAttoFIL, "getAsk", pdata)
+	msg = types.NewMessage(address.TestAddress, minerAddr, 4, types.ZeroAttoFIL, MethodGetAsk, pdata)
 	result, err = th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(4))
 	assert.NoError(t, err)
 
@@ -90,7 +90,7 @@ func TestAskFunctions(t *testing.T) {
 	assert.Equal(t, types.NewBlockHeight(203), ask2.Expiry)
 	assert.Equal(t, uint64(1), ask2.ID.Uint64())
 
-	msg = types.NewMessage(address.TestAddress, minerAddr, 5, types.ZeroAttoFIL, "getAsks", nil)
+	msg = types.NewMessage(address.TestAddress, minerAddr, 5, types.ZeroAttoFIL, MethodGetAsks, nil)
 	result, err = th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(4))
 	assert.NoError(t, err)
 	assert.NoError(t, result.ExecutionError)
@@ -119,7 +119,7 @@ func TestChangeWorker(t *testing.T) {
 
 		// change worker
 		pdata := actor.MustConvertParams(address.TestAddress2)
-		msg := types.NewMessage(address.TestAddress, minerAddr, 1, types.ZeroAttoFIL, "changeWorker", pdata)
+		msg := types.NewMessage(address.TestAddress, minerAddr, 1, types.ZeroAttoFIL, MethodChangeWorker, pdata)
 
 		_, err := th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(1))
 		assert.NoError(t, err)
@@ -143,7 +143,7 @@ func TestChangeWorker(t *testing.T) {
 		// change worker
 		pdata := actor.MustConvertParams(address.TestAddress2)
 		badActor := address.TestAddress2
-		msg := types.NewMessage(badActor, minerAddr, 1, types.ZeroAttoFIL, "changeWorker", pdata)
+		msg := types.NewMessage(badActor, minerAddr, 1, types.ZeroAttoFIL, MethodChangeWorker, pdata)
 
 		result, err := th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(1))
 		assert.NoError(t, err)
@@ -158,7 +158,7 @@ func TestChangeWorker(t *testing.T) {
 
 		// change worker
 		pdata := actor.MustConvertParams(address.TestAddress2)
-		msg := types.NewMessage(mockSigner.Addresses[0], minerAddr, 0, types.ZeroAttoFIL, "changeWorker", pdata)
+		msg := types.NewMessage(mockSigner.Addresses[0], minerAddr, 0, types.ZeroAttoFIL, MethodChangeWorker, pdata)
 
 		gasPrice, _ := types.NewAttoFILFromFILString(".00001")
 		gasLimit := types.NewGasUnits(10)
@@ -215,6 +215,59 @@ func TestGetOwner(t *testing.T) {
 	assert.Equal(t, address.TestAddress, addr)
 }
 
+func TestOwnerTransfer(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	st, vms := th.RequireCreateStorages(ctx, t)
+
+	t.Run("ownership changes once the new owner accepts", func(t *testing.T) {
+		minerAddr := th.CreateTestMiner(t, st, vms, address.TestAddress, th.RequireRandomPeerID(t))
+
+		res, err := th.CreateAndApplyTestMessage(t, st, vms, minerAddr, 0, 1, "proposeOwner", nil, address.TestAddress2)
+		require.NoError(t, err)
+		require.NoError(t, res.ExecutionError)
+
+		// owner is unchanged until the transfer is accepted
+		result := callQueryMethodSuccess("getOwner", ctx, t, st, vms, address.TestAddress, minerAddr)
+		assert.Equal(t, address.TestAddress, mustDeserializeAddress(t, result))
+		assert.Equal(t, address.TestAddress2, mustGetMinerState(st, vms, minerAddr).PendingOwner)
+
+		res, err = th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress2, minerAddr, 0, 2, "acceptOwner", nil)
+		require.NoError(t, err)
+		require.NoError(t, res.ExecutionError)
+
+		result = callQueryMethodSuccess("getOwner", ctx, t, st, vms, address.TestAddress, minerAddr)
+		assert.Equal(t, address.TestAddress2, mustDeserializeAddress(t, result))
+		assert.True(t, mustGetMinerState(st, vms, minerAddr).PendingOwner.Empty())
+	})
+
+	t.Run("only the owner can propose a transfer", func(t *testing.T) {
+		minerAddr := th.CreateTestMiner(t, st, vms, address.TestAddress, th.RequireRandomPeerID(t))
+
+		res, err := th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress2, minerAddr, 0, 1, "proposeOwner", nil, address.TestAddress2)
+		require.NoError(t, err)
+		assert.Equal(t, uint8(ErrCallerUnauthorized), res.Receipt.ExitCode)
+	})
+
+	t.Run("only the proposed owner can accept", func(t *testing.T) {
+		minerAddr := th.CreateTestMiner(t, st, vms, address.TestAddress, th.RequireRandomPeerID(t))
+
+		// nothing proposed yet
+		res, err := th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress2, minerAddr, 0, 1, "acceptOwner", nil)
+		require.NoError(t, err)
+		assert.Equal(t, uint8(ErrCallerUnauthorized), res.Receipt.ExitCode)
+
+		res, err = th.CreateAndApplyTestMessage(t, st, vms, minerAddr, 0, 1, "proposeOwner", nil, address.TestAddress2)
+		require.NoError(t, err)
+		require.NoError(t, res.ExecutionError)
+
+		res, err = th.CreateAndApplyTestMessage(t, st, vms, minerAddr, 0, 2, "acceptOwner", nil)
+		require.NoError(t, err)
+		assert.Equal(t, uint8(ErrCallerUnauthorized), res.Receipt.ExitCode)
+	})
+}
+
 func TestGetActiveCollateral(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -256,6 +309,43 @@ func TestGetActiveCollateral(t *testing.T) {
 	assert.Equal(t, MinimumCollateralPerSector, coll)
 }
 
+func TestWithdrawBalance(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	st, vms := th.RequireCreateStorages(ctx, t)
+
+	minerAddr := th.CreateTestMinerWith(types.NewAttoFILFromFIL(100), t, st, vms, address.TestAddress, th.RequireRandomPeerID(t), 0)
+
+	res, err := th.CreateAndApplyTestMessage(t, st, vms, minerAddr, 0, 3, "commitSector", nil, uint64(0), th.MakeCommitment(), th.MakeCommitment(), th.MakeCommitment(), th.MakeRandomBytes(types.TwoPoRepProofPartitions.ProofLen()))
+	require.NoError(t, err)
+	require.NoError(t, res.ExecutionError)
+
+	t.Run("only the owner may withdraw", func(t *testing.T) {
+		res, err := th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress2, minerAddr, 0, 4, "withdrawBalance", nil, types.NewAttoFILFromFIL(1))
+		require.NoError(t, err)
+		assert.Equal(t, uint8(ErrCallerUnauthorized), res.Receipt.ExitCode)
+	})
+
+	t.Run("pledge collateral cannot be withdrawn", func(t *testing.T) {
+		res, err := th.CreateAndApplyTestMessage(t, st, vms, minerAddr, 0, 4, "withdrawBalance", nil, types.NewAttoFILFromFIL(100))
+		require.NoError(t, err)
+		assert.Equal(t, uint8(ErrInsufficientFunds), res.Receipt.ExitCode)
+	})
+
+	t.Run("balance above the pledge requirement is sent to the owner", func(t *testing.T) {
+		ownerBalance := state.MustGetActor(st, address.TestAddress).Balance
+
+		res, err := th.CreateAndApplyTestMessage(t, st, vms, minerAddr, 0, 4, "withdrawBalance", nil, types.NewAttoFILFromFIL(99))
+		require.NoError(t, err)
+		require.NoError(t, res.ExecutionError)
+		require.Equal(t, uint8(0), res.Receipt.ExitCode)
+
+		assert.Equal(t, types.NewAttoFILFromFIL(1), state.MustGetActor(st, minerAddr).Balance)
+		assert.Equal(t, ownerBalance.Add(types.NewAttoFILFromFIL(99)), state.MustGetActor(st, address.TestAddress).Balance)
+	})
+}
+
 func TestCBOREncodeState(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -319,7 +409,7 @@ func TestPeerIdGetterAndSetter(t *testing.T) {
 			minerAddr,
 			th.RequireGetNonce(t, st, address.TestAddress2),
 			types.NewAttoFILFromFIL(0),
-			"updatePeerID",
+			MethodUpdatePeerID,
 			actor.MustConvertParams(th.RequireRandomPeerID(t)))
 
 		applyMsgResult, err := th.ApplyTestMessage(st, vms, updatePeerIdMsg, types.NewBlockHeight(0))
@@ -422,7 +512,7 @@ func updatePeerIdSuccess(t *testing.T, st state.Tree, vms vm.StorageMap, fromAdd
 		minerAddr,
 		th.RequireGetNonce(t, st, fromAddr),
 		types.NewAttoFILFromFIL(0),
-		"updatePeerID",
+		MethodUpdatePeerID,
 		actor.MustConvertParams(newPid))
 
 	applyMsgResult, err := th.ApplyTestMessage(st, vms, updatePeerIdMsg, types.NewBlockHeight(0))
@@ -739,10 +829,62 @@ func TestMinerSubmitPoStPowerUpdates(t *testing.T) {
 	})
 }
 
+func TestMinerTerminateSectors(t *testing.T) {
+	tf.UnitTest(t)
+
+	firstCommitBlockHeight := uint64(3)
+
+	t.Run("terminating sectors removes power, commitments and collateral", func(t *testing.T) {
+		mal := setupMinerActorLiason(t)
+
+		mal.requireCommit(firstCommitBlockHeight, uint64(1))
+		mal.requireCommit(firstCommitBlockHeight+1, uint64(2))
+		mal.requireCommit(firstCommitBlockHeight+2, uint64(3))
+		mal.requirePoSt(firstCommitBlockHeight+5, types.EmptyIntSet(), types.EmptyFaultSet())
+		require.Equal(t, types.OneKiBSectorSize, mal.requirePower(firstCommitBlockHeight+5))
+
+		res, err := th.CreateAndApplyTestMessage(t, mal.st, mal.vms, mal.minerAddr, 0, firstCommitBlockHeight+6, "terminateSectors", mal.ancestors, types.NewIntSet(1, 2))
+		require.NoError(t, err)
+		require.NoError(t, res.ExecutionError)
+		require.Equal(t, uint8(0), res.Receipt.ExitCode)
+
+		assert.Equal(t, types.NewBytesAmount(0), mal.requirePower(firstCommitBlockHeight+7))
+		assert.Equal(t, types.NewBytesAmount(0), mal.requireTotalStorage(firstCommitBlockHeight+7))
+
+		state := mal.requireReadState()
+		assert.False(t, state.SectorCommitments.Has(uint64(1)))
+		assert.False(t, state.SectorCommitments.Has(uint64(2)))
+		assert.True(t, state.SectorCommitments.Has(uint64(3)))
+		assert.Equal(t, []uint64{3}, state.ProvingSet.Values())
+		assert.Equal(t, CollateralForSector(types.OneKiBSectorSize), state.ActiveCollateral)
+	})
+
+	t.Run("terminating an uncommitted sector fails", func(t *testing.T) {
+		mal := setupMinerActorLiason(t)
+		mal.requireCommit(firstCommitBlockHeight, uint64(1))
+
+		res, err := th.CreateAndApplyTestMessage(t, mal.st, mal.vms, mal.minerAddr, 0, firstCommitBlockHeight+1, "terminateSectors", mal.ancestors, types.NewIntSet(1, 7))
+		require.NoError(t, err)
+		assert.Error(t, res.ExecutionError)
+		assert.Equal(t, uint8(ErrInvalidSector), res.Receipt.ExitCode)
+		assert.True(t, mal.requireReadState().SectorCommitments.Has(uint64(1)))
+	})
+
+	t.Run("only the owner or worker may terminate sectors", func(t *testing.T) {
+		mal := setupMinerActorLiason(t)
+		mal.requireCommit(firstCommitBlockHeight, uint64(1))
+
+		res, err := th.CreateAndApplyTestMessageFrom(t, mal.st, mal.vms, address.TestAddress2, mal.minerAddr, 0, firstCommitBlockHeight+1, "terminateSectors", mal.ancestors, types.NewIntSet(1))
+		require.NoError(t, err)
+		assert.Error(t, res.ExecutionError)
+		assert.Equal(t, uint8(ErrCallerUnauthorized), res.Receipt.ExitCode)
+	})
+}
+
 func TestMinerSubmitPoStVerification(t *testing.T) {
 	tf.UnitTest(t)
 
-	message := types.NewMessage(address.TestAddress, address.TestAddress2, 0, types.ZeroAttoFIL, "submitPoSt", nil)
+	message := types.NewMessage(address.TestAddress, address.TestAddress2, 0, types.ZeroAttoFIL, MethodSubmitPoSt, nil)
 	comm1 := th.MakeCommitments()
 	comm2 := th.MakeCommitments()
 	comm3 := th.MakeCommitments()
@@ -1106,7 +1248,7 @@ func TestMinerSubmitPoSt(t *testing.T) {
 	t.Run("computes seed randomness at correct chain height when post is on time", func(t *testing.T) {
 		var actualSampleHeight *types.BlockHeight
 
-		message := types.NewMessage(address.TestAddress, address.TestAddress2, 0, types.ZeroAttoFIL, "submitPoSt", []byte{})
+		message := types.NewMessage(address.TestAddress, address.TestAddress2, 0, types.ZeroAttoFIL, MethodSubmitPoSt, []byte{})
 
 		minerState := *NewState(address.TestAddress, address.TestAddress, peer.ID(""), types.OneKiBSectorSize)
 		minerState.ProvingPeriodEnd = types.NewBlockHeight(secondProvingPeriodEnd)
@@ -1135,7 +1277,7 @@ func TestMinerSubmitPoSt(t *testing.T) {
 	t.Run("computes seed randomness at correct chain height when post is late", func(t *testing.T) {
 		var actualSampleHeight *types.BlockHeight
 
-		message := types.NewMessage(address.TestAddress, address.TestAddress2, 0, types.ZeroAttoFIL, "submitPoSt", []byte{})
+		message := types.NewMessage(address.TestAddress, address.TestAddress2, 0, types.ZeroAttoFIL, MethodSubmitPoSt, []byte{})
 
 		minerState := *NewState(address.TestAddress, address.TestAddress, peer.ID(""), types.OneKiBSectorSize)
 		minerState.ProvingPeriodEnd = types.NewBlockHeight(secondProvingPeriodEnd)
@@ -1162,7 +1304,7 @@ func TestMinerSubmitPoSt(t *testing.T) {
 	})
 
 	t.Run("provides informative error when PoSt attempts to sample chain height before it is ready", func(t *testing.T) {
-		message := types.NewMessage(address.TestAddress, address.TestAddress2, 0, types.ZeroAttoFIL, "submitPoSt", []byte{})
+		message := types.NewMessage(address.TestAddress, address.TestAddress2, 0, types.ZeroAttoFIL, MethodSubmitPoSt, []byte{})
 
 		minerState := *NewState(address.TestAddress, address.TestAddress, peer.ID(""), types.OneKiBSectorSize)
 		minerState.ProvingPeriodEnd = types.NewBlockHeight(secondProvingPeriodEnd)
@@ -1237,7 +1379,7 @@ func TestActorSlashStorageFault(t *testing.T) {
 		mockSigner, _ := types.NewMockSignersAndKeyInfo(1)
 
 		// change worker
-		msg := types.NewMessage(mockSigner.Addresses[0], minerAddr, 0, types.ZeroAttoFIL, "slashStorageFault", []byte{})
+		msg := types.NewMessage(mockSigner.Addresses[0], minerAddr, 0, types.ZeroAttoFIL, MethodSlashStorageFault, []byte{})
 
 		gasPrice, _ := types.NewAttoFILFromFILString(".00001")
 		gasLimit := types.NewGasUnits(10)
@@ -1342,7 +1484,7 @@ func TestVerifyPIP(t *testing.T) {
 
 	t.Run("PIP is invalid if miner hasn't committed sector", func(t *testing.T) {
 		vmctx, verifier, minerActor := (&minerEnvBuilder{
-			message:   "verifyPieceInclusion",
+			message:   MethodVerifyPieceInclusion,
 			sectorSet: NewSectorSet(),
 		}).build()
 
@@ -1356,7 +1498,7 @@ func TestVerifyPIP(t *testing.T) {
 
 	t.Run("PIP is invalid if miner isn't proving anything", func(t *testing.T) {
 		msgParams := actor.MustConvertParams(commP, pieceSize, firstSectorID, pip)
-		message := types.NewMessage(address.TestAddress, address.TestAddress2, 0, types.ZeroAttoFIL, "verifyPieceInclusion", msgParams)
+		message := types.NewMessage(address.TestAddress, address.TestAddress2, 0, types.ZeroAttoFIL, MethodVerifyPieceInclusion, msgParams)
 
 		comm1 := th.MakeCommitments()
 		comm2 := th.MakeCommitments()
@@ -1379,7 +1521,7 @@ func TestVerifyPIP(t *testing.T) {
 
 	t.Run("PIP is invalid if miner is tardy/slashable", func(t *testing.T) {
 		msgParams := actor.MustConvertParams(commP, pieceSize, firstSectorID, pip)
-		message := types.NewMessage(address.TestAddress, address.TestAddress2, 0, types.ZeroAttoFIL, "verifyPieceInclusion", msgParams)
+		message := types.NewMessage(address.TestAddress, address.TestAddress2, 0, types.ZeroAttoFIL, MethodVerifyPieceInclusion, msgParams)
 
 		comm1 := th.MakeCommitments()
 		comm2 := th.MakeCommitments()
@@ -1409,7 +1551,7 @@ func TestVerifyPIP(t *testing.T) {
 
 	t.Run("verifier errors are propagated to caller", func(t *testing.T) {
 		vmctx, verifier, minerActor := (&minerEnvBuilder{
-			message:          "verifyPieceInclusion",
+			message:          MethodVerifyPieceInclusion,
 			sectorSet:        sectorSetWithOneCommitment,
 			provingPeriodEnd: types.NewBlockHeight(0),
 			verifier: &verification.FakeVerifier{
@@ -1432,7 +1574,7 @@ func TestVerifyPIP(t *testing.T) {
 
 	t.Run("verifier rejecting the proof produces an error, too", func(t *testing.T) {
 		vmctx, verifier, minerActor := (&minerEnvBuilder{
-			message:          "verifyPieceInclusion",
+			message:          MethodVerifyPieceInclusion,
 			sectorSet:        sectorSetWithOneCommitment,
 			provingPeriodEnd: types.NewBlockHeight(0),
 			verifier: &verification.FakeVerifier{
@@ -1455,7 +1597,7 @@ func TestVerifyPIP(t *testing.T) {
 
 	t.Run("PIP is valid if the miner is currently active and has the sector committed", func(t *testing.T) {
 		vmctx, verifier, minerActor := (&minerEnvBuilder{
-			message:          "verifyPieceInclusion",
+			message:          MethodVerifyPieceInclusion,
 			sectorSet:        sectorSetWithOneCommitment,
 			provingPeriodEnd: types.NewBlockHeight(0),
 			verifier: &verification.FakeVerifier{
@@ -1568,7 +1710,7 @@ func TestMinerGetPoStState(t *testing.T) {
 func TestGetProvingSetCommitments(t *testing.T) {
 	tf.UnitTest(t)
 
-	message := types.NewMessage(address.TestAddress, address.TestAddress2, 0, types.ZeroAttoFIL, "getProvingSetCommitments", nil)
+	message := types.NewMessage(address.TestAddress, address.TestAddress2, 0, types.ZeroAttoFIL, MethodGetProvingSetCommitments, nil)
 	comm1 := th.MakeCommitments()
 	comm2 := th.MakeCommitments()
 	comm3 := th.MakeCommitments()
@@ -1645,7 +1787,7 @@ func mustGetMinerState(st state.Tree, vms vm.StorageMap, a address.Address) *Sta
 
 type minerEnvBuilder struct {
 	provingPeriodEnd *types.BlockHeight
-	message          string
+	message          types.MethodID
 	sectorSet        SectorSet
 	sectorSize       *types.BytesAmount
 	verifier         *verification.FakeVerifier
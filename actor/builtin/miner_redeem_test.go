@@ -2,6 +2,7 @@ package builtin_test
 
 import (
 	"context"
+	"math/big"
 	"strconv"
 	"testing"
 
@@ -84,7 +85,7 @@ func TestVerifyPieceInclusionInRedeem(t *testing.T) {
 	}
 
 	makeAndSignVoucher := func(condition *types.Predicate) []byte {
-		sig, err := paymentbroker.SignVoucher(channelID, amt, defaultValidAt, payer, condition, mockSigner)
+		sig, err := paymentbroker.SignVoucher(channelID, 0, 0, amt, defaultValidAt, nil, payer, condition, mockSigner)
 		require.NoError(t, err)
 		signature := ([]byte)(sig)
 
@@ -93,8 +94,8 @@ func TestVerifyPieceInclusionInRedeem(t *testing.T) {
 
 	makeRedeemMsg := func(condition *types.Predicate, sectorID uint64, pip []byte, signature []byte) *types.Message {
 		suppliedParams := []interface{}{sectorID, pip}
-		pdata := abi.MustConvertParams(payer, channelID, amt, types.NewBlockHeight(0), condition, signature, suppliedParams)
-		return types.NewMessage(target, address.PaymentBrokerAddress, 0, types.NewAttoFILFromFIL(0), "redeem", pdata)
+		pdata := abi.MustConvertParams(payer, channelID, big.NewInt(0), big.NewInt(0), amt, types.NewBlockHeight(0), []types.Merge{}, condition, signature, suppliedParams)
+		return types.NewMessage(target, address.PaymentBrokerAddress, 0, types.NewAttoFILFromFIL(0), paymentbroker.MethodRedeem, pdata)
 	}
 
 	t.Run("Voucher with piece inclusion condition and correct proof succeeds", func(t *testing.T) {
@@ -221,8 +222,8 @@ func requireGenesis(ctx context.Context, t *testing.T, targetAddresses ...addres
 }
 
 func establishChannel(st state.Tree, vms vm.StorageMap, from address.Address, target address.Address, nonce uint64, amt types.AttoFIL, eol *types.BlockHeight) *types.ChannelID {
-	pdata := abi.MustConvertParams(target, eol)
-	msg := types.NewMessage(from, address.PaymentBrokerAddress, nonce, amt, "createChannel", pdata)
+	pdata := abi.MustConvertParams(target, eol, types.NewBlockHeight(paymentbroker.DefaultSettlePeriod))
+	msg := types.NewMessage(from, address.PaymentBrokerAddress, nonce, amt, paymentbroker.MethodCreateChannel, pdata)
 	result, err := th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(0))
 	if err != nil {
 		panic(err)
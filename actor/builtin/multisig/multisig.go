@@ -0,0 +1,599 @@
+package multisig
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	xerrors "github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/abi"
+	"github.com/filecoin-project/go-filecoin/actor"
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/exec"
+	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/vm/errors"
+)
+
+const (
+	// ErrNotSigner indicates the caller is not one of the wallet's signers.
+	ErrNotSigner = 33
+	// ErrUnknownTransaction indicates no pending transaction exists with the given id.
+	ErrUnknownTransaction = 34
+	// ErrAlreadyApproved indicates the caller has already approved the transaction.
+	ErrAlreadyApproved = 35
+	// ErrInvalidRequirement indicates a threshold of zero or above the number of signers.
+	ErrInvalidRequirement = 36
+	// ErrFundsLocked indicates the transaction would spend funds that have not yet vested.
+	ErrFundsLocked = 37
+	// ErrCallerUnauthorized signals an unauthorized caller.
+	ErrCallerUnauthorized = 38
+	// ErrAlreadySigner indicates an attempt to add an address that is already a signer.
+	ErrAlreadySigner = 39
+	// ErrUnknownMethod indicates a proposed call to the wallet itself names a method it does not export.
+	ErrUnknownMethod = 40
+)
+
+// Errors map error codes to revert errors this actor may return.
+var Errors = map[uint8]error{
+	ErrNotSigner:          errors.NewCodedRevertErrorf(ErrNotSigner, "caller is not a signer"),
+	ErrUnknownTransaction: errors.NewCodedRevertErrorf(ErrUnknownTransaction, "transaction not found"),
+	ErrAlreadyApproved:    errors.NewCodedRevertErrorf(ErrAlreadyApproved, "transaction already approved by caller"),
+	ErrInvalidRequirement: errors.NewCodedRevertErrorf(ErrInvalidRequirement, "required approvals must be between 1 and the number of signers"),
+	ErrFundsLocked:        errors.NewCodedRevertErrorf(ErrFundsLocked, "insufficient unlocked funds"),
+	ErrCallerUnauthorized: errors.NewCodedRevertErrorf(ErrCallerUnauthorized, "not authorized to call the method"),
+	ErrAlreadySigner:      errors.NewCodedRevertErrorf(ErrAlreadySigner, "address is already a signer"),
+	ErrUnknownMethod:      errors.NewCodedRevertErrorf(ErrUnknownMethod, "wallet does not export proposed method"),
+}
+
+func init() {
+	cbor.RegisterCborType(State{})
+	cbor.RegisterCborType(Transaction{})
+}
+
+// Actor is the builtin multisig wallet actor. It holds funds that can only
+// be spent once a proposed transaction has been approved by the required
+// number of signers.
+type Actor struct{}
+
+// Transaction is a pending call from the wallet awaiting approvals.
+type Transaction struct {
+	// ID is the wallet-local identifier of the transaction.
+	ID uint64 `json:"id"`
+
+	// To is the recipient of the call.
+	To address.Address `json:"to"`
+
+	// Value is the amount of FIL sent with the call.
+	Value types.AttoFIL `json:"value"`
+
+	// Method is the method invoked on To. It is empty for plain transfers.
+	Method string `json:"method"`
+
+	// Params are the parameters passed to Method.
+	Params []interface{} `json:"params"`
+
+	// Approved is the list of signers that have approved the transaction,
+	// starting with the proposer.
+	Approved []address.Address `json:"approved"`
+}
+
+// State is the multisig actor's storage.
+type State struct {
+	// Signers is the set of addresses allowed to propose and approve transactions.
+	Signers []address.Address
+
+	// Required is the number of approvals needed to execute a transaction.
+	Required uint64
+
+	// NextTxID is the id that will be assigned to the next proposal.
+	NextTxID uint64
+
+	// InitialBalance is the amount subject to vesting when the wallet was created.
+	InitialBalance types.AttoFIL
+
+	// StartingBlock is the height at which vesting began.
+	StartingBlock *types.BlockHeight
+
+	// UnlockDuration is the number of blocks over which InitialBalance vests
+	// linearly. A zero duration means the funds are never locked.
+	UnlockDuration *types.BlockHeight
+
+	// Transactions holds pending transactions keyed by stringified id.
+	Transactions map[string]*Transaction
+}
+
+// NewActor returns a new multisig actor.
+func NewActor() *actor.Actor {
+	return actor.NewActor(types.MultisigActorCodeCid, types.ZeroAttoFIL)
+}
+
+// NewState creates a multisig state struct. It validates the requirement
+// against the signer set so that wallets can never be created unspendable.
+func NewState(signers []address.Address, required uint64, initialBalance types.AttoFIL, start, unlockDuration *types.BlockHeight) (*State, error) {
+	if required == 0 || required > uint64(len(signers)) {
+		return nil, Errors[ErrInvalidRequirement]
+	}
+
+	return &State{
+		Signers:        signers,
+		Required:       required,
+		InitialBalance: initialBalance,
+		StartingBlock:  start,
+		UnlockDuration: unlockDuration,
+		Transactions:   map[string]*Transaction{},
+	}, nil
+}
+
+// InitializeState stores the wallet's initial data structure.
+func (msa *Actor) InitializeState(storage exec.Storage, initializerData interface{}) error {
+	msState, ok := initializerData.(*State)
+	if !ok {
+		return errors.NewFaultError("Initial state to multisig actor is not a State struct")
+	}
+
+	stateBytes, err := cbor.DumpObject(msState)
+	if err != nil {
+		return xerrors.Wrap(err, "failed to cbor marshal object")
+	}
+
+	id, err := storage.Put(stateBytes)
+	if err != nil {
+		return err
+	}
+
+	return storage.Commit(id, cid.Undef)
+}
+
+var _ exec.ExecutableActor = (*Actor)(nil)
+
+// Method ids of the multisig wallet exports.
+const (
+	MethodPropose           = types.MethodID(1)
+	MethodApprove           = types.MethodID(2)
+	MethodCancel            = types.MethodID(3)
+	MethodAddSigner         = types.MethodID(4)
+	MethodRemoveSigner      = types.MethodID(5)
+	MethodChangeRequirement = types.MethodID(6)
+	MethodGetSigners        = types.MethodID(7)
+	MethodGetLockedBalance  = types.MethodID(8)
+	MethodGetState          = types.MethodID(9)
+)
+
+var multisigExports = exec.Exports{
+	MethodPropose: &exec.FunctionSignature{
+		Name:   "propose",
+		Params: []abi.Type{abi.Address, abi.AttoFIL, abi.String, abi.Parameters},
+		Return: []abi.Type{abi.Integer},
+	},
+	MethodApprove: &exec.FunctionSignature{
+		Name:   "approve",
+		Params: []abi.Type{abi.Integer},
+		Return: []abi.Type{},
+	},
+	MethodCancel: &exec.FunctionSignature{
+		Name:   "cancel",
+		Params: []abi.Type{abi.Integer},
+		Return: []abi.Type{},
+	},
+	// The following methods may only be invoked by the wallet itself, i.e. by
+	// proposing and approving a transaction addressed to the wallet.
+	MethodAddSigner: &exec.FunctionSignature{
+		Name:   "addSigner",
+		Params: []abi.Type{abi.Address, abi.Boolean},
+		Return: []abi.Type{},
+	},
+	MethodRemoveSigner: &exec.FunctionSignature{
+		Name:   "removeSigner",
+		Params: []abi.Type{abi.Address, abi.Boolean},
+		Return: []abi.Type{},
+	},
+	MethodChangeRequirement: &exec.FunctionSignature{
+		Name:   "changeRequirement",
+		Params: []abi.Type{abi.Integer},
+		Return: []abi.Type{},
+	},
+	MethodGetSigners: &exec.FunctionSignature{
+		Name:   "getSigners",
+		Params: nil,
+		Return: []abi.Type{abi.Addresses},
+	},
+	MethodGetLockedBalance: &exec.FunctionSignature{
+		Name:   "getLockedBalance",
+		Params: nil,
+		Return: []abi.Type{abi.AttoFIL},
+	},
+	MethodGetState: &exec.FunctionSignature{
+		Name:   "getState",
+		Params: nil,
+		Return: []abi.Type{abi.Bytes},
+	},
+}
+
+// Exports returns the multisig actor's exported functions.
+func (msa *Actor) Exports() exec.Exports {
+	return multisigExports
+}
+
+//
+// Exported actor methods
+//
+
+// Propose creates a pending transaction and records the caller's approval.
+// If the caller's approval is sufficient the transaction executes immediately.
+// It returns the id of the new transaction.
+func (msa *Actor) Propose(ctx exec.VMContext, to address.Address, value types.AttoFIL, method string, params []interface{}) (*big.Int, uint8, error) {
+	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
+		return nil, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	out, err := actor.WithState(ctx, &state, func() (interface{}, error) {
+		caller := ctx.Message().From
+		if !isSigner(state.Signers, caller) {
+			return nil, Errors[ErrNotSigner]
+		}
+
+		tx := &Transaction{
+			ID:       state.NextTxID,
+			To:       to,
+			Value:    value,
+			Method:   method,
+			Params:   params,
+			Approved: []address.Address{caller},
+		}
+		state.NextTxID++
+
+		if err := msa.approveAndMaybeExecute(ctx, &state, tx); err != nil {
+			return nil, err
+		}
+
+		return big.NewInt(0).SetUint64(tx.ID), nil
+	})
+	if err != nil {
+		return nil, errors.CodeError(err), err
+	}
+
+	txID, ok := out.(*big.Int)
+	if !ok {
+		return nil, 1, errors.NewFaultErrorf("expected an Integer return value from call, but got %T instead", out)
+	}
+
+	return txID, 0, nil
+}
+
+// Approve adds the caller's approval to a pending transaction, executing it
+// once the required number of approvals has been reached.
+func (msa *Actor) Approve(ctx exec.VMContext, txID *big.Int) (uint8, error) {
+	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
+		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	_, err := actor.WithState(ctx, &state, func() (interface{}, error) {
+		caller := ctx.Message().From
+		if !isSigner(state.Signers, caller) {
+			return nil, Errors[ErrNotSigner]
+		}
+
+		tx, ok := state.Transactions[txKey(txID.Uint64())]
+		if !ok {
+			return nil, Errors[ErrUnknownTransaction]
+		}
+
+		if isSigner(tx.Approved, caller) {
+			return nil, Errors[ErrAlreadyApproved]
+		}
+		tx.Approved = append(tx.Approved, caller)
+
+		return nil, msa.approveAndMaybeExecute(ctx, &state, tx)
+	})
+	if err != nil {
+		return errors.CodeError(err), err
+	}
+
+	return 0, nil
+}
+
+// Cancel removes a pending transaction. Only the signer that proposed the
+// transaction may cancel it.
+func (msa *Actor) Cancel(ctx exec.VMContext, txID *big.Int) (uint8, error) {
+	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
+		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	_, err := actor.WithState(ctx, &state, func() (interface{}, error) {
+		key := txKey(txID.Uint64())
+		tx, ok := state.Transactions[key]
+		if !ok {
+			return nil, Errors[ErrUnknownTransaction]
+		}
+
+		if tx.Approved[0] != ctx.Message().From {
+			return nil, Errors[ErrCallerUnauthorized]
+		}
+
+		delete(state.Transactions, key)
+		return nil, nil
+	})
+	if err != nil {
+		return errors.CodeError(err), err
+	}
+
+	return 0, nil
+}
+
+// AddSigner adds a signer to the wallet, optionally increasing the number
+// of required approvals by one. It may only be called by the wallet itself.
+func (msa *Actor) AddSigner(ctx exec.VMContext, signer address.Address, increase bool) (uint8, error) {
+	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
+		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	_, err := actor.WithState(ctx, &state, func() (interface{}, error) {
+		if ctx.Message().From != ctx.Message().To {
+			return nil, Errors[ErrCallerUnauthorized]
+		}
+		return nil, addSigner(&state, signer, increase)
+	})
+	if err != nil {
+		return errors.CodeError(err), err
+	}
+
+	return 0, nil
+}
+
+// RemoveSigner removes a signer from the wallet, optionally decreasing the
+// number of required approvals by one. It may only be called by the wallet itself.
+func (msa *Actor) RemoveSigner(ctx exec.VMContext, signer address.Address, decrease bool) (uint8, error) {
+	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
+		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	_, err := actor.WithState(ctx, &state, func() (interface{}, error) {
+		if ctx.Message().From != ctx.Message().To {
+			return nil, Errors[ErrCallerUnauthorized]
+		}
+		return nil, removeSigner(&state, signer, decrease)
+	})
+	if err != nil {
+		return errors.CodeError(err), err
+	}
+
+	return 0, nil
+}
+
+// ChangeRequirement sets the number of approvals needed to execute a
+// transaction. It may only be called by the wallet itself.
+func (msa *Actor) ChangeRequirement(ctx exec.VMContext, required *big.Int) (uint8, error) {
+	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
+		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	_, err := actor.WithState(ctx, &state, func() (interface{}, error) {
+		if ctx.Message().From != ctx.Message().To {
+			return nil, Errors[ErrCallerUnauthorized]
+		}
+		return nil, changeRequirement(&state, required)
+	})
+	if err != nil {
+		return errors.CodeError(err), err
+	}
+
+	return 0, nil
+}
+
+// GetSigners returns the wallet's current signers.
+func (msa *Actor) GetSigners(ctx exec.VMContext) ([]address.Address, uint8, error) {
+	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
+		return nil, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	err := actor.ReadState(ctx, &state)
+	if err != nil {
+		return nil, errors.CodeError(err), err
+	}
+
+	return state.Signers, 0, nil
+}
+
+// GetLockedBalance returns the portion of the initial balance that has not
+// yet vested at the current block height.
+func (msa *Actor) GetLockedBalance(ctx exec.VMContext) (types.AttoFIL, uint8, error) {
+	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
+		return types.ZeroAttoFIL, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	err := actor.ReadState(ctx, &state)
+	if err != nil {
+		return types.ZeroAttoFIL, errors.CodeError(err), err
+	}
+
+	return LockedBalance(&state, ctx.BlockHeight()), 0, nil
+}
+
+// GetState returns the cbor encoded state of the wallet, including its
+// pending transactions.
+func (msa *Actor) GetState(ctx exec.VMContext) ([]byte, uint8, error) {
+	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
+		return nil, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	err := actor.ReadState(ctx, &state)
+	if err != nil {
+		return nil, errors.CodeError(err), err
+	}
+
+	stateBytes, err := actor.MarshalStorage(state)
+	if err != nil {
+		return nil, 1, errors.FaultErrorWrap(err, "Error marshalling state")
+	}
+
+	return stateBytes, 0, nil
+}
+
+//
+// Un-exported methods
+//
+
+// approveAndMaybeExecute stores the transaction if it lacks approvals or
+// executes and removes it once the threshold has been reached. Approvals of
+// signers that have since been removed do not count.
+func (msa *Actor) approveAndMaybeExecute(ctx exec.VMContext, state *State, tx *Transaction) error {
+	key := txKey(tx.ID)
+	if approvals(state, tx) < state.Required {
+		state.Transactions[key] = tx
+		return nil
+	}
+
+	delete(state.Transactions, key)
+
+	locked := LockedBalance(state, ctx.BlockHeight())
+	if ctx.MyBalance().Sub(tx.Value).LessThan(locked) {
+		return Errors[ErrFundsLocked]
+	}
+
+	// Calls addressed to the wallet itself cannot go through Send, so they
+	// are applied directly to the state loaded by the caller.
+	if tx.To == ctx.Message().To {
+		return msa.applySelfCall(state, tx)
+	}
+
+	_, _, err := ctx.Send(tx.To, tx.Method, tx.Value, tx.Params)
+	return err
+}
+
+// applySelfCall decodes the parameters of a transaction addressed to the
+// wallet and applies the corresponding state change.
+func (msa *Actor) applySelfCall(state *State, tx *Transaction) error {
+	method, signature, ok := multisigExports.Lookup(tx.Method)
+	if !ok || signature == nil {
+		return Errors[ErrUnknownMethod]
+	}
+
+	encoded, err := abi.ToEncodedValues(tx.Params...)
+	if err != nil {
+		return errors.RevertErrorWrap(err, "invalid params")
+	}
+	vals, err := abi.DecodeValues(encoded, signature.Params)
+	if err != nil {
+		return errors.RevertErrorWrap(err, "invalid params")
+	}
+
+	switch method {
+	case MethodAddSigner:
+		return addSigner(state, vals[0].Val.(address.Address), vals[1].Val.(bool))
+	case MethodRemoveSigner:
+		return removeSigner(state, vals[0].Val.(address.Address), vals[1].Val.(bool))
+	case MethodChangeRequirement:
+		return changeRequirement(state, vals[0].Val.(*big.Int))
+	default:
+		return Errors[ErrUnknownMethod]
+	}
+}
+
+//
+// Exported free functions.
+//
+
+// LockedBalance returns the amount of the initial balance that is still
+// locked at the given height under linear vesting.
+func LockedBalance(state *State, height *types.BlockHeight) types.AttoFIL {
+	if state.UnlockDuration == nil || state.UnlockDuration.Equal(types.NewBlockHeight(0)) {
+		return types.ZeroAttoFIL
+	}
+
+	elapsed := height.Sub(state.StartingBlock)
+	if elapsed.GreaterEqual(state.UnlockDuration) {
+		return types.ZeroAttoFIL
+	}
+
+	// locked = initial * (duration - elapsed) / duration
+	var locked big.Int
+	locked.Mul(state.InitialBalance.AsBigInt(), state.UnlockDuration.Sub(elapsed).AsBigInt())
+	locked.Div(&locked, state.UnlockDuration.AsBigInt())
+	return types.NewAttoFIL(&locked)
+}
+
+//
+// Internal functions
+//
+
+func addSigner(state *State, signer address.Address, increase bool) error {
+	if isSigner(state.Signers, signer) {
+		return Errors[ErrAlreadySigner]
+	}
+
+	state.Signers = append(state.Signers, signer)
+	if increase {
+		state.Required++
+	}
+
+	return nil
+}
+
+func removeSigner(state *State, signer address.Address, decrease bool) error {
+	var signers []address.Address
+	for _, s := range state.Signers {
+		if s != signer {
+			signers = append(signers, s)
+		}
+	}
+	if len(signers) == len(state.Signers) {
+		return Errors[ErrNotSigner]
+	}
+
+	required := state.Required
+	if decrease {
+		required--
+	}
+	if required == 0 || required > uint64(len(signers)) {
+		return Errors[ErrInvalidRequirement]
+	}
+
+	state.Signers = signers
+	state.Required = required
+
+	return nil
+}
+
+func changeRequirement(state *State, required *big.Int) error {
+	if !required.IsUint64() || required.Uint64() == 0 || required.Uint64() > uint64(len(state.Signers)) {
+		return Errors[ErrInvalidRequirement]
+	}
+
+	state.Required = required.Uint64()
+	return nil
+}
+
+// approvals returns the number of current signers that approved tx.
+func approvals(state *State, tx *Transaction) uint64 {
+	var count uint64
+	for _, approver := range tx.Approved {
+		if isSigner(state.Signers, approver) {
+			count++
+		}
+	}
+	return count
+}
+
+func isSigner(signers []address.Address, addr address.Address) bool {
+	for _, s := range signers {
+		if s == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func txKey(id uint64) string {
+	return strconv.FormatUint(id, 10)
+}
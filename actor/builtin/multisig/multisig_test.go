@@ -0,0 +1,202 @@
+package multisig_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	. "github.com/filecoin-project/go-filecoin/actor/builtin/multisig"
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/state"
+	th "github.com/filecoin-project/go-filecoin/testhelpers"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/vm"
+)
+
+func TestMultisigTransferRequiresApprovals(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	st, vms := th.RequireCreateStorages(ctx, t)
+	signers := []address.Address{address.TestAddress, address.TestAddress2}
+	msAddr := requireCreateMultisig(t, st, vms, signers, 2, 100, 0)
+
+	recipient := address.NewForTestGetter()()
+
+	result, err := th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress, msAddr, 0, 0, "propose", nil, recipient, types.NewAttoFILFromFIL(40), "", []interface{}{})
+	require.NoError(t, err)
+	require.NoError(t, result.ExecutionError)
+	txID := big.NewInt(0).SetBytes(result.Receipt.Return[0])
+	assert.Equal(t, uint64(0), txID.Uint64())
+
+	// not yet executed
+	msState := requireMultisigState(t, st, vms, msAddr)
+	require.Len(t, msState.Transactions, 1)
+	assert.Equal(t, types.NewAttoFILFromFIL(100), state.MustGetActor(st, msAddr).Balance)
+
+	// the proposer cannot approve twice
+	result, err = th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress, msAddr, 0, 0, "approve", nil, txID)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(ErrAlreadyApproved), result.Receipt.ExitCode)
+
+	result, err = th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress2, msAddr, 0, 0, "approve", nil, txID)
+	require.NoError(t, err)
+	require.NoError(t, result.ExecutionError)
+
+	msState = requireMultisigState(t, st, vms, msAddr)
+	assert.Len(t, msState.Transactions, 0)
+	assert.Equal(t, types.NewAttoFILFromFIL(60), state.MustGetActor(st, msAddr).Balance)
+	assert.Equal(t, types.NewAttoFILFromFIL(40), state.MustGetActor(st, recipient).Balance)
+}
+
+func TestMultisigRejectsNonSigners(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	st, vms := th.RequireCreateStorages(ctx, t)
+	msAddr := requireCreateMultisig(t, st, vms, []address.Address{address.TestAddress}, 1, 100, 0)
+
+	result, err := th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress2, msAddr, 0, 0, "propose", nil, address.TestAddress2, types.NewAttoFILFromFIL(1), "", []interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, uint8(ErrNotSigner), result.Receipt.ExitCode)
+}
+
+func TestMultisigCancel(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	st, vms := th.RequireCreateStorages(ctx, t)
+	signers := []address.Address{address.TestAddress, address.TestAddress2}
+	msAddr := requireCreateMultisig(t, st, vms, signers, 2, 100, 0)
+
+	result, err := th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress, msAddr, 0, 0, "propose", nil, address.TestAddress, types.NewAttoFILFromFIL(1), "", []interface{}{})
+	require.NoError(t, err)
+	require.NoError(t, result.ExecutionError)
+	txID := big.NewInt(0).SetBytes(result.Receipt.Return[0])
+
+	// only the proposer may cancel
+	result, err = th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress2, msAddr, 0, 0, "cancel", nil, txID)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(ErrCallerUnauthorized), result.Receipt.ExitCode)
+
+	result, err = th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress, msAddr, 0, 0, "cancel", nil, txID)
+	require.NoError(t, err)
+	require.NoError(t, result.ExecutionError)
+
+	assert.Len(t, requireMultisigState(t, st, vms, msAddr).Transactions, 0)
+}
+
+func TestMultisigChangeSigners(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	st, vms := th.RequireCreateStorages(ctx, t)
+	msAddr := requireCreateMultisig(t, st, vms, []address.Address{address.TestAddress}, 1, 100, 0)
+
+	t.Run("direct calls are rejected", func(t *testing.T) {
+		result, err := th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress, msAddr, 0, 0, "addSigner", nil, address.TestAddress2, true)
+		require.NoError(t, err)
+		assert.Equal(t, uint8(ErrCallerUnauthorized), result.Receipt.ExitCode)
+	})
+
+	t.Run("approved proposals to the wallet apply", func(t *testing.T) {
+		result, err := th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress, msAddr, 0, 0, "propose", nil, msAddr, types.ZeroAttoFIL, "addSigner", []interface{}{address.TestAddress2, true})
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		msState := requireMultisigState(t, st, vms, msAddr)
+		assert.Equal(t, []address.Address{address.TestAddress, address.TestAddress2}, msState.Signers)
+		assert.Equal(t, uint64(2), msState.Required)
+	})
+}
+
+func TestMultisigIgnoresApprovalsOfRemovedSigners(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	st, vms := th.RequireCreateStorages(ctx, t)
+	newAddr := address.NewForTestGetter()
+	signers := []address.Address{address.TestAddress, address.TestAddress2, newAddr()}
+	msAddr := requireCreateMultisig(t, st, vms, signers, 2, 100, 0)
+
+	recipient := newAddr()
+	result, err := th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress, msAddr, 0, 0, "propose", nil, recipient, types.NewAttoFILFromFIL(40), "", []interface{}{})
+	require.NoError(t, err)
+	require.NoError(t, result.ExecutionError)
+	transferID := big.NewInt(0).SetBytes(result.Receipt.Return[0])
+
+	// the proposer of the transfer is removed
+	result, err = th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress, msAddr, 0, 0, "propose", nil, msAddr, types.ZeroAttoFIL, "removeSigner", []interface{}{address.TestAddress, false})
+	require.NoError(t, err)
+	require.NoError(t, result.ExecutionError)
+	removeID := big.NewInt(0).SetBytes(result.Receipt.Return[0])
+	result, err = th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress2, msAddr, 0, 0, "approve", nil, removeID)
+	require.NoError(t, err)
+	require.NoError(t, result.ExecutionError)
+	require.Equal(t, signers[1:], requireMultisigState(t, st, vms, msAddr).Signers)
+
+	// its approval no longer counts towards the transfer
+	result, err = th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress2, msAddr, 0, 0, "approve", nil, transferID)
+	require.NoError(t, err)
+	require.NoError(t, result.ExecutionError)
+
+	assert.Len(t, requireMultisigState(t, st, vms, msAddr).Transactions, 1)
+	assert.Equal(t, types.NewAttoFILFromFIL(100), state.MustGetActor(st, msAddr).Balance)
+}
+
+func TestMultisigVesting(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	st, vms := th.RequireCreateStorages(ctx, t)
+	msAddr := requireCreateMultisig(t, st, vms, []address.Address{address.TestAddress}, 1, 100, 10)
+
+	// at height 5, half of the initial balance is still locked
+	result, err := th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress, msAddr, 0, 5, "propose", nil, address.TestAddress2, types.NewAttoFILFromFIL(51), "", []interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, uint8(ErrFundsLocked), result.Receipt.ExitCode)
+
+	result, err = th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress, msAddr, 0, 5, "propose", nil, address.TestAddress2, types.NewAttoFILFromFIL(50), "", []interface{}{})
+	require.NoError(t, err)
+	require.NoError(t, result.ExecutionError)
+	assert.Equal(t, types.NewAttoFILFromFIL(50), state.MustGetActor(st, msAddr).Balance)
+}
+
+func TestLockedBalance(t *testing.T) {
+	tf.UnitTest(t)
+
+	msState, err := NewState([]address.Address{address.TestAddress}, 1, types.NewAttoFILFromFIL(100), types.NewBlockHeight(10), types.NewBlockHeight(100))
+	require.NoError(t, err)
+
+	assert.Equal(t, types.NewAttoFILFromFIL(100), LockedBalance(msState, types.NewBlockHeight(10)))
+	assert.Equal(t, types.NewAttoFILFromFIL(75), LockedBalance(msState, types.NewBlockHeight(35)))
+	assert.Equal(t, types.ZeroAttoFIL, LockedBalance(msState, types.NewBlockHeight(110)))
+
+	_, err = NewState([]address.Address{address.TestAddress}, 2, types.ZeroAttoFIL, types.NewBlockHeight(0), types.NewBlockHeight(0))
+	assert.Equal(t, Errors[ErrInvalidRequirement], err)
+}
+
+func requireCreateMultisig(t *testing.T, st state.Tree, vms vm.StorageMap, signers []address.Address, required int64, value, unlockDuration uint64) address.Address {
+	result, err := th.CreateAndApplyTestMessage(t, st, vms, address.InitAddress, value, 0, "createMultisig", nil, signers, big.NewInt(required), types.NewBlockHeight(unlockDuration))
+	require.NoError(t, err)
+	require.NoError(t, result.ExecutionError)
+
+	addr, err := address.NewFromBytes(result.Receipt.Return[0])
+	require.NoError(t, err)
+	return addr
+}
+
+func requireMultisigState(t *testing.T, st state.Tree, vms vm.StorageMap, addr address.Address) *State {
+	act := state.MustGetActor(st, addr)
+	chunk, err := vms.NewStorage(addr, act).Get(act.Head)
+	require.NoError(t, err)
+
+	var msState State
+	require.NoError(t, cbor.DecodeInto(chunk, &msState))
+	return &msState
+}
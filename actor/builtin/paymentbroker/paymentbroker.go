@@ -2,6 +2,9 @@ package paymentbroker
 
 import (
 	"context"
+	"encoding/binary"
+	"math/big"
+	"strconv"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-hamt-ipld"
@@ -42,6 +45,16 @@ const (
 	ErrConditionInvalid = 44
 	//ErrInvalidCancel indicates that the condition attached to a voucher did execute successfully and therefore can't be cancelled
 	ErrInvalidCancel = 45
+	// ErrStaleNonce indicates a voucher nonce lower than the nonce of its lane.
+	ErrStaleNonce = 46
+	// ErrInvalidMerge indicates a voucher that merges its own lane or merges a lane more than once.
+	ErrInvalidMerge = 47
+	// ErrNotChannelParty indicates an attempt to settle a channel by an account that is neither its payer nor its target.
+	ErrNotChannelParty = 48
+	// ErrSettling indicates an attempt to settle or extend a channel that is already settling.
+	ErrSettling = 49
+	// ErrInvalidSettlePeriod indicates an attempt to create a channel with a negative settle period.
+	ErrInvalidSettlePeriod = 50
 )
 
 // CancelDelayBlockTime is the number of rounds given to the target to respond after the channel
@@ -50,6 +63,10 @@ const (
 // See https://github.com/filecoin-project/go-filecoin/issues/1887
 const CancelDelayBlockTime = 10000
 
+// DefaultSettlePeriod is the settle period, in blocks, used for channels created
+// without an explicit one.
+const DefaultSettlePeriod = 100
+
 // Errors map error codes to revert errors this actor may return.
 var Errors = map[uint8]error{
 	ErrTooEarly:                 errors.NewCodedRevertError(ErrTooEarly, "block height too low to redeem voucher"),
@@ -63,10 +80,16 @@ var Errors = map[uint8]error{
 	ErrExpired:                  errors.NewCodedRevertError(ErrExpired, "block height has exceeded channel's end of life"),
 	ErrAlreadyWithdrawn:         errors.NewCodedRevertError(ErrAlreadyWithdrawn, "update amount has already been redeemed"),
 	ErrInvalidSignature:         errors.NewCodedRevertErrorf(ErrInvalidSignature, "signature failed to validate"),
+	ErrStaleNonce:               errors.NewCodedRevertError(ErrStaleNonce, "voucher nonce is lower than the nonce of its lane"),
+	ErrInvalidMerge:             errors.NewCodedRevertError(ErrInvalidMerge, "voucher merges its own lane or merges a lane twice"),
+	ErrNotChannelParty:          errors.NewCodedRevertError(ErrNotChannelParty, "only the payer or target of a payment channel may settle it"),
+	ErrSettling:                 errors.NewCodedRevertError(ErrSettling, "payment channel is settling"),
+	ErrInvalidSettlePeriod:      errors.NewCodedRevertError(ErrInvalidSettlePeriod, "payment channel settle period may not be negative"),
 }
 
 func init() {
 	cbor.RegisterCborType(PaymentChannel{})
+	cbor.RegisterCborType(LaneState{})
 }
 
 // PaymentChannel records the intent to pay funds to a target account.
@@ -80,6 +103,11 @@ type PaymentChannel struct {
 	// AmountRedeemed is the amount of FIL already transferred to the target
 	AmountRedeemed types.AttoFIL `json:"amount_redeemed"`
 
+	// Lanes is the redeemed state of each lane of the channel that has been
+	// redeemed against, keyed by lane number. The amounts redeemed on the lanes
+	// add up to AmountRedeemed.
+	Lanes map[string]*LaneState `json:"lanes"`
+
 	// AgreedEol is the expiration for the payment channel agreed upon by the
 	// payer and payee upon initialization or extension
 	AgreedEol *types.BlockHeight `json:"agreed_eol"`
@@ -96,6 +124,28 @@ type PaymentChannel struct {
 	// payment channel yet. This is necessary because AmountRedeemed can still be
 	// zero in the event of a zero-value voucher
 	Redeemed bool `json:"redeemed"`
+
+	// SettlePeriod is the number of blocks between the channel starting to settle
+	// and its remaining funds being returned to the payer. During this window
+	// the target may still redeem vouchers.
+	SettlePeriod *types.BlockHeight `json:"settle_period"`
+
+	// SettlingAt is the block height at which a settling channel may be
+	// reclaimed by the payer. It is nil until the channel starts to settle.
+	SettlingAt *types.BlockHeight `json:"settling_at"`
+}
+
+// LaneState is the redeemed state of a single lane of a payment channel.
+type LaneState struct {
+	// Redeemed is the amount of FIL transferred to the target on this lane
+	Redeemed types.AttoFIL `json:"redeemed"`
+
+	// Nonce is the nonce of the last voucher redeemed on, or merged from, this lane
+	Nonce uint64 `json:"nonce"`
+
+	// Merged is true if the lane was last merged into another lane, in which case
+	// vouchers with nonce Nonce were covered by the merge and are stale as well
+	Merged bool `json:"merged"`
 }
 
 // Actor provides a mechanism for off chain payments.
@@ -122,37 +172,63 @@ func (pb *Actor) Exports() exec.Exports {
 
 var _ exec.ExecutableActor = (*Actor)(nil)
 
+// Method ids of the payment broker exports.
+const (
+	MethodCancel        = types.MethodID(1)
+	MethodClose         = types.MethodID(2)
+	MethodCreateChannel = types.MethodID(3)
+	MethodExtend        = types.MethodID(4)
+	MethodLs            = types.MethodID(5)
+	MethodReclaim       = types.MethodID(6)
+	MethodRedeem        = types.MethodID(7)
+	MethodSettle        = types.MethodID(8)
+	MethodVoucher       = types.MethodID(9)
+)
+
 var paymentBrokerExports = exec.Exports{
-	"cancel": &exec.FunctionSignature{
+	MethodCancel: &exec.FunctionSignature{
+		Name:   "cancel",
 		Params: []abi.Type{abi.ChannelID},
 		Return: nil,
 	},
-	"close": &exec.FunctionSignature{
-		Params: []abi.Type{abi.Address, abi.ChannelID, abi.AttoFIL, abi.BlockHeight, abi.Predicate, abi.Bytes, abi.Parameters},
+	MethodClose: &exec.FunctionSignature{
+		Name:   "close",
+		Params: []abi.Type{abi.Address, abi.ChannelID, abi.Integer, abi.Integer, abi.AttoFIL, abi.BlockHeight, abi.Merges, abi.Predicate, abi.Bytes, abi.Parameters},
 		Return: nil,
 	},
-	"createChannel": &exec.FunctionSignature{
-		Params: []abi.Type{abi.Address, abi.BlockHeight},
+	MethodCreateChannel: &exec.FunctionSignature{
+		Name:   "createChannel",
+		Params: []abi.Type{abi.Address, abi.BlockHeight, abi.BlockHeight},
 		Return: []abi.Type{abi.ChannelID},
 	},
-	"extend": &exec.FunctionSignature{
+	MethodExtend: &exec.FunctionSignature{
+		Name:   "extend",
 		Params: []abi.Type{abi.ChannelID, abi.BlockHeight},
 		Return: nil,
 	},
-	"ls": &exec.FunctionSignature{
+	MethodLs: &exec.FunctionSignature{
+		Name:   "ls",
 		Params: []abi.Type{abi.Address},
 		Return: []abi.Type{abi.Bytes},
 	},
-	"reclaim": &exec.FunctionSignature{
+	MethodReclaim: &exec.FunctionSignature{
+		Name:   "reclaim",
 		Params: []abi.Type{abi.ChannelID},
 		Return: nil,
 	},
-	"redeem": &exec.FunctionSignature{
-		Params: []abi.Type{abi.Address, abi.ChannelID, abi.AttoFIL, abi.BlockHeight, abi.Predicate, abi.Bytes, abi.Parameters},
+	MethodRedeem: &exec.FunctionSignature{
+		Name:   "redeem",
+		Params: []abi.Type{abi.Address, abi.ChannelID, abi.Integer, abi.Integer, abi.AttoFIL, abi.BlockHeight, abi.Merges, abi.Predicate, abi.Bytes, abi.Parameters},
 		Return: nil,
 	},
-	"voucher": &exec.FunctionSignature{
-		Params: []abi.Type{abi.ChannelID, abi.AttoFIL, abi.BlockHeight, abi.Predicate},
+	MethodSettle: &exec.FunctionSignature{
+		Name:   "settle",
+		Params: []abi.Type{abi.Address, abi.ChannelID},
+		Return: nil,
+	},
+	MethodVoucher: &exec.FunctionSignature{
+		Name:   "voucher",
+		Params: []abi.Type{abi.ChannelID, abi.Integer, abi.Integer, abi.AttoFIL, abi.BlockHeight, abi.Merges, abi.Predicate},
 		Return: []abi.Type{abi.Bytes},
 	},
 }
@@ -160,7 +236,9 @@ var paymentBrokerExports = exec.Exports{
 // CreateChannel creates a new payment channel from the caller to the target.
 // The value attached to the invocation is used as the deposit, and the channel
 // will expire and return all of its money to the owner after the given block height.
-func (pb *Actor) CreateChannel(vmctx exec.VMContext, target address.Address, eol *types.BlockHeight) (*types.ChannelID, uint8, error) {
+// The settle period is the number of blocks the target is given to redeem its
+// vouchers once the channel starts to settle.
+func (pb *Actor) CreateChannel(vmctx exec.VMContext, target address.Address, eol *types.BlockHeight, settlePeriod *types.BlockHeight) (*types.ChannelID, uint8, error) {
 	if err := vmctx.Charge(actor.DefaultGasCost); err != nil {
 		return nil, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
 	}
@@ -170,6 +248,13 @@ func (pb *Actor) CreateChannel(vmctx exec.VMContext, target address.Address, eol
 		return nil, errors.CodeError(Errors[ErrNonAccountActor]), Errors[ErrNonAccountActor]
 	}
 
+	if settlePeriod == nil {
+		settlePeriod = types.NewBlockHeight(DefaultSettlePeriod)
+	}
+	if settlePeriod.LessThan(types.NewBlockHeight(0)) {
+		return nil, errors.CodeError(Errors[ErrInvalidSettlePeriod]), Errors[ErrInvalidSettlePeriod]
+	}
+
 	ctx := context.Background()
 	storage := vmctx.Storage()
 	payerAddress := vmctx.Message().From
@@ -192,6 +277,7 @@ func (pb *Actor) CreateChannel(vmctx exec.VMContext, target address.Address, eol
 			AmountRedeemed: types.NewAttoFILFromFIL(0),
 			AgreedEol:      eol,
 			Eol:            eol,
+			SettlePeriod:   settlePeriod,
 		})
 		if err != nil {
 			return errors.FaultErrorWrap(err, "Could not set payment channel")
@@ -223,18 +309,23 @@ func (pb *Actor) CreateChannel(vmctx exec.VMContext, target address.Address, eol
 // target Redeem(200)          -> Payer: 1000, Target: 200, Channel: 800
 // target Close(500)           -> Payer: 1500, Target: 500, Channel: 0
 //
+// The amt is tracked separately for each lane of the channel, so vouchers on different lanes
+// may be redeemed in any order. Within a lane a voucher may not have a lower nonce than the last
+// voucher redeemed on it. A voucher may merge other lanes into its own, in which case amt covers
+// the funds already redeemed on the merged lanes as well, and those lanes are reset.
+//
 // If a condition is provided in the voucher:
 // - The parameters provided in the condition will be combined with redeemerConditionParams
 // - A message will be sent to the the condition.To address using the condition.Method with the combined params
 // - If the message returns an error the condition is considered to be false and the redeem will fail
-func (pb *Actor) Redeem(vmctx exec.VMContext, payer address.Address, chid *types.ChannelID, amt types.AttoFIL,
-	validAt *types.BlockHeight, condition *types.Predicate, sig []byte, redeemerConditionParams []interface{}) (uint8, error) {
+func (pb *Actor) Redeem(vmctx exec.VMContext, payer address.Address, chid *types.ChannelID, lane *big.Int, nonce *big.Int, amt types.AttoFIL,
+	validAt *types.BlockHeight, merges []types.Merge, condition *types.Predicate, sig []byte, redeemerConditionParams []interface{}) (uint8, error) {
 
 	if err := vmctx.Charge(actor.DefaultGasCost); err != nil {
 		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
 	}
 
-	if !VerifyVoucherSignature(payer, chid, amt, validAt, condition, sig) {
+	if !VerifyVoucherSignature(payer, chid, lane.Uint64(), nonce.Uint64(), amt, validAt, merges, condition, sig) {
 		return errors.CodeError(Errors[ErrInvalidSignature]), Errors[ErrInvalidSignature]
 	}
 
@@ -252,14 +343,16 @@ func (pb *Actor) Redeem(vmctx exec.VMContext, payer address.Address, chid *types
 		}
 
 		// validate the amount can be sent to the target and send payment to that address.
-		err = validateAndUpdateChannel(vmctx, vmctx.Message().From, &channel, amt, validAt, condition, redeemerConditionParams)
+		err = validateAndUpdateChannel(vmctx, vmctx.Message().From, &channel, lane.Uint64(), nonce.Uint64(), amt, validAt, merges, condition, redeemerConditionParams)
 		if err != nil {
 			return err
 		}
 
 		// Reset the EOL to the originally agreed upon EOL in the event that the
-		// channel has been cancelled.
-		channel.Eol = channel.AgreedEol
+		// channel has been cancelled. A settling channel keeps its EOL.
+		if channel.SettlingAt == nil {
+			channel.Eol = channel.AgreedEol
+		}
 
 		// Mark the payment channel as redeemed
 		channel.Redeemed = true
@@ -278,21 +371,22 @@ func (pb *Actor) Redeem(vmctx exec.VMContext, payer address.Address, chid *types
 	return 0, nil
 }
 
-// Close first executes the logic performed in the the Update method, then returns all
-// funds remaining in the channel to the payer account and deletes the channel.
+// Close first executes the logic performed in the the Update method, then starts
+// settling the channel. The target may redeem higher vouchers until the settle
+// period has passed, after which the payer may reclaim the remaining funds.
 //
 // If a condition is provided in the voucher:
 // - The parameters provided in the condition will be combined with redeemerConditionParams
 // - A message will be sent to the the condition.To address using the condition.Method with the combined params
 // - If the message returns an error the condition is considered to be false and the redeem will fail
-func (pb *Actor) Close(vmctx exec.VMContext, payer address.Address, chid *types.ChannelID, amt types.AttoFIL,
-	validAt *types.BlockHeight, condition *types.Predicate, sig []byte, redeemerConditionParams []interface{}) (uint8, error) {
+func (pb *Actor) Close(vmctx exec.VMContext, payer address.Address, chid *types.ChannelID, lane *big.Int, nonce *big.Int, amt types.AttoFIL,
+	validAt *types.BlockHeight, merges []types.Merge, condition *types.Predicate, sig []byte, redeemerConditionParams []interface{}) (uint8, error) {
 
 	if err := vmctx.Charge(actor.DefaultGasCost); err != nil {
 		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
 	}
 
-	if !VerifyVoucherSignature(payer, chid, amt, validAt, condition, sig) {
+	if !VerifyVoucherSignature(payer, chid, lane.Uint64(), nonce.Uint64(), amt, validAt, merges, condition, sig) {
 		return errors.CodeError(Errors[ErrInvalidSignature]), Errors[ErrInvalidSignature]
 	}
 
@@ -310,24 +404,21 @@ func (pb *Actor) Close(vmctx exec.VMContext, payer address.Address, chid *types.
 		}
 
 		// validate the amount can be sent to the target and send payment to that address.
-		err = validateAndUpdateChannel(vmctx, vmctx.Message().From, &channel, amt, validAt, condition, redeemerConditionParams)
+		err = validateAndUpdateChannel(vmctx, vmctx.Message().From, &channel, lane.Uint64(), nonce.Uint64(), amt, validAt, merges, condition, redeemerConditionParams)
 		if err != nil {
 			return err
 		}
 
-		err = byChannelID.Set(ctx, chid.KeyString(), channel)
-		if err != nil {
-			return err
-		}
+		startSettling(vmctx, &channel)
+		channel.Redeemed = true
 
-		// return funds to payer
-		return reclaim(ctx, vmctx, byChannelID, payer, chid, &channel)
+		return byChannelID.Set(ctx, chid.KeyString(), channel)
 	})
 
 	if err != nil {
 		// ensure error is properly wrapped
 		if !errors.IsFault(err) && !errors.ShouldRevert(err) {
-			return 1, errors.FaultErrorWrap(err, "Error updating or reclaiming channel")
+			return 1, errors.FaultErrorWrap(err, "Error closing channel")
 		}
 		return errors.CodeError(err), err
 	}
@@ -356,6 +447,11 @@ func (pb *Actor) Extend(vmctx exec.VMContext, chid *types.ChannelID, eol *types.
 			return errors.FaultErrorWrapf(err, "Could not retrieve payment channel with ID: %s", chid)
 		}
 
+		// a settling channel may not be extended past its settlement
+		if channel.SettlingAt != nil {
+			return Errors[ErrSettling]
+		}
+
 		// eol can only be increased
 		if channel.Eol.GreaterThan(eol) {
 			return Errors[ErrEolTooLow]
@@ -448,6 +544,56 @@ func (pb *Actor) Cancel(vmctx exec.VMContext, chid *types.ChannelID) (uint8, err
 	return 0, nil
 }
 
+// Settle can be called by either the payer or the target of a channel to end
+// it. The channel expires once its settle period has passed, giving the target
+// that long to redeem its highest vouchers before the payer reclaims the rest.
+func (pb *Actor) Settle(vmctx exec.VMContext, payer address.Address, chid *types.ChannelID) (uint8, error) {
+	if err := vmctx.Charge(actor.DefaultGasCost); err != nil {
+		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	ctx := context.Background()
+	storage := vmctx.Storage()
+	sender := vmctx.Message().From
+
+	err := withPayerChannels(ctx, storage, payer, func(byChannelID exec.Lookup) error {
+		var channel PaymentChannel
+		err := byChannelID.Find(ctx, chid.KeyString(), &channel)
+		if err != nil {
+			if err == hamt.ErrNotFound {
+				return Errors[ErrUnknownChannel]
+			}
+			return errors.FaultErrorWrapf(err, "Could not retrieve payment channel with ID: %s", chid)
+		}
+
+		if sender != payer && sender != channel.Target {
+			return Errors[ErrNotChannelParty]
+		}
+
+		if channel.SettlingAt != nil {
+			return Errors[ErrSettling]
+		}
+
+		if vmctx.BlockHeight().GreaterEqual(channel.Eol) {
+			return Errors[ErrExpired]
+		}
+
+		startSettling(vmctx, &channel)
+
+		return byChannelID.Set(ctx, chid.KeyString(), channel)
+	})
+
+	if err != nil {
+		// ensure error is properly wrapped
+		if !errors.IsFault(err) && !errors.ShouldRevert(err) {
+			return 1, errors.FaultErrorWrap(err, "Error settling channel")
+		}
+		return errors.CodeError(err), err
+	}
+
+	return 0, nil
+}
+
 // Reclaim is used by the owner of a channel to reclaim unspent funds in timed
 // out payment Channels they own.
 func (pb *Actor) Reclaim(vmctx exec.VMContext, chid *types.ChannelID) (uint8, error) {
@@ -489,15 +635,16 @@ func (pb *Actor) Reclaim(vmctx exec.VMContext, chid *types.ChannelID) (uint8, er
 	return 0, nil
 }
 
-// Voucher takes a channel id and amount creates a new unsigned PaymentVoucher
-// against the given channel.  It also takes a block height parameter "validAt"
+// Voucher takes a channel id, lane, nonce and amount and creates a new unsigned
+// PaymentVoucher against the given channel. The amount is the total paid on the
+// lane and any lanes merged into it. It also takes a block height parameter "validAt"
 // enforcing that the voucher is not reclaimed until the given block height
 // Voucher errors if the channel doesn't exist or contains less than request
 // amount.
 // If a condition is provided, attempts to redeem or close with the voucher will
 // first send a message based on the condition and require a successful response
 // for funds to be transferred.
-func (pb *Actor) Voucher(vmctx exec.VMContext, chid *types.ChannelID, amount types.AttoFIL, validAt *types.BlockHeight, condition *types.Predicate) ([]byte, uint8, error) {
+func (pb *Actor) Voucher(vmctx exec.VMContext, chid *types.ChannelID, lane *big.Int, nonce *big.Int, amount types.AttoFIL, validAt *types.BlockHeight, merges []types.Merge, condition *types.Predicate) ([]byte, uint8, error) {
 	if err := vmctx.Charge(actor.DefaultGasCost); err != nil {
 		return []byte{}, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
 	}
@@ -525,10 +672,13 @@ func (pb *Actor) Voucher(vmctx exec.VMContext, chid *types.ChannelID, amount typ
 		// set voucher
 		voucher = types.PaymentVoucher{
 			Channel:   *chid,
+			Lane:      lane.Uint64(),
+			Nonce:     nonce.Uint64(),
 			Payer:     vmctx.Message().From,
 			Target:    channel.Target,
 			Amount:    amount,
 			ValidAt:   *validAt,
+			Merges:    merges,
 			Condition: condition,
 		}
 
@@ -589,7 +739,7 @@ func (pb *Actor) Ls(vmctx exec.VMContext, payer address.Address) ([]byte, uint8,
 	return channelsBytes, 0, nil
 }
 
-func validateAndUpdateChannel(ctx exec.VMContext, target address.Address, channel *PaymentChannel, amt types.AttoFIL, validAt *types.BlockHeight, condition *types.Predicate, redeemerSuppliedParams []interface{}) error {
+func validateAndUpdateChannel(ctx exec.VMContext, target address.Address, channel *PaymentChannel, lane uint64, nonce uint64, amt types.AttoFIL, validAt *types.BlockHeight, merges []types.Merge, condition *types.Predicate, redeemerSuppliedParams []interface{}) error {
 	cacheCondition(channel, condition, redeemerSuppliedParams)
 
 	if err := checkCondition(ctx, channel); err != nil {
@@ -608,27 +758,100 @@ func validateAndUpdateChannel(ctx exec.VMContext, target address.Address, channe
 		return Errors[ErrExpired]
 	}
 
-	if amt.GreaterThan(channel.Amount) {
-		return Errors[ErrInsufficientChannelFunds]
+	laneState := channel.lane(lane)
+	if nonce < laneState.Nonce || (laneState.Merged && nonce == laneState.Nonce) {
+		return Errors[ErrStaleNonce]
+	}
+
+	// amt covers everything already redeemed on this lane and the lanes merged into it
+	redeemed := laneState.Redeemed
+	merged := map[uint64]bool{}
+	for _, merge := range merges {
+		if merge.Lane == lane || merged[merge.Lane] {
+			return Errors[ErrInvalidMerge]
+		}
+		merged[merge.Lane] = true
+
+		mergedState := channel.lane(merge.Lane)
+		if merge.Nonce <= mergedState.Nonce {
+			return Errors[ErrStaleNonce]
+		}
+		redeemed = redeemed.Add(mergedState.Redeemed)
 	}
 
-	if amt.LessEqual(channel.AmountRedeemed) {
+	if amt.LessEqual(redeemed) {
 		return Errors[ErrAlreadyWithdrawn]
 	}
 
+	updateAmount := amt.Sub(redeemed)
+	if channel.AmountRedeemed.Add(updateAmount).GreaterThan(channel.Amount) {
+		return Errors[ErrInsufficientChannelFunds]
+	}
+
 	// transfer funds to sender
-	updateAmount := amt.Sub(channel.AmountRedeemed)
 	_, _, err := ctx.Send(ctx.Message().From, "", updateAmount, nil)
 	if err != nil {
 		return err
 	}
 
-	// update amount redeemed from this channel
-	channel.AmountRedeemed = amt
+	// fold the merged lanes into this one
+	for _, merge := range merges {
+		mergedState := channel.lane(merge.Lane)
+		mergedState.Redeemed = types.ZeroAttoFIL
+		mergedState.Nonce = merge.Nonce
+		mergedState.Merged = true
+	}
+
+	// update amount redeemed from this lane and channel
+	laneState.Redeemed = amt
+	laneState.Nonce = nonce
+	laneState.Merged = false
+	channel.AmountRedeemed = channel.AmountRedeemed.Add(updateAmount)
 
 	return nil
 }
 
+// startSettling lowers the channel's EOL to the end of its settle period, unless
+// it is already settling.
+func startSettling(vmctx exec.VMContext, channel *PaymentChannel) {
+	if channel.SettlingAt != nil {
+		return
+	}
+
+	settlingAt := vmctx.BlockHeight().Add(channel.settlePeriod())
+	if channel.Eol.LessThan(settlingAt) {
+		settlingAt = channel.Eol
+	}
+
+	channel.SettlingAt = settlingAt
+	channel.Eol = settlingAt
+}
+
+// settlePeriod returns the channel's settle period, or DefaultSettlePeriod for
+// channels created before channels had one.
+func (channel *PaymentChannel) settlePeriod() *types.BlockHeight {
+	if channel.SettlePeriod == nil {
+		return types.NewBlockHeight(DefaultSettlePeriod)
+	}
+	return channel.SettlePeriod
+}
+
+// lane returns the state of the given lane, adding it to the channel if it has
+// not been redeemed against before.
+func (channel *PaymentChannel) lane(lane uint64) *LaneState {
+	if channel.Lanes == nil {
+		channel.Lanes = map[string]*LaneState{}
+	}
+
+	key := strconv.FormatUint(lane, 10)
+	laneState, ok := channel.Lanes[key]
+	if !ok {
+		laneState = &LaneState{Redeemed: types.ZeroAttoFIL}
+		channel.Lanes[key] = laneState
+	}
+	return laneState
+}
+
 func reclaim(ctx context.Context, vmctx exec.VMContext, byChannelID exec.Lookup, payer address.Address, chid *types.ChannelID, channel *PaymentChannel) error {
 	amt := channel.Amount.Sub(channel.AmountRedeemed)
 	if amt.LessEqual(types.ZeroAttoFIL) {
@@ -654,11 +877,12 @@ func reclaim(ctx context.Context, vmctx exec.VMContext, byChannelID exec.Lookup,
 // voucher signature.
 const separator = 0x0
 
-// SignVoucher creates the signature for the given combination of
-// channel, amount, validAt (earliest block height for redeem) and from address.
-// It does so by signing the following bytes: (channelID | 0x0 | amount | 0x0 | validAt)
-func SignVoucher(channelID *types.ChannelID, amount types.AttoFIL, validAt *types.BlockHeight, addr address.Address, condition *types.Predicate, signer types.Signer) (types.Signature, error) {
-	data, err := createVoucherSignatureData(channelID, amount, validAt, condition)
+// SignVoucher creates the signature for the given combination of channel, lane,
+// nonce, amount, validAt (earliest block height for redeem), merged lanes and from address.
+// It does so by signing the following bytes:
+// (channelID | 0x0 | lane | nonce | amount | 0x0 | merges | condition | validAt)
+func SignVoucher(channelID *types.ChannelID, lane uint64, nonce uint64, amount types.AttoFIL, validAt *types.BlockHeight, merges []types.Merge, addr address.Address, condition *types.Predicate, signer types.Signer) (types.Signature, error) {
+	data, err := createVoucherSignatureData(channelID, lane, nonce, amount, validAt, merges, condition)
 	if err != nil {
 		return nil, err
 	}
@@ -666,8 +890,8 @@ func SignVoucher(channelID *types.ChannelID, amount types.AttoFIL, validAt *type
 }
 
 // VerifyVoucherSignature returns whether the voucher's signature is valid
-func VerifyVoucherSignature(payer address.Address, chid *types.ChannelID, amt types.AttoFIL, validAt *types.BlockHeight, condition *types.Predicate, sig []byte) bool {
-	data, err := createVoucherSignatureData(chid, amt, validAt, condition)
+func VerifyVoucherSignature(payer address.Address, chid *types.ChannelID, lane uint64, nonce uint64, amt types.AttoFIL, validAt *types.BlockHeight, merges []types.Merge, condition *types.Predicate, sig []byte) bool {
+	data, err := createVoucherSignatureData(chid, lane, nonce, amt, validAt, merges, condition)
 	// the only error is failure to encode the values
 	if err != nil {
 		return false
@@ -675,10 +899,17 @@ func VerifyVoucherSignature(payer address.Address, chid *types.ChannelID, amt ty
 	return types.IsValidSignature(data, payer, sig)
 }
 
-func createVoucherSignatureData(channelID *types.ChannelID, amount types.AttoFIL, validAt *types.BlockHeight, condition *types.Predicate) ([]byte, error) {
+func createVoucherSignatureData(channelID *types.ChannelID, lane uint64, nonce uint64, amount types.AttoFIL, validAt *types.BlockHeight, merges []types.Merge, condition *types.Predicate) ([]byte, error) {
 	data := append(channelID.Bytes(), separator)
+	data = appendUint64(data, lane)
+	data = appendUint64(data, nonce)
 	data = append(data, amount.Bytes()...)
 	data = append(data, separator)
+	data = appendUint64(data, uint64(len(merges)))
+	for _, merge := range merges {
+		data = appendUint64(data, merge.Lane)
+		data = appendUint64(data, merge.Nonce)
+	}
 	if condition != nil {
 		data = append(data, condition.To.Bytes()...)
 		data = append(data, []byte(condition.Method)...)
@@ -691,6 +922,13 @@ func createVoucherSignatureData(channelID *types.ChannelID, amount types.AttoFIL
 	return append(data, validAt.Bytes()...), nil
 }
 
+// appendUint64 appends the fixed width big endian encoding of n to data.
+func appendUint64(data []byte, n uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], n)
+	return append(data, buf[:]...)
+}
+
 func withPayerChannels(ctx context.Context, storage exec.Storage, payer address.Address, f func(exec.Lookup) error) error {
 	stateCid, err := actor.WithLookup(ctx, storage, storage.Head(), func(byPayer exec.Lookup) error {
 		byChannelLookup, err := findByChannelLookup(ctx, storage, byPayer, payer)
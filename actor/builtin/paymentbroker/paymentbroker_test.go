@@ -56,8 +56,8 @@ func TestPaymentBrokerCreateChannel(t *testing.T) {
 	target := address.NewForTestGetter()()
 	_, st, vms := requireGenesis(ctx, t, target)
 
-	pdata := abi.MustConvertParams(target, big.NewInt(10))
-	msg := types.NewMessage(payer, address.PaymentBrokerAddress, 0, types.NewAttoFILFromFIL(1000), "createChannel", pdata)
+	pdata := abi.MustConvertParams(target, big.NewInt(10), types.NewBlockHeight(DefaultSettlePeriod))
+	msg := types.NewMessage(payer, address.PaymentBrokerAddress, 0, types.NewAttoFILFromFIL(1000), MethodCreateChannel, pdata)
 
 	result, err := th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(0))
 	require.NoError(t, err)
@@ -80,6 +80,36 @@ func TestPaymentBrokerCreateChannel(t *testing.T) {
 	assert.Equal(t, types.NewBlockHeight(10), channel.Eol)
 }
 
+func TestPaymentBrokerCreateChannelRejectsNegativeSettlePeriod(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+
+	payer := address.TestAddress
+	target := address.NewForTestGetter()()
+	_, st, vms := requireGenesis(ctx, t, target)
+
+	negative, ok := types.NewBlockHeightFromString("-1", 10)
+	require.True(t, ok)
+	pdata := abi.MustConvertParams(target, big.NewInt(10), negative)
+	msg := types.NewMessage(payer, address.PaymentBrokerAddress, 0, types.NewAttoFILFromFIL(1000), MethodCreateChannel, pdata)
+
+	result, err := th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(0))
+	require.NoError(t, err)
+	assert.Equal(t, uint8(ErrInvalidSettlePeriod), result.Receipt.ExitCode)
+}
+
+func TestPaymentChannelSettlePeriodDefault(t *testing.T) {
+	tf.UnitTest(t)
+
+	// Channels created before channels had a settle period have none stored.
+	channel := &PaymentChannel{}
+	assert.Equal(t, types.NewBlockHeight(DefaultSettlePeriod), channel.settlePeriod())
+
+	channel.SettlePeriod = types.NewBlockHeight(7)
+	assert.Equal(t, types.NewBlockHeight(7), channel.settlePeriod())
+}
+
 func TestPaymentBrokerUpdate(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -127,7 +157,7 @@ func TestPaymentBrokerRedeemWithCondition(t *testing.T) {
 		require.NoError(t, sys.st.SetActor(context.TODO(), toAddress, actor.NewActor(pbTestActorCid, types.ZeroAttoFIL)))
 
 		condition := &types.Predicate{To: toAddress, Method: method, Params: payerParams}
-		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, "redeem", 0, condition, redeemerParams...)
+		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, MethodRedeem, 0, condition, redeemerParams...)
 
 		require.NoError(t, err)
 		require.NoError(t, appResult.ExecutionError)
@@ -141,7 +171,7 @@ func TestPaymentBrokerRedeemWithCondition(t *testing.T) {
 		badParams := []interface{}{badAddressParam, sectorIdParam}
 
 		condition := &types.Predicate{To: toAddress, Method: method, Params: badParams}
-		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, "redeem", 0, condition, redeemerParams...)
+		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, MethodRedeem, 0, condition, redeemerParams...)
 
 		require.NoError(t, err)
 		require.Error(t, appResult.ExecutionError)
@@ -156,7 +186,7 @@ func TestPaymentBrokerRedeemWithCondition(t *testing.T) {
 		badToAddress := addrGetter()
 
 		condition := &types.Predicate{To: badToAddress, Method: method, Params: payerParams}
-		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, "redeem", 0, condition, redeemerParams...)
+		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, MethodRedeem, 0, condition, redeemerParams...)
 
 		require.NoError(t, err)
 		require.Error(t, appResult.ExecutionError)
@@ -171,7 +201,7 @@ func TestPaymentBrokerRedeemWithCondition(t *testing.T) {
 		badMethod := "nonexistentMethod"
 
 		condition := &types.Predicate{To: toAddress, Method: badMethod, Params: payerParams}
-		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, "redeem", 0, condition, redeemerParams...)
+		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, MethodRedeem, 0, condition, redeemerParams...)
 
 		require.NoError(t, err)
 		require.Error(t, appResult.ExecutionError)
@@ -186,7 +216,7 @@ func TestPaymentBrokerRedeemWithCondition(t *testing.T) {
 		badParams := []interface{}{}
 
 		condition := &types.Predicate{To: toAddress, Method: method, Params: badParams}
-		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, "redeem", 0, condition, redeemerParams...)
+		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, MethodRedeem, 0, condition, redeemerParams...)
 
 		require.NoError(t, err)
 		require.Error(t, appResult.ExecutionError)
@@ -201,7 +231,7 @@ func TestPaymentBrokerRedeemWithCondition(t *testing.T) {
 		badRedeemerParams := []interface{}{}
 
 		condition := &types.Predicate{To: toAddress, Method: method, Params: payerParams}
-		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, "redeem", 0, condition, badRedeemerParams...)
+		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, MethodRedeem, 0, condition, badRedeemerParams...)
 
 		require.NoError(t, err)
 		require.Error(t, appResult.ExecutionError)
@@ -233,7 +263,7 @@ func TestPaymentBrokerRedeemSetsConditionAndRedeemed(t *testing.T) {
 
 		// Successfully redeem the payment channel
 		condition := &types.Predicate{To: toAddress, Method: method, Params: payerParams}
-		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, "redeem", 0, condition, redeemerParams...)
+		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, MethodRedeem, 0, condition, redeemerParams...)
 		require.NoError(t, err)
 		require.NoError(t, appResult.ExecutionError)
 
@@ -254,7 +284,7 @@ func TestPaymentBrokerRedeemSetsConditionAndRedeemed(t *testing.T) {
 
 		// Successfully redeem the payment channel
 		condition := &types.Predicate{To: toAddress, Method: method, Params: payerParams}
-		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, "redeem", 0, condition, redeemerParams...)
+		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, MethodRedeem, 0, condition, redeemerParams...)
 		require.NoError(t, err)
 		require.NoError(t, appResult.ExecutionError)
 
@@ -275,7 +305,7 @@ func TestPaymentBrokerRedeemSetsConditionAndRedeemed(t *testing.T) {
 
 		// Successfully redeem the payment channel with condition
 		condition := &types.Predicate{To: toAddress, Method: method, Params: payerParams}
-		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, "redeem", 0, condition, redeemerParams...)
+		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, MethodRedeem, 0, condition, redeemerParams...)
 		require.NoError(t, err)
 		require.NoError(t, appResult.ExecutionError)
 
@@ -287,7 +317,7 @@ func TestPaymentBrokerRedeemSetsConditionAndRedeemed(t *testing.T) {
 		assert.Equal(t, method, channel.Condition.Method)
 
 		// Successfully redeem the payment channel again without condition
-		appResult, err = sys.applySignatureMessage(sys.target, 200, types.NewBlockHeight(0), 0, "redeem", 0, nil, redeemerParams...)
+		appResult, err = sys.applySignatureMessage(sys.target, 200, types.NewBlockHeight(0), 0, MethodRedeem, 0, nil, redeemerParams...)
 		require.NoError(t, err)
 		require.NoError(t, appResult.ExecutionError)
 
@@ -303,7 +333,7 @@ func TestPaymentBrokerRedeemSetsConditionAndRedeemed(t *testing.T) {
 		condition := &types.Predicate{To: toAddress, Method: method, Params: payerParams}
 
 		// Successfully redeem the payment channel with no condition
-		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, "redeem", 0, nil, redeemerParams...)
+		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, MethodRedeem, 0, nil, redeemerParams...)
 		require.NoError(t, err)
 		require.NoError(t, appResult.ExecutionError)
 
@@ -313,7 +343,7 @@ func TestPaymentBrokerRedeemSetsConditionAndRedeemed(t *testing.T) {
 		assert.Nil(t, channel.Condition)
 
 		// Successfully redeem the payment channel again with a condition
-		appResult, err = sys.applySignatureMessage(sys.target, 200, types.NewBlockHeight(0), 0, "redeem", 0, condition, redeemerParams...)
+		appResult, err = sys.applySignatureMessage(sys.target, 200, types.NewBlockHeight(0), 0, MethodRedeem, 0, condition, redeemerParams...)
 		require.NoError(t, err)
 		require.NoError(t, appResult.ExecutionError)
 
@@ -334,7 +364,7 @@ func TestPaymentBrokerRedeemSetsConditionAndRedeemed(t *testing.T) {
 		condition := &types.Predicate{To: toAddress, Method: method, Params: payerParams}
 
 		// Successfully redeem the payment channel with condition
-		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, "redeem", 0, condition, redeemerParams...)
+		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, MethodRedeem, 0, condition, redeemerParams...)
 		require.NoError(t, err)
 		require.NoError(t, appResult.ExecutionError)
 
@@ -348,7 +378,7 @@ func TestPaymentBrokerRedeemSetsConditionAndRedeemed(t *testing.T) {
 		// Successfully redeem the payment channel again with new redeemer params
 		newBlockHeightParam := types.NewBlockHeight(52)
 		newRedeemerParams := []interface{}{newBlockHeightParam}
-		appResult, err = sys.applySignatureMessage(sys.target, 200, types.NewBlockHeight(0), 0, "redeem", 0, condition, newRedeemerParams...)
+		appResult, err = sys.applySignatureMessage(sys.target, 200, types.NewBlockHeight(0), 0, MethodRedeem, 0, condition, newRedeemerParams...)
 		require.NoError(t, err)
 		require.NoError(t, appResult.ExecutionError)
 
@@ -369,20 +399,20 @@ func TestPaymentBrokerRedeemSetsConditionAndRedeemed(t *testing.T) {
 
 		// Redeem without params expects an invalid condition error
 		condition := &types.Predicate{To: toAddress, Method: method}
-		appResult, err := sys.applySignatureMessage(sys.target, 200, types.NewBlockHeight(0), 0, "redeem", 0, condition)
+		appResult, err := sys.applySignatureMessage(sys.target, 200, types.NewBlockHeight(0), 0, MethodRedeem, 0, condition)
 		require.NoError(t, err)
 		require.Error(t, appResult.ExecutionError)
 		require.EqualValues(t, errors.CodeError(appResult.ExecutionError), ErrConditionInvalid)
 
 		// Successfully redeem the payment channel with params
 		condition = &types.Predicate{To: toAddress, Method: method, Params: payerParams}
-		appResult, err = sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, "redeem", 0, condition, redeemerParams...)
+		appResult, err = sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, MethodRedeem, 0, condition, redeemerParams...)
 		require.NoError(t, err)
 		require.NoError(t, appResult.ExecutionError)
 
 		// Redeem again without params and expect no error
 		condition = &types.Predicate{To: toAddress, Method: method}
-		appResult, err = sys.applySignatureMessage(sys.target, 200, types.NewBlockHeight(0), 0, "redeem", 0, condition)
+		appResult, err = sys.applySignatureMessage(sys.target, 200, types.NewBlockHeight(0), 0, MethodRedeem, 0, condition)
 		assert.NoError(t, err)
 		assert.NoError(t, appResult.ExecutionError)
 	})
@@ -395,7 +425,7 @@ func TestPaymentBrokerRedeemReversesCancellations(t *testing.T) {
 
 	// Cancel the payment channel
 	pdata := abi.MustConvertParams(sys.channelID)
-	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(1000), "cancel", pdata)
+	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(1000), MethodCancel, pdata)
 	result, err := sys.ApplyMessage(msg, 100)
 	require.NoError(t, result.ExecutionError)
 	require.NoError(t, err)
@@ -487,6 +517,163 @@ func TestPaymentBrokerUpdateErrorsWhenRedeemingFundsAlreadyRedeemed(t *testing.T
 	require.Contains(t, result.ExecutionError.Error(), "update amount")
 }
 
+func TestPaymentBrokerRedeemLanes(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("Lanes are redeemed independently", func(t *testing.T) {
+		sys := setup(t)
+
+		result, err := sys.applyLaneMessage(MethodRedeem, 0, 1, 0, 300, nil)
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		// a lower amount on a different lane is still paid in full
+		result, err = sys.applyLaneMessage(MethodRedeem, 1, 2, 0, 200, nil)
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		// a higher voucher on the first lane pays only its difference
+		result, err = sys.applyLaneMessage(MethodRedeem, 2, 1, 1, 400, nil)
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		targetActor := state.MustGetActor(sys.st, sys.target)
+		assert.Equal(t, types.NewAttoFILFromFIL(600), targetActor.Balance)
+
+		channel := requireGetPaymentChannel(t, sys.ctx, sys.st, sys.vms, sys.payer, sys.channelID)
+		assert.Equal(t, types.NewAttoFILFromFIL(600), channel.AmountRedeemed)
+		assert.Equal(t, types.NewAttoFILFromFIL(400), channel.Lanes["1"].Redeemed)
+		assert.Equal(t, uint64(1), channel.Lanes["1"].Nonce)
+		assert.Equal(t, types.NewAttoFILFromFIL(200), channel.Lanes["2"].Redeemed)
+	})
+
+	t.Run("Redeem fails with a nonce lower than the lane nonce", func(t *testing.T) {
+		sys := setup(t)
+
+		result, err := sys.applyLaneMessage(MethodRedeem, 0, 1, 5, 300, nil)
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		result, err = sys.applyLaneMessage(MethodRedeem, 1, 1, 4, 400, nil)
+		require.NoError(t, err)
+		assert.Equal(t, uint8(ErrStaleNonce), result.Receipt.ExitCode)
+	})
+
+	t.Run("Redeem fails when lanes together exceed the channel amount", func(t *testing.T) {
+		sys := setup(t)
+
+		result, err := sys.applyLaneMessage(MethodRedeem, 0, 1, 0, 600, nil)
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		result, err = sys.applyLaneMessage(MethodRedeem, 1, 2, 0, 500, nil)
+		require.NoError(t, err)
+		assert.Equal(t, uint8(ErrInsufficientChannelFunds), result.Receipt.ExitCode)
+	})
+
+	t.Run("Merging lanes pays only what they have not already redeemed", func(t *testing.T) {
+		sys := setup(t)
+
+		result, err := sys.applyLaneMessage(MethodRedeem, 0, 1, 0, 300, nil)
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		result, err = sys.applyLaneMessage(MethodRedeem, 1, 2, 0, 200, nil)
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		merges := []types.Merge{{Lane: 2, Nonce: 1}}
+		result, err = sys.applyLaneMessage(MethodRedeem, 2, 1, 1, 700, merges)
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		targetActor := state.MustGetActor(sys.st, sys.target)
+		assert.Equal(t, types.NewAttoFILFromFIL(700), targetActor.Balance)
+
+		channel := requireGetPaymentChannel(t, sys.ctx, sys.st, sys.vms, sys.payer, sys.channelID)
+		assert.Equal(t, types.NewAttoFILFromFIL(700), channel.AmountRedeemed)
+		assert.Equal(t, types.NewAttoFILFromFIL(700), channel.Lanes["1"].Redeemed)
+		assert.True(t, channel.Lanes["2"].Redeemed.IsZero())
+		assert.Equal(t, uint64(1), channel.Lanes["2"].Nonce)
+
+		// old vouchers on the merged lane can no longer be redeemed
+		result, err = sys.applyLaneMessage(MethodRedeem, 3, 2, 0, 250, nil)
+		require.NoError(t, err)
+		assert.Equal(t, uint8(ErrStaleNonce), result.Receipt.ExitCode)
+	})
+
+	t.Run("Merged lane vouchers at the merge nonce are covered by the merge", func(t *testing.T) {
+		sys := setup(t)
+
+		result, err := sys.applyLaneMessage(MethodRedeem, 0, 2, 0, 200, nil)
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		// the merge covers lane 2 up to its voucher with nonce 1 for 250
+		result, err = sys.applyLaneMessage(MethodRedeem, 1, 1, 0, 550, []types.Merge{{Lane: 2, Nonce: 1}})
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		result, err = sys.applyLaneMessage(MethodRedeem, 2, 2, 1, 250, nil)
+		require.NoError(t, err)
+		assert.Equal(t, uint8(ErrStaleNonce), result.Receipt.ExitCode)
+
+		// later vouchers on the merged lane are still paid
+		result, err = sys.applyLaneMessage(MethodRedeem, 3, 2, 2, 50, nil)
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		targetActor := state.MustGetActor(sys.st, sys.target)
+		assert.Equal(t, types.NewAttoFILFromFIL(600), targetActor.Balance)
+	})
+
+	t.Run("Merging fails with a stale merge nonce", func(t *testing.T) {
+		sys := setup(t)
+
+		result, err := sys.applyLaneMessage(MethodRedeem, 0, 2, 3, 200, nil)
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		result, err = sys.applyLaneMessage(MethodRedeem, 1, 1, 0, 500, []types.Merge{{Lane: 2, Nonce: 3}})
+		require.NoError(t, err)
+		assert.Equal(t, uint8(ErrStaleNonce), result.Receipt.ExitCode)
+	})
+
+	t.Run("Merging fails when a voucher merges its own lane", func(t *testing.T) {
+		sys := setup(t)
+
+		result, err := sys.applyLaneMessage(MethodRedeem, 0, 1, 1, 500, []types.Merge{{Lane: 1, Nonce: 2}})
+		require.NoError(t, err)
+		assert.Equal(t, uint8(ErrInvalidMerge), result.Receipt.ExitCode)
+	})
+
+	t.Run("Close merges lanes and the payer reclaims the rest", func(t *testing.T) {
+		sys := setup(t)
+
+		payerBalancePriorToClose := state.MustGetActor(sys.st, sys.payer).Balance
+
+		result, err := sys.applyLaneMessage(MethodRedeem, 0, 2, 0, 200, nil)
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		result, err = sys.applyLaneMessage(MethodClose, 1, 1, 0, 500, []types.Merge{{Lane: 2, Nonce: 1}})
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		targetActor := state.MustGetActor(sys.st, sys.target)
+		assert.Equal(t, types.NewAttoFILFromFIL(500), targetActor.Balance)
+
+		pdata := abi.MustConvertParams(sys.channelID)
+		msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(0), MethodReclaim, pdata)
+		result, err = sys.ApplyMessage(msg, DefaultSettlePeriod)
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		payerActor := state.MustGetActor(sys.st, sys.payer)
+		assert.Equal(t, payerBalancePriorToClose.Add(types.NewAttoFILFromFIL(500)), payerActor.Balance)
+	})
+}
+
 func TestPaymentBrokerUpdateErrorsWhenAtEol(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -506,7 +693,7 @@ func TestPaymentBrokerUpdateErrorsBeforeValidAt(t *testing.T) {
 
 	sys := setup(t)
 
-	result, err := sys.ApplySignatureMessageWithValidAtAndBlockHeight(sys.target, 100, 0, 8, 3, "redeem")
+	result, err := sys.ApplySignatureMessageWithValidAtAndBlockHeight(sys.target, 100, 0, 8, 3, MethodRedeem)
 	require.NoError(t, err)
 
 	assert.NotEqual(t, uint8(0), result.Receipt.ExitCode)
@@ -519,7 +706,7 @@ func TestPaymentBrokerUpdateSuccessWithValidAt(t *testing.T) {
 	sys := setup(t)
 
 	// Redeem at block height == validAt != 0.
-	result, err := sys.ApplySignatureMessageWithValidAtAndBlockHeight(sys.target, 100, 0, 4, 4, "redeem")
+	result, err := sys.ApplySignatureMessageWithValidAtAndBlockHeight(sys.target, 100, 0, 4, 4, MethodRedeem)
 	require.NoError(t, err)
 
 	require.Equal(t, uint8(0), result.Receipt.ExitCode)
@@ -536,7 +723,7 @@ func TestPaymentBrokerUpdateSuccessWithValidAt(t *testing.T) {
 	assert.Equal(t, sys.target, channel.Target)
 
 	// Redeem after block height == validAt.
-	result, err = sys.ApplySignatureMessageWithValidAtAndBlockHeight(sys.target, 200, 0, 4, 6, "redeem")
+	result, err = sys.ApplySignatureMessageWithValidAtAndBlockHeight(sys.target, 200, 0, 4, 6, MethodRedeem)
 	require.NoError(t, err)
 
 	require.Equal(t, uint8(0), result.Receipt.ExitCode)
@@ -565,19 +752,145 @@ func TestPaymentBrokerClose(t *testing.T) {
 	require.NoError(t, err)
 	require.NoError(t, result.ExecutionError)
 
-	paymentBroker := state.MustGetActor(sys.st, address.PaymentBrokerAddress)
-
-	// all funds have been redeemed or returned
-	assert.Equal(t, types.NewAttoFILFromFIL(0), paymentBroker.Balance)
-
 	targetActor := state.MustGetActor(sys.st, sys.target)
 
 	// targetActor has been paid
 	assert.Equal(t, types.NewAttoFILFromFIL(100), targetActor.Balance)
 
-	// remaining balance is returned to payer
+	// the channel is settling and still holds the remaining funds
+	paymentBroker := state.MustGetActor(sys.st, address.PaymentBrokerAddress)
+	assert.Equal(t, types.NewAttoFILFromFIL(900), paymentBroker.Balance)
+
+	channel := sys.retrieveChannel(paymentBroker)
+	assert.Equal(t, types.NewBlockHeight(DefaultSettlePeriod), channel.SettlingAt)
+	assert.Equal(t, types.NewBlockHeight(DefaultSettlePeriod), channel.Eol)
+
+	// the target may redeem a higher voucher while the channel settles
+	result, err = sys.ApplyRedeemMessageWithBlockHeight(sys.target, 200, 1, DefaultSettlePeriod-1)
+	require.NoError(t, err)
+	require.NoError(t, result.ExecutionError)
+
+	// but not once the settle period has passed
+	result, err = sys.ApplyRedeemMessageWithBlockHeight(sys.target, 300, 2, DefaultSettlePeriod)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(ErrExpired), result.Receipt.ExitCode)
+
+	// remaining balance is returned to payer after the settle period
+	pdata := abi.MustConvertParams(sys.channelID)
+	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(0), MethodReclaim, pdata)
+	result, err = sys.ApplyMessage(msg, DefaultSettlePeriod)
+	require.NoError(t, err)
+	require.NoError(t, result.ExecutionError)
+
+	paymentBroker = state.MustGetActor(sys.st, address.PaymentBrokerAddress)
+	assert.Equal(t, types.NewAttoFILFromFIL(0), paymentBroker.Balance)
+
+	targetActor = state.MustGetActor(sys.st, sys.target)
+	assert.Equal(t, types.NewAttoFILFromFIL(200), targetActor.Balance)
+
 	payerActor = state.MustGetActor(sys.st, sys.payer)
-	assert.Equal(t, payerBalancePriorToClose.Add(types.NewAttoFILFromFIL(900)), payerActor.Balance)
+	assert.Equal(t, payerBalancePriorToClose.Add(types.NewAttoFILFromFIL(800)), payerActor.Balance)
+}
+
+func TestPaymentBrokerSettle(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("Payer settle gives the target the settle period to redeem", func(t *testing.T) {
+		sys := setup(t)
+
+		result, err := sys.applySettleMessage(sys.payer, 1, 10)
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		paymentBroker := state.MustGetActor(sys.st, address.PaymentBrokerAddress)
+		channel := sys.retrieveChannel(paymentBroker)
+		assert.Equal(t, types.NewBlockHeight(10+DefaultSettlePeriod), channel.SettlingAt)
+		assert.Equal(t, types.NewBlockHeight(10+DefaultSettlePeriod), channel.Eol)
+		assert.Equal(t, types.NewBlockHeight(20000), channel.AgreedEol)
+
+		// the payer may not reclaim during the settle period
+		pdata := abi.MustConvertParams(sys.channelID)
+		msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 2, types.NewAttoFILFromFIL(0), MethodReclaim, pdata)
+		result, err = sys.ApplyMessage(msg, 10+DefaultSettlePeriod-1)
+		require.NoError(t, err)
+		assert.Equal(t, uint8(ErrReclaimBeforeEol), result.Receipt.ExitCode)
+
+		// redeeming during the settle period does not restore the agreed eol
+		result, err = sys.ApplyRedeemMessageWithBlockHeight(sys.target, 300, 0, 20)
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		paymentBroker = state.MustGetActor(sys.st, address.PaymentBrokerAddress)
+		channel = sys.retrieveChannel(paymentBroker)
+		assert.Equal(t, types.NewBlockHeight(10+DefaultSettlePeriod), channel.Eol)
+
+		payerBalancePriorToReclaim := state.MustGetActor(sys.st, sys.payer).Balance
+
+		msg = types.NewMessage(sys.payer, address.PaymentBrokerAddress, 3, types.NewAttoFILFromFIL(0), MethodReclaim, pdata)
+		result, err = sys.ApplyMessage(msg, 10+DefaultSettlePeriod)
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		payerActor := state.MustGetActor(sys.st, sys.payer)
+		assert.Equal(t, payerBalancePriorToReclaim.Add(types.NewAttoFILFromFIL(700)), payerActor.Balance)
+	})
+
+	t.Run("Target may settle", func(t *testing.T) {
+		sys := setup(t)
+
+		result, err := sys.applySettleMessage(sys.target, 0, 10)
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+	})
+
+	t.Run("Settle is limited by the channel eol", func(t *testing.T) {
+		sys := setup(t)
+
+		result, err := sys.applySettleMessage(sys.payer, 1, 19990)
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		paymentBroker := state.MustGetActor(sys.st, address.PaymentBrokerAddress)
+		channel := sys.retrieveChannel(paymentBroker)
+		assert.Equal(t, types.NewBlockHeight(20000), channel.SettlingAt)
+	})
+
+	t.Run("Settle fails from an account not party to the channel", func(t *testing.T) {
+		sys := setup(t)
+
+		other := sys.addressGetter()
+		state.MustSetActor(sys.st, other, th.RequireNewAccountActor(t, types.ZeroAttoFIL))
+
+		result, err := sys.applySettleMessage(other, 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, uint8(ErrNotChannelParty), result.Receipt.ExitCode)
+	})
+
+	t.Run("Settle fails when the channel is already settling", func(t *testing.T) {
+		sys := setup(t)
+
+		result, err := sys.applySettleMessage(sys.payer, 1, 10)
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		result, err = sys.applySettleMessage(sys.target, 0, 11)
+		require.NoError(t, err)
+		assert.Equal(t, uint8(ErrSettling), result.Receipt.ExitCode)
+	})
+
+	t.Run("Settling channels may not be extended", func(t *testing.T) {
+		sys := setup(t)
+
+		result, err := sys.applySettleMessage(sys.payer, 1, 10)
+		require.NoError(t, err)
+		require.NoError(t, result.ExecutionError)
+
+		pdata := abi.MustConvertParams(sys.channelID, types.NewBlockHeight(30000))
+		msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 2, types.NewAttoFILFromFIL(0), MethodExtend, pdata)
+		result, err = sys.ApplyMessage(msg, 11)
+		require.NoError(t, err)
+		assert.Equal(t, uint8(ErrSettling), result.Receipt.ExitCode)
+	})
 }
 
 func TestPaymentBrokerCloseErrorsBeforeValidAt(t *testing.T) {
@@ -585,7 +898,7 @@ func TestPaymentBrokerCloseErrorsBeforeValidAt(t *testing.T) {
 
 	sys := setup(t)
 
-	result, err := sys.ApplySignatureMessageWithValidAtAndBlockHeight(sys.target, 100, 0, 8, 3, "close")
+	result, err := sys.ApplySignatureMessageWithValidAtAndBlockHeight(sys.target, 100, 0, 8, 3, MethodClose)
 	require.NoError(t, err)
 
 	assert.NotEqual(t, uint8(0), result.Receipt.ExitCode)
@@ -605,8 +918,8 @@ func TestPaymentBrokerCloseInvalidSig(t *testing.T) {
 	signature[1] = 1
 
 	var condition *types.Predicate
-	pdata := abi.MustConvertParams(sys.payer, sys.channelID, amt, sys.defaultValidAt, condition, signature, []interface{}{})
-	msg := types.NewMessage(sys.target, address.PaymentBrokerAddress, 0, types.NewAttoFILFromFIL(0), "close", pdata)
+	pdata := abi.MustConvertParams(sys.payer, sys.channelID, big.NewInt(0), big.NewInt(0), amt, sys.defaultValidAt, []types.Merge{}, condition, signature, []interface{}{})
+	msg := types.NewMessage(sys.target, address.PaymentBrokerAddress, 0, types.NewAttoFILFromFIL(0), MethodClose, pdata)
 	res, err := sys.ApplyMessage(msg, 0)
 	require.EqualError(t, res.ExecutionError, Errors[ErrInvalidSignature].Error())
 	require.NoError(t, err)
@@ -624,7 +937,7 @@ func TestPaymentBrokerCloseWithCondition(t *testing.T) {
 
 		condition := &types.Predicate{To: toAddress, Method: "paramsNotZero", Params: []interface{}{addrGetter(), uint64(6)}}
 
-		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, "close", 0, condition, types.NewBlockHeight(43))
+		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, MethodClose, 0, condition, types.NewBlockHeight(43))
 		require.NoError(t, err)
 		require.NoError(t, appResult.ExecutionError)
 	})
@@ -635,7 +948,7 @@ func TestPaymentBrokerCloseWithCondition(t *testing.T) {
 
 		condition := &types.Predicate{To: toAddress, Method: "paramsNotZero", Params: []interface{}{address.Undef, uint64(6)}}
 
-		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, "close", 0, condition, types.NewBlockHeight(43))
+		appResult, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, MethodClose, 0, condition, types.NewBlockHeight(43))
 		require.NoError(t, err)
 		require.Error(t, appResult.ExecutionError)
 		require.Contains(t, appResult.ExecutionError.Error(), "failed to validate voucher condition: got undefined address")
@@ -659,19 +972,19 @@ func TestPaymentBrokerCloseChecksCachedConditions(t *testing.T) {
 
 	// Close without params and expect a panic
 	condition := &types.Predicate{To: toAddress, Method: method, Params: payerParams}
-	result, err := sys.applySignatureMessage(sys.target, 100, sys.defaultValidAt, 0, "close", 0, condition)
+	result, err := sys.applySignatureMessage(sys.target, 100, sys.defaultValidAt, 0, MethodClose, 0, condition)
 	require.NoError(t, err)
 	require.Error(t, result.ExecutionError)
 	require.EqualValues(t, errors.CodeError(result.ExecutionError), ErrConditionInvalid)
 
 	// Successfully redeem the payment channel with params
 	condition = &types.Predicate{To: toAddress, Method: method, Params: payerParams}
-	result, err = sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, "redeem", 0, condition, redeemerParams...)
+	result, err = sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, MethodRedeem, 0, condition, redeemerParams...)
 	require.NoError(t, err)
 	require.NoError(t, result.ExecutionError)
 
 	// Close again without params and expect no error
-	result, err = sys.applySignatureMessage(sys.target, 200, sys.defaultValidAt, 0, "close", 0, condition)
+	result, err = sys.applySignatureMessage(sys.target, 200, sys.defaultValidAt, 0, MethodClose, 0, condition)
 	require.NoError(t, err)
 	require.NoError(t, result.ExecutionError)
 }
@@ -689,8 +1002,8 @@ func TestPaymentBrokerRedeemInvalidSig(t *testing.T) {
 	signature[1] = 1
 
 	var condition *types.Predicate
-	pdata := abi.MustConvertParams(sys.payer, sys.channelID, amt, sys.defaultValidAt, condition, signature, []interface{}{})
-	msg := types.NewMessage(sys.target, address.PaymentBrokerAddress, 0, types.NewAttoFILFromFIL(0), "redeem", pdata)
+	pdata := abi.MustConvertParams(sys.payer, sys.channelID, big.NewInt(0), big.NewInt(0), amt, sys.defaultValidAt, []types.Merge{}, condition, signature, []interface{}{})
+	msg := types.NewMessage(sys.target, address.PaymentBrokerAddress, 0, types.NewAttoFILFromFIL(0), MethodRedeem, pdata)
 	res, err := sys.ApplyMessage(msg, 0)
 	require.EqualError(t, res.ExecutionError, Errors[ErrInvalidSignature].Error())
 	require.NoError(t, err)
@@ -705,7 +1018,7 @@ func TestPaymentBrokerReclaim(t *testing.T) {
 	payerBalancePriorToClose := payer.Balance
 
 	pdata := abi.MustConvertParams(sys.channelID)
-	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(0), "reclaim", pdata)
+	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(0), MethodReclaim, pdata)
 	// block height is after Eol
 	res, err := sys.ApplyMessage(msg, 20001)
 	require.NoError(t, err)
@@ -727,7 +1040,7 @@ func TestPaymentBrokerReclaimFailsBeforeChannelEol(t *testing.T) {
 	sys := setup(t)
 
 	pdata := abi.MustConvertParams(sys.channelID)
-	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(0), "reclaim", pdata)
+	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(0), MethodReclaim, pdata)
 	// block height is before Eol
 	result, err := sys.ApplyMessage(msg, 0)
 	require.NoError(t, err)
@@ -745,7 +1058,7 @@ func TestPaymentBrokerExtend(t *testing.T) {
 
 	// extend channel
 	pdata := abi.MustConvertParams(sys.channelID, types.NewBlockHeight(30000))
-	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(1000), "extend", pdata)
+	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(1000), MethodExtend, pdata)
 
 	result, err := sys.ApplyMessage(msg, 9)
 	require.NoError(t, result.ExecutionError)
@@ -780,7 +1093,7 @@ func TestPaymentBrokerExtendFailsWithNonExistentChannel(t *testing.T) {
 
 	// extend channel
 	pdata := abi.MustConvertParams(types.NewChannelID(383), types.NewBlockHeight(30000))
-	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(1000), "extend", pdata)
+	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(1000), MethodExtend, pdata)
 
 	result, err := sys.ApplyMessage(msg, 9)
 	require.NoError(t, err)
@@ -795,7 +1108,7 @@ func TestPaymentBrokerExtendRefusesToShortenTheEol(t *testing.T) {
 
 	// extend channel setting block height to 5 (<10)
 	pdata := abi.MustConvertParams(sys.channelID, types.NewBlockHeight(5))
-	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(1000), "extend", pdata)
+	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(1000), MethodExtend, pdata)
 
 	result, err := sys.ApplyMessage(msg, 9)
 	require.NoError(t, err)
@@ -810,7 +1123,7 @@ func TestPaymentBrokerCancel(t *testing.T) {
 	sys := setup(t)
 
 	pdata := abi.MustConvertParams(sys.channelID)
-	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(1000), "cancel", pdata)
+	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(1000), MethodCancel, pdata)
 
 	result, err := sys.ApplyMessage(msg, 100)
 	require.NoError(t, result.ExecutionError)
@@ -841,13 +1154,13 @@ func TestPaymentBrokerCancelFailsAfterSuccessfulRedeem(t *testing.T) {
 
 	// Successfully redeem the payment channel with params
 	condition := &types.Predicate{To: toAddress, Method: method, Params: payerParams}
-	result, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, "redeem", 0, condition, redeemerParams...)
+	result, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, MethodRedeem, 0, condition, redeemerParams...)
 	require.NoError(t, err)
 	require.NoError(t, result.ExecutionError)
 
 	// Attempts to Cancel and expects failure
 	pdata := abi.MustConvertParams(sys.channelID)
-	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(1000), "cancel", pdata)
+	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(1000), MethodCancel, pdata)
 	result, err = sys.ApplyMessage(msg, 100)
 	assert.NoError(t, err)
 	assert.Error(t, result.ExecutionError)
@@ -864,13 +1177,13 @@ func TestPaymentBrokerCancelFailsAfterSuccessfulRedeemWithNilCondtion(t *testing
 	require.NoError(t, sys.st.SetActor(context.Background(), toAddress, actor.NewActor(pbTestActorCid, types.ZeroAttoFIL)))
 
 	// Successfully redeem the payment channel with params
-	result, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, "redeem", 0, nil)
+	result, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, MethodRedeem, 0, nil)
 	require.NoError(t, err)
 	require.NoError(t, result.ExecutionError)
 
 	// Attempts to Cancel and expects failure
 	pdata := abi.MustConvertParams(sys.channelID)
-	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(1000), "cancel", pdata)
+	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(1000), MethodCancel, pdata)
 	result, err = sys.ApplyMessage(msg, 100)
 	assert.NoError(t, err)
 	assert.Error(t, result.ExecutionError)
@@ -893,7 +1206,7 @@ func TestPaymentBrokerCancelSucceedsAfterSuccessfulRedeemButFailedConditions(t *
 
 	// Successfully redeem the payment channel with params
 	condition := &types.Predicate{To: toAddress, Method: method, Params: payerParams}
-	result, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, "redeem", 0, condition, redeemerParams...)
+	result, err := sys.applySignatureMessage(sys.target, 100, types.NewBlockHeight(0), 0, MethodRedeem, 0, condition, redeemerParams...)
 	require.NoError(t, err)
 	require.NoError(t, result.ExecutionError)
 
@@ -908,7 +1221,7 @@ func TestPaymentBrokerCancelSucceedsAfterSuccessfulRedeemButFailedConditions(t *
 
 	// Attempt to Cancel and expects success
 	pdata := abi.MustConvertParams(sys.channelID)
-	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(1000), "cancel", pdata)
+	msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.NewAttoFILFromFIL(1000), MethodCancel, pdata)
 	_, err = sys.ApplyMessage(msg, 100)
 	assert.NoError(t, err)
 }
@@ -994,8 +1307,8 @@ func TestNewPaymentBrokerVoucher(t *testing.T) {
 
 		// create voucher
 		voucherAmount := types.NewAttoFILFromFIL(100)
-		pdata := abi.MustConvertParams(sys.channelID, voucherAmount, sys.defaultValidAt, nilCondition)
-		msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.ZeroAttoFIL, "voucher", pdata)
+		pdata := abi.MustConvertParams(sys.channelID, big.NewInt(0), big.NewInt(0), voucherAmount, sys.defaultValidAt, []types.Merge{}, nilCondition)
+		msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.ZeroAttoFIL, MethodVoucher, pdata)
 		res, err := sys.ApplyMessage(msg, 9)
 		assert.NoError(t, err)
 		assert.NoError(t, res.ExecutionError)
@@ -1019,7 +1332,7 @@ func TestNewPaymentBrokerVoucher(t *testing.T) {
 
 		// create voucher
 		voucherAmount := types.NewAttoFILFromFIL(100)
-		_, exitCode, err := sys.CallQueryMethod("voucher", 9, notChannelID, voucherAmount, sys.defaultValidAt, nilCondition)
+		_, exitCode, err := sys.CallQueryMethod("voucher", 9, notChannelID, big.NewInt(0), big.NewInt(0), voucherAmount, sys.defaultValidAt, []types.Merge{}, nilCondition)
 		assert.NotEqual(t, uint8(0), exitCode)
 		assert.Contains(t, fmt.Sprintf("%v", err), "unknown")
 	})
@@ -1029,9 +1342,9 @@ func TestNewPaymentBrokerVoucher(t *testing.T) {
 
 		// create voucher
 		voucherAmount := types.NewAttoFILFromFIL(2000)
-		args := abi.MustConvertParams(sys.channelID, voucherAmount, sys.defaultValidAt, nilCondition)
+		args := abi.MustConvertParams(sys.channelID, big.NewInt(0), big.NewInt(0), voucherAmount, sys.defaultValidAt, []types.Merge{}, nilCondition)
 
-		msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.ZeroAttoFIL, "voucher", args)
+		msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.ZeroAttoFIL, MethodVoucher, args)
 		res, err := sys.ApplyMessage(msg, 9)
 		assert.NoError(t, err)
 		assert.NotEqual(t, uint8(0), res.Receipt.ExitCode)
@@ -1049,8 +1362,8 @@ func TestNewPaymentBrokerVoucher(t *testing.T) {
 
 		// create voucher
 		voucherAmount := types.NewAttoFILFromFIL(100)
-		pdata := abi.MustConvertParams(sys.channelID, voucherAmount, sys.defaultValidAt, condition)
-		msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.ZeroAttoFIL, "voucher", pdata)
+		pdata := abi.MustConvertParams(sys.channelID, big.NewInt(0), big.NewInt(0), voucherAmount, sys.defaultValidAt, []types.Merge{}, condition)
+		msg := types.NewMessage(sys.payer, address.PaymentBrokerAddress, 1, types.ZeroAttoFIL, MethodVoucher, pdata)
 		res, err := sys.ApplyMessage(msg, 9)
 		assert.NoError(t, err)
 		assert.NoError(t, res.ExecutionError)
@@ -1083,28 +1396,43 @@ func TestSignVoucher(t *testing.T) {
 		require := require.New(t)
 		assert := assert.New(t)
 
-		sig, err := SignVoucher(channelId, value, blockHeight, payer, nilCondition, mockSigner)
+		sig, err := SignVoucher(channelId, 0, 0, value, blockHeight, nil, payer, nilCondition, mockSigner)
 		require.NoError(err)
 
-		assert.True(VerifyVoucherSignature(payer, channelId, value, blockHeight, nilCondition, sig))
-		assert.False(VerifyVoucherSignature(payer, channelId, value, blockHeight, condition, sig))
+		assert.True(VerifyVoucherSignature(payer, channelId, 0, 0, value, blockHeight, nil, nilCondition, sig))
+		assert.False(VerifyVoucherSignature(payer, channelId, 0, 0, value, blockHeight, nil, condition, sig))
 	})
 
 	t.Run("validates signatures with condition", func(t *testing.T) {
 		require := require.New(t)
 		assert := assert.New(t)
 
-		sig, err := SignVoucher(channelId, value, blockHeight, payer, condition, mockSigner)
+		sig, err := SignVoucher(channelId, 0, 0, value, blockHeight, nil, payer, condition, mockSigner)
 		require.NoError(err)
 
-		assert.True(VerifyVoucherSignature(payer, channelId, value, blockHeight, condition, sig))
-		assert.False(VerifyVoucherSignature(payer, channelId, value, blockHeight, nilCondition, sig))
+		assert.True(VerifyVoucherSignature(payer, channelId, 0, 0, value, blockHeight, nil, condition, sig))
+		assert.False(VerifyVoucherSignature(payer, channelId, 0, 0, value, blockHeight, nil, nilCondition, sig))
+	})
+
+	t.Run("validates signatures with lane, nonce and merges", func(t *testing.T) {
+		require := require.New(t)
+		assert := assert.New(t)
+
+		merges := []types.Merge{{Lane: 2, Nonce: 1}}
+		sig, err := SignVoucher(channelId, 1, 4, value, blockHeight, merges, payer, nilCondition, mockSigner)
+		require.NoError(err)
+
+		assert.True(VerifyVoucherSignature(payer, channelId, 1, 4, value, blockHeight, merges, nilCondition, sig))
+		assert.False(VerifyVoucherSignature(payer, channelId, 2, 4, value, blockHeight, merges, nilCondition, sig))
+		assert.False(VerifyVoucherSignature(payer, channelId, 1, 5, value, blockHeight, merges, nilCondition, sig))
+		assert.False(VerifyVoucherSignature(payer, channelId, 1, 4, value, blockHeight, nil, nilCondition, sig))
+		assert.False(VerifyVoucherSignature(payer, channelId, 1, 4, value, blockHeight, []types.Merge{{Lane: 2, Nonce: 2}}, nilCondition, sig))
 	})
 }
 
 func establishChannel(ctx context.Context, st state.Tree, vms vm.StorageMap, from address.Address, target address.Address, nonce uint64, amt types.AttoFIL, eol *types.BlockHeight) *types.ChannelID {
-	pdata := abi.MustConvertParams(target, eol)
-	msg := types.NewMessage(from, address.PaymentBrokerAddress, nonce, amt, "createChannel", pdata)
+	pdata := abi.MustConvertParams(target, eol, types.NewBlockHeight(DefaultSettlePeriod))
+	msg := types.NewMessage(from, address.PaymentBrokerAddress, nonce, amt, MethodCreateChannel, pdata)
 	result, err := th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(0))
 	if err != nil {
 		panic(err)
@@ -1186,7 +1514,11 @@ func setup(t *testing.T) system {
 }
 
 func (sys *system) Signature(amt types.AttoFIL, validAt *types.BlockHeight, condition *types.Predicate) ([]byte, error) {
-	sig, err := SignVoucher(sys.channelID, amt, validAt, sys.payer, condition, mockSigner)
+	return sys.LaneSignature(0, 0, amt, validAt, nil, condition)
+}
+
+func (sys *system) LaneSignature(lane uint64, nonce uint64, amt types.AttoFIL, validAt *types.BlockHeight, merges []types.Merge, condition *types.Predicate) ([]byte, error) {
+	sig, err := SignVoucher(sys.channelID, lane, nonce, amt, validAt, merges, sys.payer, condition, mockSigner)
 	if err != nil {
 		return nil, err
 	}
@@ -1204,25 +1536,34 @@ func (sys *system) CallQueryMethod(method string, height uint64, params ...inter
 func (sys *system) ApplyRedeemMessage(target address.Address, amtInt uint64, nonce uint64) (*consensus.ApplicationResult, error) {
 	sys.t.Helper()
 
-	return sys.applySignatureMessage(target, amtInt, sys.defaultValidAt, nonce, "redeem", 0, nil)
+	return sys.applySignatureMessage(target, amtInt, sys.defaultValidAt, nonce, MethodRedeem, 0, nil)
 }
 
 func (sys *system) ApplyRedeemMessageWithBlockHeight(target address.Address, amtInt uint64, nonce uint64, height uint64) (*consensus.ApplicationResult, error) {
 	sys.t.Helper()
 
-	return sys.applySignatureMessage(target, amtInt, sys.defaultValidAt, nonce, "redeem", height, nil)
+	return sys.applySignatureMessage(target, amtInt, sys.defaultValidAt, nonce, MethodRedeem, height, nil)
 }
 
 func (sys *system) ApplyCloseMessage(target address.Address, amtInt uint64, nonce uint64) (*consensus.ApplicationResult, error) {
 	sys.t.Helper()
 
-	return sys.applySignatureMessage(target, amtInt, sys.defaultValidAt, nonce, "close", 0, nil)
+	return sys.applySignatureMessage(target, amtInt, sys.defaultValidAt, nonce, MethodClose, 0, nil)
+}
+
+func (sys *system) applySettleMessage(from address.Address, nonce uint64, height uint64) (*consensus.ApplicationResult, error) {
+	sys.t.Helper()
+
+	pdata := abi.MustConvertParams(sys.payer, sys.channelID)
+	msg := types.NewMessage(from, address.PaymentBrokerAddress, nonce, types.NewAttoFILFromFIL(0), MethodSettle, pdata)
+
+	return sys.ApplyMessage(msg, height)
 }
 
-func (sys *system) ApplySignatureMessageWithValidAtAndBlockHeight(target address.Address, amtInt uint64, nonce uint64, validAt uint64, height uint64, method string) (*consensus.ApplicationResult, error) {
+func (sys *system) ApplySignatureMessageWithValidAtAndBlockHeight(target address.Address, amtInt uint64, nonce uint64, validAt uint64, height uint64, method types.MethodID) (*consensus.ApplicationResult, error) {
 	sys.t.Helper()
 
-	if method != "redeem" && method != "close" {
+	if method != MethodRedeem && method != MethodClose {
 		sys.t.Fatalf("method %s is not a signature method", method)
 	}
 
@@ -1249,19 +1590,35 @@ func (sys *system) retrieveChannel(paymentBroker *actor.Actor) *PaymentChannel {
 
 // applySignatureMessage signs voucher parameters and then creates a redeem or close message with all
 // the voucher parameters and the signature, sends it to the payment broker, and returns the result
-func (sys *system) applySignatureMessage(target address.Address, amtInt uint64, validAt *types.BlockHeight, nonce uint64, method string, height uint64, condition *types.Predicate, suppliedParams ...interface{}) (*consensus.ApplicationResult, error) {
+func (sys *system) applySignatureMessage(target address.Address, amtInt uint64, validAt *types.BlockHeight, nonce uint64, method types.MethodID, height uint64, condition *types.Predicate, suppliedParams ...interface{}) (*consensus.ApplicationResult, error) {
 	sys.t.Helper()
 
 	amt := types.NewAttoFILFromFIL(amtInt)
 	signature, err := sys.Signature(amt, validAt, condition)
 	require.NoError(sys.t, err)
 
-	pdata := abi.MustConvertParams(sys.payer, sys.channelID, amt, validAt, condition, signature, suppliedParams)
+	pdata := abi.MustConvertParams(sys.payer, sys.channelID, big.NewInt(0), big.NewInt(0), amt, validAt, []types.Merge{}, condition, signature, suppliedParams)
 	msg := types.NewMessage(target, address.PaymentBrokerAddress, nonce, types.NewAttoFILFromFIL(0), method, pdata)
 
 	return sys.ApplyMessage(msg, height)
 }
 
+// applyLaneMessage signs a voucher for the given lane, nonce and merges and redeems or closes the
+// channel with it, sending from the channel target with the given message nonce
+func (sys *system) applyLaneMessage(method types.MethodID, msgNonce uint64, lane uint64, nonce uint64, amtInt uint64, merges []types.Merge) (*consensus.ApplicationResult, error) {
+	sys.t.Helper()
+
+	amt := types.NewAttoFILFromFIL(amtInt)
+	signature, err := sys.LaneSignature(lane, nonce, amt, sys.defaultValidAt, merges, nil)
+	require.NoError(sys.t, err)
+
+	var condition *types.Predicate
+	pdata := abi.MustConvertParams(sys.payer, sys.channelID, new(big.Int).SetUint64(lane), new(big.Int).SetUint64(nonce), amt, sys.defaultValidAt, merges, condition, signature, []interface{}{})
+	msg := types.NewMessage(sys.target, address.PaymentBrokerAddress, msgNonce, types.NewAttoFILFromFIL(0), method, pdata)
+
+	return sys.ApplyMessage(msg, 0)
+}
+
 func (sys *system) ApplyMessage(msg *types.Message, height uint64) (*consensus.ApplicationResult, error) {
 	return th.ApplyTestMessage(sys.st, sys.vms, msg, types.NewBlockHeight(height))
 }
@@ -1290,7 +1647,8 @@ var _ exec.ExecutableActor = (*PBTestActor)(nil)
 // Exports returns the list of fake actor exported functions.
 func (ma *PBTestActor) Exports() exec.Exports {
 	return exec.Exports{
-		"paramsNotZero": &exec.FunctionSignature{
+		1: &exec.FunctionSignature{
+			Name:   "paramsNotZero",
 			Params: []abi.Type{abi.Address, abi.SectorID, abi.BlockHeight},
 			Return: nil,
 		},
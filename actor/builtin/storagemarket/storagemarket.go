@@ -1,9 +1,12 @@
 package storagemarket
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"math/big"
+	"sort"
+	"strconv"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-hamt-ipld"
@@ -24,16 +27,39 @@ const (
 	ErrUnknownMiner = 34
 	// ErrUnsupportedSectorSize indicates that the sector size is incompatible with the proofs mode.
 	ErrUnsupportedSectorSize = 44
+	// ErrUnknownDeal indicates that no deal was found with the given ID.
+	ErrUnknownDeal = 45
+	// ErrInvalidDeal indicates that the terms of a proposed deal are invalid.
+	ErrInvalidDeal = 46
+	// ErrDealAlreadyAccepted indicates an attempt to accept or cancel a deal that has already been accepted.
+	ErrDealAlreadyAccepted = 47
+	// ErrCallerUnauthorized signals an unauthorized caller.
+	ErrCallerUnauthorized = 48
+	// ErrInsufficientCollateral indicates that the miner's escrow balance does not cover the deal collateral.
+	ErrInsufficientCollateral = 49
+	// ErrInsufficientFunds indicates that an escrow balance does not cover the requested amount.
+	ErrInsufficientFunds = 50
+	// ErrSectorNotCommitted indicates that a deal was sealed into a sector the miner has not committed.
+	ErrSectorNotCommitted = 51
 )
 
 // Errors map error codes to revert errors this actor may return.
 var Errors = map[uint8]error{
-	ErrUnknownMiner:          errors.NewCodedRevertErrorf(ErrUnknownMiner, "unknown miner"),
-	ErrUnsupportedSectorSize: errors.NewCodedRevertErrorf(ErrUnsupportedSectorSize, "sector size is not supported"),
+	ErrUnknownMiner:           errors.NewCodedRevertErrorf(ErrUnknownMiner, "unknown miner"),
+	ErrUnsupportedSectorSize:  errors.NewCodedRevertErrorf(ErrUnsupportedSectorSize, "sector size is not supported"),
+	ErrUnknownDeal:            errors.NewCodedRevertErrorf(ErrUnknownDeal, "unknown deal"),
+	ErrInvalidDeal:            errors.NewCodedRevertErrorf(ErrInvalidDeal, "invalid deal"),
+	ErrDealAlreadyAccepted:    errors.NewCodedRevertErrorf(ErrDealAlreadyAccepted, "deal already accepted"),
+	ErrCallerUnauthorized:     errors.NewCodedRevertErrorf(ErrCallerUnauthorized, "not authorized to call the method"),
+	ErrInsufficientCollateral: errors.NewCodedRevertErrorf(ErrInsufficientCollateral, "insufficient deal collateral"),
+	ErrInsufficientFunds:      errors.NewCodedRevertErrorf(ErrInsufficientFunds, "insufficient escrow balance"),
+	ErrSectorNotCommitted:     errors.NewCodedRevertErrorf(ErrSectorNotCommitted, "sector not committed"),
 }
 
 func init() {
 	cbor.RegisterCborType(State{})
+	cbor.RegisterCborType(Deal{})
+	cbor.RegisterCborType(Balance{})
 	cbor.RegisterCborType(struct{}{})
 }
 
@@ -55,6 +81,77 @@ type State struct {
 	TotalCommittedStorage *types.BytesAmount
 
 	ProofsMode types.ProofsMode
+
+	// Deals is a lookup of published storage deals by deal ID.
+	Deals cid.Cid `refmt:",omitempty"`
+
+	// NextDealID is the ID assigned to the next published deal.
+	NextDealID uint64
+
+	// MinerDeals is a lookup of the IDs of accepted deals by miner address.
+	MinerDeals cid.Cid `refmt:",omitempty"`
+
+	// Balances is a lookup of escrow balances by address.
+	Balances cid.Cid `refmt:",omitempty"`
+}
+
+// Balance is the escrow held by the storage market for an address. Locked funds
+// are committed to deals, as payment for clients or collateral for miners, and
+// Available funds may be committed to new deals or withdrawn.
+type Balance struct {
+	Available types.AttoFIL `json:"available"`
+	Locked    types.AttoFIL `json:"locked"`
+}
+
+// Deal is a storage deal published to the storage market. The client's
+// payment is escrowed by the storage market when the deal is proposed, and the
+// miner's collateral when it is accepted. Payment is released to the miner
+// pro rata as it submits PoSts proving the deal's sector over the duration of
+// the deal, and returned to the client for periods the sector was not proven.
+// The collateral is returned once the deal has run its full duration, after
+// which the deal is removed.
+type Deal struct {
+	ID     uint64          `json:"id"`
+	Client address.Address `json:"client"`
+	Miner  address.Address `json:"miner"`
+
+	// CommP is the piece commitment of the data being stored.
+	CommP []byte `json:"commP"`
+	// Size is the size of the piece in bytes.
+	Size *types.BytesAmount `json:"size"`
+	// Duration is the number of blocks the miner must store the piece for.
+	Duration *types.BlockHeight `json:"duration"`
+
+	// TotalPrice is the amount the client pays for the whole deal.
+	TotalPrice types.AttoFIL `json:"totalPrice"`
+	// Collateral is the amount the miner puts up when accepting the deal.
+	Collateral types.AttoFIL `json:"collateral"`
+
+	// Accepted is true once the miner has accepted the deal and StartHeight
+	// is meaningful.
+	Accepted    bool               `json:"accepted"`
+	StartHeight *types.BlockHeight `json:"startHeight"`
+
+	// PaidThrough is the block height up to which payment has been released.
+	PaidThrough *types.BlockHeight `json:"paidThrough"`
+	// PaymentReleased is the amount of TotalPrice released so far, either to
+	// the miner or back to the client.
+	PaymentReleased types.AttoFIL `json:"paymentReleased"`
+
+	// Sealed is true once the miner has reported the sector holding the piece,
+	// and SectorID is meaningful.
+	Sealed   bool   `json:"sealed"`
+	SectorID uint64 `json:"sectorId"`
+}
+
+// EndHeight returns the block height at which an accepted deal expires.
+func (d *Deal) EndHeight() *types.BlockHeight {
+	return d.StartHeight.Add(d.Duration)
+}
+
+// Active returns true if the deal has been accepted and not yet fully paid.
+func (d *Deal) Active() bool {
+	return d.Accepted && d.PaidThrough.LessThan(d.EndHeight())
 }
 
 // NewActor returns a new storage market actor.
@@ -90,27 +187,95 @@ func (sma *Actor) Exports() exec.Exports {
 	return storageMarketExports
 }
 
+// Method ids of the storage market exports.
+const (
+	MethodCreateStorageMiner  = types.MethodID(1)
+	MethodUpdateStorage       = types.MethodID(2)
+	MethodGetTotalStorage     = types.MethodID(3)
+	MethodGetProofsMode       = types.MethodID(4)
+	MethodGetLateMiners       = types.MethodID(5)
+	MethodAddBalance          = types.MethodID(6)
+	MethodWithdrawBalance     = types.MethodID(7)
+	MethodGetBalance          = types.MethodID(8)
+	MethodProposeDeal         = types.MethodID(9)
+	MethodAcceptDeal          = types.MethodID(10)
+	MethodCancelDeal          = types.MethodID(11)
+	MethodReleaseDealPayments = types.MethodID(12)
+	MethodGetDeal             = types.MethodID(13)
+	MethodSealDeal            = types.MethodID(14)
+)
+
 var storageMarketExports = exec.Exports{
-	"createStorageMiner": &exec.FunctionSignature{
+	MethodCreateStorageMiner: &exec.FunctionSignature{
+		Name:   "createStorageMiner",
 		Params: []abi.Type{abi.BytesAmount, abi.PeerID},
 		Return: []abi.Type{abi.Address},
 	},
-	"updateStorage": &exec.FunctionSignature{
+	MethodUpdateStorage: &exec.FunctionSignature{
+		Name:   "updateStorage",
 		Params: []abi.Type{abi.BytesAmount},
 		Return: nil,
 	},
-	"getTotalStorage": &exec.FunctionSignature{
+	MethodGetTotalStorage: &exec.FunctionSignature{
+		Name:   "getTotalStorage",
 		Params: []abi.Type{},
 		Return: []abi.Type{abi.BytesAmount},
 	},
-	"getProofsMode": &exec.FunctionSignature{
+	MethodGetProofsMode: &exec.FunctionSignature{
+		Name:   "getProofsMode",
 		Params: []abi.Type{},
 		Return: []abi.Type{abi.ProofsMode},
 	},
-	"getLateMiners": &exec.FunctionSignature{
+	MethodGetLateMiners: &exec.FunctionSignature{
+		Name:   "getLateMiners",
 		Params: nil,
 		Return: []abi.Type{abi.MinerPoStStates},
 	},
+	MethodAddBalance: &exec.FunctionSignature{
+		Name:   "addBalance",
+		Params: []abi.Type{abi.Address},
+		Return: nil,
+	},
+	MethodWithdrawBalance: &exec.FunctionSignature{
+		Name:   "withdrawBalance",
+		Params: []abi.Type{abi.Address, abi.AttoFIL},
+		Return: nil,
+	},
+	MethodGetBalance: &exec.FunctionSignature{
+		Name:   "getBalance",
+		Params: []abi.Type{abi.Address},
+		Return: []abi.Type{abi.AttoFIL, abi.AttoFIL},
+	},
+	MethodProposeDeal: &exec.FunctionSignature{
+		Name:   "proposeDeal",
+		Params: []abi.Type{abi.Address, abi.Bytes, abi.BytesAmount, abi.BlockHeight, abi.AttoFIL, abi.AttoFIL},
+		Return: []abi.Type{abi.Integer},
+	},
+	MethodAcceptDeal: &exec.FunctionSignature{
+		Name:   "acceptDeal",
+		Params: []abi.Type{abi.Integer},
+		Return: nil,
+	},
+	MethodCancelDeal: &exec.FunctionSignature{
+		Name:   "cancelDeal",
+		Params: []abi.Type{abi.Integer},
+		Return: nil,
+	},
+	MethodReleaseDealPayments: &exec.FunctionSignature{
+		Name:   "releaseDealPayments",
+		Params: []abi.Type{abi.IntSet},
+		Return: nil,
+	},
+	MethodGetDeal: &exec.FunctionSignature{
+		Name:   "getDeal",
+		Params: []abi.Type{abi.Integer},
+		Return: []abi.Type{abi.Bytes},
+	},
+	MethodSealDeal: &exec.FunctionSignature{
+		Name:   "sealDeal",
+		Params: []abi.Type{abi.Integer, abi.SectorID},
+		Return: nil,
+	},
 }
 
 // CreateStorageMiner creates a new miner which will commit sectors of the
@@ -177,20 +342,8 @@ func (sma *Actor) UpdateStorage(vmctx exec.VMContext, delta *types.BytesAmount)
 
 	var state State
 	_, err := actor.WithState(vmctx, &state, func() (interface{}, error) {
-		miner := vmctx.Message().From
-		ctx := context.Background()
-
-		miners, err := actor.LoadLookup(ctx, vmctx.Storage(), state.Miners)
-		if err != nil {
-			return nil, errors.FaultErrorWrapf(err, "could not load lookup for miner with CID: %s", state.Miners)
-		}
-
-		err = miners.Find(ctx, miner.String(), nil)
-		if err != nil {
-			if err == hamt.ErrNotFound {
-				return nil, Errors[ErrUnknownMiner]
-			}
-			return nil, errors.FaultErrorWrapf(err, "could not load lookup for miner with address: %s", miner)
+		if err := sma.requireMiner(context.Background(), vmctx, state, vmctx.Message().From); err != nil {
+			return nil, err
 		}
 
 		state.TotalCommittedStorage = state.TotalCommittedStorage.Add(delta)
@@ -294,6 +447,642 @@ func (sma *Actor) GetProofsMode(vmctx exec.VMContext) (types.ProofsMode, uint8,
 	return size, 0, nil
 }
 
+// AddBalance credits the message value to the escrow balance of addr. Escrowed funds are
+// available to pay for or collateralize deals involving addr until they are withdrawn.
+func (sma *Actor) AddBalance(vmctx exec.VMContext, addr address.Address) (uint8, error) {
+	if err := vmctx.Charge(actor.DefaultGasCost); err != nil {
+		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	_, err := actor.WithState(vmctx, &state, func() (interface{}, error) {
+		ctx := context.Background()
+
+		balance, err := sma.loadBalance(ctx, vmctx, state, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		balance.Available = balance.Available.Add(vmctx.Message().Value)
+
+		return nil, sma.storeBalance(ctx, vmctx, &state, addr, balance)
+	})
+	if err != nil {
+		return errors.CodeError(err), err
+	}
+
+	return 0, nil
+}
+
+// WithdrawBalance withdraws amount from the available escrow balance of addr. Funds held for
+// an account are returned to it, and funds held for a miner are returned to its owner, who
+// must be the sender.
+func (sma *Actor) WithdrawBalance(vmctx exec.VMContext, addr address.Address, amount types.AttoFIL) (uint8, error) {
+	if err := vmctx.Charge(actor.DefaultGasCost); err != nil {
+		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	if !amount.IsPositive() {
+		return ErrInsufficientFunds, Errors[ErrInsufficientFunds]
+	}
+
+	var state State
+	_, err := actor.WithState(vmctx, &state, func() (interface{}, error) {
+		ctx := context.Background()
+
+		sender := vmctx.Message().From
+		if sender != addr {
+			if err := sma.requireMiner(ctx, vmctx, state, addr); err != nil {
+				return nil, Errors[ErrCallerUnauthorized]
+			}
+			owner, err := sma.getMinerOwner(vmctx, addr)
+			if err != nil {
+				return nil, err
+			}
+			if sender != owner {
+				return nil, Errors[ErrCallerUnauthorized]
+			}
+		}
+
+		balance, err := sma.loadBalance(ctx, vmctx, state, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		if balance.Available.LessThan(amount) {
+			return nil, Errors[ErrInsufficientFunds]
+		}
+		balance.Available = balance.Available.Sub(amount)
+
+		if err := sma.storeBalance(ctx, vmctx, &state, addr, balance); err != nil {
+			return nil, err
+		}
+
+		_, _, err = vmctx.Send(sender, "", amount, nil)
+		return nil, err
+	})
+	if err != nil {
+		return errors.CodeError(err), err
+	}
+
+	return 0, nil
+}
+
+// GetBalance returns the available and locked escrow balances of addr.
+func (sma *Actor) GetBalance(vmctx exec.VMContext, addr address.Address) (types.AttoFIL, types.AttoFIL, uint8, error) {
+	if err := vmctx.Charge(actor.DefaultGasCost); err != nil {
+		return types.ZeroAttoFIL, types.ZeroAttoFIL, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	err := actor.ReadState(vmctx, &state)
+	if err != nil {
+		return types.ZeroAttoFIL, types.ZeroAttoFIL, errors.CodeError(err), err
+	}
+
+	balance, err := sma.loadBalance(context.Background(), vmctx, state, addr)
+	if err != nil {
+		return types.ZeroAttoFIL, types.ZeroAttoFIL, errors.CodeError(err), err
+	}
+
+	return balance.Available, balance.Locked, 0, nil
+}
+
+// ProposeDeal publishes a storage deal with minerAddr for the piece with the given commitment
+// and size. The message value is credited to the client's escrow balance, from which the
+// total price of the deal is then locked until the deal is either cancelled or paid out to
+// the miner. The deal takes effect once the miner accepts it.
+func (sma *Actor) ProposeDeal(vmctx exec.VMContext, minerAddr address.Address, commP []byte, size *types.BytesAmount, duration *types.BlockHeight, totalPrice types.AttoFIL, collateral types.AttoFIL) (*big.Int, uint8, error) {
+	if err := vmctx.Charge(actor.DefaultGasCost); err != nil {
+		return nil, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	if len(commP) != int(types.CommitmentBytesLen) {
+		return nil, ErrInvalidDeal, errors.NewCodedRevertError(ErrInvalidDeal, "invalid sized commP")
+	}
+	if !size.IsPositive() || !duration.GreaterThan(types.NewBlockHeight(0)) {
+		return nil, ErrInvalidDeal, errors.NewCodedRevertError(ErrInvalidDeal, "deal size and duration must be positive")
+	}
+	if totalPrice.IsNegative() || collateral.IsNegative() {
+		return nil, ErrInvalidDeal, errors.NewCodedRevertError(ErrInvalidDeal, "deal price and collateral must not be negative")
+	}
+
+	var state State
+	ret, err := actor.WithState(vmctx, &state, func() (interface{}, error) {
+		ctx := context.Background()
+		client := vmctx.Message().From
+
+		if err := sma.requireMiner(ctx, vmctx, state, minerAddr); err != nil {
+			return nil, err
+		}
+
+		balance, err := sma.loadBalance(ctx, vmctx, state, client)
+		if err != nil {
+			return nil, err
+		}
+
+		balance.Available = balance.Available.Add(vmctx.Message().Value)
+		if balance.Available.LessThan(totalPrice) {
+			return nil, Errors[ErrInsufficientFunds]
+		}
+		balance.Available = balance.Available.Sub(totalPrice)
+		balance.Locked = balance.Locked.Add(totalPrice)
+
+		if err := sma.storeBalance(ctx, vmctx, &state, client, balance); err != nil {
+			return nil, err
+		}
+
+		deal := &Deal{
+			ID:              state.NextDealID,
+			Client:          client,
+			Miner:           minerAddr,
+			CommP:           commP,
+			Size:            size,
+			Duration:        duration,
+			TotalPrice:      totalPrice,
+			Collateral:      collateral,
+			StartHeight:     types.NewBlockHeight(0),
+			PaidThrough:     types.NewBlockHeight(0),
+			PaymentReleased: types.ZeroAttoFIL,
+		}
+
+		state.Deals, err = actor.SetKeyValue(ctx, vmctx.Storage(), state.Deals, dealKey(deal.ID), deal)
+		if err != nil {
+			return nil, errors.FaultErrorWrap(err, "could not store deal")
+		}
+		state.NextDealID++
+
+		return big.NewInt(0).SetUint64(deal.ID), nil
+	})
+	if err != nil {
+		return nil, errors.CodeError(err), err
+	}
+
+	return ret.(*big.Int), 0, nil
+}
+
+// AcceptDeal is called by the worker of the deal's miner to accept a proposed deal. The
+// message value is credited to the miner's escrow balance, from which the deal collateral
+// is then locked until the deal has run its full duration.
+func (sma *Actor) AcceptDeal(vmctx exec.VMContext, dealID *big.Int) (uint8, error) {
+	if err := vmctx.Charge(actor.DefaultGasCost); err != nil {
+		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	_, err := actor.WithState(vmctx, &state, func() (interface{}, error) {
+		ctx := context.Background()
+
+		deal, err := sma.loadDeal(ctx, vmctx, state, dealID.Uint64())
+		if err != nil {
+			return nil, err
+		}
+
+		if deal.Accepted {
+			return nil, Errors[ErrDealAlreadyAccepted]
+		}
+
+		worker, err := sma.getMinerWorker(vmctx, deal.Miner)
+		if err != nil {
+			return nil, err
+		}
+		if vmctx.Message().From != worker {
+			return nil, Errors[ErrCallerUnauthorized]
+		}
+
+		balance, err := sma.loadBalance(ctx, vmctx, state, deal.Miner)
+		if err != nil {
+			return nil, err
+		}
+
+		balance.Available = balance.Available.Add(vmctx.Message().Value)
+		if balance.Available.LessThan(deal.Collateral) {
+			return nil, Errors[ErrInsufficientCollateral]
+		}
+		balance.Available = balance.Available.Sub(deal.Collateral)
+		balance.Locked = balance.Locked.Add(deal.Collateral)
+
+		if err := sma.storeBalance(ctx, vmctx, &state, deal.Miner, balance); err != nil {
+			return nil, err
+		}
+
+		deal.Accepted = true
+		deal.StartHeight = vmctx.BlockHeight()
+		deal.PaidThrough = vmctx.BlockHeight()
+
+		state.Deals, err = actor.SetKeyValue(ctx, vmctx.Storage(), state.Deals, dealKey(deal.ID), deal)
+		if err != nil {
+			return nil, errors.FaultErrorWrap(err, "could not store deal")
+		}
+
+		dealIDs, err := sma.loadMinerDeals(ctx, vmctx, state, deal.Miner)
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, sma.storeMinerDeals(ctx, vmctx, &state, deal.Miner, dealIDs.Add(deal.ID))
+	})
+	if err != nil {
+		return errors.CodeError(err), err
+	}
+
+	return 0, nil
+}
+
+// CancelDeal is called by the client of a deal that has not yet been accepted. It removes
+// the deal and unlocks its price in the client's escrow balance.
+func (sma *Actor) CancelDeal(vmctx exec.VMContext, dealID *big.Int) (uint8, error) {
+	if err := vmctx.Charge(actor.DefaultGasCost); err != nil {
+		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	_, err := actor.WithState(vmctx, &state, func() (interface{}, error) {
+		ctx := context.Background()
+
+		deal, err := sma.loadDeal(ctx, vmctx, state, dealID.Uint64())
+		if err != nil {
+			return nil, err
+		}
+
+		if vmctx.Message().From != deal.Client {
+			return nil, Errors[ErrCallerUnauthorized]
+		}
+		if deal.Accepted {
+			return nil, Errors[ErrDealAlreadyAccepted]
+		}
+
+		state.Deals, err = actor.WithLookup(ctx, vmctx.Storage(), state.Deals, func(lookup exec.Lookup) error {
+			return lookup.Delete(ctx, dealKey(deal.ID))
+		})
+		if err != nil {
+			return nil, errors.FaultErrorWrap(err, "could not delete deal")
+		}
+
+		balance, err := sma.loadBalance(ctx, vmctx, state, deal.Client)
+		if err != nil {
+			return nil, err
+		}
+		balance.Locked = balance.Locked.Sub(deal.TotalPrice)
+		balance.Available = balance.Available.Add(deal.TotalPrice)
+
+		return nil, sma.storeBalance(ctx, vmctx, &state, deal.Client, balance)
+	})
+	if err != nil {
+		return errors.CodeError(err), err
+	}
+
+	return 0, nil
+}
+
+// SealDeal is called by the worker of the deal's miner to report the sector the deal's piece
+// has been sealed into. The sector must have been committed by the miner. Payment for the
+// deal is only released for proving periods in which that sector is proven. Piece inclusion
+// is not verified here, so the storage market trusts the miner to report the right sector.
+func (sma *Actor) SealDeal(vmctx exec.VMContext, dealID *big.Int, sectorID uint64) (uint8, error) {
+	if err := vmctx.Charge(actor.DefaultGasCost); err != nil {
+		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	_, err := actor.WithState(vmctx, &state, func() (interface{}, error) {
+		ctx := context.Background()
+
+		deal, err := sma.loadDeal(ctx, vmctx, state, dealID.Uint64())
+		if err != nil {
+			return nil, err
+		}
+
+		if !deal.Accepted {
+			return nil, errors.NewCodedRevertError(ErrInvalidDeal, "deal has not been accepted")
+		}
+
+		worker, err := sma.getMinerWorker(vmctx, deal.Miner)
+		if err != nil {
+			return nil, err
+		}
+		if vmctx.Message().From != worker {
+			return nil, Errors[ErrCallerUnauthorized]
+		}
+
+		minerState, err := sma.getMinerState(vmctx, deal.Miner)
+		if err != nil {
+			return nil, err
+		}
+		if !minerState.SectorCommitments.Has(sectorID) {
+			return nil, Errors[ErrSectorNotCommitted]
+		}
+
+		deal.Sealed = true
+		deal.SectorID = sectorID
+
+		state.Deals, err = actor.SetKeyValue(ctx, vmctx.Storage(), state.Deals, dealKey(deal.ID), deal)
+		if err != nil {
+			return nil, errors.FaultErrorWrap(err, "could not store deal")
+		}
+
+		return nil, nil
+	})
+	if err != nil {
+		return errors.CodeError(err), err
+	}
+
+	return 0, nil
+}
+
+// dealRelease totals the deal payments released from a client's locked escrow.
+type dealRelease struct {
+	// paid is released to the miner.
+	paid types.AttoFIL
+	// refunded is returned to the client.
+	refunded types.AttoFIL
+}
+
+// ReleaseDealPayments is called by a miner actor when it has successfully submitted a PoSt
+// proving provenSectors. For each of the miner's accepted deals it releases the payment due
+// for the blocks elapsed since the last payment from the client's locked escrow. The payment
+// goes to the miner's available escrow if the deal is sealed into a proven sector, and back
+// to the client's available escrow otherwise. Deals that have run their full duration have
+// their collateral unlocked and are removed.
+func (sma *Actor) ReleaseDealPayments(vmctx exec.VMContext, provenSectors types.IntSet) (uint8, error) {
+	if err := vmctx.Charge(actor.DefaultGasCost); err != nil {
+		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	_, err := actor.WithState(vmctx, &state, func() (interface{}, error) {
+		ctx := context.Background()
+		minerAddr := vmctx.Message().From
+
+		if err := sma.requireMiner(ctx, vmctx, state, minerAddr); err != nil {
+			return nil, err
+		}
+
+		minerBalance, err := sma.loadBalance(ctx, vmctx, state, minerAddr)
+		if err != nil {
+			return nil, err
+		}
+
+		dealIDs, err := sma.loadMinerDeals(ctx, vmctx, state, minerAddr)
+		if err != nil {
+			return nil, err
+		}
+
+		// releases are totalled per client so each client balance is updated once
+		releases := map[address.Address]*dealRelease{}
+		completed := types.EmptyIntSet()
+		state.Deals, err = actor.WithLookup(ctx, vmctx.Storage(), state.Deals, func(lookup exec.Lookup) error {
+			for _, id := range dealIDs.Values() {
+				var deal Deal
+				if err := lookup.Find(ctx, dealKey(id), &deal); err != nil {
+					return err
+				}
+
+				release, ok := releases[deal.Client]
+				if !ok {
+					release = &dealRelease{paid: types.ZeroAttoFIL, refunded: types.ZeroAttoFIL}
+					releases[deal.Client] = release
+				}
+
+				payment := releaseDealPayment(&deal, vmctx.BlockHeight())
+				if deal.Sealed && provenSectors.Has(deal.SectorID) {
+					release.paid = release.paid.Add(payment)
+				} else {
+					release.refunded = release.refunded.Add(payment)
+				}
+
+				if deal.Active() {
+					if err := lookup.Set(ctx, dealKey(deal.ID), &deal); err != nil {
+						return err
+					}
+					continue
+				}
+
+				minerBalance.Locked = minerBalance.Locked.Sub(deal.Collateral)
+				minerBalance.Available = minerBalance.Available.Add(deal.Collateral)
+
+				if err := lookup.Delete(ctx, dealKey(deal.ID)); err != nil {
+					return err
+				}
+				completed = completed.Add(deal.ID)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, errors.FaultErrorWrap(err, "could not update deals")
+		}
+
+		if completed.Size() > 0 {
+			if err := sma.storeMinerDeals(ctx, vmctx, &state, minerAddr, dealIDs.Difference(completed)); err != nil {
+				return nil, err
+			}
+		}
+
+		// Update balances in address order, storage writes are charged gas so the order must
+		// be the same on every node.
+		clients := make([]address.Address, 0, len(releases))
+		for client := range releases {
+			clients = append(clients, client)
+		}
+		sort.Slice(clients, func(i, j int) bool { return bytes.Compare(clients[i].Bytes(), clients[j].Bytes()) < 0 })
+
+		for _, client := range clients {
+			release := releases[client]
+			if client == minerAddr {
+				released := release.paid.Add(release.refunded)
+				minerBalance.Locked = minerBalance.Locked.Sub(released)
+				minerBalance.Available = minerBalance.Available.Add(released)
+				continue
+			}
+			clientBalance, err := sma.loadBalance(ctx, vmctx, state, client)
+			if err != nil {
+				return nil, err
+			}
+			clientBalance.Locked = clientBalance.Locked.Sub(release.paid.Add(release.refunded))
+			clientBalance.Available = clientBalance.Available.Add(release.refunded)
+			if err := sma.storeBalance(ctx, vmctx, &state, client, clientBalance); err != nil {
+				return nil, err
+			}
+			minerBalance.Available = minerBalance.Available.Add(release.paid)
+		}
+
+		return nil, sma.storeBalance(ctx, vmctx, &state, minerAddr, minerBalance)
+	})
+	if err != nil {
+		return errors.CodeError(err), err
+	}
+
+	return 0, nil
+}
+
+// GetDeal returns the cbor encoded deal with the given ID.
+func (sma *Actor) GetDeal(vmctx exec.VMContext, dealID *big.Int) ([]byte, uint8, error) {
+	if err := vmctx.Charge(actor.DefaultGasCost); err != nil {
+		return nil, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	err := actor.ReadState(vmctx, &state)
+	if err != nil {
+		return nil, errors.CodeError(err), err
+	}
+
+	deal, err := sma.loadDeal(context.Background(), vmctx, state, dealID.Uint64())
+	if err != nil {
+		return nil, errors.CodeError(err), err
+	}
+
+	dealBytes, err := cbor.DumpObject(deal)
+	if err != nil {
+		return nil, 1, errors.FaultErrorWrap(err, "could not encode deal")
+	}
+
+	return dealBytes, 0, nil
+}
+
+// releaseDealPayment advances the deal's payment to height and returns the amount of the
+// deal price due for the blocks since the deal was last paid.
+func releaseDealPayment(deal *Deal, height *types.BlockHeight) types.AttoFIL {
+	payUntil := height
+	end := deal.EndHeight()
+	if payUntil.GreaterThan(end) {
+		payUntil = end
+	}
+	if payUntil.LessEqual(deal.PaidThrough) {
+		return types.ZeroAttoFIL
+	}
+
+	// pay the cumulative amount due up to payUntil so rounding never over or under pays
+	elapsed := payUntil.Sub(deal.StartHeight).AsBigInt()
+	due := big.NewInt(0).Mul(deal.TotalPrice.AsBigInt(), elapsed)
+	due.Div(due, deal.Duration.AsBigInt())
+
+	payment := types.NewAttoFIL(due).Sub(deal.PaymentReleased)
+	deal.PaymentReleased = types.NewAttoFIL(due)
+	deal.PaidThrough = payUntil
+
+	return payment
+}
+
+// loadBalance returns the escrow balance of addr, which is empty if nothing has been
+// escrowed for it.
+func (sma *Actor) loadBalance(ctx context.Context, vmctx exec.VMContext, state State, addr address.Address) (*Balance, error) {
+	balance := Balance{Available: types.ZeroAttoFIL, Locked: types.ZeroAttoFIL}
+	err := actor.WithLookupForReading(ctx, vmctx.Storage(), state.Balances, func(lookup exec.Lookup) error {
+		return lookup.Find(ctx, addr.String(), &balance)
+	})
+	if err != nil && err != hamt.ErrNotFound {
+		return nil, errors.FaultErrorWrapf(err, "could not load balance for %s", addr)
+	}
+
+	return &balance, nil
+}
+
+func (sma *Actor) storeBalance(ctx context.Context, vmctx exec.VMContext, state *State, addr address.Address, balance *Balance) error {
+	var err error
+	state.Balances, err = actor.SetKeyValue(ctx, vmctx.Storage(), state.Balances, addr.String(), balance)
+	if err != nil {
+		return errors.FaultErrorWrapf(err, "could not store balance for %s", addr)
+	}
+	return nil
+}
+
+func (sma *Actor) loadDeal(ctx context.Context, vmctx exec.VMContext, state State, dealID uint64) (*Deal, error) {
+	var deal Deal
+	err := actor.WithLookupForReading(ctx, vmctx.Storage(), state.Deals, func(lookup exec.Lookup) error {
+		return lookup.Find(ctx, dealKey(dealID), &deal)
+	})
+	if err != nil {
+		if err == hamt.ErrNotFound {
+			return nil, Errors[ErrUnknownDeal]
+		}
+		return nil, errors.FaultErrorWrapf(err, "could not load deal %d", dealID)
+	}
+
+	return &deal, nil
+}
+
+// loadMinerDeals returns the IDs of the accepted deals of minerAddr that have not yet run
+// their full duration.
+func (sma *Actor) loadMinerDeals(ctx context.Context, vmctx exec.VMContext, state State, minerAddr address.Address) (types.IntSet, error) {
+	dealIDs := types.EmptyIntSet()
+	err := actor.WithLookupForReading(ctx, vmctx.Storage(), state.MinerDeals, func(lookup exec.Lookup) error {
+		return lookup.Find(ctx, minerAddr.String(), &dealIDs)
+	})
+	if err != nil && err != hamt.ErrNotFound {
+		return types.IntSet{}, errors.FaultErrorWrapf(err, "could not load deals for miner %s", minerAddr)
+	}
+
+	return dealIDs, nil
+}
+
+func (sma *Actor) storeMinerDeals(ctx context.Context, vmctx exec.VMContext, state *State, minerAddr address.Address, dealIDs types.IntSet) error {
+	var err error
+	state.MinerDeals, err = actor.WithLookup(ctx, vmctx.Storage(), state.MinerDeals, func(lookup exec.Lookup) error {
+		if dealIDs.Size() == 0 {
+			return lookup.Delete(ctx, minerAddr.String())
+		}
+		return lookup.Set(ctx, minerAddr.String(), dealIDs)
+	})
+	if err != nil {
+		return errors.FaultErrorWrapf(err, "could not store deals for miner %s", minerAddr)
+	}
+	return nil
+}
+
+func (sma *Actor) requireMiner(ctx context.Context, vmctx exec.VMContext, state State, minerAddr address.Address) error {
+	miners, err := actor.LoadLookup(ctx, vmctx.Storage(), state.Miners)
+	if err != nil {
+		return errors.FaultErrorWrapf(err, "could not load lookup for miner with CID: %s", state.Miners)
+	}
+
+	err = miners.Find(ctx, minerAddr.String(), nil)
+	if err != nil {
+		if err == hamt.ErrNotFound {
+			return Errors[ErrUnknownMiner]
+		}
+		return errors.FaultErrorWrapf(err, "could not load lookup for miner with address: %s", minerAddr)
+	}
+
+	return nil
+}
+
+func (sma *Actor) getMinerWorker(vmctx exec.VMContext, minerAddr address.Address) (address.Address, error) {
+	msgResult, _, err := vmctx.Send(minerAddr, "getWorker", types.ZeroAttoFIL, nil)
+	if err != nil {
+		return address.Undef, err
+	}
+
+	return address.NewFromBytes(msgResult[0])
+}
+
+func (sma *Actor) getMinerOwner(vmctx exec.VMContext, minerAddr address.Address) (address.Address, error) {
+	msgResult, _, err := vmctx.Send(minerAddr, "getOwner", types.ZeroAttoFIL, nil)
+	if err != nil {
+		return address.Undef, err
+	}
+
+	return address.NewFromBytes(msgResult[0])
+}
+
+func (sma *Actor) getMinerState(vmctx exec.VMContext, minerAddr address.Address) (types.MinerState, error) {
+	msgResult, _, err := vmctx.Send(minerAddr, "getState", types.ZeroAttoFIL, nil)
+	if err != nil {
+		return types.MinerState{}, err
+	}
+
+	res, err := abi.Deserialize(msgResult[0], abi.MinerState)
+	if err != nil {
+		return types.MinerState{}, errors.FaultErrorWrap(err, "could not decode miner state")
+	}
+
+	return res.Val.(types.MinerState), nil
+}
+
+func dealKey(dealID uint64) string {
+	return strconv.FormatUint(dealID, 10)
+}
+
 func (sma *Actor) getMinerPoStState(vmctx exec.VMContext, minerAddr address.Address) (uint64, error) {
 	msgResult, _, err := vmctx.Send(minerAddr, "getPoStState", types.ZeroAttoFIL, nil)
 	if err != nil {
@@ -4,12 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"math/big"
 	"testing"
 
 	"github.com/filecoin-project/go-filecoin/abi"
 	"github.com/filecoin-project/go-filecoin/actor"
 	"github.com/filecoin-project/go-filecoin/actor/builtin"
 	"github.com/filecoin-project/go-filecoin/actor/builtin/miner"
+	"github.com/filecoin-project/go-filecoin/actor/builtin/storagemarket"
 	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/chain"
 	"github.com/filecoin-project/go-filecoin/state"
@@ -18,6 +20,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/types"
 	"github.com/filecoin-project/go-filecoin/vm"
 
+	cbor "github.com/ipfs/go-ipld-cbor"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -32,7 +35,7 @@ func TestStorageMarketCreateStorageMiner(t *testing.T) {
 
 	pid := th.RequireRandomPeerID(t)
 	pdata := actor.MustConvertParams(types.OneKiBSectorSize, pid)
-	msg := types.NewMessage(address.TestAddress, address.StorageMarketAddress, 0, types.NewAttoFILFromFIL(100), "createStorageMiner", pdata)
+	msg := types.NewMessage(address.TestAddress, address.StorageMarketAddress, 0, types.NewAttoFILFromFIL(100), storagemarket.MethodCreateStorageMiner, pdata)
 	result, err := th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(0))
 	require.NoError(t, err)
 	require.Nil(t, result.ExecutionError)
@@ -68,13 +71,13 @@ func TestStorageMarketCreateStorageMinerDoesNotOverwriteActorBalance(t *testing.
 	minerAddr, err := deriveMinerAddress(address.TestAddress, 0)
 	require.NoError(t, err)
 
-	msg := types.NewMessage(address.TestAddress2, minerAddr, 0, types.NewAttoFILFromFIL(100), "", []byte{})
+	msg := types.NewMessage(address.TestAddress2, minerAddr, 0, types.NewAttoFILFromFIL(100), types.SendMethodID, []byte{})
 	result, err := th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(0))
 	require.NoError(t, err)
 	require.Equal(t, uint8(0), result.Receipt.ExitCode)
 
 	pdata := actor.MustConvertParams(types.OneKiBSectorSize, th.RequireRandomPeerID(t))
-	msg = types.NewMessage(address.TestAddress, address.StorageMarketAddress, 0, types.NewAttoFILFromFIL(200), "createStorageMiner", pdata)
+	msg = types.NewMessage(address.TestAddress, address.StorageMarketAddress, 0, types.NewAttoFILFromFIL(200), storagemarket.MethodCreateStorageMiner, pdata)
 	result, err = th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(0))
 	require.NoError(t, err)
 	require.Equal(t, uint8(0), result.Receipt.ExitCode)
@@ -96,7 +99,7 @@ func TestProofsMode(t *testing.T) {
 	defer cancel()
 
 	st, vms := th.RequireCreateStorages(ctx, t)
-	msg := types.NewMessage(address.TestAddress, address.StorageMarketAddress, 0, types.NewAttoFILFromFIL(14), "getProofsMode", []byte{})
+	msg := types.NewMessage(address.TestAddress, address.StorageMarketAddress, 0, types.NewAttoFILFromFIL(14), storagemarket.MethodGetProofsMode, []byte{})
 	result, err := th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(0))
 
 	require.NoError(t, err)
@@ -361,3 +364,354 @@ func assertGetLateMiners(t *testing.T, st state.Tree, vms vm.StorageMap, height
 	miners := dsz.Val.(*map[string]uint64)
 	return miners
 }
+
+func TestStorageMarketEscrow(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+
+	t.Run("add and withdraw balance", func(t *testing.T) {
+		st, vms := th.RequireCreateStorages(ctx, t)
+		walletBalance := requireActorBalance(t, st, address.TestAddress2)
+
+		res, err := th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress2, address.StorageMarketAddress, 10, 0, "addBalance", nil, address.TestAddress2)
+		require.NoError(t, err)
+		require.NoError(t, res.ExecutionError)
+		require.Equal(t, uint8(0), res.Receipt.ExitCode)
+
+		available, locked := requireGetEscrowBalance(t, st, vms, address.TestAddress2)
+		assert.Equal(t, types.NewAttoFILFromFIL(10), available)
+		assert.Equal(t, types.ZeroAttoFIL, locked)
+
+		res, err = th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress2, address.StorageMarketAddress, 0, 0, "withdrawBalance", nil, address.TestAddress2, types.NewAttoFILFromFIL(4))
+		require.NoError(t, err)
+		require.NoError(t, res.ExecutionError)
+		require.Equal(t, uint8(0), res.Receipt.ExitCode)
+
+		available, _ = requireGetEscrowBalance(t, st, vms, address.TestAddress2)
+		assert.Equal(t, types.NewAttoFILFromFIL(6), available)
+		assert.Equal(t, walletBalance.Sub(types.NewAttoFILFromFIL(6)), requireActorBalance(t, st, address.TestAddress2))
+	})
+
+	t.Run("cannot withdraw more than the available balance", func(t *testing.T) {
+		st, vms := th.RequireCreateStorages(ctx, t)
+
+		res, err := th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress2, address.StorageMarketAddress, 10, 0, "addBalance", nil, address.TestAddress2)
+		require.NoError(t, err)
+		require.Equal(t, uint8(0), res.Receipt.ExitCode)
+
+		res, err = th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress2, address.StorageMarketAddress, 0, 0, "withdrawBalance", nil, address.TestAddress2, types.NewAttoFILFromFIL(11))
+		require.NoError(t, err)
+		assert.Equal(t, uint8(storagemarket.ErrInsufficientFunds), res.Receipt.ExitCode)
+	})
+
+	t.Run("only the owner may withdraw a miner balance", func(t *testing.T) {
+		st, vms := th.RequireCreateStorages(ctx, t)
+		minerAddr := th.CreateTestMiner(t, st, vms, address.TestAddress, th.RequireRandomPeerID(t))
+
+		res, err := th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress2, address.StorageMarketAddress, 10, 0, "addBalance", nil, minerAddr)
+		require.NoError(t, err)
+		require.Equal(t, uint8(0), res.Receipt.ExitCode)
+
+		res, err = th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress2, address.StorageMarketAddress, 0, 0, "withdrawBalance", nil, minerAddr, types.NewAttoFILFromFIL(1))
+		require.NoError(t, err)
+		assert.Equal(t, uint8(storagemarket.ErrCallerUnauthorized), res.Receipt.ExitCode)
+
+		ownerBalance := requireActorBalance(t, st, address.TestAddress)
+		res, err = th.CreateAndApplyTestMessage(t, st, vms, address.StorageMarketAddress, 0, 0, "withdrawBalance", nil, minerAddr, types.NewAttoFILFromFIL(1))
+		require.NoError(t, err)
+		require.NoError(t, res.ExecutionError)
+		require.Equal(t, uint8(0), res.Receipt.ExitCode)
+		assert.Equal(t, ownerBalance.Add(types.NewAttoFILFromFIL(1)), requireActorBalance(t, st, address.TestAddress))
+	})
+}
+
+func TestStorageMarketDeals(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	client := address.TestAddress2
+	dealSize := types.NewBytesAmount(1024)
+	dealDuration := types.NewBlockHeight(10)
+	dealPrice := types.NewAttoFILFromFIL(10)
+	dealCollateral := types.NewAttoFILFromFIL(2)
+
+	requireProposeDeal := func(t *testing.T, st state.Tree, vms vm.StorageMap, minerAddr address.Address, value uint64) uint64 {
+		res, err := th.CreateAndApplyTestMessageFrom(t, st, vms, client, address.StorageMarketAddress, value, 0, "proposeDeal", nil, minerAddr, th.MakeCommitment(), dealSize, dealDuration, dealPrice, dealCollateral)
+		require.NoError(t, err)
+		require.NoError(t, res.ExecutionError)
+		require.Equal(t, uint8(0), res.Receipt.ExitCode)
+		return big.NewInt(0).SetBytes(res.Receipt.Return[0]).Uint64()
+	}
+
+	requireGetDeal := func(t *testing.T, st state.Tree, vms vm.StorageMap, dealID uint64) *storagemarket.Deal {
+		res, err := th.CreateAndApplyTestMessage(t, st, vms, address.StorageMarketAddress, 0, 0, "getDeal", nil, big.NewInt(0).SetUint64(dealID))
+		require.NoError(t, err)
+		require.NoError(t, res.ExecutionError)
+		require.Equal(t, uint8(0), res.Receipt.ExitCode)
+
+		var deal storagemarket.Deal
+		require.NoError(t, cbor.DecodeInto(res.Receipt.Return[0], &deal))
+		return &deal
+	}
+
+	requireAcceptDeal := func(t *testing.T, st state.Tree, vms vm.StorageMap, dealID uint64, value uint64, height uint64) {
+		res, err := th.CreateAndApplyTestMessage(t, st, vms, address.StorageMarketAddress, value, height, "acceptDeal", nil, big.NewInt(0).SetUint64(dealID))
+		require.NoError(t, err)
+		require.NoError(t, res.ExecutionError)
+		require.Equal(t, uint8(0), res.Receipt.ExitCode)
+	}
+
+	requireCommitSector := func(t *testing.T, st state.Tree, vms vm.StorageMap, minerAddr address.Address, sectorID uint64, height uint64) {
+		res, err := th.CreateAndApplyTestMessage(t, st, vms, minerAddr, 0, height, "commitSector", nil, sectorID, th.MakeCommitment(), th.MakeCommitment(), th.MakeCommitment(), th.MakeRandomBytes(types.TwoPoRepProofPartitions.ProofLen()))
+		require.NoError(t, err)
+		require.NoError(t, res.ExecutionError)
+		require.Equal(t, uint8(0), res.Receipt.ExitCode)
+	}
+
+	requireSealDeal := func(t *testing.T, st state.Tree, vms vm.StorageMap, dealID uint64, sectorID uint64, height uint64) {
+		res, err := th.CreateAndApplyTestMessage(t, st, vms, address.StorageMarketAddress, 0, height, "sealDeal", nil, big.NewInt(0).SetUint64(dealID), sectorID)
+		require.NoError(t, err)
+		require.NoError(t, res.ExecutionError)
+		require.Equal(t, uint8(0), res.Receipt.ExitCode)
+	}
+
+	requireReleasePayments := func(t *testing.T, st state.Tree, vms vm.StorageMap, minerAddr address.Address, height uint64, provenSectors types.IntSet) {
+		res, err := th.CreateAndApplyTestMessageFrom(t, st, vms, minerAddr, address.StorageMarketAddress, 0, height, "releaseDealPayments", nil, provenSectors)
+		require.NoError(t, err)
+		require.NoError(t, res.ExecutionError)
+		require.Equal(t, uint8(0), res.Receipt.ExitCode)
+	}
+
+	requireNoDeal := func(t *testing.T, st state.Tree, vms vm.StorageMap, dealID uint64) {
+		res, err := th.CreateAndApplyTestMessage(t, st, vms, address.StorageMarketAddress, 0, 0, "getDeal", nil, big.NewInt(0).SetUint64(dealID))
+		require.NoError(t, err)
+		assert.Equal(t, uint8(storagemarket.ErrUnknownDeal), res.Receipt.ExitCode)
+	}
+
+	t.Run("proposing a deal locks the price in escrow", func(t *testing.T) {
+		st, vms := th.RequireCreateStorages(ctx, t)
+		minerAddr := th.CreateTestMiner(t, st, vms, address.TestAddress, th.RequireRandomPeerID(t))
+
+		dealID := requireProposeDeal(t, st, vms, minerAddr, 10)
+		assert.Equal(t, uint64(0), dealID)
+		assert.Equal(t, uint64(1), requireProposeDeal(t, st, vms, minerAddr, 10))
+
+		assert.Equal(t, types.NewAttoFILFromFIL(20), requireActorBalance(t, st, address.StorageMarketAddress))
+		available, locked := requireGetEscrowBalance(t, st, vms, client)
+		assert.Equal(t, types.ZeroAttoFIL, available)
+		assert.Equal(t, types.NewAttoFILFromFIL(20), locked)
+
+		deal := requireGetDeal(t, st, vms, dealID)
+		assert.Equal(t, client, deal.Client)
+		assert.Equal(t, minerAddr, deal.Miner)
+		assert.Equal(t, dealPrice, deal.TotalPrice)
+		assert.Equal(t, dealCollateral, deal.Collateral)
+		assert.False(t, deal.Accepted)
+	})
+
+	t.Run("a pre-funded client can propose deals without value", func(t *testing.T) {
+		st, vms := th.RequireCreateStorages(ctx, t)
+		minerAddr := th.CreateTestMiner(t, st, vms, address.TestAddress, th.RequireRandomPeerID(t))
+
+		res, err := th.CreateAndApplyTestMessageFrom(t, st, vms, client, address.StorageMarketAddress, 25, 0, "addBalance", nil, client)
+		require.NoError(t, err)
+		require.Equal(t, uint8(0), res.Receipt.ExitCode)
+
+		requireProposeDeal(t, st, vms, minerAddr, 0)
+		requireProposeDeal(t, st, vms, minerAddr, 0)
+
+		available, locked := requireGetEscrowBalance(t, st, vms, client)
+		assert.Equal(t, types.NewAttoFILFromFIL(5), available)
+		assert.Equal(t, types.NewAttoFILFromFIL(20), locked)
+
+		res, err = th.CreateAndApplyTestMessageFrom(t, st, vms, client, address.StorageMarketAddress, 0, 0, "proposeDeal", nil, minerAddr, th.MakeCommitment(), dealSize, dealDuration, dealPrice, dealCollateral)
+		require.NoError(t, err)
+		assert.Equal(t, uint8(storagemarket.ErrInsufficientFunds), res.Receipt.ExitCode)
+	})
+
+	t.Run("proposing a deal with an unknown miner fails", func(t *testing.T) {
+		st, vms := th.RequireCreateStorages(ctx, t)
+
+		res, err := th.CreateAndApplyTestMessageFrom(t, st, vms, client, address.StorageMarketAddress, 10, 0, "proposeDeal", nil, address.TestAddress, th.MakeCommitment(), dealSize, dealDuration, dealPrice, dealCollateral)
+		require.NoError(t, err)
+		assert.Equal(t, uint8(storagemarket.ErrUnknownMiner), res.Receipt.ExitCode)
+	})
+
+	t.Run("only the miner worker may accept a deal", func(t *testing.T) {
+		st, vms := th.RequireCreateStorages(ctx, t)
+		minerAddr := th.CreateTestMiner(t, st, vms, address.TestAddress, th.RequireRandomPeerID(t))
+		dealID := requireProposeDeal(t, st, vms, minerAddr, 10)
+
+		res, err := th.CreateAndApplyTestMessageFrom(t, st, vms, client, address.StorageMarketAddress, 2, 0, "acceptDeal", nil, big.NewInt(0).SetUint64(dealID))
+		require.NoError(t, err)
+		assert.Equal(t, uint8(storagemarket.ErrCallerUnauthorized), res.Receipt.ExitCode)
+	})
+
+	t.Run("accepting a deal requires the collateral", func(t *testing.T) {
+		st, vms := th.RequireCreateStorages(ctx, t)
+		minerAddr := th.CreateTestMiner(t, st, vms, address.TestAddress, th.RequireRandomPeerID(t))
+		dealID := requireProposeDeal(t, st, vms, minerAddr, 10)
+
+		res, err := th.CreateAndApplyTestMessage(t, st, vms, address.StorageMarketAddress, 1, 0, "acceptDeal", nil, big.NewInt(0).SetUint64(dealID))
+		require.NoError(t, err)
+		assert.Equal(t, uint8(storagemarket.ErrInsufficientCollateral), res.Receipt.ExitCode)
+	})
+
+	t.Run("client may cancel a deal until it is accepted", func(t *testing.T) {
+		st, vms := th.RequireCreateStorages(ctx, t)
+		minerAddr := th.CreateTestMiner(t, st, vms, address.TestAddress, th.RequireRandomPeerID(t))
+
+		cancelled := requireProposeDeal(t, st, vms, minerAddr, 10)
+		res, err := th.CreateAndApplyTestMessageFrom(t, st, vms, client, address.StorageMarketAddress, 0, 0, "cancelDeal", nil, big.NewInt(0).SetUint64(cancelled))
+		require.NoError(t, err)
+		require.NoError(t, res.ExecutionError)
+		available, locked := requireGetEscrowBalance(t, st, vms, client)
+		assert.Equal(t, dealPrice, available)
+		assert.Equal(t, types.ZeroAttoFIL, locked)
+
+		requireNoDeal(t, st, vms, cancelled)
+
+		accepted := requireProposeDeal(t, st, vms, minerAddr, 0)
+		requireAcceptDeal(t, st, vms, accepted, 2, 0)
+
+		res, err = th.CreateAndApplyTestMessageFrom(t, st, vms, client, address.StorageMarketAddress, 0, 0, "cancelDeal", nil, big.NewInt(0).SetUint64(accepted))
+		require.NoError(t, err)
+		assert.Equal(t, uint8(storagemarket.ErrDealAlreadyAccepted), res.Receipt.ExitCode)
+	})
+
+	t.Run("only the miner worker may seal an accepted deal into a committed sector", func(t *testing.T) {
+		st, vms := th.RequireCreateStorages(ctx, t)
+		minerAddr := th.CreateTestMiner(t, st, vms, address.TestAddress, th.RequireRandomPeerID(t))
+		dealID := requireProposeDeal(t, st, vms, minerAddr, 10)
+		requireCommitSector(t, st, vms, minerAddr, 1, 0)
+
+		res, err := th.CreateAndApplyTestMessage(t, st, vms, address.StorageMarketAddress, 0, 0, "sealDeal", nil, big.NewInt(0).SetUint64(dealID), uint64(1))
+		require.NoError(t, err)
+		assert.Equal(t, uint8(storagemarket.ErrInvalidDeal), res.Receipt.ExitCode)
+
+		requireAcceptDeal(t, st, vms, dealID, 2, 0)
+
+		res, err = th.CreateAndApplyTestMessageFrom(t, st, vms, client, address.StorageMarketAddress, 0, 0, "sealDeal", nil, big.NewInt(0).SetUint64(dealID), uint64(1))
+		require.NoError(t, err)
+		assert.Equal(t, uint8(storagemarket.ErrCallerUnauthorized), res.Receipt.ExitCode)
+
+		res, err = th.CreateAndApplyTestMessage(t, st, vms, address.StorageMarketAddress, 0, 0, "sealDeal", nil, big.NewInt(0).SetUint64(dealID), uint64(2))
+		require.NoError(t, err)
+		assert.Equal(t, uint8(storagemarket.ErrSectorNotCommitted), res.Receipt.ExitCode)
+
+		requireSealDeal(t, st, vms, dealID, 1, 0)
+		deal := requireGetDeal(t, st, vms, dealID)
+		assert.True(t, deal.Sealed)
+		assert.Equal(t, uint64(1), deal.SectorID)
+	})
+
+	t.Run("payment is released to the miner over the deal duration", func(t *testing.T) {
+		st, vms := th.RequireCreateStorages(ctx, t)
+		minerAddr := th.CreateTestMiner(t, st, vms, address.TestAddress, th.RequireRandomPeerID(t))
+		dealID := requireProposeDeal(t, st, vms, minerAddr, 10)
+
+		// value in excess of the collateral stays available in the miner's escrow
+		requireAcceptDeal(t, st, vms, dealID, 5, 10)
+		available, locked := requireGetEscrowBalance(t, st, vms, minerAddr)
+		assert.Equal(t, types.NewAttoFILFromFIL(3), available)
+		assert.Equal(t, dealCollateral, locked)
+
+		deal := requireGetDeal(t, st, vms, dealID)
+		assert.True(t, deal.Accepted)
+		assert.Equal(t, types.NewBlockHeight(10), deal.StartHeight)
+
+		requireCommitSector(t, st, vms, minerAddr, 1, 10)
+		requireSealDeal(t, st, vms, dealID, 1, 10)
+		proven := types.NewIntSet(1)
+
+		// half way through the deal half the price is paid
+		requireReleasePayments(t, st, vms, minerAddr, 15, proven)
+		available, _ = requireGetEscrowBalance(t, st, vms, minerAddr)
+		assert.Equal(t, types.NewAttoFILFromFIL(8), available)
+		_, locked = requireGetEscrowBalance(t, st, vms, client)
+		assert.Equal(t, types.NewAttoFILFromFIL(5), locked)
+
+		// releasing again at the same height pays nothing
+		requireReleasePayments(t, st, vms, minerAddr, 15, proven)
+		available, _ = requireGetEscrowBalance(t, st, vms, minerAddr)
+		assert.Equal(t, types.NewAttoFILFromFIL(8), available)
+
+		// after the deal ends the remainder is paid, the collateral unlocked and the deal removed
+		requireReleasePayments(t, st, vms, minerAddr, 30, proven)
+		available, locked = requireGetEscrowBalance(t, st, vms, minerAddr)
+		assert.Equal(t, types.NewAttoFILFromFIL(15), available)
+		assert.Equal(t, types.ZeroAttoFIL, locked)
+		available, locked = requireGetEscrowBalance(t, st, vms, client)
+		assert.Equal(t, types.ZeroAttoFIL, available)
+		assert.Equal(t, types.ZeroAttoFIL, locked)
+
+		requireNoDeal(t, st, vms, dealID)
+	})
+
+	t.Run("payment for unproven sectors is returned to the client", func(t *testing.T) {
+		st, vms := th.RequireCreateStorages(ctx, t)
+		minerAddr := th.CreateTestMiner(t, st, vms, address.TestAddress, th.RequireRandomPeerID(t))
+		sealed := requireProposeDeal(t, st, vms, minerAddr, 10)
+		unsealed := requireProposeDeal(t, st, vms, minerAddr, 10)
+		requireAcceptDeal(t, st, vms, sealed, 2, 10)
+		requireAcceptDeal(t, st, vms, unsealed, 2, 10)
+		requireCommitSector(t, st, vms, minerAddr, 1, 10)
+		requireSealDeal(t, st, vms, sealed, 1, 10)
+
+		// the sealed deal's sector is faulted, so neither deal is paid
+		requireReleasePayments(t, st, vms, minerAddr, 15, types.EmptyIntSet())
+		available, _ := requireGetEscrowBalance(t, st, vms, minerAddr)
+		assert.Equal(t, types.ZeroAttoFIL, available)
+		available, locked := requireGetEscrowBalance(t, st, vms, client)
+		assert.Equal(t, types.NewAttoFILFromFIL(10), available)
+		assert.Equal(t, types.NewAttoFILFromFIL(10), locked)
+
+		// once the sector is proven only the sealed deal is paid
+		requireReleasePayments(t, st, vms, minerAddr, 18, types.NewIntSet(1))
+		available, _ = requireGetEscrowBalance(t, st, vms, minerAddr)
+		assert.Equal(t, types.NewAttoFILFromFIL(3), available)
+		available, locked = requireGetEscrowBalance(t, st, vms, client)
+		assert.Equal(t, types.NewAttoFILFromFIL(13), available)
+		assert.Equal(t, types.NewAttoFILFromFIL(4), locked)
+
+		assert.Equal(t, types.NewAttoFILFromFIL(8), requireGetDeal(t, st, vms, sealed).PaymentReleased)
+		assert.Equal(t, types.NewAttoFILFromFIL(8), requireGetDeal(t, st, vms, unsealed).PaymentReleased)
+	})
+
+	t.Run("expired deals are removed", func(t *testing.T) {
+		st, vms := th.RequireCreateStorages(ctx, t)
+		minerAddr := th.CreateTestMiner(t, st, vms, address.TestAddress, th.RequireRandomPeerID(t))
+		dealID := requireProposeDeal(t, st, vms, minerAddr, 10)
+		requireAcceptDeal(t, st, vms, dealID, 2, 10)
+
+		// the deal was never proven, so its whole price is returned to the client
+		requireReleasePayments(t, st, vms, minerAddr, 25, types.NewIntSet(1))
+		requireNoDeal(t, st, vms, dealID)
+		available, locked := requireGetEscrowBalance(t, st, vms, client)
+		assert.Equal(t, dealPrice, available)
+		assert.Equal(t, types.ZeroAttoFIL, locked)
+		available, locked = requireGetEscrowBalance(t, st, vms, minerAddr)
+		assert.Equal(t, dealCollateral, available)
+		assert.Equal(t, types.ZeroAttoFIL, locked)
+
+		// later PoSts no longer see the deal
+		requireReleasePayments(t, st, vms, minerAddr, 40, types.NewIntSet(1))
+		available, _ = requireGetEscrowBalance(t, st, vms, client)
+		assert.Equal(t, dealPrice, available)
+	})
+}
+
+func requireActorBalance(t *testing.T, st state.Tree, addr address.Address) types.AttoFIL {
+	a, err := st.GetActor(context.Background(), addr)
+	require.NoError(t, err)
+	return a.Balance
+}
+
+func requireGetEscrowBalance(t *testing.T, st state.Tree, vms vm.StorageMap, addr address.Address) (types.AttoFIL, types.AttoFIL) {
+	res, err := th.CreateAndApplyTestMessage(t, st, vms, address.StorageMarketAddress, 0, 0, "getBalance", nil, addr)
+	require.NoError(t, err)
+	require.NoError(t, res.ExecutionError)
+	require.Equal(t, uint8(0), res.Receipt.ExitCode)
+	return types.NewAttoFILFromBytes(res.Receipt.Return[0]), types.NewAttoFILFromBytes(res.Receipt.Return[1])
+}
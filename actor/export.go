@@ -20,18 +20,18 @@ import (
 // TODO: the work of creating the wrapper should be ideally done at compile time, otherwise at least only once + cached
 // TODO: find a better name, naming is hard..
 // TODO: Ensure the method is not empty. We need to be paranoid we're not calling methods on transfer messages.
-func MakeTypedExport(actor exec.ExecutableActor, method string) exec.ExportedFunc {
-	f, ok := reflect.TypeOf(actor).MethodByName(strings.Title(method))
-	if !ok {
-		panic(fmt.Sprintf("MakeTypedExport could not find passed in method in actor: %s", method))
-	}
-
+func MakeTypedExport(actor exec.ExecutableActor, method types.MethodID) exec.ExportedFunc {
 	exports := actor.Exports()
 	signature, ok := exports[method]
 	if !ok {
 		panic(fmt.Sprintf("MakeTypedExport could not find passed in method in exports: %s", method))
 	}
 
+	f, ok := reflect.TypeOf(actor).MethodByName(strings.Title(signature.Name))
+	if !ok {
+		panic(fmt.Sprintf("MakeTypedExport could not find passed in method in actor: %s", signature.Name))
+	}
+
 	val := f.Func
 	t := f.Type
 
@@ -101,7 +101,7 @@ func MakeTypedExport(actor exec.ExecutableActor, method string) exec.ExportedFun
 				for _, param := range params {
 					paramStr = append(paramStr, param.String())
 				}
-				msg := fmt.Sprintf("actor: %#+v, method: %s, args: %v, error: %s", actor, method, paramStr, outErr.Error())
+				msg := fmt.Sprintf("actor: %#+v, method: %s, args: %v, error: %s", actor, signature.Name, paramStr, outErr.Error())
 				panic(fmt.Sprintf("you are a bad person: error must be either a reverterror or a fault: %v", msg))
 			}
 
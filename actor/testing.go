@@ -24,53 +24,81 @@ type FakeActor struct{}
 
 var _ exec.ExecutableActor = (*FakeActor)(nil)
 
+// Method ids of the exports of the fake actor.
+const (
+	FakeMethodHasReturnValue          = types.MethodID(1)
+	FakeMethodChargeGasAndRevertError = types.MethodID(2)
+	FakeMethodReturnRevertError       = types.MethodID(3)
+	FakeMethodGoodCall                = types.MethodID(4)
+	FakeMethodNonZeroExitCode         = types.MethodID(5)
+	FakeMethodNestedBalance           = types.MethodID(6)
+	FakeMethodSendTokens              = types.MethodID(7)
+	FakeMethodCallSendTokens          = types.MethodID(8)
+	FakeMethodAttemptMultiSpend1      = types.MethodID(9)
+	FakeMethodAttemptMultiSpend2      = types.MethodID(10)
+	FakeMethodRunsAnotherMessage      = types.MethodID(11)
+	FakeMethodBlockLimitTestMethod    = types.MethodID(12)
+)
+
 // FakeActorExports are the exports of the fake actor.
 var FakeActorExports = exec.Exports{
-	"hasReturnValue": &exec.FunctionSignature{
+	FakeMethodHasReturnValue: &exec.FunctionSignature{
+		Name:   "hasReturnValue",
 		Params: nil,
 		Return: []abi.Type{abi.Address},
 	},
-	"chargeGasAndRevertError": &exec.FunctionSignature{
+	FakeMethodChargeGasAndRevertError: &exec.FunctionSignature{
+		Name:   "chargeGasAndRevertError",
 		Params: nil,
 		Return: nil,
 	},
-	"returnRevertError": &exec.FunctionSignature{
+	FakeMethodReturnRevertError: &exec.FunctionSignature{
+		Name:   "returnRevertError",
 		Params: nil,
 		Return: nil,
 	},
-	"goodCall": &exec.FunctionSignature{
+	FakeMethodGoodCall: &exec.FunctionSignature{
+		Name:   "goodCall",
 		Params: nil,
 		Return: nil,
 	},
-	"nonZeroExitCode": &exec.FunctionSignature{
+	FakeMethodNonZeroExitCode: &exec.FunctionSignature{
+		Name:   "nonZeroExitCode",
 		Params: nil,
 		Return: nil,
 	},
-	"nestedBalance": &exec.FunctionSignature{
+	FakeMethodNestedBalance: &exec.FunctionSignature{
+		Name:   "nestedBalance",
 		Params: []abi.Type{abi.Address},
 		Return: nil,
 	},
-	"sendTokens": &exec.FunctionSignature{
+	FakeMethodSendTokens: &exec.FunctionSignature{
+		Name:   "sendTokens",
 		Params: []abi.Type{abi.Address},
 		Return: nil,
 	},
-	"callSendTokens": &exec.FunctionSignature{
+	FakeMethodCallSendTokens: &exec.FunctionSignature{
+		Name:   "callSendTokens",
 		Params: []abi.Type{abi.Address, abi.Address},
 		Return: nil,
 	},
-	"attemptMultiSpend1": &exec.FunctionSignature{
+	FakeMethodAttemptMultiSpend1: &exec.FunctionSignature{
+		Name:   "attemptMultiSpend1",
 		Params: []abi.Type{abi.Address, abi.Address},
 		Return: nil,
 	},
-	"attemptMultiSpend2": &exec.FunctionSignature{
+	FakeMethodAttemptMultiSpend2: &exec.FunctionSignature{
+		Name:   "attemptMultiSpend2",
 		Params: []abi.Type{abi.Address, abi.Address},
 		Return: nil,
 	},
-	"runsAnotherMessage": &exec.FunctionSignature{
+	FakeMethodRunsAnotherMessage: &exec.FunctionSignature{
+		Name:   "runsAnotherMessage",
 		Params: []abi.Type{abi.Address},
 		Return: nil,
 	},
-	"blockLimitTestMethod": &exec.FunctionSignature{
+	FakeMethodBlockLimitTestMethod: &exec.FunctionSignature{
+		Name:   "blockLimitTestMethod",
 		Params: nil,
 		Return: nil,
 	},
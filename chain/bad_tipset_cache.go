@@ -1,42 +1,158 @@
 package chain
 
 import (
+	"sort"
 	"sync"
 
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/repo"
 	"github.com/filecoin-project/go-filecoin/types"
 )
 
-// badTipSetCache keeps track of bad tipsets that the syncer should not try to
+func init() {
+	cbor.RegisterCborType(BadTipSet{})
+}
+
+// badTipSetPrefix is the datastore prefix of persisted bad tipsets.
+const badTipSetPrefix = "/chain/bad"
+
+// BadTipSet records why a tipset was rejected by the syncer.
+type BadTipSet struct {
+	// Key is the key of the rejected tipset.
+	Key types.TipSetKey `json:"key"`
+	// Reason is the validation error of the tipset, or of the ancestor it
+	// was rejected for.
+	Reason string `json:"reason"`
+	// Peer is the peer the tipset was received from.
+	Peer peer.ID `json:"peer"`
+}
+
+// BadTipSetCache keeps track of bad tipsets that the syncer should not try to
 // download. Readers and writers grab a lock. The purpose of this cache is to
 // prevent a node from having to repeatedly invalidate a block (and its children)
-// in the event that the tipset does not conform to the rules of consensus. Note
-// that the cache is only in-memory, so it is reset whenever the node is restarted.
+// in the event that the tipset does not conform to the rules of consensus. A
+// cache with a datastore persists its entries so they survive restarts.
 // TODO: this needs to be limited.
-type badTipSetCache struct {
+type BadTipSetCache struct {
 	mu  sync.Mutex
-	bad map[string]struct{}
+	ds  repo.Datastore
+	bad map[string]*BadTipSet
+}
+
+// NewBadTipSetCache returns a cache persisting its entries in ds, loaded with
+// the entries previously persisted there.
+func NewBadTipSetCache(ds repo.Datastore) (*BadTipSetCache, error) {
+	cache := &BadTipSetCache{
+		ds:  ds,
+		bad: make(map[string]*BadTipSet),
+	}
+
+	results, err := ds.Query(query.Query{Prefix: badTipSetPrefix + "/"})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query bad tipsets")
+	}
+	entries, err := results.Rest()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query bad tipsets")
+	}
+	for _, entry := range entries {
+		var bad BadTipSet
+		if err := cbor.DecodeInto(entry.Value, &bad); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode bad tipset %s", entry.Key)
+		}
+		cache.bad[bad.Key.String()] = &bad
+	}
+	return cache, nil
+}
+
+// newMemoryBadTipSetCache returns a cache that is reset whenever the node is
+// restarted.
+func newMemoryBadTipSetCache() *BadTipSetCache {
+	return &BadTipSetCache{
+		bad: make(map[string]*BadTipSet),
+	}
 }
 
-// AddChain adds the chain of tipsets to the badTipSetCache.  For now it just
-// does the simplest thing and adds all blocks of the chain to the cache.
+// AddChain adds the chain of tipsets received from a peer to the cache. The
+// first tipset failed validation with reason, the rest of the chain descends
+// from it.
+// For now it just does the simplest thing and adds all blocks of the chain to
+// the cache.
 // TODO: might want to cache a random subset once cache size is limited.
-func (cache *badTipSetCache) AddChain(chain []types.TipSet) {
-	for _, ts := range chain {
-		cache.Add(ts.String())
+func (cache *BadTipSetCache) AddChain(chain []types.TipSet, reason error, from peer.ID) error {
+	for i, ts := range chain {
+		bad := &BadTipSet{Key: ts.Key(), Reason: reason.Error(), Peer: from}
+		if i > 0 {
+			bad.Reason = "descends from bad tipset " + chain[0].String()
+		}
+		if err := cache.Add(bad); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
-// Add adds a single tipset key to the badTipSetCache.
-func (cache *badTipSetCache) Add(tsKey string) {
+// Add adds a single bad tipset to the cache.
+func (cache *BadTipSetCache) Add(bad *BadTipSet) error {
 	cache.mu.Lock()
 	defer cache.mu.Unlock()
-	cache.bad[tsKey] = struct{}{}
+
+	if cache.ds != nil {
+		val, err := cbor.DumpObject(bad)
+		if err != nil {
+			return err
+		}
+		if err := cache.ds.Put(badTipSetKey(bad.Key), val); err != nil {
+			return errors.Wrapf(err, "failed to persist bad tipset %s", bad.Key)
+		}
+	}
+	cache.bad[bad.Key.String()] = bad
+	return nil
 }
 
-// Has checks for membership in the badTipSetCache.
-func (cache *badTipSetCache) Has(tsKey string) bool {
+// Has checks for membership in the cache.
+func (cache *BadTipSetCache) Has(tsKey string) bool {
 	cache.mu.Lock()
 	defer cache.mu.Unlock()
 	_, ok := cache.bad[tsKey]
 	return ok
 }
+
+// List returns the cached bad tipsets ordered by key.
+func (cache *BadTipSetCache) List() []*BadTipSet {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	out := make([]*BadTipSet, 0, len(cache.bad))
+	for _, bad := range cache.bad {
+		out = append(out, bad)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Key.String() < out[j].Key.String() })
+	return out
+}
+
+// Clear removes every tipset from the cache, so the syncer will validate
+// them again.
+func (cache *BadTipSetCache) Clear() error {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+
+	if cache.ds != nil {
+		for _, bad := range cache.bad {
+			if err := cache.ds.Delete(badTipSetKey(bad.Key)); err != nil {
+				return errors.Wrapf(err, "failed to forget bad tipset %s", bad.Key)
+			}
+		}
+	}
+	cache.bad = make(map[string]*BadTipSet)
+	return nil
+}
+
+func badTipSetKey(key types.TipSetKey) datastore.Key {
+	return datastore.NewKey(badTipSetPrefix).ChildString(key.String())
+}
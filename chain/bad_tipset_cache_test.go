@@ -0,0 +1,58 @@
+package chain_test
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/chain"
+	"github.com/filecoin-project/go-filecoin/repo"
+	th "github.com/filecoin-project/go-filecoin/testhelpers"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+func TestBadTipSetCache(t *testing.T) {
+	tf.UnitTest(t)
+
+	builder := chain.NewBuilder(t, address.Undef)
+	genesis := builder.NewGenesis()
+	a1 := builder.AppendOn(genesis, 1)
+	a2 := builder.AppendOn(a1, 1)
+	pid := th.RequireIntPeerID(t, 1)
+	ds := repo.NewInMemoryRepo().ChainDatastore()
+
+	cache, err := chain.NewBadTipSetCache(ds)
+	require.NoError(t, err)
+	require.NoError(t, cache.AddChain([]types.TipSet{a1, a2}, errors.New("invalid"), pid))
+	assert.True(t, cache.Has(a1.String()))
+	assert.True(t, cache.Has(a2.String()))
+	assert.False(t, cache.Has(genesis.String()))
+
+	t.Run("persists entries", func(t *testing.T) {
+		reloaded, err := chain.NewBadTipSetCache(ds)
+		require.NoError(t, err)
+		assert.Equal(t, cache.List(), reloaded.List())
+		for _, bad := range reloaded.List() {
+			assert.Equal(t, pid, bad.Peer)
+			if bad.Key.Equals(a1.Key()) {
+				assert.Equal(t, "invalid", bad.Reason)
+			} else {
+				assert.Equal(t, "descends from bad tipset "+a1.String(), bad.Reason)
+			}
+		}
+	})
+
+	t.Run("clears entries", func(t *testing.T) {
+		require.NoError(t, cache.Clear())
+		assert.False(t, cache.Has(a1.String()))
+		assert.Empty(t, cache.List())
+
+		reloaded, err := chain.NewBadTipSetCache(ds)
+		require.NoError(t, err)
+		assert.Empty(t, reloaded.List())
+	})
+}
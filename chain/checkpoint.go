@@ -0,0 +1,251 @@
+package chain
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+// checkpointPrefix is the datastore prefix of the checkpoints set with
+// Store.SetCheckpoint.
+const checkpointPrefix = "/chain/checkpoints"
+
+// checkedCacheSize bounds the number of tipsets the store remembers as
+// passing CheckCheckpoints.
+const checkedCacheSize = 10000
+
+// ErrReorgPastCheckpoint is returned when a chain does not include a
+// checkpointed tipset the store has.
+var ErrReorgPastCheckpoint = errors.New("chain does not include a checkpointed tipset")
+
+// PinCheckpoints adds checkpoints that are not persisted by the store, such as
+// those of the node config. A checkpoint takes effect once its tipset is in
+// the store.
+func (store *Store) PinCheckpoints(keys ...types.TipSetKey) {
+	store.checkpointMu.Lock()
+	defer store.checkpointMu.Unlock()
+	for _, key := range keys {
+		store.checkpoints[key.String()] = key
+	}
+}
+
+// SetCheckpoint checkpoints a tipset of the head chain and persists the
+// checkpoint. Once checkpointed, the store refuses heads that do not descend
+// from the tipset.
+func (store *Store) SetCheckpoint(ctx context.Context, key types.TipSetKey) error {
+	ts, err := store.GetTipSet(key)
+	if err != nil {
+		return errors.Wrapf(err, "unknown tipset %s", key)
+	}
+	head, err := store.GetTipSet(store.GetHead())
+	if err != nil {
+		return err
+	}
+	onHeadChain, err := store.descendsFrom(ctx, head, ts)
+	if err != nil {
+		return err
+	}
+	if !onHeadChain {
+		return errors.Errorf("tipset %s is not on the head chain", key)
+	}
+
+	val, err := cbor.DumpObject(key)
+	if err != nil {
+		return err
+	}
+	if err := store.ds.Put(checkpointKey(key), val); err != nil {
+		return errors.Wrapf(err, "failed to persist checkpoint %s", key)
+	}
+	store.PinCheckpoints(key)
+	return store.updateFinalizedHeight()
+}
+
+// Checkpoints returns the keys of the checkpoints of the store, including
+// those whose tipset is not in the store yet.
+func (store *Store) Checkpoints() []types.TipSetKey {
+	store.checkpointMu.Lock()
+	defer store.checkpointMu.Unlock()
+
+	out := make([]types.TipSetKey, 0, len(store.checkpoints))
+	for _, key := range store.checkpoints {
+		out = append(out, key)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].String() < out[j].String() })
+	return out
+}
+
+// CheckCheckpoints returns ErrReorgPastCheckpoint if the chain ending at ts
+// does not include every checkpointed tipset in the store.
+//
+// The store remembers the tipsets that passed the check, so checking a
+// tipset only walks its chain down to the nearest ancestor that passed.
+func (store *Store) CheckCheckpoints(ctx context.Context, ts types.TipSet) error {
+	checkpoints, err := store.knownCheckpoints()
+	if err != nil {
+		return err
+	}
+	if len(checkpoints) == 0 {
+		return nil
+	}
+	checkedID := ""
+	for _, cp := range checkpoints {
+		checkedID += cp.String()
+	}
+
+	// Checkpoints, highest first, are reached in turn walking down the chain.
+	next := 0
+	for iter := IterAncestors(ctx, store, ts); !iter.Complete() && next < len(checkpoints); err = iter.Next() {
+		if err != nil {
+			return err
+		}
+		if store.isChecked(checkedID, iter.Value()) {
+			next = len(checkpoints)
+			break
+		}
+		h, err := iter.Value().Height()
+		if err != nil {
+			return err
+		}
+		for ; next < len(checkpoints); next++ {
+			cpHeight, err := checkpoints[next].Height()
+			if err != nil {
+				return err
+			}
+			if cpHeight < h {
+				break
+			}
+			if !iter.Value().Equals(checkpoints[next]) {
+				return errors.Wrapf(ErrReorgPastCheckpoint, "%s does not descend from %s", ts.Key(), checkpoints[next].Key())
+			}
+		}
+	}
+	if next < len(checkpoints) {
+		return errors.Wrapf(ErrReorgPastCheckpoint, "%s does not descend from %s", ts.Key(), checkpoints[next].Key())
+	}
+
+	store.setChecked(checkedID, ts)
+	return nil
+}
+
+// isChecked returns whether ts passed CheckCheckpoints with the checkpoints
+// identified by checkedID.
+func (store *Store) isChecked(checkedID string, ts types.TipSet) bool {
+	store.checkpointMu.Lock()
+	defer store.checkpointMu.Unlock()
+	if store.checkedID != checkedID {
+		return false
+	}
+	_, ok := store.checked[ts.String()]
+	return ok
+}
+
+// setChecked records that ts passed CheckCheckpoints with the checkpoints
+// identified by checkedID, forgetting the tipsets checked with other
+// checkpoints.
+func (store *Store) setChecked(checkedID string, ts types.TipSet) {
+	store.checkpointMu.Lock()
+	defer store.checkpointMu.Unlock()
+	if store.checkedID != checkedID || len(store.checked) >= checkedCacheSize {
+		store.checked = make(map[string]struct{})
+		store.checkedID = checkedID
+	}
+	store.checked[ts.String()] = struct{}{}
+}
+
+// FinalizedHeight returns the height of the highest checkpointed tipset in
+// the store, or zero if there is none.
+func (store *Store) FinalizedHeight() (uint64, error) {
+	checkpoints, err := store.knownCheckpoints()
+	if err != nil {
+		return 0, err
+	}
+	if len(checkpoints) == 0 {
+		return 0, nil
+	}
+	return checkpoints[0].Height()
+}
+
+// loadCheckpoints pins the checkpoints persisted by SetCheckpoint.
+func (store *Store) loadCheckpoints() error {
+	results, err := store.ds.Query(query.Query{Prefix: checkpointPrefix + "/"})
+	if err != nil {
+		return errors.Wrap(err, "failed to query checkpoints")
+	}
+	entries, err := results.Rest()
+	if err != nil {
+		return errors.Wrap(err, "failed to query checkpoints")
+	}
+	for _, entry := range entries {
+		var key types.TipSetKey
+		if err := cbor.DecodeInto(entry.Value, &key); err != nil {
+			return errors.Wrapf(err, "failed to decode checkpoint %s", entry.Key)
+		}
+		store.PinCheckpoints(key)
+	}
+	return nil
+}
+
+func (store *Store) updateFinalizedHeight() error {
+	h, err := store.FinalizedHeight()
+	if err != nil {
+		return err
+	}
+	store.reporter.UpdateStatus(finalizedHeight(h))
+	return nil
+}
+
+// knownCheckpoints returns the checkpointed tipsets in the store, highest
+// first.
+func (store *Store) knownCheckpoints() ([]types.TipSet, error) {
+	var known []types.TipSet
+	for _, key := range store.Checkpoints() {
+		ts, err := store.GetTipSet(key)
+		if err != nil {
+			// Not synced yet.
+			continue
+		}
+		known = append(known, ts)
+	}
+
+	heights := make(map[string]uint64, len(known))
+	for _, ts := range known {
+		h, err := ts.Height()
+		if err != nil {
+			return nil, err
+		}
+		heights[ts.String()] = h
+	}
+	sort.Slice(known, func(i, j int) bool { return heights[known[i].String()] > heights[known[j].String()] })
+	return known, nil
+}
+
+// descendsFrom returns whether ancestor is ts or one of its ancestors.
+func (store *Store) descendsFrom(ctx context.Context, ts, ancestor types.TipSet) (bool, error) {
+	ancestorHeight, err := ancestor.Height()
+	if err != nil {
+		return false, err
+	}
+	for iter := IterAncestors(ctx, store, ts); !iter.Complete(); err = iter.Next() {
+		if err != nil {
+			return false, err
+		}
+		h, err := iter.Value().Height()
+		if err != nil {
+			return false, err
+		}
+		if h <= ancestorHeight {
+			return iter.Value().Equals(ancestor), nil
+		}
+	}
+	return false, nil
+}
+
+func checkpointKey(key types.TipSetKey) datastore.Key {
+	return datastore.NewKey(checkpointPrefix).ChildString(key.String())
+}
@@ -0,0 +1,73 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/chain"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+func TestCheckpoints(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	builder, store, syncer := setup(ctx, t)
+	genesis := builder.RequireTipSet(store.GetHead())
+
+	// genesis -> a1 -> a2
+	//         \-> b1 -> b2 -> b3
+	a1 := builder.AppendOn(genesis, 1)
+	a2 := builder.AppendOn(a1, 1)
+	b1 := builder.AppendOn(genesis, 1)
+	b2 := builder.AppendOn(b1, 1)
+	b3 := builder.AppendOn(b2, 1)
+	require.NoError(t, syncer.HandleNewTipSet(ctx, types.NewChainInfo("", a2.Key(), heightFromTip(t, a2)), true))
+	verifyHead(t, store, a2)
+
+	t.Run("only tipsets of the head chain can be checkpointed", func(t *testing.T) {
+		require.NoError(t, store.PutTipSetAndState(ctx, &chain.TipSetAndState{TipSet: b1, TipSetStateRoot: builder.StateForKey(b1.Key())}))
+		assert.Error(t, store.SetCheckpoint(ctx, b1.Key()))
+		assert.Error(t, store.SetCheckpoint(ctx, b2.Key()))
+	})
+
+	require.NoError(t, store.SetCheckpoint(ctx, a1.Key()))
+	assert.Equal(t, []types.TipSetKey{a1.Key()}, store.Checkpoints())
+	finalized, err := store.FinalizedHeight()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), finalized)
+	assert.Equal(t, uint64(1), syncer.Status().FinalizedHeight)
+
+	t.Run("store refuses heads not descending from a checkpoint", func(t *testing.T) {
+		err := store.SetHead(ctx, b1)
+		assert.Equal(t, chain.ErrReorgPastCheckpoint, errors.Cause(err))
+		require.NoError(t, store.SetHead(ctx, a1))
+		require.NoError(t, store.SetHead(ctx, a2))
+	})
+
+	t.Run("syncer refuses heavier forks past a checkpoint", func(t *testing.T) {
+		err := syncer.HandleNewTipSet(ctx, types.NewChainInfo("", b3.Key(), heightFromTip(t, b3)), true)
+		assert.Equal(t, chain.ErrReorgPastCheckpoint, errors.Cause(err))
+		verifyHead(t, store, a2)
+		// The fork is not considered invalid.
+		assert.Empty(t, syncer.BadTipSets())
+	})
+
+	t.Run("pinned checkpoints apply once synced", func(t *testing.T) {
+		a3 := builder.AppendOn(a2, 1)
+		store.PinCheckpoints(a3.Key())
+		finalized, err := store.FinalizedHeight()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), finalized)
+
+		require.NoError(t, syncer.HandleNewTipSet(ctx, types.NewChainInfo("", a3.Key(), heightFromTip(t, a3)), true))
+		finalized, err = store.FinalizedHeight()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), finalized)
+		assert.Equal(t, chain.ErrReorgPastCheckpoint, errors.Cause(store.SetHead(ctx, a2)))
+	})
+}
@@ -0,0 +1,49 @@
+package chain
+
+import (
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	bstore "github.com/ipfs/go-ipfs-blockstore"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/pkg/errors"
+)
+
+// walkDAG visits every block of the cbor DAG rooted at c that is in bs and not
+// in seen, adding it to seen. Links to blocks absent from the blockstore, such
+// as the code cids of builtin actors, are skipped. The links of a block are
+// decoded before it is visited, so visit may delete it.
+func walkDAG(bs bstore.Blockstore, c cid.Cid, seen map[cid.Cid]struct{}, visit func(blocks.Block) error) error {
+	if _, ok := seen[c]; ok {
+		return nil
+	}
+	seen[c] = struct{}{}
+
+	blk, err := bs.Get(c)
+	if err == bstore.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to get block %s", c)
+	}
+
+	var links []cid.Cid
+	if c.Prefix().Codec == cid.DagCBOR {
+		nd, err := cbor.DecodeBlock(blk)
+		if err != nil {
+			return errors.Wrapf(err, "failed to decode block %s", c)
+		}
+		for _, link := range nd.Links() {
+			links = append(links, link.Cid)
+		}
+	}
+
+	if err := visit(blk); err != nil {
+		return err
+	}
+	for _, link := range links {
+		if err := walkDAG(bs, link, seen, visit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
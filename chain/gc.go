@@ -0,0 +1,333 @@
+package chain
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	bstore "github.com/ipfs/go-ipfs-blockstore"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+// stateRootKeyPrefix is the prefix of the datastore keys written by
+// Store.writeTipSetAndState.
+const stateRootKeyPrefix = "/p-"
+
+// GCStats summarizes a garbage collection.
+type GCStats struct {
+	// StateRoots is the number of state roots whose state was deleted.
+	StateRoots int `json:"stateRoots"`
+	// ForkTipSets is the number of tipsets off the head chain that were
+	// forgotten along with their state.
+	ForkTipSets int `json:"forkTipSets"`
+	// Blocks is the number of blocks deleted from the blockstore.
+	Blocks int `json:"blocks"`
+}
+
+// gcDeleteBatchSize is the number of blocks deleted at a time while holding
+// off state writes.
+const gcDeleteBatchSize = 1000
+
+// GarbageCollector prunes the state of old tipsets from the blockstore. It
+// keeps the headers, messages and receipts of the whole chain, the state of
+// the tipsets of the head chain above the finalized height, the state of the
+// most recent finalized tipsets and the genesis state.
+//
+// The blockstore is shared with data that is not part of the chain, so the
+// collector only ever deletes blocks reachable from the state roots it prunes.
+type GarbageCollector struct {
+	store *Store
+	bs    bstore.Blockstore
+	// Serializes collections.
+	mu sync.Mutex
+}
+
+// NewGarbageCollector returns a garbage collector for the state of the chain
+// in store, kept in bs.
+func NewGarbageCollector(store *Store, bs bstore.Blockstore) *GarbageCollector {
+	return &GarbageCollector{
+		store: store,
+		bs:    bs,
+	}
+}
+
+// gcMarks is the outcome of marking the state to keep.
+type gcMarks struct {
+	// marked holds the blocks of the kept state.
+	marked map[cid.Cid]struct{}
+	// roots holds every state root recorded by the store when marking.
+	roots map[cid.Cid]struct{}
+	// pruned holds the state roots to delete.
+	pruned []cid.Cid
+}
+
+// Collect deletes the state of every tipset except the tipsets of the head
+// chain above the finalized height, the keepStateRoots most recent
+// finalized tipsets of the head chain and genesis. A tipset is finalized if
+// it is at or below the store's finalized height, or at least finalityDepth
+// below the head. Tipsets off the head chain older than the oldest kept
+// tipset are forgotten by the store.
+//
+// The head does not change and no new state is written while the state to
+// keep is marked. Blocks are then deleted in batches, each of which holds off
+// state writes only while it keeps the state written since.
+func (gc *GarbageCollector) Collect(ctx context.Context, keepStateRoots, finalityDepth uint) (*GCStats, error) {
+	if keepStateRoots == 0 {
+		return nil, errors.New("must keep the state of at least one finalized tipset")
+	}
+
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	stats := &GCStats{}
+	marks, err := gc.mark(ctx, keepStateRoots, finalityDepth, stats)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the blocks of the pruned state that are not kept. The pruned state
+	// is not written to, so this does not hold off state writes.
+	prunedRoots := make(map[cid.Cid]struct{})
+	garbage := make(map[cid.Cid]struct{})
+	var order []cid.Cid
+	for _, root := range marks.pruned {
+		prunedRoots[root] = struct{}{}
+		err := walkDAG(gc.bs, root, marks.marked, func(blk blocks.Block) error {
+			garbage[blk.Cid()] = struct{}{}
+			order = append(order, blk.Cid())
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to walk state %s", root)
+		}
+	}
+	for c := range garbage {
+		delete(marks.marked, c)
+	}
+
+	for start := 0; start < len(order); start += gcDeleteBatchSize {
+		end := start + gcDeleteBatchSize
+		if end > len(order) {
+			end = len(order)
+		}
+		if err := gc.sweep(order[start:end], marks, garbage, prunedRoots, stats); err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+// mark marks the state to keep and forgets old fork tipsets, while holding
+// off head changes and state writes.
+func (gc *GarbageCollector) mark(ctx context.Context, keepStateRoots, finalityDepth uint, stats *GCStats) (*gcMarks, error) {
+	gc.store.stateWriteMu.Lock()
+	defer gc.store.stateWriteMu.Unlock()
+	gc.store.headChangeMu.Lock()
+	defer gc.store.headChangeMu.Unlock()
+
+	finalized, err := gc.store.FinalizedHeight()
+	if err != nil {
+		return nil, err
+	}
+	head, err := gc.store.GetTipSet(gc.store.GetHead())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get head")
+	}
+	headHeight, err := head.Height()
+	if err != nil {
+		return nil, err
+	}
+	if headHeight > uint64(finalityDepth) && headHeight-uint64(finalityDepth) > finalized {
+		finalized = headHeight - uint64(finalityDepth)
+	}
+
+	var headChain []types.TipSet
+	for iter := IterAncestors(ctx, gc.store, head); !iter.Complete(); err = iter.Next() {
+		if err != nil {
+			return nil, err
+		}
+		headChain = append(headChain, iter.Value())
+	}
+
+	onHeadChain := make(map[string]struct{})
+	for _, ts := range headChain {
+		onHeadChain[ts.String()] = struct{}{}
+	}
+	var kept []types.TipSet
+	var keptFinalized uint
+	for _, ts := range headChain {
+		h, err := ts.Height()
+		if err != nil {
+			return nil, err
+		}
+		if h <= finalized {
+			if keptFinalized == keepStateRoots {
+				break
+			}
+			keptFinalized++
+		}
+		kept = append(kept, ts)
+	}
+	cutoff, err := kept[len(kept)-1].Height()
+	if err != nil {
+		return nil, err
+	}
+	kept = append(kept, headChain[len(headChain)-1])
+	keptTipSets := make(map[string]struct{})
+	for _, ts := range kept {
+		keptTipSets[ts.String()] = struct{}{}
+	}
+
+	results, err := gc.store.ds.Query(query.Query{Prefix: stateRootKeyPrefix})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query state roots")
+	}
+	entries, err := results.Rest()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query state roots")
+	}
+
+	marks := &gcMarks{
+		marked: make(map[cid.Cid]struct{}),
+		roots:  make(map[cid.Cid]struct{}),
+	}
+	var keptRoots []cid.Cid
+	for _, entry := range entries {
+		key, height, err := parseStateRootKey(entry.Key)
+		if err != nil {
+			logStore.Warningf("skipping state root entry %s: %s", entry.Key, err)
+			continue
+		}
+		var root cid.Cid
+		if err := cbor.DecodeInto(entry.Value, &root); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode state root of %s", key)
+		}
+		marks.roots[root] = struct{}{}
+
+		_, isKept := keptTipSets[key.String()]
+		_, isHeadChain := onHeadChain[key.String()]
+		// Recent forks may still become the head.
+		if isKept || (!isHeadChain && height >= cutoff) {
+			keptRoots = append(keptRoots, root)
+			continue
+		}
+		if !isHeadChain {
+			if err := gc.store.ds.Delete(datastore.NewKey(entry.Key)); err != nil {
+				return nil, errors.Wrapf(err, "failed to forget tipset %s", key)
+			}
+			if err := gc.store.tipIndex.Remove(key); err != nil {
+				return nil, err
+			}
+			stats.ForkTipSets++
+		}
+		marks.pruned = append(marks.pruned, root)
+	}
+
+	for _, root := range keptRoots {
+		if err := walkDAG(gc.bs, root, marks.marked, func(blocks.Block) error { return nil }); err != nil {
+			return nil, errors.Wrapf(err, "failed to mark state %s", root)
+		}
+	}
+	return marks, nil
+}
+
+// sweep deletes the blocks of batch that are still garbage, while holding off
+// state writes. It first keeps the blocks of the state recorded since the
+// state was marked, which may share blocks with the pruned state.
+func (gc *GarbageCollector) sweep(batch []cid.Cid, marks *gcMarks, garbage, prunedRoots map[cid.Cid]struct{}, stats *GCStats) error {
+	gc.store.stateWriteMu.Lock()
+	defer gc.store.stateWriteMu.Unlock()
+
+	results, err := gc.store.ds.Query(query.Query{Prefix: stateRootKeyPrefix})
+	if err != nil {
+		return errors.Wrap(err, "failed to query state roots")
+	}
+	entries, err := results.Rest()
+	if err != nil {
+		return errors.Wrap(err, "failed to query state roots")
+	}
+	for _, entry := range entries {
+		var root cid.Cid
+		if err := cbor.DecodeInto(entry.Value, &root); err != nil {
+			return errors.Wrapf(err, "failed to decode state root entry %s", entry.Key)
+		}
+		if _, ok := marks.roots[root]; ok {
+			continue
+		}
+		marks.roots[root] = struct{}{}
+		err := walkDAG(gc.bs, root, marks.marked, func(blk blocks.Block) error {
+			delete(garbage, blk.Cid())
+			return nil
+		})
+		if err != nil {
+			return errors.Wrapf(err, "failed to mark state %s", root)
+		}
+	}
+
+	for _, c := range batch {
+		if _, ok := garbage[c]; !ok {
+			continue
+		}
+		if err := gc.bs.DeleteBlock(c); err != nil {
+			return errors.Wrapf(err, "failed to delete block %s", c)
+		}
+		delete(garbage, c)
+		if _, ok := prunedRoots[c]; ok {
+			stats.StateRoots++
+		}
+		stats.Blocks++
+	}
+	return nil
+}
+
+// Run collects garbage every period until ctx is done.
+func (gc *GarbageCollector) Run(ctx context.Context, keepStateRoots, finalityDepth uint, period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			stats, err := gc.Collect(ctx, keepStateRoots, finalityDepth)
+			if err != nil {
+				logStore.Errorf("chain garbage collection failed: %s", err)
+				continue
+			}
+			logStore.Infof("chain garbage collection pruned %d state roots, %d fork tipsets, %d blocks", stats.StateRoots, stats.ForkTipSets, stats.Blocks)
+		}
+	}
+}
+
+// parseStateRootKey returns the tipset key and height of a datastore key
+// written by Store.writeTipSetAndState.
+func parseStateRootKey(k string) (types.TipSetKey, uint64, error) {
+	heightIdx := strings.LastIndex(k, " h-")
+	if !strings.HasPrefix(k, stateRootKeyPrefix+"{") || heightIdx < 0 {
+		return types.TipSetKey{}, 0, errors.New("not a state root key")
+	}
+	height, err := strconv.ParseUint(k[heightIdx+len(" h-"):], 10, 64)
+	if err != nil {
+		return types.TipSetKey{}, 0, errors.Wrap(err, "invalid height")
+	}
+
+	var ids []cid.Cid
+	for _, s := range strings.Fields(strings.TrimSuffix(k[len(stateRootKeyPrefix)+1:heightIdx], "}")) {
+		id, err := cid.Decode(s)
+		if err != nil {
+			return types.TipSetKey{}, 0, errors.Wrapf(err, "invalid block cid %s", s)
+		}
+		ids = append(ids, id)
+	}
+	return types.NewTipSetKey(ids...), height, nil
+}
@@ -0,0 +1,125 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+
+	bserv "github.com/ipfs/go-blockservice"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-hamt-ipld"
+	bstore "github.com/ipfs/go-ipfs-blockstore"
+	offline "github.com/ipfs/go-ipfs-exchange-offline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/chain"
+	"github.com/filecoin-project/go-filecoin/repo"
+	"github.com/filecoin-project/go-filecoin/state"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+func TestGarbageCollector(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+
+	// genesis -> a1 -> a2 -> a3 -> a4
+	//         \-> b1     \-> b3
+	type fixture struct {
+		store                           *chain.Store
+		bs                              bstore.Blockstore
+		roots                           map[string]cid.Cid
+		shared                          cid.Cid
+		genesis, a1, a2, a3, a4, b1, b3 types.TipSet
+	}
+	setup := func(t *testing.T) *fixture {
+		r := repo.NewInMemoryRepo()
+		bs := bstore.NewBlockstore(r.Datastore())
+		cst := &hamt.CborIpldStore{Blocks: bserv.New(bs, offline.Exchange(bs))}
+
+		f := &fixture{bs: bs, roots: make(map[string]cid.Cid)}
+		builder := chain.NewBuilder(t, address.Undef)
+		f.genesis = builder.NewGenesis()
+		f.a1 = builder.AppendOn(f.genesis, 1)
+		f.a2 = builder.AppendOn(f.a1, 1)
+		f.a3 = builder.AppendOn(f.a2, 1)
+		f.a4 = builder.AppendOn(f.a3, 1)
+		f.b1 = builder.AppendOn(f.genesis, 1)
+		f.b3 = builder.AppendOn(f.a2, 1)
+
+		f.store = chain.NewStore(r.ChainDatastore(), cst, &state.TreeStateLoader{}, chain.NewStatusReporter(), f.genesis.At(0).Cid())
+
+		// Every state links to a shared node, which is kept with the head state.
+		var err error
+		f.shared, err = cst.Put(ctx, map[string]interface{}{"shared": true})
+		require.NoError(t, err)
+		for i, ts := range []types.TipSet{f.genesis, f.a1, f.a2, f.a3, f.a4, f.b1, f.b3} {
+			root, err := cst.Put(ctx, map[string]interface{}{"n": i, "shared": f.shared})
+			require.NoError(t, err)
+			f.roots[ts.String()] = root
+			require.NoError(t, f.store.PutTipSetAndState(ctx, &chain.TipSetAndState{TipSet: ts, TipSetStateRoot: root}))
+		}
+		require.NoError(t, f.store.SetHead(ctx, f.a4))
+		return f
+	}
+
+	// requirePruned checks that the state of a1 and b1 was deleted, that old
+	// forks were forgotten and that everything else was kept.
+	requirePruned := func(t *testing.T, f *fixture) {
+		requireHas := func(ts types.TipSet, expected bool) {
+			has, err := f.bs.Has(f.roots[ts.String()])
+			require.NoError(t, err)
+			assert.Equal(t, expected, has, "state of %s", ts.Key())
+		}
+		for _, ts := range []types.TipSet{f.genesis, f.a2, f.a3, f.a4, f.b3} {
+			requireHas(ts, true)
+		}
+		for _, ts := range []types.TipSet{f.a1, f.b1} {
+			requireHas(ts, false)
+		}
+		has, err := f.bs.Has(f.shared)
+		require.NoError(t, err)
+		assert.True(t, has)
+
+		// Headers of the head chain are kept, old forks are forgotten.
+		assert.True(t, f.store.HasTipSetAndState(ctx, f.a1.Key()))
+		assert.True(t, f.store.HasTipSetAndState(ctx, f.b3.Key()))
+		assert.False(t, f.store.HasTipSetAndState(ctx, f.b1.Key()))
+	}
+
+	t.Run("prunes tipsets at the finality depth below the head", func(t *testing.T) {
+		f := setup(t)
+		gc := chain.NewGarbageCollector(f.store, f.bs)
+
+		// Nothing but genesis is finalized.
+		stats, err := gc.Collect(ctx, 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, &chain.GCStats{}, stats)
+
+		// a2 is finalized and its state is the most recent finalized state.
+		stats, err = gc.Collect(ctx, 1, 2)
+		require.NoError(t, err)
+		assert.Equal(t, &chain.GCStats{StateRoots: 2, ForkTipSets: 1, Blocks: 2}, stats)
+		requirePruned(t, f)
+
+		// Collecting again finds nothing more to delete.
+		stats, err = gc.Collect(ctx, 1, 2)
+		require.NoError(t, err)
+		assert.Equal(t, &chain.GCStats{}, stats)
+	})
+
+	t.Run("prunes tipsets below a checkpoint", func(t *testing.T) {
+		f := setup(t)
+		gc := chain.NewGarbageCollector(f.store, f.bs)
+
+		// The state of tipsets above the finalized height is kept along with
+		// the state of the most recent finalized tipsets.
+		require.NoError(t, f.store.SetCheckpoint(ctx, f.a3.Key()))
+		stats, err := gc.Collect(ctx, 2, 10)
+		require.NoError(t, err)
+		assert.Equal(t, &chain.GCStats{StateRoots: 2, ForkTipSets: 1, Blocks: 2}, stats)
+		requirePruned(t, f)
+	})
+}
@@ -0,0 +1,105 @@
+package chain
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+// HeadChangeTopic is the topic used to publish the changes of the head. Each
+// event is the []HeadChange moving the head to a new tipset.
+const HeadChangeTopic = "head-change"
+
+// Types of HeadChange.
+const (
+	// HCRevert is the type of the change removing a tipset from the head chain.
+	HCRevert = "revert"
+	// HCApply is the type of the change adding a tipset to the head chain.
+	HCApply = "apply"
+	// HCCurrent is the type of the change reporting the head when a
+	// subscription starts.
+	HCCurrent = "current"
+)
+
+// HeadChange is a tipset leaving or joining the head chain.
+type HeadChange struct {
+	Type   string
+	TipSet types.TipSet
+}
+
+// HeadChanges returns the changes moving the head from oldHead to newHead:
+// reverts of the tipsets of the old chain back to the common ancestor, newest
+// first, then applies of the tipsets of the new chain, oldest first.
+func HeadChanges(ctx context.Context, store TipSetProvider, oldHead, newHead types.TipSet) ([]HeadChange, error) {
+	if !oldHead.Defined() {
+		return []HeadChange{{Type: HCApply, TipSet: newHead}}, nil
+	}
+
+	oldTips, newTips, err := CollectTipsToCommonAncestor(ctx, store, oldHead, newHead)
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]HeadChange, 0, len(oldTips)+len(newTips))
+	for _, ts := range oldTips {
+		changes = append(changes, HeadChange{Type: HCRevert, TipSet: ts})
+	}
+	for i := len(newTips) - 1; i >= 0; i-- {
+		changes = append(changes, HeadChange{Type: HCApply, TipSet: newTips[i]})
+	}
+	return changes, nil
+}
+
+// headChangeBuffer is the number of head changes buffered for a subscriber
+// before it is considered to have fallen behind.
+const headChangeBuffer = 16
+
+// SubscribeHeadChanges returns a channel receiving the current head, then the
+// changes of the head until ctx is done. Head changes are never delayed by
+// subscribers: the channel is closed if the subscriber falls more than
+// headChangeBuffer changes behind.
+func (store *Store) SubscribeHeadChanges(ctx context.Context) <-chan []HeadChange {
+	out := make(chan []HeadChange, headChangeBuffer)
+
+	// Subscribe and read the head between head changes, so that each change
+	// is reported exactly once.
+	store.headChangeMu.Lock()
+	sub := store.headEvents.Sub(HeadChangeTopic)
+	store.mu.RLock()
+	head := store.head
+	store.mu.RUnlock()
+	store.headChangeMu.Unlock()
+
+	go func() {
+		defer close(out)
+		defer func() {
+			// The pubsub may be blocked publishing to sub, which must be
+			// drained until it is closed by unsubscribing.
+			go func() {
+				for range sub {
+				}
+			}()
+			store.headEvents.Unsub(sub, HeadChangeTopic)
+		}()
+
+		if head.Defined() {
+			out <- []HeadChange{{Type: HCCurrent, TipSet: head}}
+		}
+		for {
+			select {
+			case e, ok := <-sub:
+				if !ok {
+					return
+				}
+				select {
+				case out <- e.([]HeadChange):
+				default:
+					logStore.Warning("closing head change subscription that fell behind")
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,100 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-hamt-ipld"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/chain"
+	"github.com/filecoin-project/go-filecoin/repo"
+	"github.com/filecoin-project/go-filecoin/state"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+func TestHeadChanges(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+
+	// genesis -> a1 -> a2
+	//         \-> b1 -> b2 -> b3
+	builder := chain.NewBuilder(t, address.Undef)
+	genesis := builder.NewGenesis()
+	a1 := builder.AppendOn(genesis, 1)
+	a2 := builder.AppendOn(a1, 1)
+	b1 := builder.AppendOn(genesis, 1)
+	b2 := builder.AppendOn(b1, 1)
+	b3 := builder.AppendOn(b2, 1)
+
+	t.Run("orders reverts newest first and applies oldest first", func(t *testing.T) {
+		changes, err := chain.HeadChanges(ctx, builder, a2, b3)
+		require.NoError(t, err)
+		assert.Equal(t, []chain.HeadChange{
+			{Type: chain.HCRevert, TipSet: a2},
+			{Type: chain.HCRevert, TipSet: a1},
+			{Type: chain.HCApply, TipSet: b1},
+			{Type: chain.HCApply, TipSet: b2},
+			{Type: chain.HCApply, TipSet: b3},
+		}, changes)
+	})
+
+	t.Run("applies the first head", func(t *testing.T) {
+		changes, err := chain.HeadChanges(ctx, builder, types.UndefTipSet, a1)
+		require.NoError(t, err)
+		assert.Equal(t, []chain.HeadChange{{Type: chain.HCApply, TipSet: a1}}, changes)
+	})
+
+	t.Run("store publishes head changes to subscribers", func(t *testing.T) {
+		store := chain.NewStore(repo.NewInMemoryRepo().ChainDatastore(), hamt.NewCborStore(), &state.TreeStateLoader{}, chain.NewStatusReporter(), genesis.At(0).Cid())
+		for _, ts := range []types.TipSet{genesis, a1, a2, b1, b2, b3} {
+			require.NoError(t, store.PutTipSetAndState(ctx, &chain.TipSetAndState{TipSet: ts, TipSetStateRoot: builder.StateForKey(ts.Key())}))
+		}
+		require.NoError(t, store.SetHead(ctx, a1))
+
+		subCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		sub := store.SubscribeHeadChanges(subCtx)
+		assert.Equal(t, []chain.HeadChange{{Type: chain.HCCurrent, TipSet: a1}}, <-sub)
+
+		require.NoError(t, store.SetHead(ctx, a2))
+		assert.Equal(t, []chain.HeadChange{{Type: chain.HCApply, TipSet: a2}}, <-sub)
+
+		require.NoError(t, store.SetHead(ctx, b2))
+		assert.Equal(t, []chain.HeadChange{
+			{Type: chain.HCRevert, TipSet: a2},
+			{Type: chain.HCRevert, TipSet: a1},
+			{Type: chain.HCApply, TipSet: b1},
+			{Type: chain.HCApply, TipSet: b2},
+		}, <-sub)
+
+		cancel()
+		for range sub {
+		}
+	})
+
+	t.Run("store closes subscriptions that fall behind", func(t *testing.T) {
+		store := chain.NewStore(repo.NewInMemoryRepo().ChainDatastore(), hamt.NewCborStore(), &state.TreeStateLoader{}, chain.NewStatusReporter(), genesis.At(0).Cid())
+		for _, ts := range []types.TipSet{genesis, a1, a2} {
+			require.NoError(t, store.PutTipSetAndState(ctx, &chain.TipSetAndState{TipSet: ts, TipSetStateRoot: builder.StateForKey(ts.Key())}))
+		}
+		require.NoError(t, store.SetHead(ctx, a1))
+		sub := store.SubscribeHeadChanges(ctx)
+
+		// Head changes are not held up by the subscriber.
+		const changes = 500
+		for i := 0; i < changes/2; i++ {
+			require.NoError(t, store.SetHead(ctx, a2))
+			require.NoError(t, store.SetHead(ctx, a1))
+		}
+
+		received := 0
+		for range sub {
+			received++
+		}
+		assert.True(t, received < changes)
+	})
+}
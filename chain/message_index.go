@@ -0,0 +1,346 @@
+package chain
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/repo"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+func init() {
+	cbor.RegisterCborType(MessageLocation{})
+}
+
+// ErrNoMessageIndex is returned when looking up a message location in a store
+// that does not maintain a message index, or whose index failed to follow the
+// head.
+var ErrNoMessageIndex = errors.New("store does not maintain a message index")
+
+// messageIndexPrefix is the datastore namespace of the message index.
+const messageIndexPrefix = "/chain/msgindex"
+
+// messageIndexHeadKey is the key of the head the index was last updated to.
+var messageIndexHeadKey = datastore.NewKey(messageIndexPrefix + "/head")
+
+const (
+	// minRebuildBackoff is the delay before retrying a failed rebuild of the index.
+	minRebuildBackoff = 10 * time.Second
+	// maxRebuildBackoff bounds the delay between retries of failed rebuilds.
+	maxRebuildBackoff = 10 * time.Minute
+)
+
+// MessageLocation is the place where a message was first included in the
+// chain.
+type MessageLocation struct {
+	// TipSet is the key of the tipset including the message.
+	TipSet types.TipSetKey `json:"tipSet"`
+	// Block is the cid of the first block of the tipset including the message.
+	Block cid.Cid `json:"block"`
+	// Index is the position of the message in the messages of that block.
+	Index int `json:"index"`
+}
+
+// MessageIndex maps message cids to their location in the chain ending at the
+// head it was last updated to. The mapping is persisted in the chain
+// datastore alongside the head it is valid for, so that it survives restarts
+// and can be rolled back when the head moves to another fork.
+type MessageIndex struct {
+	ds       repo.Datastore
+	messages MessageProvider
+	// Serializes updates.
+	mu sync.Mutex
+	// stale is set to 1 when the last update failed.
+	stale int32
+
+	// Protects the state of the background rebuild of a stale index.
+	rebuildMu sync.Mutex
+	// target is the head to rebuild the index to.
+	target types.TipSet
+	// rebuilding is set while a rebuild runs.
+	rebuilding bool
+	// backoff is the delay before retrying after the last rebuild failed, zero if it succeeded.
+	backoff time.Duration
+	// retryAt is the earliest time of the next rebuild.
+	retryAt time.Time
+}
+
+// NewMessageIndex creates a message index persisted in ds that reads the
+// messages of blocks from messages.
+func NewMessageIndex(ds repo.Datastore, messages MessageProvider) *MessageIndex {
+	return &MessageIndex{
+		ds:       ds,
+		messages: messages,
+	}
+}
+
+// Get returns the location of the message with cid msgCid and whether the
+// index knows of one. It returns ErrNoMessageIndex if the last update of the
+// index failed.
+func (idx *MessageIndex) Get(msgCid cid.Cid) (*MessageLocation, bool, error) {
+	if atomic.LoadInt32(&idx.stale) == 1 {
+		return nil, false, ErrNoMessageIndex
+	}
+	return idx.get(msgCid)
+}
+
+func (idx *MessageIndex) get(msgCid cid.Cid) (*MessageLocation, bool, error) {
+	bb, err := idx.ds.Get(messageKey(msgCid))
+	if err == datastore.ErrNotFound {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.Wrapf(err, "failed to read location of message %s", msgCid)
+	}
+
+	var loc MessageLocation
+	if err := cbor.DecodeInto(bb, &loc); err != nil {
+		return nil, false, errors.Wrapf(err, "failed to decode location of message %s", msgCid)
+	}
+	return &loc, true, nil
+}
+
+// Update moves the index to newHead. The messages of the tipsets on the
+// previous chain that are not ancestors of newHead are removed, and those of
+// the tipsets on the new chain are added. An index that has never been
+// updated, or whose previous head can't be loaded anymore, is rebuilt from
+// the whole chain. If the update fails, the index reports ErrNoMessageIndex
+// and following updates rebuild it from the whole chain in the background,
+// backing off while rebuilds fail, so that they do not hold up the caller.
+func (idx *MessageIndex) Update(ctx context.Context, tips TipSetProvider, newHead types.TipSet) error {
+	if idx.rebuildLater(tips, newHead) {
+		return nil
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	if err := idx.update(ctx, tips, newHead); err != nil {
+		idx.rebuildMu.Lock()
+		atomic.StoreInt32(&idx.stale, 1)
+		idx.rebuildMu.Unlock()
+		if delErr := idx.ds.Delete(messageIndexHeadKey); delErr != nil && delErr != datastore.ErrNotFound {
+			logStore.Errorf("failed to reset message index head: %s", delErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// rebuildLater returns whether the index is stale. If it is, newHead becomes
+// the head to rebuild the index to, and a background rebuild is started
+// unless one is running or the last one failed less than the backoff ago.
+func (idx *MessageIndex) rebuildLater(tips TipSetProvider, newHead types.TipSet) bool {
+	idx.rebuildMu.Lock()
+	defer idx.rebuildMu.Unlock()
+
+	if atomic.LoadInt32(&idx.stale) == 0 {
+		return false
+	}
+	idx.target = newHead
+	if idx.rebuilding || time.Now().Before(idx.retryAt) {
+		return true
+	}
+	idx.rebuilding = true
+	go idx.rebuild(tips)
+	return true
+}
+
+// rebuild updates the index to the target head until it reaches the latest
+// one, or an update fails.
+func (idx *MessageIndex) rebuild(tips TipSetProvider) {
+	for {
+		idx.rebuildMu.Lock()
+		head := idx.target
+		idx.rebuildMu.Unlock()
+
+		idx.mu.Lock()
+		err := idx.update(context.Background(), tips, head)
+		if err != nil {
+			if delErr := idx.ds.Delete(messageIndexHeadKey); delErr != nil && delErr != datastore.ErrNotFound {
+				logStore.Errorf("failed to reset message index head: %s", delErr)
+			}
+		}
+		idx.mu.Unlock()
+
+		idx.rebuildMu.Lock()
+		if err != nil {
+			idx.backoff *= 2
+			if idx.backoff < minRebuildBackoff {
+				idx.backoff = minRebuildBackoff
+			}
+			if idx.backoff > maxRebuildBackoff {
+				idx.backoff = maxRebuildBackoff
+			}
+			idx.retryAt = time.Now().Add(idx.backoff)
+			idx.rebuilding = false
+			idx.rebuildMu.Unlock()
+			logStore.Errorf("failed to rebuild message index to %s, retrying in %s: %s", head.Key(), idx.backoff, err)
+			return
+		}
+		if idx.target.Equals(head) {
+			atomic.StoreInt32(&idx.stale, 0)
+			idx.backoff = 0
+			idx.rebuilding = false
+			idx.rebuildMu.Unlock()
+			return
+		}
+		idx.rebuildMu.Unlock()
+	}
+}
+
+func (idx *MessageIndex) update(ctx context.Context, tips TipSetProvider, newHead types.TipSet) error {
+	oldHead, err := idx.loadHead(tips)
+	if err != nil {
+		return err
+	}
+
+	var reverted, applied []types.TipSet
+	if oldHead.Defined() {
+		reverted, applied, err = CollectTipsToCommonAncestor(ctx, tips, oldHead, newHead)
+		if err != nil {
+			return errors.Wrap(err, "failed to find common ancestor of indexed head")
+		}
+	} else {
+		if err := idx.clear(); err != nil {
+			return err
+		}
+		applied, err = CollectTipSetsOfHeightAtLeast(ctx, IterAncestors(ctx, tips, newHead), types.NewBlockHeight(0))
+		if err != nil {
+			return errors.Wrap(err, "failed to collect chain to index")
+		}
+	}
+
+	for _, ts := range reverted {
+		if err := idx.revertTipSet(ctx, ts); err != nil {
+			return err
+		}
+	}
+	// Apply from the oldest tipset so that the first inclusion of a message wins.
+	Reverse(applied)
+	for _, ts := range applied {
+		if err := idx.applyTipSet(ctx, ts); err != nil {
+			return err
+		}
+	}
+
+	val, err := cbor.DumpObject(newHead.Key())
+	if err != nil {
+		return err
+	}
+	return idx.ds.Put(messageIndexHeadKey, val)
+}
+
+// loadHead returns the tipset the index was last updated to, or an undefined
+// tipset if there is none or it is no longer known.
+func (idx *MessageIndex) loadHead(tips TipSetProvider) (types.TipSet, error) {
+	bb, err := idx.ds.Get(messageIndexHeadKey)
+	if err == datastore.ErrNotFound {
+		return types.UndefTipSet, nil
+	}
+	if err != nil {
+		return types.UndefTipSet, errors.Wrap(err, "failed to read message index head")
+	}
+
+	var key types.TipSetKey
+	if err := cbor.DecodeInto(bb, &key); err != nil {
+		return types.UndefTipSet, errors.Wrap(err, "failed to decode message index head")
+	}
+	ts, err := tips.GetTipSet(key)
+	if err != nil {
+		logStore.Warningf("message index head %s is unknown, rebuilding index", key)
+		return types.UndefTipSet, nil
+	}
+	return ts, nil
+}
+
+// applyTipSet adds the messages of ts that are not indexed yet.
+func (idx *MessageIndex) applyTipSet(ctx context.Context, ts types.TipSet) error {
+	for i := 0; i < ts.Len(); i++ {
+		blk := ts.At(i)
+		msgs, err := idx.messages.LoadMessages(ctx, blk.Messages)
+		if err != nil {
+			return errors.Wrapf(err, "failed to load messages of block %s", blk.Cid())
+		}
+		for j, msg := range msgs {
+			c, err := msg.Cid()
+			if err != nil {
+				return err
+			}
+			has, err := idx.ds.Has(messageKey(c))
+			if err != nil {
+				return err
+			}
+			if has {
+				continue
+			}
+
+			val, err := cbor.DumpObject(MessageLocation{TipSet: ts.Key(), Block: blk.Cid(), Index: j})
+			if err != nil {
+				return err
+			}
+			if err := idx.ds.Put(messageKey(c), val); err != nil {
+				return errors.Wrapf(err, "failed to index message %s", c)
+			}
+		}
+	}
+	return nil
+}
+
+// revertTipSet removes the messages that were first included by ts.
+func (idx *MessageIndex) revertTipSet(ctx context.Context, ts types.TipSet) error {
+	for i := 0; i < ts.Len(); i++ {
+		msgs, err := idx.messages.LoadMessages(ctx, ts.At(i).Messages)
+		if err != nil {
+			return errors.Wrapf(err, "failed to load messages of block %s", ts.At(i).Cid())
+		}
+		for _, msg := range msgs {
+			c, err := msg.Cid()
+			if err != nil {
+				return err
+			}
+			loc, found, err := idx.get(c)
+			if err != nil {
+				return err
+			}
+			if !found || !loc.TipSet.Equals(ts.Key()) {
+				continue
+			}
+			if err := idx.ds.Delete(messageKey(c)); err != nil {
+				return errors.Wrapf(err, "failed to remove message %s from index", c)
+			}
+		}
+	}
+	return nil
+}
+
+// clear removes every entry of the index.
+func (idx *MessageIndex) clear() error {
+	results, err := idx.ds.Query(query.Query{Prefix: messageIndexPrefix, KeysOnly: true})
+	if err != nil {
+		return errors.Wrap(err, "failed to query message index")
+	}
+	entries, err := results.Rest()
+	if err != nil {
+		return errors.Wrap(err, "failed to query message index")
+	}
+
+	for _, entry := range entries {
+		if err := idx.ds.Delete(datastore.NewKey(entry.Key)); err != nil {
+			return errors.Wrap(err, "failed to clear message index")
+		}
+	}
+	return nil
+}
+
+func messageKey(msgCid cid.Cid) datastore.Key {
+	return datastore.NewKey(messageIndexPrefix + "/msgs/" + msgCid.String())
+}
@@ -0,0 +1,114 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/chain"
+	"github.com/filecoin-project/go-filecoin/repo"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+func TestMessageIndex(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+	mockSigner, _ := types.NewMockSignersAndKeyInfo(1)
+	newMsg := types.NewSignedMessageForTestGetter(mockSigner)
+	m1, m2, m3 := newMsg(), newMsg(), newMsg()
+
+	withMessages := func(msgs ...*types.SignedMessage) func(b *chain.BlockBuilder) {
+		return func(b *chain.BlockBuilder) {
+			b.AddMessages(msgs, types.EmptyReceipts(len(msgs)))
+		}
+	}
+
+	// genesis -> a1 (m1) -> a2 (m2)
+	//         \-> b1 (m3) -> b2 (m1)
+	genesis := builder.NewGenesis()
+	a1 := builder.BuildOneOn(genesis, withMessages(m1))
+	a2 := builder.BuildOneOn(a1, withMessages(m2))
+	b1 := builder.BuildOneOn(genesis, withMessages(m3))
+	b2 := builder.BuildOneOn(b1, withMessages(m1))
+
+	index := chain.NewMessageIndex(repo.NewInMemoryRepo().ChainDatastore(), builder)
+
+	requireLocation := func(msg *types.SignedMessage, ts types.TipSet, idx int) {
+		c, err := msg.Cid()
+		require.NoError(t, err)
+		loc, found, err := index.Get(c)
+		require.NoError(t, err)
+		require.True(t, found)
+		assert.Equal(t, ts.Key(), loc.TipSet)
+		assert.Equal(t, ts.At(0).Cid(), loc.Block)
+		assert.Equal(t, idx, loc.Index)
+	}
+	requireMissing := func(msg *types.SignedMessage) {
+		c, err := msg.Cid()
+		require.NoError(t, err)
+		_, found, err := index.Get(c)
+		require.NoError(t, err)
+		assert.False(t, found)
+	}
+
+	t.Run("indexes the chain on first update", func(t *testing.T) {
+		require.NoError(t, index.Update(ctx, builder, a2))
+		requireLocation(m1, a1, 0)
+		requireLocation(m2, a2, 0)
+		requireMissing(m3)
+	})
+
+	t.Run("rolls back reverted tipsets on reorg", func(t *testing.T) {
+		require.NoError(t, index.Update(ctx, builder, b2))
+		requireLocation(m1, b2, 0)
+		requireLocation(m3, b1, 0)
+		requireMissing(m2)
+
+		require.NoError(t, index.Update(ctx, builder, a2))
+		requireLocation(m1, a1, 0)
+		requireLocation(m2, a2, 0)
+		requireMissing(m3)
+	})
+
+	t.Run("keeps the first inclusion of a message", func(t *testing.T) {
+		a3 := builder.BuildOneOn(a2, withMessages(m3, m1))
+		require.NoError(t, index.Update(ctx, builder, a3))
+		requireLocation(m1, a1, 0)
+		requireLocation(m3, a3, 0)
+	})
+
+	t.Run("recovers from a failed update", func(t *testing.T) {
+		orphan := types.RequireNewTipSet(t, &types.Block{
+			Parents: types.NewTipSetKey(types.CidFromString(t, "unknown")),
+			Height:  5,
+		})
+		assert.Error(t, index.Update(ctx, builder, orphan))
+		c, err := m1.Cid()
+		require.NoError(t, err)
+		_, _, err = index.Get(c)
+		assert.Equal(t, chain.ErrNoMessageIndex, err)
+
+		// The index is rebuilt in the background.
+		require.NoError(t, index.Update(ctx, builder, a2))
+		deadline := time.Now().Add(5 * time.Second)
+		for _, _, err = index.Get(c); err == chain.ErrNoMessageIndex; _, _, err = index.Get(c) {
+			require.True(t, time.Now().Before(deadline), "message index was not rebuilt")
+			time.Sleep(10 * time.Millisecond)
+		}
+		require.NoError(t, err)
+		requireLocation(m1, a1, 0)
+		requireLocation(m2, a2, 0)
+
+		// Once rebuilt, updates apply synchronously again.
+		a3 := builder.BuildOneOn(a2, withMessages(m3))
+		require.NoError(t, index.Update(ctx, builder, a3))
+		requireLocation(m3, a3, 0)
+	})
+}
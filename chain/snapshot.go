@@ -0,0 +1,230 @@
+package chain
+
+import (
+	"context"
+	"io"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-car"
+	carutil "github.com/ipfs/go-car/util"
+	"github.com/ipfs/go-cid"
+	bstore "github.com/ipfs/go-ipfs-blockstore"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+func init() {
+	cbor.RegisterCborType(SnapshotHeader{})
+}
+
+// snapshotStateRootsPerChunk is the number of tipset state roots recorded in
+// each block of the state roots of a snapshot.
+const snapshotStateRootsPerChunk = 1000
+
+// SnapshotHeader is the root of a chain snapshot archive. It identifies the
+// tipset the snapshot was taken at.
+type SnapshotHeader struct {
+	// Head is the key of the most recent tipset in the snapshot.
+	Head types.TipSetKey
+	// StateRoots are the cids of the blocks recording the state roots of the
+	// tipsets of the snapshot, from the head down to genesis. Each block holds
+	// the state roots of up to snapshotStateRootsPerChunk consecutive tipsets.
+	StateRoots []cid.Cid
+	// Genesis is the cid of the genesis block of the chain.
+	Genesis cid.Cid
+}
+
+// snapshotSource provides the tipsets and state roots of the chain to export.
+type snapshotSource interface {
+	TipSetProvider
+	GetTipSetStateRoot(types.TipSetKey) (cid.Cid, error)
+}
+
+// ErrNoRecentState is returned when exporting a snapshot without the state of
+// its head, which importing nodes need to validate the next blocks.
+var ErrNoRecentState = errors.New("must export the state of at least the head")
+
+// ExportSnapshot writes a CAR archive of the chain ending at head to out. The
+// archive holds the headers, messages and receipts of every tipset down to
+// genesis, and the full state of the recentStates most recent tipsets and of
+// genesis, which nodes read their network parameters from. The state roots of
+// all tipsets are recorded so that importing nodes can restore them.
+// recentStates must be at least one.
+func ExportSnapshot(ctx context.Context, chn snapshotSource, bs bstore.Blockstore, head types.TipSet, recentStates uint, out io.Writer) error {
+	if recentStates == 0 {
+		return ErrNoRecentState
+	}
+
+	var tips []types.TipSet
+	var stateRoots []cid.Cid
+	var err error
+	for iter := IterAncestors(ctx, chn, head); !iter.Complete(); err = iter.Next() {
+		if err != nil {
+			return err
+		}
+		ts := iter.Value()
+		stateRoot, err := chn.GetTipSetStateRoot(ts.Key())
+		if err != nil {
+			return errors.Wrapf(err, "failed to get state root of %s", ts.Key())
+		}
+		tips = append(tips, ts)
+		stateRoots = append(stateRoots, stateRoot)
+	}
+	genesis := tips[len(tips)-1]
+	if genesis.Len() != 1 {
+		return errors.Errorf("chain ending at %s does not start with a genesis block", head.Key())
+	}
+
+	var chunks []*cbor.Node
+	var chunkCids []cid.Cid
+	for start := 0; start < len(stateRoots); start += snapshotStateRootsPerChunk {
+		end := start + snapshotStateRootsPerChunk
+		if end > len(stateRoots) {
+			end = len(stateRoots)
+		}
+		chunk, err := cbor.WrapObject(stateRoots[start:end], types.DefaultHashFunction, -1)
+		if err != nil {
+			return err
+		}
+		chunks = append(chunks, chunk)
+		chunkCids = append(chunkCids, chunk.Cid())
+	}
+
+	header, err := cbor.WrapObject(SnapshotHeader{
+		Head:       head.Key(),
+		StateRoots: chunkCids,
+		Genesis:    genesis.At(0).Cid(),
+	}, types.DefaultHashFunction, -1)
+	if err != nil {
+		return err
+	}
+	if err := car.WriteHeader(&car.CarHeader{Roots: []cid.Cid{header.Cid()}, Version: 1}, out); err != nil {
+		return err
+	}
+	for _, nd := range append([]*cbor.Node{header}, chunks...) {
+		if err := carutil.LdWrite(out, nd.Cid().Bytes(), nd.RawData()); err != nil {
+			return err
+		}
+	}
+
+	w := &snapshotWriter{bs: bs, out: out, seen: make(map[cid.Cid]struct{})}
+	for i, ts := range tips {
+		for j := 0; j < ts.Len(); j++ {
+			blk := ts.At(j)
+			for _, c := range []cid.Cid{blk.Cid(), blk.Messages, blk.MessageReceipts} {
+				if err := w.writeBlock(c); err != nil {
+					return err
+				}
+			}
+		}
+
+		if uint(i) >= recentStates && i != len(tips)-1 {
+			continue
+		}
+		if err := w.writeDAG(stateRoots[i]); err != nil {
+			return errors.Wrapf(err, "failed to export state of %s", ts.Key())
+		}
+	}
+	return nil
+}
+
+// ImportSnapshot loads the blocks of a snapshot archive into bs and returns
+// the snapshot header. Use Store.LoadSnapshot to set the chain from it.
+func ImportSnapshot(ctx context.Context, bs bstore.Blockstore, in io.Reader) (*SnapshotHeader, error) {
+	ch, err := car.LoadCar(bs, in)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load snapshot")
+	}
+	if len(ch.Roots) != 1 {
+		return nil, errors.New("expected snapshot with a single root")
+	}
+
+	raw, err := bs.Get(ch.Roots[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read snapshot header")
+	}
+	var header SnapshotHeader
+	if err := cbor.DecodeInto(raw.RawData(), &header); err != nil {
+		return nil, errors.Wrap(err, "failed to decode snapshot header")
+	}
+	return &header, nil
+}
+
+// LoadSnapshot records every tipset of the chain in a snapshot, with the state
+// root recorded for it, whose blocks are in the store's block source, and sets
+// the snapshot's head as the head of the store. The chain must start at the
+// store's genesis block, whose state the store already records.
+func (store *Store) LoadSnapshot(ctx context.Context, header *SnapshotHeader) error {
+	if !header.Genesis.Equals(store.GenesisCid()) {
+		return errors.Errorf("snapshot genesis %s does not match store genesis %s", header.Genesis, store.GenesisCid())
+	}
+
+	var stateRoots []cid.Cid
+	for _, c := range header.StateRoots {
+		var chunk []cid.Cid
+		if err := store.stateAndBlockSource.cborStore.Get(ctx, c, &chunk); err != nil {
+			return errors.Wrapf(err, "failed to load snapshot state roots %s", c)
+		}
+		stateRoots = append(stateRoots, chunk...)
+	}
+
+	head, err := LoadTipSetBlocks(ctx, store.stateAndBlockSource, header.Head)
+	if err != nil {
+		return errors.Wrap(err, "failed to load snapshot head")
+	}
+
+	ts := head
+	for i := 0; ; i++ {
+		parents, err := ts.Parents()
+		if err != nil {
+			return err
+		}
+		if parents.Empty() {
+			break
+		}
+
+		if i >= len(stateRoots) {
+			return errors.Errorf("snapshot has no state root for tipset %s", ts.Key())
+		}
+		if err := store.PutTipSetAndState(ctx, &TipSetAndState{TipSet: ts, TipSetStateRoot: stateRoots[i]}); err != nil {
+			return err
+		}
+		if ts, err = LoadTipSetBlocks(ctx, store.stateAndBlockSource, parents); err != nil {
+			return errors.Wrapf(err, "failed to load snapshot tipset %s", parents)
+		}
+	}
+	if !ts.At(0).Cid().Equals(header.Genesis) {
+		return errors.Errorf("snapshot chain ends at %s, not at its genesis %s", ts.Key(), header.Genesis)
+	}
+
+	return store.SetHead(ctx, head)
+}
+
+// snapshotWriter writes blocks to a CAR archive, each only once.
+type snapshotWriter struct {
+	bs   bstore.Blockstore
+	out  io.Writer
+	seen map[cid.Cid]struct{}
+}
+
+func (w *snapshotWriter) writeBlock(c cid.Cid) error {
+	if _, ok := w.seen[c]; ok {
+		return nil
+	}
+	w.seen[c] = struct{}{}
+
+	blk, err := w.bs.Get(c)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get block %s", c)
+	}
+	return carutil.LdWrite(w.out, c.Bytes(), blk.RawData())
+}
+
+// writeDAG writes the cbor DAG rooted at c.
+func (w *snapshotWriter) writeDAG(c cid.Cid) error {
+	return walkDAG(w.bs, c, w.seen, func(blk blocks.Block) error {
+		return carutil.LdWrite(w.out, blk.Cid().Bytes(), blk.RawData())
+	})
+}
@@ -0,0 +1,77 @@
+package chain_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	bserv "github.com/ipfs/go-blockservice"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-hamt-ipld"
+	bstore "github.com/ipfs/go-ipfs-blockstore"
+	offline "github.com/ipfs/go-ipfs-exchange-offline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/chain"
+	"github.com/filecoin-project/go-filecoin/repo"
+	"github.com/filecoin-project/go-filecoin/state"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+func TestSnapshotRestoresStateRoots(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	r := repo.NewInMemoryRepo()
+	bs := bstore.NewBlockstore(r.Datastore())
+	cst := &hamt.CborIpldStore{Blocks: bserv.New(bs, offline.Exchange(bs))}
+
+	builder := chain.NewBuilder(t, address.Undef)
+	genesis := builder.NewGenesis()
+	a1 := builder.AppendOn(genesis, 1)
+	a2 := builder.AppendOn(a1, 2)
+	a3 := builder.AppendOn(a2, 1)
+	tips := []types.TipSet{genesis, a1, a2, a3}
+
+	require.NoError(t, bs.Put(types.MessageCollection{}.ToNode()))
+	require.NoError(t, bs.Put(types.ReceiptCollection{}.ToNode()))
+	store := chain.NewStore(r.ChainDatastore(), cst, &state.TreeStateLoader{}, chain.NewStatusReporter(), genesis.At(0).Cid())
+	roots := make(map[string]cid.Cid)
+	for i, ts := range tips {
+		for j := 0; j < ts.Len(); j++ {
+			require.NoError(t, bs.Put(ts.At(j).ToNode()))
+		}
+		root, err := cst.Put(ctx, map[string]interface{}{"n": i})
+		require.NoError(t, err)
+		roots[ts.String()] = root
+		require.NoError(t, store.PutTipSetAndState(ctx, &chain.TipSetAndState{TipSet: ts, TipSetStateRoot: root}))
+	}
+	require.NoError(t, store.SetHead(ctx, a3))
+
+	var buf bytes.Buffer
+	// The head state is always exported.
+	assert.Equal(t, chain.ErrNoRecentState, chain.ExportSnapshot(ctx, store, bs, a3, 0, &buf))
+	assert.Equal(t, 0, buf.Len())
+	require.NoError(t, chain.ExportSnapshot(ctx, store, bs, a3, uint(len(tips)), &buf))
+
+	r2 := repo.NewInMemoryRepo()
+	bs2 := bstore.NewBlockstore(r2.Datastore())
+	cst2 := &hamt.CborIpldStore{Blocks: bserv.New(bs2, offline.Exchange(bs2))}
+	header, err := chain.ImportSnapshot(ctx, bs2, &buf)
+	require.NoError(t, err)
+
+	store2 := chain.NewStore(r2.ChainDatastore(), cst2, &state.TreeStateLoader{}, chain.NewStatusReporter(), genesis.At(0).Cid())
+	require.NoError(t, store2.PutTipSetAndState(ctx, &chain.TipSetAndState{TipSet: genesis, TipSetStateRoot: roots[genesis.String()]}))
+	require.NoError(t, store2.SetHead(ctx, genesis))
+	require.NoError(t, store2.LoadSnapshot(ctx, header))
+
+	assert.Equal(t, a3.Key(), store2.GetHead())
+	for _, ts := range tips {
+		root, err := store2.GetTipSetStateRoot(ts.Key())
+		require.NoError(t, err)
+		assert.Equal(t, roots[ts.String()], root, "state of %s", ts.Key())
+	}
+}
@@ -51,6 +51,8 @@ type Status struct {
 	ValidatedHead types.TipSetKey
 	// The height of ValidatedHead.
 	ValidatedHeadHeight uint64
+	// The height of the highest checkpoint, below which the chain will not reorg.
+	FinalizedHeight uint64
 
 	// They head of the chain currently being fetched/validated, or undef if none.
 	SyncingHead types.TipSetKey
@@ -76,6 +78,7 @@ func newDefaultChainStatus() *Status {
 	return &Status{
 		ValidatedHead:        types.UndefTipSet.Key(),
 		ValidatedHeadHeight:  0,
+		FinalizedHeight:      0,
 		SyncingHead:          types.UndefTipSet.Key(),
 		SyncingHeight:        0,
 		SyncingTrusted:       false,
@@ -89,8 +92,8 @@ func newDefaultChainStatus() *Status {
 
 // String returns the Status as a string
 func (s Status) String() string {
-	return fmt.Sprintf("validatedHead=%s, validatedHeight=%d, syncingStarted=%d, syncingHead=%s, syncingHeight=%d, syncingTrusted=%t, syncingComplete=%t syncingFetchComplete=%t fetchingHead=%s, fetchingHeight=%d",
-		s.ValidatedHead, s.ValidatedHeadHeight, s.SyncingStarted,
+	return fmt.Sprintf("validatedHead=%s, validatedHeight=%d, finalizedHeight=%d, syncingStarted=%d, syncingHead=%s, syncingHeight=%d, syncingTrusted=%t, syncingComplete=%t syncingFetchComplete=%t fetchingHead=%s, fetchingHeight=%d",
+		s.ValidatedHead, s.ValidatedHeadHeight, s.FinalizedHeight, s.SyncingStarted,
 		s.SyncingHead, s.SyncingHeight, s.SyncingTrusted, s.SyncingComplete, s.SyncingFetchComplete,
 		s.FetchingHead, s.FetchingHeight)
 }
@@ -113,6 +116,12 @@ func validateHeight(u uint64) StatusUpdates {
 	}
 }
 
+func finalizedHeight(u uint64) StatusUpdates {
+	return func(s *Status) {
+		s.FinalizedHeight = u
+	}
+}
+
 //
 // Syncing Updates
 //
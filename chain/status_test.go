@@ -30,6 +30,7 @@ func TestStatus(t *testing.T) {
 	expStatus := Status{
 		ValidatedHead:        t1,
 		ValidatedHeadHeight:  1,
+		FinalizedHeight:      1,
 		SyncingHead:          t2,
 		SyncingHeight:        456,
 		SyncingTrusted:       true,
@@ -39,7 +40,7 @@ func TestStatus(t *testing.T) {
 		FetchingHead:         t3,
 		FetchingHeight:       789,
 	}
-	sr.UpdateStatus(validateHead(t1), validateHeight(1), syncingStarted(123), syncHead(t2),
+	sr.UpdateStatus(validateHead(t1), validateHeight(1), finalizedHeight(1), syncingStarted(123), syncHead(t2),
 		syncHeight(456), syncTrusted(true), syncComplete(false), syncFetchComplete(true),
 		fetchHead(t3), fetchHeight(789))
 	assert.Equal(t, expStatus, sr.Status())
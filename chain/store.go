@@ -83,12 +83,34 @@ type Store struct {
 	// TODO: rename to notifications.  Also, reconsider ordering assumption depending
 	// on decisions made around the FC node notification system.
 	headEvents *pubsub.PubSub
+	// Serializes head changes with their publication and with new
+	// subscriptions to them.
+	headChangeMu sync.Mutex
 
 	// Tracks tipsets by height/parentset for use by expected consensus.
 	tipIndex *TipIndex
 
 	// Reporter is used by the store to update the current status of the chain.
 	reporter Reporter
+
+	// msgIndex, if set, is moved to every new head.
+	msgIndex *MessageIndex
+
+	// checkpoints are the keys of the tipsets every new head must descend
+	// from, once they are in the store.
+	checkpoints map[string]types.TipSetKey
+	// checked holds the keys of tipsets known to descend from every
+	// checkpoint in the store when they were checked, identified by
+	// checkedID.
+	checked   map[string]struct{}
+	checkedID string
+	// Protects checkpoints and checked.
+	checkpointMu sync.Mutex
+
+	// Held for reading while the state of a tipset is written and recorded,
+	// and for writing while the garbage collector deletes state, so that
+	// blocks shared by new state are not deleted under it.
+	stateWriteMu sync.RWMutex
 }
 
 // NewStore constructs a new default store.
@@ -101,9 +123,26 @@ func NewStore(ds repo.Datastore, cst state.IpldStore, stl state.TreeLoader, sr R
 		tipIndex:            NewTipIndex(),
 		genesis:             genesisCid,
 		reporter:            sr,
+		checkpoints:         make(map[string]types.TipSetKey),
 	}
 }
 
+// SetMessageIndex makes the store maintain the given message index. It must
+// be called before the store is loaded.
+func (store *Store) SetMessageIndex(index *MessageIndex) {
+	store.msgIndex = index
+}
+
+// GetMessageLocation returns the location of the message with cid msgCid in
+// the chain ending at the current head, and whether it was found. It returns
+// ErrNoMessageIndex if the store does not maintain a message index.
+func (store *Store) GetMessageLocation(msgCid cid.Cid) (*MessageLocation, bool, error) {
+	if store.msgIndex == nil {
+		return nil, false, ErrNoMessageIndex
+	}
+	return store.msgIndex.Get(msgCid)
+}
+
 // Load rebuilds the Store's caches by traversing backwards from the
 // most recent best head as stored in its datastore.  Because Load uses a
 // content addressed datastore it guarantees that parent blocks are correctly
@@ -126,6 +165,10 @@ func (store *Store) Load(ctx context.Context) (err error) {
 	// Clear the tipset index.
 	store.tipIndex = NewTipIndex()
 
+	if err := store.loadCheckpoints(); err != nil {
+		return err
+	}
+
 	headTsKey, err := store.loadHead()
 	if err != nil {
 		return err
@@ -181,8 +224,9 @@ func (store *Store) Load(ctx context.Context) (err error) {
 	}
 
 	logStore.Infof("finished loading %d tipsets from %s", startHeight, headTs.String())
-	// Set actual head.
-	return store.SetHead(ctx, headTs)
+	// Set actual head. The persisted head is trusted even if a checkpoint
+	// pinned since does not agree with it.
+	return store.setHead(ctx, headTs)
 }
 
 // loadHead loads the latest known head from disk.
@@ -236,6 +280,14 @@ func (store *Store) PutTipSetAndState(ctx context.Context, tsas *TipSetAndState)
 	return nil
 }
 
+// BeginStateWrite must be called before writing the state of a tipset to the
+// blockstore. It holds off garbage collection until the returned function is
+// called, once the state root has been recorded with PutTipSetAndState.
+func (store *Store) BeginStateWrite() func() {
+	store.stateWriteMu.RLock()
+	return store.stateWriteMu.RUnlock
+}
+
 // GetTipSet returns the tipset identified by `key`.
 func (store *Store) GetTipSet(key types.TipSetKey) (types.TipSet, error) {
 	return store.tipIndex.GetTipSet(key)
@@ -291,8 +343,16 @@ func (store *Store) HeadEvents() *pubsub.PubSub {
 	return store.headEvents
 }
 
-// SetHead sets the passed in tipset as the new head of this chain.
+// SetHead sets the passed in tipset as the new head of this chain. It returns
+// ErrReorgPastCheckpoint if the tipset does not descend from the checkpoints.
 func (store *Store) SetHead(ctx context.Context, ts types.TipSet) error {
+	if err := store.CheckCheckpoints(ctx, ts); err != nil {
+		return err
+	}
+	return store.setHead(ctx, ts)
+}
+
+func (store *Store) setHead(ctx context.Context, ts types.TipSet) error {
 	logStore.Debugf("SetHead %s", ts.String())
 
 	// Add logging to debug sporadic test failure.
@@ -301,17 +361,44 @@ func (store *Store) SetHead(ctx context.Context, ts types.TipSet) error {
 		logStore.Error(debug.Stack())
 	}
 
+	store.headChangeMu.Lock()
+	defer store.headChangeMu.Unlock()
+
+	store.mu.RLock()
+	prevHead := store.head
+	store.mu.RUnlock()
+	changes, err := HeadChanges(ctx, store, prevHead, ts)
+	if err != nil {
+		// The chain between the heads is not in the store, so only the new
+		// head can be reported.
+		logStore.Errorf("failed to compute head changes from %s to %s: %s", prevHead.Key(), ts.Key(), err)
+		changes = []HeadChange{{Type: HCApply, TipSet: ts}}
+	}
+
 	if err := store.setHeadPersistent(ctx, ts); err != nil {
 		return err
 	}
 
+	if store.msgIndex != nil {
+		// Readers fall back to traversing the chain until the index recovers.
+		if err := store.msgIndex.Update(ctx, store, ts); err != nil {
+			logStore.Errorf("failed to update message index to %s: %s", ts.Key(), err)
+		}
+	}
+
 	h, err := ts.Height()
 	if err != nil {
 		return err
 	}
 	store.reporter.UpdateStatus(validateHead(ts.Key()), validateHeight(h))
+	if err := store.updateFinalizedHeight(); err != nil {
+		return err
+	}
 	// Publish an event that we have a new head.
 	store.HeadEvents().Pub(ts, NewHeadTopic)
+	if len(changes) > 0 {
+		store.HeadEvents().Pub(changes, HeadChangeTopic)
+	}
 
 	return nil
 }
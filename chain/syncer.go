@@ -2,10 +2,12 @@ package chain
 
 import (
 	"context"
+	"runtime"
 	"sync"
 
 	"github.com/ipfs/go-cid"
 	logging "github.com/ipfs/go-log"
+	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/pkg/errors"
 	"go.opencensus.io/trace"
 
@@ -36,6 +38,10 @@ var (
 	ErrUnexpectedStoreState = errors.New("the chain store is in an unexpected state")
 )
 
+// StatelessValidationWorkers is the number of tipsets the syncer validates
+// concurrently ahead of their state transitions.
+var StatelessValidationWorkers = runtime.NumCPU()
+
 var syncOneTimer *metrics.Float64Timer
 
 func init() {
@@ -50,9 +56,11 @@ type syncerChainReaderWriter interface {
 	GetTipSetStateRoot(tsKey types.TipSetKey) (cid.Cid, error)
 	HasTipSetAndState(ctx context.Context, tsKey types.TipSetKey) bool
 	PutTipSetAndState(ctx context.Context, tsas *TipSetAndState) error
+	BeginStateWrite() func()
 	SetHead(ctx context.Context, s types.TipSet) error
 	HasTipSetAndStatesWithParentsAndHeight(pTsKey types.TipSetKey, h uint64) bool
 	GetTipSetAndStatesByParentsAndHeight(pTsKey types.TipSetKey, h uint64) ([]*TipSetAndState, error)
+	CheckCheckpoints(ctx context.Context, ts types.TipSet) error
 }
 
 type syncStateEvaluator interface {
@@ -65,6 +73,17 @@ type syncStateEvaluator interface {
 	IsHeavier(ctx context.Context, a, b types.TipSet, aStateID, bStateID cid.Cid) (bool, error)
 }
 
+// syncStatelessValidator validates the parts of a tipset that do not depend on
+// its parent state.
+type syncStatelessValidator interface {
+	ValidateTipSet(ctx context.Context, ts, parent types.TipSet, tsMessages [][]*types.SignedMessage) error
+}
+
+// badPeerReporter is told about peers that sent the syncer invalid chains.
+type badPeerReporter interface {
+	RecordBadChain(pid peer.ID)
+}
+
 // Syncer updates its chain.Store according to the methods of its
 // consensus.Protocol.  It uses a bad tipset cache and a limit on new
 // blocks to traverse during chain collection.  The Syncer can query the
@@ -92,10 +111,15 @@ type Syncer struct {
 	// and messages.
 	fetcher net.Fetcher
 	// badTipSetCache is used to filter out collections of invalid blocks.
-	badTipSets *badTipSetCache
+	badTipSets *BadTipSetCache
+	// badPeers, if set, is told about the peers that sent invalid chains.
+	badPeers badPeerReporter
 
 	// Evaluates tipset messages and stores the resulting states.
 	stateEvaluator syncStateEvaluator
+	// statelessValidator, if set, checks tipsets before their state
+	// transitions are run.
+	statelessValidator syncStatelessValidator
 	// Provides and stores validated tipsets and their state roots.
 	chainStore syncerChainReaderWriter
 	// Provides message collections given cids
@@ -110,10 +134,8 @@ type Syncer struct {
 // NewSyncer constructs a Syncer ready for use.
 func NewSyncer(e syncStateEvaluator, s syncerChainReaderWriter, m MessageProvider, f net.Fetcher, sr Reporter, c clock.Clock) *Syncer {
 	return &Syncer{
-		fetcher: f,
-		badTipSets: &badTipSetCache{
-			bad: make(map[string]struct{}),
-		},
+		fetcher:         f,
+		badTipSets:      newMemoryBadTipSetCache(),
 		stateEvaluator:  e,
 		chainStore:      s,
 		messageProvider: m,
@@ -122,6 +144,24 @@ func NewSyncer(e syncStateEvaluator, s syncerChainReaderWriter, m MessageProvide
 	}
 }
 
+// SetBadTipSetCache replaces the syncer's in-memory bad tipset cache, e.g.
+// with one persisted in the repo.
+func (syncer *Syncer) SetBadTipSetCache(cache *BadTipSetCache) {
+	syncer.badTipSets = cache
+}
+
+// SetBadPeerReporter sets the reporter told about peers that send invalid
+// chains.
+func (syncer *Syncer) SetBadPeerReporter(reporter badPeerReporter) {
+	syncer.badPeers = reporter
+}
+
+// SetStatelessValidator sets the validator checking the state-independent
+// validity of tipsets, in parallel and ahead of their state transitions.
+func (syncer *Syncer) SetStatelessValidator(validator syncStatelessValidator) {
+	syncer.statelessValidator = validator
+}
+
 // syncOne syncs a single tipset with the chain store. syncOne calculates the
 // parent state of the tipset and calls into consensus to run a state transition
 // in order to validate the tipset.  In the case the input tipset is valid,
@@ -141,6 +181,11 @@ func (syncer *Syncer) syncOne(ctx context.Context, parent, next types.TipSet) er
 	stopwatch := syncOneTimer.Start(ctx)
 	defer stopwatch.Stop(ctx)
 
+	// The new state may share blocks with the state of old tipsets, so it
+	// must be recorded before they can be pruned.
+	endStateWrite := syncer.chainStore.BeginStateWrite()
+	defer endStateWrite()
+
 	// Lookup parent state root. It is guaranteed by the syncer that it is in the chainStore.
 	stateRoot, err := syncer.chainStore.GetTipSetStateRoot(parent.Key())
 	if err != nil {
@@ -343,6 +388,10 @@ func (syncer *Syncer) HandleNewTipSet(ctx context.Context, ci *types.ChainInfo,
 	if syncer.chainStore.HasTipSetAndState(ctx, ci.Head) {
 		return nil
 	}
+	if syncer.badTipSets.Has(ci.Head.String()) {
+		syncer.recordBadPeer(ci.Peer)
+		return ErrChainHasBadTipSet
+	}
 
 	curHead, err := syncer.chainStore.GetTipSet(syncer.chainStore.GetHead())
 	if err != nil {
@@ -371,6 +420,9 @@ func (syncer *Syncer) HandleNewTipSet(ctx context.Context, ci *types.ChainInfo,
 		if err != nil {
 			return false, err
 		}
+		if syncer.badTipSets.Has(t.String()) {
+			return true, ErrChainHasBadTipSet
+		}
 
 		// update status with latest fetched head and height
 		syncer.reporter.UpdateStatus(fetchHead(t.Key()), fetchHeight(height))
@@ -378,6 +430,9 @@ func (syncer *Syncer) HandleNewTipSet(ctx context.Context, ci *types.ChainInfo,
 	})
 	syncer.reporter.UpdateStatus(syncFetchComplete(true))
 	if err != nil {
+		if errors.Cause(err) == ErrChainHasBadTipSet {
+			syncer.recordBadPeer(ci.Peer)
+		}
 		return err
 	}
 	// Fetcher returns chain in Traversal order, reverse it to height order
@@ -391,6 +446,16 @@ func (syncer *Syncer) HandleNewTipSet(ctx context.Context, ci *types.ChainInfo,
 	if err != nil {
 		return err
 	}
+	// Refuse chains forking off below a checkpoint before validating them.
+	if err := syncer.chainStore.CheckCheckpoints(ctx, parent); err != nil {
+		return err
+	}
+
+	// Validate the state-independent parts of the chain's tipsets ahead of
+	// their state transitions.
+	validateCtx, cancelValidation := context.WithCancel(ctx)
+	defer cancelValidation()
+	validated := syncer.validateStateless(validateCtx, parent, chain)
 
 	sink.Begin()
 	defer sink.End()
@@ -398,6 +463,11 @@ func (syncer *Syncer) HandleNewTipSet(ctx context.Context, ci *types.ChainInfo,
 	// Try adding the tipsets of the chain to the store, checking for new
 	// heaviest tipsets.
 	for i, ts := range chain {
+		if err = validated(i); err != nil {
+			syncer.rejectChain(chain[i:], err, ci.Peer)
+			return err
+		}
+
 		// TODO: this "i==0" leaks EC specifics into syncer abstraction
 		// for the sake of efficiency, consider plugging up this leak.
 		var wts types.TipSet
@@ -423,12 +493,7 @@ func (syncer *Syncer) HandleNewTipSet(ctx context.Context, ci *types.ChainInfo,
 		if !wts.Defined() || len(chain) > 1 {
 			err = syncer.syncOne(ctx, parent, ts)
 			if err != nil {
-				// While `syncOne` can indeed fail for reasons other than consensus,
-				// adding to the badTipSets at this point is the simplest, since we
-				// have access to the chain. If syncOne fails for non-consensus reasons,
-				// there is no assumption that the running node's data is valid at all,
-				// so we don't really lose anything with this simplification.
-				syncer.badTipSets.AddChain(chain[i:])
+				syncer.rejectChain(chain[i:], err, ci.Peer)
 				return err
 			}
 		}
@@ -440,11 +505,105 @@ func (syncer *Syncer) HandleNewTipSet(ctx context.Context, ci *types.ChainInfo,
 	return nil
 }
 
+// rejectChain caches chain, received from pid, as bad if err reports that its
+// first tipset breaks the rules of consensus. Other errors, such as failures
+// to fetch blocks or read state, may not recur so the chain is validated
+// again when next received.
+func (syncer *Syncer) rejectChain(chain []types.TipSet, err error, pid peer.ID) {
+	if !consensus.IsInvalidTipSet(err) {
+		return
+	}
+	if cacheErr := syncer.badTipSets.AddChain(chain, err, pid); cacheErr != nil {
+		logSyncer.Errorf("failed to cache bad tipset %s: %s", chain[0].Key(), cacheErr)
+	}
+	syncer.recordBadPeer(pid)
+}
+
+// validateStateless validates the tipsets of chain, whose first tipset's
+// parent is parent, with StatelessValidationWorkers workers. It returns a
+// function waiting for the result of the validation of chain[i].
+func (syncer *Syncer) validateStateless(ctx context.Context, parent types.TipSet, chain []types.TipSet) func(i int) error {
+	if syncer.statelessValidator == nil {
+		return func(int) error { return nil }
+	}
+
+	results := make([]chan error, len(chain))
+	for i := range results {
+		results[i] = make(chan error, 1)
+	}
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := range chain {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	workers := StatelessValidationWorkers
+	if workers < 1 {
+		workers = 1
+	}
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range jobs {
+				p := parent
+				if i > 0 {
+					p = chain[i-1]
+				}
+				results[i] <- syncer.validateTipSetStateless(ctx, chain[i], p)
+			}
+		}()
+	}
+
+	return func(i int) error {
+		select {
+		case err := <-results[i]:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (syncer *Syncer) validateTipSetStateless(ctx context.Context, ts, parent types.TipSet) error {
+	var tsMessages [][]*types.SignedMessage
+	for i := 0; i < ts.Len(); i++ {
+		blk := ts.At(i)
+		msgs, err := syncer.messageProvider.LoadMessages(ctx, blk.Messages)
+		if err != nil {
+			return errors.Wrapf(err, "validating tip %s failed loading message list %s for block %s", ts.Key(), blk.Messages, blk.Cid())
+		}
+		tsMessages = append(tsMessages, msgs)
+	}
+	return syncer.statelessValidator.ValidateTipSet(ctx, ts, parent, tsMessages)
+}
+
 // Status returns the current chain status.
 func (syncer *Syncer) Status() Status {
 	return syncer.reporter.Status()
 }
 
+// BadTipSets returns the tipsets the syncer rejected.
+func (syncer *Syncer) BadTipSets() []*BadTipSet {
+	return syncer.badTipSets.List()
+}
+
+// ClearBadTipSets forgets the tipsets the syncer rejected, so they are
+// validated again when next received.
+func (syncer *Syncer) ClearBadTipSets() error {
+	return syncer.badTipSets.Clear()
+}
+
+func (syncer *Syncer) recordBadPeer(pid peer.ID) {
+	if syncer.badPeers != nil && pid != "" {
+		syncer.badPeers.RecordBadChain(pid)
+	}
+}
+
 // ExceedsUntrustedChainLength returns true if the delta between curHeight and newHeight
 // exceeds the maximum number of blocks to accept if syncing without trust, false otherwise.
 func ExceedsUntrustedChainLength(curHeight, newHeight uint64) bool {
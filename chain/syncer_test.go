@@ -2,17 +2,20 @@ package chain_test
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-hamt-ipld"
 	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/chain"
+	"github.com/filecoin-project/go-filecoin/consensus"
 	"github.com/filecoin-project/go-filecoin/repo"
 	"github.com/filecoin-project/go-filecoin/state"
 	th "github.com/filecoin-project/go-filecoin/testhelpers"
@@ -390,6 +393,106 @@ func TestBlockNotLinkedRejected(t *testing.T) {
 	assert.NoError(t, syncer.HandleNewTipSet(ctx, types.NewChainInfo(peer.ID(""), b1.Key(), heightFromTip(t, b1)), true))
 }
 
+func TestBadTipSetsCached(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	builder, store, _ := setup(ctx, t)
+	genesis := builder.RequireTipSet(store.GetHead())
+
+	a1 := builder.AppendOn(genesis, 1)
+	a2 := builder.AppendOn(a1, 1)
+	eval := &failingStateEvaluator{bad: a1.Key(), err: consensus.NewInvalidTipSetError(errors.New("invalid tipset"))}
+	syncer := chain.NewSyncer(eval, store, builder, builder, chain.NewStatusReporter(), th.NewFakeSystemClock(time.Unix(1234567890, 0)))
+	badPeers := &fakeBadPeerReporter{}
+	syncer.SetBadPeerReporter(badPeers)
+
+	pid := th.RequireIntPeerID(t, 1)
+	err := syncer.HandleNewTipSet(ctx, types.NewChainInfo(pid, a2.Key(), heightFromTip(t, a2)), true)
+	assert.EqualError(t, err, "invalid tipset")
+	assert.Equal(t, []peer.ID{pid}, badPeers.peers)
+
+	bad := syncer.BadTipSets()
+	require.Equal(t, 2, len(bad))
+	reasons := map[string]string{}
+	for _, b := range bad {
+		assert.Equal(t, pid, b.Peer)
+		reasons[b.Key.String()] = b.Reason
+	}
+	assert.Equal(t, "invalid tipset", reasons[a1.String()])
+	assert.Equal(t, "descends from bad tipset "+a1.String(), reasons[a2.String()])
+
+	// The cached chain and its descendants are rejected without validation.
+	a3 := builder.AppendOn(a2, 1)
+	err = syncer.HandleNewTipSet(ctx, types.NewChainInfo(pid, a2.Key(), heightFromTip(t, a2)), true)
+	assert.Equal(t, chain.ErrChainHasBadTipSet, err)
+	err = syncer.HandleNewTipSet(ctx, types.NewChainInfo(pid, a3.Key(), heightFromTip(t, a3)), true)
+	assert.Equal(t, chain.ErrChainHasBadTipSet, err)
+	assert.Equal(t, 3, len(badPeers.peers))
+
+	// Once cleared, the chain is validated again.
+	require.NoError(t, syncer.ClearBadTipSets())
+	assert.Empty(t, syncer.BadTipSets())
+	eval.bad = types.TipSetKey{}
+	require.NoError(t, syncer.HandleNewTipSet(ctx, types.NewChainInfo(pid, a3.Key(), heightFromTip(t, a3)), true))
+	verifyHead(t, store, a3)
+}
+
+func TestTransientFailuresNotCached(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	builder, store, _ := setup(ctx, t)
+	genesis := builder.RequireTipSet(store.GetHead())
+
+	a1 := builder.AppendOn(genesis, 1)
+	a2 := builder.AppendOn(a1, 1)
+	eval := &failingStateEvaluator{bad: a1.Key(), err: errors.New("missing state")}
+	syncer := chain.NewSyncer(eval, store, builder, builder, chain.NewStatusReporter(), th.NewFakeSystemClock(time.Unix(1234567890, 0)))
+	badPeers := &fakeBadPeerReporter{}
+	syncer.SetBadPeerReporter(badPeers)
+
+	pid := th.RequireIntPeerID(t, 1)
+	err := syncer.HandleNewTipSet(ctx, types.NewChainInfo(pid, a2.Key(), heightFromTip(t, a2)), true)
+	assert.EqualError(t, err, "missing state")
+	assert.Empty(t, syncer.BadTipSets())
+	assert.Empty(t, badPeers.peers)
+
+	// The chain is validated again once the failure is gone.
+	eval.bad = types.TipSetKey{}
+	require.NoError(t, syncer.HandleNewTipSet(ctx, types.NewChainInfo(pid, a2.Key(), heightFromTip(t, a2)), true))
+	verifyHead(t, store, a2)
+}
+
+func TestStatelessValidation(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	builder, store, syncer := setup(ctx, t)
+	genesis := builder.RequireTipSet(store.GetHead())
+
+	a1 := builder.AppendOn(genesis, 1)
+	a2 := builder.AppendOn(a1, 1)
+	a3 := builder.AppendOn(a2, 1)
+	validator := &fakeStatelessValidator{bad: a2.Key(), parents: map[string]types.TipSetKey{}}
+	syncer.SetStatelessValidator(validator)
+
+	pid := th.RequireIntPeerID(t, 1)
+	err := syncer.HandleNewTipSet(ctx, types.NewChainInfo(pid, a3.Key(), heightFromTip(t, a3)), true)
+	assert.EqualError(t, err, "invalid signature")
+
+	// The tipsets before the invalid one are synced.
+	verifyHead(t, store, a1)
+	assert.Equal(t, genesis.Key(), validator.parentOf(a1.Key()))
+	assert.Equal(t, a1.Key(), validator.parentOf(a2.Key()))
+
+	bad := syncer.BadTipSets()
+	require.Equal(t, 2, len(bad))
+	reasons := map[string]string{}
+	for _, b := range bad {
+		reasons[b.Key.String()] = b.Reason
+	}
+	assert.Equal(t, "invalid signature", reasons[a2.String()])
+	assert.Equal(t, "descends from bad tipset "+a2.String(), reasons[a3.String()])
+}
+
 func TestSyncerStatus(t *testing.T) {
 	tf.UnitTest(t)
 	ctx := context.Background()
@@ -476,6 +579,54 @@ func setup(ctx context.Context, t *testing.T) (*chain.Builder, *chain.Store, *ch
 	return builder, store, syncer
 }
 
+// failingStateEvaluator fails the state transition of a single tipset with
+// err.
+type failingStateEvaluator struct {
+	chain.FakeStateEvaluator
+	bad types.TipSetKey
+	err error
+}
+
+func (e *failingStateEvaluator) RunStateTransition(ctx context.Context, tip types.TipSet, messages [][]*types.SignedMessage, receipts [][]*types.MessageReceipt, ancestors []types.TipSet, stateID cid.Cid) (cid.Cid, error) {
+	if tip.Key().Equals(e.bad) {
+		return cid.Undef, e.err
+	}
+	return e.FakeStateEvaluator.RunStateTransition(ctx, tip, messages, receipts, ancestors, stateID)
+}
+
+// fakeStatelessValidator fails the validation of a single tipset and records
+// the parent each tipset is validated against.
+type fakeStatelessValidator struct {
+	bad types.TipSetKey
+
+	mu      sync.Mutex
+	parents map[string]types.TipSetKey
+}
+
+func (v *fakeStatelessValidator) ValidateTipSet(ctx context.Context, ts, parent types.TipSet, tsMessages [][]*types.SignedMessage) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.parents[ts.String()] = parent.Key()
+	if ts.Key().Equals(v.bad) {
+		return consensus.NewInvalidTipSetError(errors.New("invalid signature"))
+	}
+	return nil
+}
+
+func (v *fakeStatelessValidator) parentOf(key types.TipSetKey) types.TipSetKey {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.parents[key.String()]
+}
+
+type fakeBadPeerReporter struct {
+	peers []peer.ID
+}
+
+func (r *fakeBadPeerReporter) RecordBadChain(pid peer.ID) {
+	r.peers = append(r.peers, pid)
+}
+
 ///// Verification helpers /////
 
 // Sub-interface of the store used for verification.
@@ -141,6 +141,33 @@ func (ti *TipIndex) HasByParentsAndHeight(pKey types.TipSetKey, h uint64) bool {
 	return ok
 }
 
+// Remove removes the tipset with the input ID from both of TipIndex's
+// internal indexes, if it is stored.
+func (ti *TipIndex) Remove(tsKey types.TipSetKey) error {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	tsas, ok := ti.tsasByID[tsKey.String()]
+	if !ok {
+		return nil
+	}
+	delete(ti.tsasByID, tsKey.String())
+
+	pSet, err := tsas.TipSet.Parents()
+	if err != nil {
+		return err
+	}
+	h, err := tsas.TipSet.Height()
+	if err != nil {
+		return err
+	}
+	key := makeKey(pSet.String(), h)
+	delete(ti.tsasByParentsAndHeight[key], tsKey.String())
+	if len(ti.tsasByParentsAndHeight[key]) == 0 {
+		delete(ti.tsasByParentsAndHeight, key)
+	}
+	return nil
+}
+
 // makeKey returns a unique string for every parent set key and height input
 func makeKey(pKey string, h uint64) string {
 	return fmt.Sprintf("p-%s h-%d", pKey, h)
@@ -4,12 +4,14 @@ import (
 	"encoding/json"
 	"io"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/filecoin-project/go-filecoin/actor"
 	"github.com/filecoin-project/go-filecoin/actor/builtin/account"
 	"github.com/filecoin-project/go-filecoin/actor/builtin/initactor"
 	"github.com/filecoin-project/go-filecoin/actor/builtin/miner"
+	"github.com/filecoin-project/go-filecoin/actor/builtin/multisig"
 	"github.com/filecoin-project/go-filecoin/actor/builtin/paymentbroker"
 	"github.com/filecoin-project/go-filecoin/actor/builtin/storagemarket"
 	"github.com/filecoin-project/go-filecoin/exec"
@@ -34,13 +36,14 @@ type ActorView struct {
 // readableFunctionSignature is a representation of an actors function signature,
 // such that it can be shown to the user.
 type readableFunctionSignature struct {
+	Name   string
 	Params []string
 	Return []string
 }
 
-// readableExports is a representation of exports (map of method names to signatures),
+// readableExports is a representation of exports (map of method ids to signatures),
 // such that it can be shown to the user.
-type readableExports map[string]*readableFunctionSignature
+type readableExports map[types.MethodID]*readableFunctionSignature
 
 var actorCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
@@ -52,8 +55,15 @@ var actorCmd = &cmds.Command{
 }
 
 var actorLsCmd = &cmds.Command{
+	Options: []cmdkit.Option{
+		atOption,
+	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
-		results, err := GetPorcelainAPI(env).ActorLs(req.Context)
+		baseKey, err := parseAtOption(req, env)
+		if err != nil {
+			return err
+		}
+		results, err := GetPorcelainAPI(env).ActorLs(req.Context, baseKey)
 		if err != nil {
 			return err
 		}
@@ -80,6 +90,8 @@ var actorLsCmd = &cmds.Command{
 				output = makeActorView(result.Actor, result.Address, &miner.Actor{})
 			case result.Actor.Code.Equals(types.BootstrapMinerActorCodeCid):
 				output = makeActorView(result.Actor, result.Address, &miner.Actor{})
+			case result.Actor.Code.Equals(types.MultisigActorCodeCid):
+				output = makeActorView(result.Actor, result.Address, &multisig.Actor{})
 			default:
 				output = makeActorView(result.Actor, result.Address, nil)
 			}
@@ -104,9 +116,28 @@ var actorLsCmd = &cmds.Command{
 			_, err = w.Write([]byte("\n"))
 			return err
 		}),
+		cmds.Text: cmds.MakeTypedEncoder(actorViewTextEncoder),
 	},
 }
 
+func actorViewTextEncoder(req *cmds.Request, w io.Writer, a *ActorView) error {
+	sw := NewSilentWriter(w)
+	sw.Printf("%s %s\n", a.Address, a.ActorType)
+	sw.Printf("  balance: %s, nonce: %d\n", a.Balance, a.Nonce)
+
+	ids := make([]types.MethodID, 0, len(a.Exports))
+	for id := range a.Exports {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	for _, id := range ids {
+		sig := a.Exports[id]
+		sw.Printf("  %4d  %s(%s) (%s)\n", id, sig.Name, strings.Join(sig.Params, ", "), strings.Join(sig.Return, ", "))
+	}
+	return sw.Error()
+}
+
 func makeActorView(act *actor.Actor, addr string, actType exec.ExecutableActor) *ActorView {
 	var actorType string
 	var exports readableExports
@@ -130,6 +161,7 @@ func makeActorView(act *actor.Actor, addr string, actType exec.ExecutableActor)
 
 func makeReadable(f *exec.FunctionSignature) *readableFunctionSignature {
 	rfs := &readableFunctionSignature{
+		Name:   f.Name,
 		Params: make([]string, len(f.Params)),
 		Return: make([]string, len(f.Return)),
 	}
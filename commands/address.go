@@ -136,13 +136,20 @@ var balanceCmd = &cmds.Command{
 	Arguments: []cmdkit.Argument{
 		cmdkit.StringArg("address", true, false, "Address to get balance for"),
 	},
+	Options: []cmdkit.Option{
+		atOption,
+	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		addr, err := address.NewFromString(req.Arguments[0])
 		if err != nil {
 			return err
 		}
+		baseKey, err := parseAtOption(req, env)
+		if err != nil {
+			return err
+		}
 
-		balance, err := GetPorcelainAPI(env).WalletBalance(req.Context, addr)
+		balance, err := GetPorcelainAPI(env).WalletBalance(req.Context, addr, baseKey)
 		if err != nil {
 			return err
 		}
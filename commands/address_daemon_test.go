@@ -54,6 +54,36 @@ func TestWalletBalance(t *testing.T) {
 	assert.Equal(t, "0", balance.ReadStdoutTrimNewlines())
 }
 
+func TestWalletBalanceAt(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(
+		t,
+		th.DefaultAddress(fixtures.TestAddresses[0]),
+		th.KeyFile(fixtures.KeyFilePaths()[1]),
+		th.WithMiner(fixtures.TestMiners[0]),
+		th.KeyFile(fixtures.KeyFilePaths()[0]),
+	).Start()
+	defer d.ShutdownSuccess()
+
+	before := th.RunSuccessFirstLine(d, "mining", "once")
+	d.RunSuccess("message", "send",
+		"--from", fixtures.TestAddresses[0],
+		"--gas-price", "1",
+		"--gas-limit", "300",
+		"--value", "10",
+		fixtures.TestAddresses[3],
+	)
+	after := th.RunSuccessFirstLine(d, "mining", "once")
+
+	atBefore := d.RunSuccess("wallet", "balance", fixtures.TestAddresses[3], "--at", before).ReadStdoutTrimNewlines()
+	atAfter := d.RunSuccess("wallet", "balance", fixtures.TestAddresses[3], "--at", after).ReadStdoutTrimNewlines()
+	assert.NotEqual(t, atBefore, atAfter)
+	assert.Equal(t, atAfter, d.RunSuccess("wallet", "balance", fixtures.TestAddresses[3]).ReadStdoutTrimNewlines())
+
+	d.RunFail("invalid block cid", "wallet", "balance", fixtures.TestAddresses[3], "--at", "notacid")
+}
+
 func TestAddrLookupAndUpdate(t *testing.T) {
 	tf.IntegrationTest(t)
 
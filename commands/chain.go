@@ -10,7 +10,10 @@ import (
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-ipfs-cmdkit"
 	"github.com/ipfs/go-ipfs-cmds"
+	"github.com/pkg/errors"
 
+	"github.com/filecoin-project/go-filecoin/chain"
+	"github.com/filecoin-project/go-filecoin/state"
 	"github.com/filecoin-project/go-filecoin/types"
 )
 
@@ -19,9 +22,15 @@ var chainCmd = &cmds.Command{
 		Tagline: "Inspect the filecoin blockchain",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"head":   storeHeadCmd,
-		"ls":     storeLsCmd,
-		"status": storeStatusCmd,
+		"bad":        storeBadCmd,
+		"checkpoint": storeCheckpointCmd,
+		"export":     storeExportCmd,
+		"gc":         storeGCCmd,
+		"head":       storeHeadCmd,
+		"ls":         storeLsCmd,
+		"notify":     storeNotifyCmd,
+		"state-diff": storeStateDiffCmd,
+		"status":     storeStatusCmd,
 	},
 }
 
@@ -110,6 +119,50 @@ var storeLsCmd = &cmds.Command{
 	},
 }
 
+// HeadChangeView is the output of chain notify for a tipset leaving or joining
+// the head chain.
+type HeadChangeView struct {
+	Type   string          `json:"type"`
+	TipSet types.TipSetKey `json:"tipset"`
+	Height uint64          `json:"height"`
+}
+
+var storeNotifyCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Stream the changes of the chain head",
+		ShortDescription: `Prints the current head, then the tipsets leaving ("revert") and joining
+("apply") the chain as the head changes. On a reorg the tipsets of the old
+chain are reverted newest first, then those of the new chain are applied
+oldest first. The stream ends with an error if the client falls behind the
+changes of the head.`,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		for changes := range GetPorcelainAPI(env).ChainNotify(req.Context) {
+			for _, change := range changes {
+				h, err := change.TipSet.Height()
+				if err != nil {
+					return err
+				}
+				if err := re.Emit(&HeadChangeView{Type: change.Type, TipSet: change.TipSet.Key(), Height: h}); err != nil {
+					return err
+				}
+			}
+		}
+		if req.Context.Err() == nil {
+			return errors.New("fell behind the changes of the chain head")
+		}
+		return nil
+	},
+	Type: HeadChangeView{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, change *HeadChangeView) error {
+			sw := NewSilentWriter(w)
+			sw.Printf("%s\t%d\t%s\n", change.Type, change.Height, change.TipSet)
+			return sw.Error()
+		}),
+	},
+}
+
 var storeStatusCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Show status of chain sync operation.",
@@ -122,3 +175,244 @@ var storeStatusCmd = &cmds.Command{
 		return nil
 	},
 }
+
+var storeExportCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Export the chain as a CAR snapshot",
+		ShortDescription: `Writes a CAR archive of the headers, messages and receipts of the chain
+ending at a tipset, and of the state of its most recent tipsets, to stdout.
+The archive can be used to initialize a node with
+go-filecoin init --import-snapshot.`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("tipset", "Comma separated block CIDs of the tipset to export, defaults to the head"),
+		cmdkit.UintOption("recent-state", "Number of most recent tipsets whose state to include, at least 1").WithDefault(uint(1)),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		api := GetPorcelainAPI(env)
+
+		key := api.ChainHeadKey()
+		if tipset, ok := req.Options["tipset"].(string); ok {
+			var err error
+			if key, err = parseTipSetKey(tipset); err != nil {
+				return err
+			}
+		}
+		recentStates, _ := req.Options["recent-state"].(uint)
+		if recentStates == 0 {
+			return chain.ErrNoRecentState
+		}
+
+		r, w := io.Pipe()
+		go func() {
+			w.CloseWithError(api.ChainExport(req.Context, key, recentStates, w)) // nolint: errcheck
+		}()
+
+		return re.Emit(r)
+	},
+}
+
+var storeBadCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Inspect the tipsets rejected by the syncer",
+	},
+	Subcommands: map[string]*cmds.Command{
+		"clear": storeBadClearCmd,
+		"ls":    storeBadLsCmd,
+	},
+}
+
+var storeBadLsCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "List the tipsets rejected by the syncer",
+		ShortDescription: `Lists the tipsets the syncer rejected, with the validation error and the
+peer each was received from. Descendants of an invalid tipset are rejected
+with it.`,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		return re.Emit(GetPorcelainAPI(env).ChainBadTipSets())
+	},
+	Type: []*chain.BadTipSet{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, bad []*chain.BadTipSet) error {
+			sw := NewSilentWriter(w)
+			for _, b := range bad {
+				sw.Printf("%s\t%s\t%s\n", b.Key, b.Peer.Pretty(), b.Reason)
+			}
+			return sw.Error()
+		}),
+	},
+}
+
+var storeBadClearCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Forget the tipsets rejected by the syncer",
+		ShortDescription: `Clears the tipsets rejected by the syncer, so they are validated again when
+next received.`,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		return GetPorcelainAPI(env).ChainClearBadTipSets()
+	},
+}
+
+var storeCheckpointCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Checkpoint a tipset of the chain",
+		ShortDescription: `The tipset is given as a comma separated list of block CIDs and must be on
+the current chain. The node will not reorg past a checkpointed tipset.
+Checkpoints can also be pinned with chain.checkpoints of the config.
+Lists the checkpoints if no tipset is given.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("tipset", false, false, "Tipset to checkpoint"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		api := GetPorcelainAPI(env)
+		if len(req.Arguments) > 0 {
+			key, err := parseTipSetKey(req.Arguments[0])
+			if err != nil {
+				return err
+			}
+			if err := api.ChainSetCheckpoint(req.Context, key); err != nil {
+				return err
+			}
+		}
+		return re.Emit(api.ChainCheckpoints())
+	},
+	Type: []types.TipSetKey{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, keys []types.TipSetKey) error {
+			sw := NewSilentWriter(w)
+			for _, key := range keys {
+				sw.Printf("%s\n", key)
+			}
+			return sw.Error()
+		}),
+	},
+}
+
+var storeGCCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Delete the state of old tipsets",
+		ShortDescription: `Deletes the state of every tipset but the tipsets of the chain above the
+finalized height, the most recent finalized tipsets and genesis from the
+blockstore. The finalized height is the height of the highest checkpoint or
+the finality depth below the head, whichever is higher. Headers, messages and
+receipts of the whole chain are kept. Tipsets off the chain that are older
+than the oldest kept tipset are forgotten.`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.UintOption("keep-state-roots", "Number of most recent finalized tipsets whose state to keep, defaults to chain.keepStateRoots of the config"),
+		cmdkit.UintOption("finality-depth", "Number of tipsets below the head after which a tipset is finalized, defaults to chain.finalityDepth of the config"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		api := GetPorcelainAPI(env)
+
+		keep, ok := req.Options["keep-state-roots"].(uint)
+		if !ok {
+			cfgKeep, err := api.ConfigGet("chain.keepStateRoots")
+			if err != nil {
+				return err
+			}
+			keep = cfgKeep.(uint)
+		}
+		depth, ok := req.Options["finality-depth"].(uint)
+		if !ok {
+			cfgDepth, err := api.ConfigGet("chain.finalityDepth")
+			if err != nil {
+				return err
+			}
+			depth = cfgDepth.(uint)
+		}
+
+		stats, err := api.ChainGC(req.Context, keep, depth)
+		if err != nil {
+			return err
+		}
+		return re.Emit(stats)
+	},
+	Type: chain.GCStats{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, stats *chain.GCStats) error {
+			sw := NewSilentWriter(w)
+			sw.Printf("Pruned state roots: %d\n", stats.StateRoots)
+			sw.Printf("Forgotten fork tipsets: %d\n", stats.ForkTipSets)
+			sw.Printf("Deleted blocks: %d\n", stats.Blocks)
+			return sw.Error()
+		}),
+	},
+}
+
+var storeStateDiffCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show the actors that changed between the states of two tipsets",
+		ShortDescription: `Tipsets are given as comma separated lists of block CIDs.
+Lists the actors added, removed and modified between the state of the first
+tipset and the state of the second, with the changed fields of the state of
+modified builtin actors.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("before", true, false, "Tipset to compare from"),
+		cmdkit.StringArg("after", true, false, "Tipset to compare to"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		before, err := parseTipSetKey(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		after, err := parseTipSetKey(req.Arguments[1])
+		if err != nil {
+			return err
+		}
+
+		diff, err := GetPorcelainAPI(env).ChainStateDiff(req.Context, before, after)
+		if err != nil {
+			return err
+		}
+		return re.Emit(diff)
+	},
+	Type: state.TreeDiff{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, diff *state.TreeDiff) error {
+			sw := NewSilentWriter(w)
+			for _, d := range diff.Added {
+				sw.Printf("+ %s code: %s balance: %s nonce: %d\n", d.Address, d.After.Code, d.After.Balance, d.After.Nonce)
+			}
+			for _, d := range diff.Removed {
+				sw.Printf("- %s code: %s balance: %s nonce: %d\n", d.Address, d.Before.Code, d.Before.Balance, d.Before.Nonce)
+			}
+			for _, d := range diff.Modified {
+				sw.Printf("~ %s\n", d.Address)
+				for _, field := range d.ChangedFields() {
+					switch field {
+					case "code":
+						sw.Printf("    code: %s -> %s\n", d.Before.Code, d.After.Code)
+					case "head":
+						sw.Printf("    head: %s -> %s\n", d.Before.Head, d.After.Head)
+					case "nonce":
+						sw.Printf("    nonce: %d -> %d\n", d.Before.Nonce, d.After.Nonce)
+					case "balance":
+						sw.Printf("    balance: %s -> %s\n", d.Before.Balance, d.After.Balance)
+					}
+				}
+				for _, f := range d.Storage {
+					sw.Printf("    storage %s: %s -> %s\n", f.Field, f.Before, f.After)
+				}
+			}
+			return sw.Error()
+		}),
+	},
+}
+
+// parseTipSetKey parses a comma separated list of block CIDs.
+func parseTipSetKey(s string) (types.TipSetKey, error) {
+	var ids []cid.Cid
+	for _, str := range strings.Split(s, ",") {
+		id, err := cid.Decode(strings.TrimSpace(str))
+		if err != nil {
+			return types.TipSetKey{}, errors.Wrapf(err, "invalid block cid %s", str)
+		}
+		ids = append(ids, id)
+	}
+	return types.NewTipSetKeyFromUnique(ids...)
+}
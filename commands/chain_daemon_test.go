@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/ipfs/go-cid"
@@ -11,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/go-filecoin/fixtures"
+	"github.com/filecoin-project/go-filecoin/state"
 	th "github.com/filecoin-project/go-filecoin/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
 	"github.com/filecoin-project/go-filecoin/types"
@@ -127,3 +130,131 @@ func TestChainLs(t *testing.T) {
 		assert.Contains(t, chainLsResult, `"height":"1"`)
 	})
 }
+
+func TestChainStateDiff(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(
+		t,
+		th.DefaultAddress(fixtures.TestAddresses[0]),
+		th.KeyFile(fixtures.KeyFilePaths()[1]),
+		th.WithMiner(fixtures.TestMiners[0]),
+		th.KeyFile(fixtures.KeyFilePaths()[0]),
+	).Start()
+	defer d.ShutdownSuccess()
+
+	before := th.RunSuccessFirstLine(d, "mining", "once")
+
+	d.RunSuccess("message", "send",
+		"--from", fixtures.TestAddresses[0],
+		"--gas-price", "1",
+		"--gas-limit", "300",
+		"--value", "10",
+		fixtures.TestAddresses[3],
+	)
+	after := th.RunSuccessFirstLine(d, "mining", "once")
+
+	diffJSON := d.RunSuccess("chain", "state-diff", before, after, "--enc", "json").ReadStdoutTrimNewlines()
+	var diff state.TreeDiff
+	require.NoError(t, json.Unmarshal([]byte(diffJSON), &diff))
+
+	var modified []string
+	for _, actorDiff := range diff.Modified {
+		modified = append(modified, actorDiff.Address.String())
+	}
+	assert.Contains(t, modified, fixtures.TestAddresses[0])
+	assert.Contains(t, modified, fixtures.TestAddresses[3])
+
+	diffText := d.RunSuccess("chain", "state-diff", before, after).ReadStdoutTrimNewlines()
+	assert.Contains(t, diffText, "~ "+fixtures.TestAddresses[3])
+
+	d.RunFail("invalid block cid", "chain", "state-diff", "notacid", after)
+}
+
+func TestChainExportImport(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	d.RunSuccess("mining", "once")
+	d.RunSuccess("mining", "once")
+	head := d.RunSuccess("chain", "head", "--enc", "json").ReadStdoutTrimNewlines()
+
+	snapshot, err := ioutil.TempFile("", "snapshot")
+	require.NoError(t, err)
+	defer os.Remove(snapshot.Name()) // nolint: errcheck
+
+	d.RunFail("must export the state of at least the head", "chain", "export", "--recent-state", "0")
+	_, err = snapshot.WriteString(d.RunSuccess("chain", "export", "--recent-state", "2").ReadStdout())
+	require.NoError(t, err)
+	require.NoError(t, snapshot.Close())
+
+	imported := th.NewDaemon(t, th.ImportSnapshot(snapshot.Name())).Start()
+	defer imported.ShutdownSuccess()
+
+	assert.Equal(t, head, imported.RunSuccess("chain", "head", "--enc", "json").ReadStdoutTrimNewlines())
+	assert.Equal(t, 3, len(th.RunSuccessLines(imported, "chain", "ls")))
+}
+
+func TestChainGC(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	d.RunSuccess("mining", "once")
+	d.RunSuccess("mining", "once")
+	d.RunSuccess("mining", "once")
+
+	// Nothing but genesis is finalized.
+	out := d.RunSuccess("chain", "gc", "--keep-state-roots", "1").ReadStdoutTrimNewlines()
+	assert.Contains(t, out, "Pruned state roots: 0")
+
+	// The tipset at height 1 is pruned once the tipset at height 2 is finalized.
+	out = d.RunSuccess("chain", "gc", "--keep-state-roots", "1", "--finality-depth", "1").ReadStdoutTrimNewlines()
+	assert.Contains(t, out, "Pruned state roots: 1")
+
+	head := d.RunSuccess("chain", "head").ReadStdoutTrimNewlines()
+	d.RunSuccess("chain", "checkpoint", head)
+	out = d.RunSuccess("chain", "gc", "--keep-state-roots", "1").ReadStdoutTrimNewlines()
+	assert.Contains(t, out, "Pruned state roots: 1")
+
+	// The head state is still available.
+	d.RunSuccess("actor", "ls")
+	d.RunFail("must keep the state of at least one finalized tipset", "chain", "gc", "--keep-state-roots", "0")
+}
+
+func TestChainBad(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(t).Start()
+	defer d.ShutdownSuccess()
+
+	assert.Equal(t, "[]", d.RunSuccess("chain", "bad", "ls", "--enc", "json").ReadStdoutTrimNewlines())
+	d.RunSuccess("chain", "bad", "clear")
+	assert.Empty(t, d.RunSuccess("chain", "bad", "ls").ReadStdoutTrimNewlines())
+}
+
+func TestChainCheckpoint(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	d.RunSuccess("mining", "once")
+	head := d.RunSuccess("chain", "head").ReadStdoutTrimNewlines()
+	d.RunSuccess("mining", "once")
+
+	out := d.RunSuccess("chain", "checkpoint", head).ReadStdoutTrimNewlines()
+	assert.Contains(t, out, head)
+
+	var status struct{ FinalizedHeight uint64 }
+	require.NoError(t, json.Unmarshal([]byte(d.RunSuccess("chain", "status", "--enc", "json").ReadStdoutTrimNewlines()), &status))
+	assert.Equal(t, uint64(1), status.FinalizedHeight)
+
+	// Checkpoints are persisted.
+	d.Restart()
+	assert.Contains(t, d.RunSuccess("chain", "checkpoint").ReadStdoutTrimNewlines(), head)
+	d.RunFail("unknown tipset", "chain", "checkpoint", types.CidFromString(t, "unknown").String())
+}
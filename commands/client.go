@@ -11,6 +11,7 @@ import (
 	"github.com/ipfs/go-ipfs-cmds"
 	"github.com/ipfs/go-ipfs-files"
 
+	"github.com/filecoin-project/go-filecoin/actor/builtin/storagemarket"
 	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/porcelain"
 	"github.com/filecoin-project/go-filecoin/protocol/storage/storagedeal"
@@ -22,6 +23,7 @@ var clientCmd = &cmds.Command{
 		Tagline: "Make deals, store data, retrieve data",
 	},
 	Subcommands: map[string]*cmds.Command{
+		"balance":              clientBalanceCmd,
 		"cat":                  clientCatCmd,
 		"import":               clientImportDataCmd,
 		"propose-storage-deal": clientProposeStorageDealCmd,
@@ -311,3 +313,148 @@ var paymentsCmd = &cmds.Command{
 		}),
 	},
 }
+
+var clientBalanceCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show the storage market escrow balance of <address>",
+		ShortDescription: `Shows the funds the storage market holds in escrow for paying for storage
+deals. Locked funds are committed to published deals, and available funds may be
+used for new deals or withdrawn. Defaults to the balance of the default wallet
+address.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("address", false, false, "Address to show the escrow balance of"),
+	},
+	Options: []cmdkit.Option{
+		atOption,
+	},
+	Subcommands: map[string]*cmds.Command{
+		"add":      clientBalanceAddCmd,
+		"withdraw": clientBalanceWithdrawCmd,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		var addr address.Address
+		var err error
+		if len(req.Arguments) > 0 {
+			addr, err = address.NewFromString(req.Arguments[0])
+		} else {
+			addr, err = GetPorcelainAPI(env).WalletDefaultAddress()
+		}
+		if err != nil {
+			return err
+		}
+
+		baseKey, err := parseAtOption(req, env)
+		if err != nil {
+			return err
+		}
+
+		balance, err := GetPorcelainAPI(env).MarketGetBalance(req.Context, addr, baseKey)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(balance)
+	},
+	Type: storagemarket.Balance{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(escrowBalanceTextEncoder),
+	},
+}
+
+var clientBalanceAddCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Add <amount> FIL to the storage market escrow balance",
+		ShortDescription: `Issues a new message adding funds to the escrow balance of the sender, from
+which the price of deals published with 'deals publish' is locked.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("amount", true, false, "The amount to add, in FIL"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address to send from"),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fromAddr, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		amount, ok := types.NewAttoFILFromFILString(req.Arguments[0])
+		if !ok {
+			return ErrInvalidAmount
+		}
+
+		gasPrice, gasLimit, _, err := parseGasOptions(req)
+		if err != nil {
+			return err
+		}
+
+		c, err := GetPorcelainAPI(env).MarketAddBalance(req.Context, fromAddr, gasPrice, gasLimit, fromAddr, amount)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(c)
+	},
+	Type: cid.Cid{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, c cid.Cid) error {
+			return PrintString(w, c)
+		}),
+	},
+}
+
+var clientBalanceWithdrawCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Withdraw <amount> FIL from the storage market escrow balance",
+		ShortDescription: `Issues a new message withdrawing funds from the escrow balance of the sender.
+Only available funds, which are not locked by published deals, can be withdrawn.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("amount", true, false, "The amount to withdraw, in FIL"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address to send from"),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fromAddr, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		amount, ok := types.NewAttoFILFromFILString(req.Arguments[0])
+		if !ok {
+			return ErrInvalidAmount
+		}
+
+		gasPrice, gasLimit, _, err := parseGasOptions(req)
+		if err != nil {
+			return err
+		}
+
+		c, err := GetPorcelainAPI(env).MarketWithdrawBalance(req.Context, fromAddr, gasPrice, gasLimit, fromAddr, amount)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(c)
+	},
+	Type: cid.Cid{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, c cid.Cid) error {
+			return PrintString(w, c)
+		}),
+	},
+}
+
+func escrowBalanceTextEncoder(req *cmds.Request, w io.Writer, balance *storagemarket.Balance) error {
+	sw := NewSilentWriter(w)
+	sw.Printf("Available: %s FIL\n", balance.Available)
+	sw.Printf("Locked: %s FIL\n", balance.Locked)
+	return sw.Error()
+}
@@ -312,3 +312,11 @@ func TestSelfDialStorageGoodError(t *testing.T) {
 	assert.Error(t, err)
 	fastesting.AssertStdErrContains(t, miningNode, "attempting to make storage deal with self")
 }
+
+func TestClientBalanceHelp(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	result := runHelpSuccess(t, "client", "balance", "--help")
+	assert.Contains(t, result, "Show the storage market escrow balance of <address>")
+	assert.Contains(t, result, "Add <amount> FIL to the storage market escrow balance")
+}
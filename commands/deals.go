@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"io"
 	"strconv"
@@ -10,6 +11,7 @@ import (
 	"github.com/ipfs/go-ipfs-cmds"
 	"github.com/pkg/errors"
 
+	"github.com/filecoin-project/go-filecoin/actor/builtin/storagemarket"
 	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/protocol/storage/storagedeal"
 	"github.com/filecoin-project/go-filecoin/types"
@@ -25,9 +27,14 @@ var dealsCmd = &cmds.Command{
 		Tagline: "Manage and inspect deals made by or with this node",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"list":   dealsListCmd,
-		"redeem": dealsRedeemCmd,
-		"show":   dealsShowCmd,
+		"accept":  dealsAcceptCmd,
+		"cancel":  dealsCancelCmd,
+		"list":    dealsListCmd,
+		"onchain": dealsOnchainCmd,
+		"publish": dealsPublishCmd,
+		"redeem":  dealsRedeemCmd,
+		"seal":    dealsSealCmd,
+		"show":    dealsShowCmd,
 	},
 }
 
@@ -258,3 +265,283 @@ func paymentVouchersResult(vouchers []*types.PaymentVoucher) (pvres []*PaymenVou
 	}
 	return pvres, nil
 }
+
+// DealsPublishResult is the type returned when publishing a deal to the storage market.
+type DealsPublishResult struct {
+	DealID uint64
+}
+
+var dealsPublishCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Publish a storage deal with <miner> to the storage market",
+		ShortDescription: `Issues a new message recording the deal on chain and waits for it to be mined.
+The total price is locked in the client's escrow balance and released to the miner
+as it proves the data over the duration of the deal. Any part of the price not
+covered by the available escrow balance is sent with the message. The deal takes
+effect once the miner accepts it; until then the client may cancel it to unlock
+the price. Outputs the id of the published deal.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("miner", true, false, "Address of the miner storing the piece"),
+		cmdkit.StringArg("commp", true, false, "Hex encoded piece commitment"),
+		cmdkit.StringArg("size", true, false, "Size of the piece in bytes"),
+		cmdkit.StringArg("duration", true, false, "Number of blocks the piece is stored for"),
+		cmdkit.StringArg("price", true, false, "Total price of the deal, in FIL"),
+		cmdkit.StringArg("collateral", true, false, "Collateral the miner must put up, in FIL"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address to send from"),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fromAddr, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		minerAddr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		commP, err := hex.DecodeString(req.Arguments[1])
+		if err != nil {
+			return errors.Wrap(err, "commp must be hex encoded")
+		}
+
+		size, err := strconv.ParseUint(req.Arguments[2], 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "size must be a valid integer")
+		}
+
+		duration, err := strconv.ParseUint(req.Arguments[3], 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "duration must be a valid integer")
+		}
+
+		price, ok := types.NewAttoFILFromFILString(req.Arguments[4])
+		if !ok {
+			return ErrInvalidAmount
+		}
+
+		collateral, ok := types.NewAttoFILFromFILString(req.Arguments[5])
+		if !ok {
+			return ErrInvalidAmount
+		}
+
+		gasPrice, gasLimit, _, err := parseGasOptions(req)
+		if err != nil {
+			return err
+		}
+
+		dealID, err := GetPorcelainAPI(env).MarketProposeDeal(
+			req.Context,
+			fromAddr,
+			gasPrice,
+			gasLimit,
+			minerAddr,
+			commP,
+			types.NewBytesAmount(size),
+			types.NewBlockHeight(duration),
+			price,
+			collateral,
+		)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(&DealsPublishResult{DealID: dealID})
+	},
+	Type: DealsPublishResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *DealsPublishResult) error {
+			return PrintString(w, res.DealID)
+		}),
+	},
+}
+
+var dealsAcceptCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Accept a published storage deal as its miner",
+		ShortDescription: `Issues a new message accepting deal <id> and locking the deal collateral in
+the miner's escrow balance until the deal has run its full duration. Any collateral
+not covered by the available escrow balance is sent with the message. Must be sent
+from the worker address of the deal's miner.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("id", true, false, "Id of the published deal"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address to send from"),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fromAddr, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		dealID, err := strconv.ParseUint(req.Arguments[0], 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "id must be a valid integer")
+		}
+
+		gasPrice, gasLimit, _, err := parseGasOptions(req)
+		if err != nil {
+			return err
+		}
+
+		c, err := GetPorcelainAPI(env).MarketAcceptDeal(req.Context, fromAddr, gasPrice, gasLimit, dealID)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(c)
+	},
+	Type: cid.Cid{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, c cid.Cid) error {
+			return PrintString(w, c)
+		}),
+	},
+}
+
+var dealsCancelCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Cancel a published storage deal that has not been accepted",
+		ShortDescription: `Issues a new message cancelling deal <id> and unlocking its price in the
+client's escrow balance. Only the client may cancel, and only before the miner
+accepts the deal.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("id", true, false, "Id of the published deal"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address to send from"),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fromAddr, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		dealID, err := strconv.ParseUint(req.Arguments[0], 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "id must be a valid integer")
+		}
+
+		gasPrice, gasLimit, _, err := parseGasOptions(req)
+		if err != nil {
+			return err
+		}
+
+		c, err := GetPorcelainAPI(env).MarketCancelDeal(req.Context, fromAddr, gasPrice, gasLimit, dealID)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(c)
+	},
+	Type: cid.Cid{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, c cid.Cid) error {
+			return PrintString(w, c)
+		}),
+	},
+}
+
+var dealsSealCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Report the sector an accepted storage deal is sealed into",
+		ShortDescription: `Issues a new message reporting that the piece of deal <id> has been sealed into
+committed sector <sector-id>. Payment for the deal is only released to the miner
+for proving periods in which that sector is proven. Must be sent from the worker
+address of the deal's miner.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("id", true, false, "Id of the accepted deal"),
+		cmdkit.StringArg("sector-id", true, false, "Id of the committed sector holding the deal's piece"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address to send from"),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fromAddr, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		dealID, err := strconv.ParseUint(req.Arguments[0], 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "id must be a valid integer")
+		}
+
+		sectorID, err := strconv.ParseUint(req.Arguments[1], 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "sector-id must be a valid integer")
+		}
+
+		gasPrice, gasLimit, _, err := parseGasOptions(req)
+		if err != nil {
+			return err
+		}
+
+		c, err := GetPorcelainAPI(env).MarketSealDeal(req.Context, fromAddr, gasPrice, gasLimit, dealID, sectorID)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(c)
+	},
+	Type: cid.Cid{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, c cid.Cid) error {
+			return PrintString(w, c)
+		}),
+	},
+}
+
+var dealsOnchainCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show a storage deal published to the storage market",
+		ShortDescription: `Queries the storage market at the current head, or at the tipset given with
+--at, for deal <id>.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("id", true, false, "Id of the published deal"),
+	},
+	Options: []cmdkit.Option{
+		atOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		dealID, err := strconv.ParseUint(req.Arguments[0], 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "id must be a valid integer")
+		}
+		baseKey, err := parseAtOption(req, env)
+		if err != nil {
+			return err
+		}
+
+		deal, err := GetPorcelainAPI(env).MarketGetDeal(req.Context, dealID, baseKey)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(deal)
+	},
+	Type: storagemarket.Deal{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, deal *storagemarket.Deal) error {
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "\t")
+			return encoder.Encode(deal)
+		}),
+	},
+}
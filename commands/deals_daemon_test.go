@@ -470,3 +470,26 @@ func assertEqualVoucherResults(t *testing.T, expected, actual []*commands.Paymen
 		}
 	}
 }
+
+func TestDealsOnchainHelp(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	t.Run("--help shows the storage market deal commands", func(t *testing.T) {
+		expected := []string{
+			"Publish a storage deal with <miner> to the storage market",
+			"Accept a published storage deal as its miner",
+			"Cancel a published storage deal that has not been accepted",
+			"Show a storage deal published to the storage market",
+		}
+
+		result := runHelpSuccess(t, "deals", "--help")
+		for _, elem := range expected {
+			assert.Contains(t, result, elem)
+		}
+	})
+
+	t.Run("publish --help shows publish help", func(t *testing.T) {
+		result := runHelpSuccess(t, "deals", "publish", "--help")
+		assert.Contains(t, result, "The total price is locked in the client's escrow balance")
+	})
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/libp2p/go-libp2p-core/crypto"
 
 	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/chain"
 	"github.com/filecoin-project/go-filecoin/config"
 	"github.com/filecoin-project/go-filecoin/consensus"
 	"github.com/filecoin-project/go-filecoin/fixtures"
@@ -32,6 +33,7 @@ var initCmd = &cmds.Command{
 	},
 	Options: []cmdkit.Option{
 		cmdkit.StringOption(GenesisFile, "path of file or HTTP(S) URL containing archive of genesis block DAG data"),
+		cmdkit.StringOption(ImportSnapshot, "path of file containing a chain snapshot archive, as written by chain export, to start the chain from"),
 		cmdkit.StringOption(PeerKeyFile, "path of file containing key to use for new node's libp2p identity"),
 		cmdkit.StringOption(WithMiner, "when set, creates a custom genesis block with a pre generated miner account, requires running the daemon using dev mode (--dev)"),
 		cmdkit.StringOption(OptionSectorDir, "path of directory into which staged and sealed sectors will be written"),
@@ -48,6 +50,12 @@ var initCmd = &cmds.Command{
 			return err
 		}
 
+		genesisFileSource, _ := req.Options[GenesisFile].(string)
+		snapshotFile, _ := req.Options[ImportSnapshot].(string)
+		if genesisFileSource != "" && snapshotFile != "" {
+			return fmt.Errorf("cannot specify both %s and %s", GenesisFile, ImportSnapshot)
+		}
+
 		if err := re.Emit(fmt.Sprintf("initializing filecoin node at %s\n", repoDir)); err != nil {
 			return err
 		}
@@ -61,16 +69,22 @@ var initCmd = &cmds.Command{
 		// The only error Close can return is that the repo has already been closed.
 		defer func() { _ = rep.Close() }()
 
-		genesisFileSource, _ := req.Options[GenesisFile].(string)
-		// Writing to the repo here is messed up; this should create a genesis init function that
-		// writes to the repo when invoked.
-		genesisFile, err := loadGenesis(req.Context, rep, genesisFileSource)
+		peerKeyFile, _ := req.Options[PeerKeyFile].(string)
+		initopts, err := getNodeInitOpts(peerKeyFile)
 		if err != nil {
 			return err
 		}
 
-		peerKeyFile, _ := req.Options[PeerKeyFile].(string)
-		initopts, err := getNodeInitOpts(peerKeyFile)
+		// Writing to the repo here is messed up; this should create a genesis init function that
+		// writes to the repo when invoked.
+		var genesisFile consensus.GenesisInitFunc
+		if snapshotFile != "" {
+			var header *chain.SnapshotHeader
+			genesisFile, header, err = loadSnapshot(req.Context, rep, snapshotFile)
+			initopts = append(initopts, node.SnapshotOpt(header))
+		} else {
+			genesisFile, err = loadGenesis(req.Context, rep, genesisFileSource)
+		}
 		if err != nil {
 			return err
 		}
@@ -204,6 +218,34 @@ func loadGenesis(ctx context.Context, rep repo.Repo, sourceName string) (consens
 	return gif, nil
 }
 
+// loadSnapshot imports the blocks of a chain snapshot archive into the repo
+// and returns a genesis init function for the snapshot's genesis block.
+func loadSnapshot(ctx context.Context, rep repo.Repo, fileName string) (consensus.GenesisInitFunc, *chain.SnapshotHeader, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	bs := blockstore.NewBlockstore(rep.Datastore())
+	header, err := chain.ImportSnapshot(ctx, bs, file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gif := func(cst *hamt.CborIpldStore, bs blockstore.Blockstore) (*types.Block, error) {
+		var blk types.Block
+
+		if err := cst.Get(ctx, header.Genesis, &blk); err != nil {
+			return nil, err
+		}
+
+		return &blk, nil
+	}
+
+	return gif, header, nil
+}
+
 func getNodeInitOpts(peerKeyFile string) ([]node.InitOpt, error) {
 	var initOpts []node.InitOpt
 	if peerKeyFile != "" {
@@ -16,6 +16,7 @@ import (
 	"github.com/multiformats/go-multiaddr-net"
 	"github.com/pkg/errors"
 
+	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/paths"
 	"github.com/filecoin-project/go-filecoin/repo"
 	"github.com/filecoin-project/go-filecoin/types"
@@ -69,6 +70,9 @@ const (
 	// GenesisFile is the path of file containing archive of genesis block DAG data
 	GenesisFile = "genesisfile"
 
+	// ImportSnapshot is the path of a chain snapshot archive to initialize the chain from
+	ImportSnapshot = "import-snapshot"
+
 	// DevnetStaging populates config bootstrap addrs with the dns multiaddrs of the staging devnet and other staging devnet specific bootstrap parameters
 	DevnetStaging = "devnet-staging"
 
@@ -178,6 +182,7 @@ var rootSubcmdsDaemon = map[string]*cmds.Command{
 	"miner":            minerCmd,
 	"mining":           miningCmd,
 	"mpool":            mpoolCmd,
+	"msig":             msigCmd,
 	"outbox":           outboxCmd,
 	"paych":            paymentChannelCmd,
 	"ping":             pingCmd,
@@ -334,31 +339,100 @@ func isConnectionRefused(err error) bool {
 
 var priceOption = cmdkit.StringOption("gas-price", "Price (FIL e.g. 0.00013) to pay for each GasUnit consumed mining this message")
 var limitOption = cmdkit.Uint64Option("gas-limit", "Maximum GasUnits this message is allowed to consume")
+var estimatedPriceOption = cmdkit.StringOption("gas-price", "Price (FIL e.g. 0.00013) to pay for each GasUnit consumed mining this message, estimated from recent blocks if omitted")
+var estimatedLimitOption = cmdkit.Uint64Option("gas-limit", "Maximum GasUnits this message is allowed to consume, estimated by applying the message to the head state if omitted")
 var previewOption = cmdkit.BoolOption("preview", "Preview the Gas cost of this command without actually executing it")
+var atOption = cmdkit.StringOption("at", "Read the state of the tipset with this key (comma separated block cids) instead of the head")
+
+// parseAtOption returns the tipset key given with the at option, or the key
+// of the head if it is not given.
+func parseAtOption(req *cmds.Request, env cmds.Environment) (types.TipSetKey, error) {
+	at, _ := req.Options["at"].(string)
+	if at == "" {
+		return GetPorcelainAPI(env).ChainHeadKey(), nil
+	}
+	return parseTipSetKey(at)
+}
 
 func parseGasOptions(req *cmds.Request) (types.AttoFIL, types.GasUnits, bool, error) {
+	price, found, err := parseGasPriceOption(req)
+	if err != nil {
+		return types.ZeroAttoFIL, types.NewGasUnits(0), false, err
+	}
+	if !found {
+		return types.ZeroAttoFIL, types.NewGasUnits(0), false, errors.New("gas-price option is required")
+	}
+
+	gasLimit, found, err := parseGasLimitOption(req)
+	if err != nil {
+		return types.ZeroAttoFIL, types.NewGasUnits(0), false, err
+	}
+	if !found {
+		return types.ZeroAttoFIL, types.NewGasUnits(0), false, errors.New("gas-limit option is required")
+	}
+
+	preview, _ := req.Options["preview"].(bool)
+
+	return price, gasLimit, preview, nil
+}
+
+// parseGasOptionsOrEstimate is parseGasOptions for the commands using estimatedPriceOption and
+// estimatedLimitOption. When not previewing, the gas price and limit not given are estimated
+// for a message from from sending method of to with value and params.
+func parseGasOptionsOrEstimate(req *cmds.Request, env cmds.Environment, from, to address.Address, value types.AttoFIL, method string, params ...interface{}) (types.AttoFIL, types.GasUnits, bool, error) {
+	price, foundPrice, err := parseGasPriceOption(req)
+	if err != nil {
+		return types.ZeroAttoFIL, types.NewGasUnits(0), false, err
+	}
+
+	gasLimit, foundLimit, err := parseGasLimitOption(req)
+	if err != nil {
+		return types.ZeroAttoFIL, types.NewGasUnits(0), false, err
+	}
+
+	preview, _ := req.Options["preview"].(bool)
+	if preview || (foundPrice && foundLimit) {
+		return price, gasLimit, preview, nil
+	}
+
+	estimatedPrice, estimatedLimit, err := GetPorcelainAPI(env).GasEstimate(req.Context, from, to, value, method, params...)
+	if err != nil {
+		return types.ZeroAttoFIL, types.NewGasUnits(0), false, errors.Wrap(err, "failed to estimate gas, set gas-price and gas-limit")
+	}
+	if !foundPrice {
+		price = estimatedPrice
+	}
+	if !foundLimit {
+		gasLimit = estimatedLimit
+	}
+	return price, gasLimit, false, nil
+}
+
+// parseGasPriceOption returns the gas price given with the gas-price option and whether it is given.
+func parseGasPriceOption(req *cmds.Request) (types.AttoFIL, bool, error) {
 	priceOption := req.Options["gas-price"]
 	if priceOption == nil {
-		return types.ZeroAttoFIL, types.NewGasUnits(0), false, errors.New("gas-price option is required")
+		return types.ZeroAttoFIL, false, nil
 	}
 
 	price, ok := types.NewAttoFILFromFILString(priceOption.(string))
 	if !ok {
-		return types.ZeroAttoFIL, types.NewGasUnits(0), false, errors.New("invalid gas price (specify FIL as a decimal number)")
+		return types.ZeroAttoFIL, false, errors.New("invalid gas price (specify FIL as a decimal number)")
 	}
+	return price, true, nil
+}
 
+// parseGasLimitOption returns the gas limit given with the gas-limit option and whether it is given.
+func parseGasLimitOption(req *cmds.Request) (types.GasUnits, bool, error) {
 	limitOption := req.Options["gas-limit"]
 	if limitOption == nil {
-		return types.ZeroAttoFIL, types.NewGasUnits(0), false, errors.New("gas-limit option is required")
+		return types.NewGasUnits(0), false, nil
 	}
 
 	gasLimitInt, ok := limitOption.(uint64)
 	if !ok {
 		msg := fmt.Sprintf("invalid gas limit: %s", limitOption)
-		return types.ZeroAttoFIL, types.NewGasUnits(0), false, errors.New(msg)
+		return types.NewGasUnits(0), false, errors.New(msg)
 	}
-
-	preview, _ := req.Options["preview"].(bool)
-
-	return price, types.NewGasUnits(gasLimitInt), preview, nil
+	return types.NewGasUnits(gasLimitInt), true, nil
 }
@@ -51,8 +51,8 @@ var msgSendCmd = &cmds.Command{
 	Options: []cmdkit.Option{
 		cmdkit.StringOption("value", "Value to send with message in FIL"),
 		cmdkit.StringOption("from", "Address to send message from"),
-		priceOption,
-		limitOption,
+		estimatedPriceOption,
+		estimatedLimitOption,
 		previewOption,
 		// TODO: (per dignifiedquire) add an option to set the nonce and method explicitly
 	},
@@ -76,16 +76,16 @@ var msgSendCmd = &cmds.Command{
 			return err
 		}
 
-		gasPrice, gasLimit, preview, err := parseGasOptions(req)
-		if err != nil {
-			return err
-		}
-
 		method, ok := req.Options["method"].(string)
 		if !ok {
 			method = ""
 		}
 
+		gasPrice, gasLimit, preview, err := parseGasOptionsOrEstimate(req, env, fromAddr, target, val, method)
+		if err != nil {
+			return err
+		}
+
 		if preview {
 			usedGas, err := GetPorcelainAPI(env).MessagePreview(
 				req.Context,
@@ -175,7 +175,7 @@ var msgWaitCmd = &cmds.Command{
 
 		err = GetPorcelainAPI(env).MessageWait(ctx, msgCid, func(blk *types.Block, msg *types.SignedMessage, receipt *types.MessageReceipt) error {
 			found = true
-			sig, err := GetPorcelainAPI(env).ActorGetSignature(req.Context, msg.To, msg.Method)
+			sig, err := GetPorcelainAPI(env).ActorGetMethodSignature(req.Context, msg.To, msg.Method)
 			if err != nil && err != cst.ErrNoMethod && err != cst.ErrNoActorImpl {
 				return errors.Wrap(err, "Couldn't get signature for message")
 			}
@@ -68,6 +68,21 @@ func TestMessageSend(t *testing.T) {
 		"--value", "5.5",
 		fixtures.TestAddresses[3],
 	)
+
+	t.Log("[success] with estimated gas price and limit")
+	d.RunSuccess("message", "send",
+		"--from", from,
+		"--value", "10",
+		fixtures.TestAddresses[3],
+	)
+
+	t.Log("[success] with gas price and estimated limit")
+	d.RunSuccess("message", "send",
+		"--from", from,
+		"--gas-price", "1",
+		"--value", "10",
+		fixtures.TestAddresses[3],
+	)
 }
 
 func TestMessageWait(t *testing.T) {
@@ -13,6 +13,7 @@ import (
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/pkg/errors"
 
+	"github.com/filecoin-project/go-filecoin/actor/builtin/storagemarket"
 	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/porcelain"
 	"github.com/filecoin-project/go-filecoin/types"
@@ -23,15 +24,18 @@ var minerCmd = &cmds.Command{
 		Tagline: "Manage a single miner actor",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"create":         minerCreateCmd,
-		"owner":          minerOwnerCmd,
-		"power":          minerPowerCmd,
-		"set-price":      minerSetPriceCmd,
-		"update-peerid":  minerUpdatePeerIDCmd,
-		"collateral":     minerCollateralCmd,
-		"proving-window": minerProvingWindowCmd,
-		"set-worker":     minerSetWorkerAddressCmd,
-		"worker":         minerWorkerAddressCmd,
+		"balance":           minerBalanceCmd,
+		"create":            minerCreateCmd,
+		"owner":             minerOwnerCmd,
+		"power":             minerPowerCmd,
+		"set-price":         minerSetPriceCmd,
+		"update-peerid":     minerUpdatePeerIDCmd,
+		"collateral":        minerCollateralCmd,
+		"proving-window":    minerProvingWindowCmd,
+		"set-worker":        minerSetWorkerAddressCmd,
+		"terminate-sectors": minerTerminateSectorsCmd,
+		"withdraw":          minerWithdrawCmd,
+		"worker":            minerWorkerAddressCmd,
 	},
 }
 
@@ -58,8 +62,8 @@ additional sectors.`,
 		cmdkit.StringOption("sectorsize", "size of the sectors which this miner will commit, in bytes"),
 		cmdkit.StringOption("from", "address to send from"),
 		cmdkit.StringOption("peerid", "Base58-encoded libp2p peer ID that the miner will operate"),
-		priceOption,
-		limitOption,
+		estimatedPriceOption,
+		estimatedLimitOption,
 		previewOption,
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
@@ -110,7 +114,7 @@ additional sectors.`,
 			return ErrInvalidCollateral
 		}
 
-		gasPrice, gasLimit, preview, err := parseGasOptions(req)
+		gasPrice, gasLimit, preview, err := parseGasOptionsOrEstimate(req, env, fromAddr, address.StorageMarketAddress, collateral, "createStorageMiner", sectorSize, pid)
 		if err != nil {
 			return err
 		}
@@ -369,17 +373,205 @@ var minerUpdatePeerIDCmd = &cmds.Command{
 	},
 }
 
+// MinerTerminateSectorsResult is the return type for miner terminate-sectors command
+type MinerTerminateSectorsResult struct {
+	Cid     cid.Cid
+	GasUsed types.GasUnits
+	Preview bool
+}
+
+var minerTerminateSectorsCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Retire committed sectors of a miner before they expire",
+		ShortDescription: `Issues a new message to the network to terminate the given sectors. The sectors
+are removed from the miner's proving set, their power is removed from the storage
+market and the pledge collateral backing them is released.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("miner", true, false, "Address of the miner owning the sectors"),
+		cmdkit.StringArg("sector-ids", true, true, "IDs of the sectors to terminate"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address to send from"),
+		priceOption,
+		limitOption,
+		previewOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		minerAddr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		var sectorIDs []uint64
+		for _, arg := range req.Arguments[1:] {
+			id, err := strconv.ParseUint(arg, 10, 64)
+			if err != nil {
+				return errors.Wrapf(err, "invalid sector id %s", arg)
+			}
+			sectorIDs = append(sectorIDs, id)
+		}
+		sectors := types.NewIntSet(sectorIDs...)
+
+		fromAddr, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		gasPrice, gasLimit, preview, err := parseGasOptions(req)
+		if err != nil {
+			return err
+		}
+
+		if preview {
+			usedGas, err := GetPorcelainAPI(env).MessagePreview(
+				req.Context,
+				fromAddr,
+				minerAddr,
+				"terminateSectors",
+				sectors,
+			)
+			if err != nil {
+				return err
+			}
+
+			return re.Emit(&MinerTerminateSectorsResult{
+				Cid:     cid.Cid{},
+				GasUsed: usedGas,
+				Preview: true,
+			})
+		}
+
+		c, err := GetPorcelainAPI(env).MessageSend(
+			req.Context,
+			fromAddr,
+			minerAddr,
+			types.ZeroAttoFIL,
+			gasPrice,
+			gasLimit,
+			"terminateSectors",
+			sectors,
+		)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(&MinerTerminateSectorsResult{
+			Cid:     c,
+			GasUsed: types.NewGasUnits(0),
+			Preview: false,
+		})
+	},
+	Type: &MinerTerminateSectorsResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *MinerTerminateSectorsResult) error {
+			if res.Preview {
+				output := strconv.FormatUint(uint64(res.GasUsed), 10)
+				_, err := w.Write([]byte(output))
+				return err
+			}
+			return PrintString(w, res.Cid)
+		}),
+	},
+}
+
+// MinerWithdrawResult is the return type for miner withdraw command
+type MinerWithdrawResult struct {
+	Cid     cid.Cid
+	GasUsed types.GasUnits
+	Preview bool
+}
+
+var minerWithdrawCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Withdraw <amount> FIL from the miner to its owner",
+		ShortDescription: `Issues a new message from the miner owner to withdraw funds from the miner and
+waits for it to be mined. Only the balance in excess of the miner's pledge
+collateral requirement can be withdrawn.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("amount", true, false, "The amount to withdraw, in FIL"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("miner", "The address of the miner to withdraw from"),
+		priceOption,
+		limitOption,
+		previewOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		amount, ok := types.NewAttoFILFromFILString(req.Arguments[0])
+		if !ok {
+			return ErrInvalidAmount
+		}
+
+		minerAddr, err := optionalAddr(req.Options["miner"])
+		if err != nil {
+			return errors.Wrap(err, "miner must be an address")
+		}
+
+		gasPrice, gasLimit, preview, err := parseGasOptions(req)
+		if err != nil {
+			return err
+		}
+
+		if preview {
+			usedGas, err := GetPorcelainAPI(env).MinerPreviewWithdraw(req.Context, minerAddr, amount)
+			if err != nil {
+				return err
+			}
+			return re.Emit(&MinerWithdrawResult{
+				Cid:     cid.Cid{},
+				GasUsed: usedGas,
+				Preview: true,
+			})
+		}
+
+		c, err := GetPorcelainAPI(env).MinerWithdraw(req.Context, minerAddr, gasPrice, gasLimit, amount)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(&MinerWithdrawResult{
+			Cid:     c,
+			GasUsed: types.NewGasUnits(0),
+			Preview: false,
+		})
+	},
+	Type: &MinerWithdrawResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *MinerWithdrawResult) error {
+			if res.Preview {
+				output := strconv.FormatUint(uint64(res.GasUsed), 10)
+				_, err := w.Write([]byte(output))
+				return err
+			}
+			return PrintString(w, res.Cid)
+		}),
+	},
+}
+
 var minerOwnerCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline:          "Show the actor address of <miner>",
 		ShortDescription: `Given <miner> miner address, output the address of the actor that owns the miner.`,
 	},
+	Subcommands: map[string]*cmds.Command{
+		"set":    minerOwnerSetCmd,
+		"accept": minerOwnerAcceptCmd,
+	},
+	Options: []cmdkit.Option{
+		atOption,
+	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		minerAddr, err := optionalAddr(req.Arguments[0])
 		if err != nil {
 			return err
 		}
-		ownerAddr, err := GetPorcelainAPI(env).MinerGetOwnerAddress(req.Context, minerAddr)
+		baseKey, err := parseAtOption(req, env)
+		if err != nil {
+			return err
+		}
+		ownerAddr, err := GetPorcelainAPI(env).MinerGetOwnerAddress(req.Context, minerAddr, baseKey)
 		if err != nil {
 			return err
 		}
@@ -397,19 +589,117 @@ var minerOwnerCmd = &cmds.Command{
 	},
 }
 
+var minerOwnerSetCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Propose <new-owner> as the owner of <miner>. Returns a message CID",
+		ShortDescription: `Issues a message from the current owner of <miner> proposing <new-owner> as its
+owner. Ownership does not change until the new owner confirms the transfer with
+'go-filecoin miner owner accept'.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("miner", true, false, "The address of the miner"),
+		cmdkit.StringArg("new-owner", true, false, "The address of the proposed owner"),
+	},
+	Options: []cmdkit.Option{
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		minerAddr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		newOwner, err := address.NewFromString(req.Arguments[1])
+		if err != nil {
+			return err
+		}
+
+		gasPrice, gasLimit, _, err := parseGasOptions(req)
+		if err != nil {
+			return err
+		}
+
+		msgCid, err := GetPorcelainAPI(env).MinerProposeOwner(req.Context, minerAddr, gasPrice, gasLimit, newOwner)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(msgCid)
+	},
+	Type: cid.Cid{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, c cid.Cid) error {
+			return PrintString(w, c)
+		}),
+	},
+}
+
+var minerOwnerAcceptCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Accept a proposed transfer of ownership of <miner>. Returns a message CID",
+		ShortDescription: `Issues a message from the proposed owner of <miner> that completes the ownership
+transfer started with 'go-filecoin miner owner set'.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("miner", true, false, "The address of the miner"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address of the proposed owner"),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		minerAddr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		fromAddr, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		gasPrice, gasLimit, _, err := parseGasOptions(req)
+		if err != nil {
+			return err
+		}
+
+		msgCid, err := GetPorcelainAPI(env).MinerAcceptOwner(req.Context, fromAddr, minerAddr, gasPrice, gasLimit)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(msgCid)
+	},
+	Type: cid.Cid{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, c cid.Cid) error {
+			return PrintString(w, c)
+		}),
+	},
+}
+
 var minerPowerCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Get the power of a miner versus the total storage market power",
 		ShortDescription: `Check the current power of a given miner and total power of the storage market.
 Values will be output as a ratio where the first number is the miner power and second is the total market power.`,
 	},
+	Options: []cmdkit.Option{
+		atOption,
+	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		minerAddr, err := optionalAddr(req.Arguments[0])
 		if err != nil {
 			return err
 		}
+		baseKey, err := parseAtOption(req, env)
+		if err != nil {
+			return err
+		}
 
-		minerPower, err := GetPorcelainAPI(env).MinerGetPower(req.Context, minerAddr)
+		minerPower, err := GetPorcelainAPI(env).MinerGetPower(req.Context, minerAddr, baseKey)
 		if err != nil {
 			return err
 		}
@@ -433,12 +723,19 @@ var minerCollateralCmd = &cmds.Command{
 		Tagline:          "Get the active collateral of a miner",
 		ShortDescription: `Check the actively staked collateral of a given miner. Values reported in attoFIL`,
 	},
+	Options: []cmdkit.Option{
+		atOption,
+	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		minerAddr, err := optionalAddr(req.Arguments[0])
 		if err != nil {
 			return err
 		}
-		collateral, err := GetPorcelainAPI(env).MinerGetCollateral(req.Context, minerAddr)
+		baseKey, err := parseAtOption(req, env)
+		if err != nil {
+			return err
+		}
+		collateral, err := GetPorcelainAPI(env).MinerGetCollateral(req.Context, minerAddr, baseKey)
 		if err != nil {
 			return err
 		}
@@ -459,14 +756,21 @@ var minerProvingWindowCmd = &cmds.Command{
 	Arguments: []cmdkit.Argument{
 		cmdkit.StringArg("miner", true, false, "Miner address to get proving window for"),
 	},
+	Options: []cmdkit.Option{
+		atOption,
+	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		// Get the Miner Address
 		minerAddress, err := address.NewFromString(req.Arguments[0])
 		if err != nil {
 			return err
 		}
+		baseKey, err := parseAtOption(req, env)
+		if err != nil {
+			return err
+		}
 
-		mpp, err := GetPorcelainAPI(env).MinerGetProvingWindow(req.Context, minerAddress)
+		mpp, err := GetPorcelainAPI(env).MinerGetProvingWindow(req.Context, minerAddress, baseKey)
 		if err != nil {
 			return err
 		}
@@ -542,6 +846,9 @@ var minerWorkerAddressCmd = &cmds.Command{
 		Tagline:          "Show the address of the miner worker",
 		ShortDescription: "Show the address of the miner worker",
 	},
+	Options: []cmdkit.Option{
+		atOption,
+	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		ret, err := GetPorcelainAPI(env).ConfigGet("mining.minerAddress")
 		if err != nil {
@@ -551,7 +858,11 @@ var minerWorkerAddressCmd = &cmds.Command{
 		if !ok {
 			return errors.New("problem converting miner address")
 		}
-		workerAddr, err := GetPorcelainAPI(env).MinerGetWorkerAddress(req.Context, minerAddr, GetPorcelainAPI(env).ChainHeadKey())
+		baseKey, err := parseAtOption(req, env)
+		if err != nil {
+			return err
+		}
+		workerAddr, err := GetPorcelainAPI(env).MinerGetWorkerAddress(req.Context, minerAddr, baseKey)
 		if err != nil {
 			return errors.Wrap(err, "problem getting worker address")
 		}
@@ -568,3 +879,166 @@ var minerWorkerAddressCmd = &cmds.Command{
 		}),
 	},
 }
+
+var minerBalanceCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show the storage market escrow balance of the miner",
+		ShortDescription: `Shows the funds the storage market holds in escrow for the miner. Locked funds
+are collateral for accepted deals, and available funds hold deal payments and
+collateral that may be used for new deals or withdrawn by the miner owner.`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("miner", "The address of the miner"),
+		atOption,
+	},
+	Subcommands: map[string]*cmds.Command{
+		"add":      minerBalanceAddCmd,
+		"withdraw": minerBalanceWithdrawCmd,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		minerAddr, err := minerAddrOption(req, env)
+		if err != nil {
+			return err
+		}
+
+		baseKey, err := parseAtOption(req, env)
+		if err != nil {
+			return err
+		}
+
+		balance, err := GetPorcelainAPI(env).MarketGetBalance(req.Context, minerAddr, baseKey)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(balance)
+	},
+	Type: storagemarket.Balance{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(escrowBalanceTextEncoder),
+	},
+}
+
+var minerBalanceAddCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Add <amount> FIL to the storage market escrow balance of the miner",
+		ShortDescription: `Issues a new message adding funds to the escrow balance of the miner, from
+which the collateral of deals accepted with 'deals accept' is locked.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("amount", true, false, "The amount to add, in FIL"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("miner", "The address of the miner"),
+		cmdkit.StringOption("from", "Address to send from"),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fromAddr, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		minerAddr, err := minerAddrOption(req, env)
+		if err != nil {
+			return err
+		}
+
+		amount, ok := types.NewAttoFILFromFILString(req.Arguments[0])
+		if !ok {
+			return ErrInvalidAmount
+		}
+
+		gasPrice, gasLimit, _, err := parseGasOptions(req)
+		if err != nil {
+			return err
+		}
+
+		c, err := GetPorcelainAPI(env).MarketAddBalance(req.Context, fromAddr, gasPrice, gasLimit, minerAddr, amount)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(c)
+	},
+	Type: cid.Cid{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, c cid.Cid) error {
+			return PrintString(w, c)
+		}),
+	},
+}
+
+var minerBalanceWithdrawCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Withdraw <amount> FIL from the storage market escrow balance of the miner",
+		ShortDescription: `Issues a new message from the miner owner withdrawing funds from the escrow
+balance of the miner to the owner. Only available funds, which are not locked as
+deal collateral, can be withdrawn.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("amount", true, false, "The amount to withdraw, in FIL"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("miner", "The address of the miner"),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		minerAddr, err := minerAddrOption(req, env)
+		if err != nil {
+			return err
+		}
+
+		ownerAddr, err := GetPorcelainAPI(env).MinerGetOwnerAddress(req.Context, minerAddr, GetPorcelainAPI(env).ChainHeadKey())
+		if err != nil {
+			return errors.Wrap(err, "could not get miner owner address")
+		}
+
+		amount, ok := types.NewAttoFILFromFILString(req.Arguments[0])
+		if !ok {
+			return ErrInvalidAmount
+		}
+
+		gasPrice, gasLimit, _, err := parseGasOptions(req)
+		if err != nil {
+			return err
+		}
+
+		c, err := GetPorcelainAPI(env).MarketWithdrawBalance(req.Context, ownerAddr, gasPrice, gasLimit, minerAddr, amount)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(c)
+	},
+	Type: cid.Cid{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, c cid.Cid) error {
+			return PrintString(w, c)
+		}),
+	},
+}
+
+// minerAddrOption returns the address given with the --miner option, or the
+// configured miner address if the option is absent.
+func minerAddrOption(req *cmds.Request, env cmds.Environment) (address.Address, error) {
+	minerAddr, err := optionalAddr(req.Options["miner"])
+	if err != nil {
+		return address.Undef, errors.Wrap(err, "miner must be an address")
+	}
+	if !minerAddr.Empty() {
+		return minerAddr, nil
+	}
+
+	ret, err := GetPorcelainAPI(env).ConfigGet("mining.minerAddress")
+	if err != nil {
+		return address.Undef, errors.Wrap(err, "problem getting miner address")
+	}
+	minerAddr, ok := ret.(address.Address)
+	if !ok || minerAddr.Empty() {
+		return address.Undef, errors.New("no miner address configured, use --miner")
+	}
+	return minerAddr, nil
+}
@@ -94,6 +94,23 @@ func TestMinerHelp(t *testing.T) {
 		result := runHelpSuccess(t, "miner", "worker", "--help")
 		assert.Contains(t, result, "go-filecoin miner worker - Show the address of the miner worker")
 	})
+	t.Run("owner set --help shows owner set help", func(t *testing.T) {
+		result := runHelpSuccess(t, "miner", "owner", "set", "--help")
+		assert.Contains(t, result, "Ownership does not change until the new owner confirms the transfer")
+	})
+	t.Run("withdraw --help shows withdraw help", func(t *testing.T) {
+		result := runHelpSuccess(t, "miner", "withdraw", "--help")
+		assert.Contains(t, result, "Only the balance in excess of the miner's pledge")
+	})
+	t.Run("terminate-sectors --help shows terminate-sectors help", func(t *testing.T) {
+		result := runHelpSuccess(t, "miner", "terminate-sectors", "--help")
+		assert.Contains(t, result, "go-filecoin miner terminate-sectors <miner> <sector-ids>... - Retire committed sectors of a miner before they expire")
+	})
+	t.Run("balance --help shows balance help", func(t *testing.T) {
+		result := runHelpSuccess(t, "miner", "balance", "--help")
+		assert.Contains(t, result, "Show the storage market escrow balance of the miner")
+		assert.Contains(t, result, "Withdraw <amount> FIL from the storage market escrow balance of the miner")
+	})
 }
 
 func runHelpSuccess(t *testing.T, args ...string) string {
@@ -134,7 +151,7 @@ func TestMinerCreate(t *testing.T) {
 
 			d1.ConnectSuccess(d)
 
-			args := []string{"miner", "create", "--from", fromAddress.String(), "--gas-price", "1", "--gas-limit", "300"}
+			args := []string{"miner", "create", "--from", fromAddress.String(), "--gas-price", "1", "--gas-limit", "1000"}
 
 			if pid.Pretty() != peer.ID("").Pretty() {
 				args = append(args, "--peerid", pid.Pretty())
@@ -218,7 +235,7 @@ func TestMinerSetPrice(t *testing.T) {
 
 	d1.RunSuccess("mining", "start")
 
-	setPrice := d1.RunSuccess("miner", "set-price", "62", "6", "--gas-price", "1", "--gas-limit", "300")
+	setPrice := d1.RunSuccess("miner", "set-price", "62", "6", "--gas-price", "1", "--gas-limit", "1000")
 	assert.Contains(t, setPrice.ReadStdoutTrimNewlines(), fmt.Sprintf("Set price for miner %s to 62.", fixtures.TestMiners[0]))
 
 	configuredPrice := d1.RunSuccess("config", "mining.storagePrice")
@@ -276,7 +293,7 @@ func TestMinerCreateChargesGas(t *testing.T) {
 
 	wg.Add(1)
 	go func() {
-		testMiner := d.RunSuccess("miner", "create", "--from", fixtures.TestAddresses[2], "--gas-price", "333", "--gas-limit", "100", "200")
+		testMiner := d.RunSuccess("miner", "create", "--from", fixtures.TestAddresses[2], "--gas-price", "333", "--gas-limit", "1000", "200")
 		addr, err := address.NewFromString(strings.Trim(testMiner.ReadStdout(), "\n"))
 		assert.NoError(t, err)
 		assert.NotEqual(t, addr, address.Undef)
@@ -113,6 +113,9 @@ var miningStatusCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Report on mining status",
 	},
+	Options: []cmdkit.Option{
+		atOption,
+	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		isMining := GetBlockAPI(env).MiningIsActive()
 
@@ -122,22 +125,27 @@ var miningStatusCmd = &cmds.Command{
 			return err
 		}
 
-		mpp, err := GetPorcelainAPI(env).MinerGetProvingWindow(req.Context, minerAddress)
+		baseKey, err := parseAtOption(req, env)
+		if err != nil {
+			return err
+		}
+
+		mpp, err := GetPorcelainAPI(env).MinerGetProvingWindow(req.Context, minerAddress, baseKey)
 		if err != nil {
 			return err
 		}
 
-		owner, err := GetPorcelainAPI(env).MinerGetOwnerAddress(req.Context, minerAddress)
+		owner, err := GetPorcelainAPI(env).MinerGetOwnerAddress(req.Context, minerAddress, baseKey)
 		if err != nil {
 			return err
 		}
 
-		collateral, err := GetPorcelainAPI(env).MinerGetCollateral(req.Context, minerAddress)
+		collateral, err := GetPorcelainAPI(env).MinerGetCollateral(req.Context, minerAddress, baseKey)
 		if err != nil {
 			return err
 		}
 
-		power, err := GetPorcelainAPI(env).MinerGetPower(req.Context, minerAddress)
+		power, err := GetPorcelainAPI(env).MinerGetPower(req.Context, minerAddress, baseKey)
 		if err != nil {
 			return err
 		}
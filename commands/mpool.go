@@ -11,6 +11,7 @@ import (
 	"github.com/ipfs/go-ipfs-cmds"
 	"github.com/pkg/errors"
 
+	"github.com/filecoin-project/go-filecoin/message"
 	"github.com/filecoin-project/go-filecoin/types"
 )
 
@@ -19,9 +20,11 @@ var mpoolCmd = &cmds.Command{
 		Tagline: "Manage the message pool",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"ls":   mpoolLsCmd,
-		"show": mpoolShowCmd,
-		"rm":   mpoolRemoveCmd,
+		"ls":      mpoolLsCmd,
+		"show":    mpoolShowCmd,
+		"rm":      mpoolRemoveCmd,
+		"replace": mpoolReplaceCmd,
+		"stat":    mpoolStatCmd,
 	},
 }
 
@@ -57,6 +60,36 @@ var mpoolLsCmd = &cmds.Command{
 	},
 }
 
+var mpoolStatCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Summarize the pool of outstanding messages",
+		ShortDescription: `
+Shows the number of messages in the pool, the number pending from each sender, and a
+histogram of their gas prices. Each histogram bucket counts the messages with a gas price
+of at least its lower bound and less than ten times it.
+`,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		return re.Emit(GetPorcelainAPI(env).MessagePoolStats())
+	},
+	Type: message.PoolStats{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, stats *message.PoolStats) error {
+			sw := NewSilentWriter(w)
+			sw.Printf("Messages: %d\n", stats.Size)
+			sw.Println("Senders:")
+			for _, sender := range stats.Senders {
+				sw.Printf("  %s: %d\n", sender.Address, sender.Count)
+			}
+			sw.Println("Gas prices (FIL):")
+			for _, bucket := range stats.GasPrices {
+				sw.Printf("  >= %s: %d\n", bucket.Min, bucket.Count)
+			}
+			return sw.Error()
+		}),
+	},
+}
+
 var mpoolShowCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Show content of an outstanding message",
@@ -123,3 +156,48 @@ var mpoolRemoveCmd = &cmds.Command{
 		return nil
 	},
 }
+
+var mpoolReplaceCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Replace a message sent from this node with one paying a higher gas price",
+		ShortDescription: `
+Re-signs a message sent from this node that has not been mined yet with a new gas price,
+and broadcasts it in place of the original. The replacement keeps the original nonce, so
+at most one of the two messages is mined. Message pools accept the replacement only if
+its gas price exceeds the original by the percentage configured in mpool.replaceByFeePercent.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("cid", true, false, "The CID of the message to replace"),
+	},
+	Options: []cmdkit.Option{
+		priceOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		msgCid, err := cid.Parse(req.Arguments[0])
+		if err != nil {
+			return errors.Wrap(err, "invalid message cid")
+		}
+
+		rawPrice, ok := req.Options["gas-price"].(string)
+		if !ok {
+			return errors.New("gas-price option is required")
+		}
+		gasPrice, ok := types.NewAttoFILFromFILString(rawPrice)
+		if !ok {
+			return errors.New("invalid gas price (specify FIL as a decimal number)")
+		}
+
+		c, err := GetPorcelainAPI(env).MessageReplace(req.Context, msgCid, gasPrice)
+		if err != nil {
+			return err
+		}
+		return re.Emit(c)
+	},
+	Type: cid.Cid{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, c cid.Cid) error {
+			return PrintString(w, c)
+		}),
+	},
+}
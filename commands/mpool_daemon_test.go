@@ -131,3 +131,61 @@ func TestMpoolRm(t *testing.T) {
 		assert.Equal(t, "", out)
 	})
 }
+
+func TestMpoolReplace(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[0])).Start()
+	defer d.ShutdownSuccess()
+
+	msgCid := d.RunSuccess("message", "send",
+		"--from", fixtures.TestAddresses[0],
+		"--gas-price", "1", "--gas-limit", "300",
+		"--value=10", fixtures.TestAddresses[2],
+	).ReadStdoutTrimNewlines()
+
+	t.Run("rejects an insufficient gas price increase", func(t *testing.T) {
+		d.RunFail("replacement must have a gas price of at least", "mpool", "replace", msgCid, "--gas-price", "1.05")
+
+		out := d.RunSuccess("mpool", "ls").ReadStdoutTrimNewlines()
+		assert.Equal(t, msgCid, out)
+		assert.Contains(t, d.RunSuccess("outbox", "ls").ReadStdoutTrimNewlines(), msgCid)
+	})
+
+	t.Run("replaces the message in the pool and outbox", func(t *testing.T) {
+		replacement := d.RunSuccess("mpool", "replace", msgCid, "--gas-price", "2").ReadStdoutTrimNewlines()
+		assert.NotEqual(t, msgCid, replacement)
+
+		out := d.RunSuccess("mpool", "ls").ReadStdoutTrimNewlines()
+		assert.Equal(t, replacement, out)
+
+		outbox := d.RunSuccess("outbox", "ls").ReadStdoutTrimNewlines()
+		assert.Contains(t, outbox, replacement)
+		assert.NotContains(t, outbox, msgCid)
+	})
+
+	t.Run("fails for messages not in the outbox", func(t *testing.T) {
+		d.RunFail("not found in outbound queue", "mpool", "replace", msgCid, "--gas-price", "3")
+	})
+}
+
+func TestMpoolStat(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[0])).Start()
+	defer d.ShutdownSuccess()
+
+	for _, price := range []string{"1", "1", "0.5"} {
+		d.RunSuccess("message", "send",
+			"--from", fixtures.TestAddresses[0],
+			"--gas-price", price, "--gas-limit", "300",
+			"--value=10", fixtures.TestAddresses[2],
+		)
+	}
+
+	out := d.RunSuccess("mpool", "stat").ReadStdoutTrimNewlines()
+	assert.Contains(t, out, "Messages: 3")
+	assert.Contains(t, out, fixtures.TestAddresses[0]+": 3")
+	assert.Contains(t, out, ">= 1: 2")
+	assert.Contains(t, out, ">= 0.1: 1")
+}
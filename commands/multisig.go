@@ -0,0 +1,508 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-ipfs-cmdkit"
+	"github.com/ipfs/go-ipfs-cmds"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/abi"
+	"github.com/filecoin-project/go-filecoin/actor/builtin/multisig"
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+var msigCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Manage multisig wallets",
+	},
+	Subcommands: map[string]*cmds.Command{
+		"create":             msigCreateCmd,
+		"propose":            msigProposeCmd,
+		"approve":            msigApproveCmd,
+		"cancel":             msigCancelCmd,
+		"add-signer":         msigAddSignerCmd,
+		"remove-signer":      msigRemoveSignerCmd,
+		"change-requirement": msigChangeRequirementCmd,
+		"info":               msigInfoCmd,
+	},
+}
+
+var msigCreateCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Create a new multisig wallet holding <value> FIL",
+		ShortDescription: `Issues a new message to the network to create the wallet, then waits for the
+message to be mined as this is required to return the address of the new wallet.
+If --unlock-duration is given, the initial value vests linearly over that many
+blocks and may not be spent before it has vested.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("signers", true, false, "Comma separated addresses allowed to approve transactions"),
+		cmdkit.StringArg("required", true, false, "Number of approvals required to execute a transaction"),
+		cmdkit.StringArg("value", true, false, "Initial balance of the wallet, in FIL"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address to send from"),
+		cmdkit.Uint64Option("unlock-duration", "Number of blocks over which the initial balance vests").WithDefault(uint64(0)),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fromAddr, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		signers, err := parseAddressList(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		required, err := strconv.ParseUint(req.Arguments[1], 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "required must be a valid integer")
+		}
+
+		value, ok := types.NewAttoFILFromFILString(req.Arguments[2])
+		if !ok {
+			return ErrInvalidAmount
+		}
+
+		unlockDuration := types.NewBlockHeight(req.Options["unlock-duration"].(uint64))
+
+		gasPrice, gasLimit, _, err := parseGasOptions(req)
+		if err != nil {
+			return err
+		}
+
+		addr, err := GetPorcelainAPI(env).MultisigCreate(req.Context, fromAddr, gasPrice, gasLimit, signers, required, unlockDuration, value)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(&addr)
+	},
+	Type: address.Address{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, a *address.Address) error {
+			return PrintString(w, a)
+		}),
+	},
+}
+
+// MsigProposeResult is the type returned when proposing a multisig transaction.
+type MsigProposeResult struct {
+	TxID uint64
+}
+
+var msigProposeCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Propose sending <value> FIL from the multisig wallet to <to>",
+		ShortDescription: `Issues a new message proposing a transaction and waits for it to be mined.
+Outputs the id of the proposed transaction, which other signers pass to approve.
+The proposal counts as the first approval. With --method, the transaction calls
+that method of <to>, passing it the given params.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("wallet", true, false, "Address of the multisig wallet"),
+		cmdkit.StringArg("to", true, false, "Address the transaction is sent to"),
+		cmdkit.StringArg("value", true, false, "Amount of FIL to send"),
+		cmdkit.StringArg("params", false, true, "Parameters of the method"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address to send from"),
+		cmdkit.StringOption("method", "Method to invoke on <to>"),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		to, err := address.NewFromString(req.Arguments[1])
+		if err != nil {
+			return err
+		}
+
+		value, ok := types.NewAttoFILFromFILString(req.Arguments[2])
+		if !ok {
+			return ErrInvalidAmount
+		}
+
+		method, _ := req.Options["method"].(string)
+		args := req.Arguments[3:]
+		if method == "" {
+			if len(args) > 0 {
+				return errors.New("params require a method")
+			}
+			return msigPropose(req, re, env, to, value, method)
+		}
+
+		signature, err := GetPorcelainAPI(env).ActorGetSignature(req.Context, to, method)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get signature of method %s", method)
+		}
+		params, err := parseMethodParams(signature.Params, args)
+		if err != nil {
+			return err
+		}
+
+		return msigPropose(req, re, env, to, value, method, params...)
+	},
+	Type: &MsigProposeResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *MsigProposeResult) error {
+			_, err := fmt.Fprintln(w, res.TxID)
+			return err
+		}),
+	},
+}
+
+var msigApproveCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Approve a pending multisig transaction",
+		ShortDescription: `Issues a new message approving transaction <id>. The transaction is executed
+once it has collected the number of approvals the wallet requires.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("wallet", true, false, "Address of the multisig wallet"),
+		cmdkit.StringArg("id", true, false, "Id of the transaction to approve"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address to send from"),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fromAddr, wallet, txID, err := parseMsigTxArgs(req, env)
+		if err != nil {
+			return err
+		}
+
+		gasPrice, gasLimit, _, err := parseGasOptions(req)
+		if err != nil {
+			return err
+		}
+
+		c, err := GetPorcelainAPI(env).MultisigApprove(req.Context, fromAddr, wallet, gasPrice, gasLimit, txID)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(c)
+	},
+	Type: cid.Cid{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, c cid.Cid) error {
+			return PrintString(w, c)
+		}),
+	},
+}
+
+var msigCancelCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline:          "Cancel a pending multisig transaction",
+		ShortDescription: `Issues a new message cancelling transaction <id>. Only the proposer may cancel.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("wallet", true, false, "Address of the multisig wallet"),
+		cmdkit.StringArg("id", true, false, "Id of the transaction to cancel"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address to send from"),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fromAddr, wallet, txID, err := parseMsigTxArgs(req, env)
+		if err != nil {
+			return err
+		}
+
+		gasPrice, gasLimit, _, err := parseGasOptions(req)
+		if err != nil {
+			return err
+		}
+
+		c, err := GetPorcelainAPI(env).MultisigCancel(req.Context, fromAddr, wallet, gasPrice, gasLimit, txID)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(c)
+	},
+	Type: cid.Cid{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, c cid.Cid) error {
+			return PrintString(w, c)
+		}),
+	},
+}
+
+var msigAddSignerCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Propose adding <signer> to the multisig wallet",
+		ShortDescription: `Proposes a transaction from the wallet to itself that adds <signer>. The change
+takes effect once the proposal has collected enough approvals.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("wallet", true, false, "Address of the multisig wallet"),
+		cmdkit.StringArg("signer", true, false, "Address of the new signer"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address to send from"),
+		cmdkit.BoolOption("increase", "Also increase the number of required approvals by one"),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		signer, err := address.NewFromString(req.Arguments[1])
+		if err != nil {
+			return err
+		}
+
+		increase, _ := req.Options["increase"].(bool)
+
+		return msigProposeSelf(req, re, env, "addSigner", signer, increase)
+	},
+	Type:     &MsigProposeResult{},
+	Encoders: msigProposeCmd.Encoders,
+}
+
+var msigRemoveSignerCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Propose removing <signer> from the multisig wallet",
+		ShortDescription: `Proposes a transaction from the wallet to itself that removes <signer>. The
+change takes effect once the proposal has collected enough approvals.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("wallet", true, false, "Address of the multisig wallet"),
+		cmdkit.StringArg("signer", true, false, "Address of the signer to remove"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address to send from"),
+		cmdkit.BoolOption("decrease", "Also decrease the number of required approvals by one"),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		signer, err := address.NewFromString(req.Arguments[1])
+		if err != nil {
+			return err
+		}
+
+		decrease, _ := req.Options["decrease"].(bool)
+
+		return msigProposeSelf(req, re, env, "removeSigner", signer, decrease)
+	},
+	Type:     &MsigProposeResult{},
+	Encoders: msigProposeCmd.Encoders,
+}
+
+var msigChangeRequirementCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Propose changing the number of approvals the multisig wallet requires",
+		ShortDescription: `Proposes a transaction from the wallet to itself that sets the number of
+required approvals to <required>.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("wallet", true, false, "Address of the multisig wallet"),
+		cmdkit.StringArg("required", true, false, "New number of required approvals"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address to send from"),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		required, ok := big.NewInt(0).SetString(req.Arguments[1], 10)
+		if !ok {
+			return fmt.Errorf("required must be a valid integer")
+		}
+
+		return msigProposeSelf(req, re, env, "changeRequirement", required)
+	},
+	Type:     &MsigProposeResult{},
+	Encoders: msigProposeCmd.Encoders,
+}
+
+var msigInfoCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show the signers, vesting schedule and pending transactions of a multisig wallet",
+		ShortDescription: `Queries the state of the multisig wallet at the current head, or at the tipset
+given with --at.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("wallet", true, false, "Address of the multisig wallet"),
+	},
+	Options: []cmdkit.Option{
+		atOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		wallet, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		baseKey, err := parseAtOption(req, env)
+		if err != nil {
+			return err
+		}
+
+		state, err := GetPorcelainAPI(env).MultisigGetState(req.Context, wallet, baseKey)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(state)
+	},
+	Type: multisig.State{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, state *multisig.State) error {
+			sw := NewSilentWriter(w)
+			sw.Printf("Signers: %s\n", joinAddresses(state.Signers))
+			sw.Printf("Required: %d\n", state.Required)
+			if state.UnlockDuration != nil && !state.UnlockDuration.Equal(types.NewBlockHeight(0)) {
+				sw.Printf("Vesting: %s FIL from block %s over %s blocks\n", state.InitialBalance, state.StartingBlock, state.UnlockDuration)
+			}
+			for _, tx := range state.Transactions {
+				sw.Printf("Transaction %d: %s FIL to %s", tx.ID, tx.Value, tx.To)
+				if tx.Method != "" {
+					sw.Printf(" calling %s", tx.Method)
+				}
+				sw.Printf(" (approved by %s)\n", joinAddresses(tx.Approved))
+			}
+			return sw.Error()
+		}),
+	},
+}
+
+// msigPropose proposes a transaction from the wallet named by the first
+// argument and emits its id.
+func msigPropose(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment, to address.Address, value types.AttoFIL, method string, params ...interface{}) error {
+	fromAddr, err := fromAddrOrDefault(req, env)
+	if err != nil {
+		return err
+	}
+
+	wallet, err := address.NewFromString(req.Arguments[0])
+	if err != nil {
+		return err
+	}
+
+	gasPrice, gasLimit, _, err := parseGasOptions(req)
+	if err != nil {
+		return err
+	}
+
+	txID, err := GetPorcelainAPI(env).MultisigPropose(req.Context, fromAddr, wallet, gasPrice, gasLimit, to, value, method, params...)
+	if err != nil {
+		return err
+	}
+
+	return re.Emit(&MsigProposeResult{TxID: txID})
+}
+
+// msigProposeSelf proposes a call from the wallet to one of its own
+// configuration methods.
+func msigProposeSelf(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment, method string, params ...interface{}) error {
+	wallet, err := address.NewFromString(req.Arguments[0])
+	if err != nil {
+		return err
+	}
+
+	return msigPropose(req, re, env, wallet, types.ZeroAttoFIL, method, params...)
+}
+
+// parseMethodParams parses the string arguments of a method call into values
+// of the method's parameter types.
+func parseMethodParams(paramTypes []abi.Type, args []string) ([]interface{}, error) {
+	if len(args) != len(paramTypes) {
+		return nil, fmt.Errorf("method takes %d params, got %d", len(paramTypes), len(args))
+	}
+
+	params := make([]interface{}, len(args))
+	for i, arg := range args {
+		var ok bool
+		switch paramTypes[i] {
+		case abi.Address:
+			addr, err := address.NewFromString(arg)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid address param %s", arg)
+			}
+			params[i], ok = addr, true
+		case abi.AttoFIL:
+			params[i], ok = types.NewAttoFILFromFILString(arg)
+		case abi.BytesAmount:
+			params[i], ok = types.NewBytesAmountFromString(arg, 10)
+		case abi.BlockHeight:
+			var h uint64
+			h, ok = parseUint(arg)
+			params[i] = types.NewBlockHeight(h)
+		case abi.Integer:
+			params[i], ok = big.NewInt(0).SetString(arg, 10)
+		case abi.SectorID:
+			params[i], ok = parseUint(arg)
+		case abi.Boolean:
+			b, err := strconv.ParseBool(arg)
+			params[i], ok = b, err == nil
+		case abi.String:
+			params[i], ok = arg, true
+		case abi.PeerID:
+			pid, err := peer.IDB58Decode(arg)
+			params[i], ok = pid, err == nil
+		default:
+			return nil, fmt.Errorf("params of type %s are not supported", paramTypes[i])
+		}
+		if !ok {
+			return nil, fmt.Errorf("invalid %s param %s", paramTypes[i], arg)
+		}
+	}
+	return params, nil
+}
+
+func parseUint(s string) (uint64, bool) {
+	u, err := strconv.ParseUint(s, 10, 64)
+	return u, err == nil
+}
+
+func parseMsigTxArgs(req *cmds.Request, env cmds.Environment) (from address.Address, wallet address.Address, txID uint64, err error) {
+	from, err = fromAddrOrDefault(req, env)
+	if err != nil {
+		return
+	}
+
+	wallet, err = address.NewFromString(req.Arguments[0])
+	if err != nil {
+		return
+	}
+
+	txID, err = strconv.ParseUint(req.Arguments[1], 10, 64)
+	if err != nil {
+		err = errors.Wrap(err, "id must be a valid integer")
+	}
+	return
+}
+
+func parseAddressList(s string) ([]address.Address, error) {
+	var addrs []address.Address
+	for _, a := range strings.Split(s, ",") {
+		addr, err := address.NewFromString(strings.TrimSpace(a))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid address %q", a)
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+func joinAddresses(addrs []address.Address) string {
+	strs := make([]string, len(addrs))
+	for i, a := range addrs {
+		strs[i] = a.String()
+	}
+	return strings.Join(strs, ", ")
+}
@@ -0,0 +1,33 @@
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+)
+
+func TestMultisigHelp(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	t.Run("--help shows general msig help", func(t *testing.T) {
+		expected := []string{
+			"Create a new multisig wallet holding <value> FIL",
+			"Propose sending <value> FIL from the multisig wallet to <to>",
+			"Approve a pending multisig transaction",
+			"Cancel a pending multisig transaction",
+			"Propose adding <signer> to the multisig wallet",
+		}
+
+		result := runHelpSuccess(t, "msig", "--help")
+		for _, elem := range expected {
+			assert.Contains(t, result, elem)
+		}
+	})
+
+	t.Run("create --help shows create help", func(t *testing.T) {
+		result := runHelpSuccess(t, "msig", "create", "--help")
+		assert.Contains(t, result, "the initial value vests linearly over that many")
+	})
+}
@@ -0,0 +1,43 @@
+package commands
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/abi"
+	"github.com/filecoin-project/go-filecoin/address"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+func TestParseMethodParams(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("parses params of the method's types", func(t *testing.T) {
+		params, err := parseMethodParams(
+			[]abi.Type{abi.Address, abi.AttoFIL, abi.BlockHeight, abi.Integer, abi.Boolean},
+			[]string{address.TestAddress.String(), "2", "7", "42", "true"},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{
+			address.TestAddress,
+			types.NewAttoFILFromFIL(2),
+			types.NewBlockHeight(7),
+			big.NewInt(42),
+			true,
+		}, params)
+	})
+
+	t.Run("fails with the wrong number of params", func(t *testing.T) {
+		_, err := parseMethodParams([]abi.Type{abi.Address}, []string{})
+		assert.Error(t, err)
+	})
+
+	t.Run("fails with an invalid param", func(t *testing.T) {
+		_, err := parseMethodParams([]abi.Type{abi.SectorID}, []string{"-1"})
+		assert.Error(t, err)
+	})
+}
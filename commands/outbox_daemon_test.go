@@ -77,4 +77,37 @@ func TestOutbox(t *testing.T) {
 		out = d.RunSuccess("outbox", "ls").ReadStdoutTrimNewlines()
 		assert.Empty(t, out)
 	})
+
+	t.Run("persists queue across restarts", func(t *testing.T) {
+
+		d := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[0])).Start()
+		defer d.ShutdownSuccess()
+		peer := th.NewDaemon(t).Start()
+		defer peer.ShutdownSuccess()
+
+		c1 := sendMessage(d, fixtures.TestAddresses[0], fixtures.TestAddresses[2]).ReadStdoutTrimNewlines()
+		c2 := sendMessage(d, fixtures.TestAddresses[0], fixtures.TestAddresses[2]).ReadStdoutTrimNewlines()
+
+		d.Restart()
+
+		out := d.RunSuccess("outbox", "ls").ReadStdout()
+		assert.Contains(t, out, c1)
+		assert.Contains(t, out, c2)
+
+		// Pending messages are published to the message pool again.
+		out = d.RunSuccess("mpool", "ls").ReadStdout()
+		assert.Contains(t, out, c1)
+		assert.Contains(t, out, c2)
+
+		// They are broadcast once the restarted node has synced with a peer.
+		d.ConnectSuccess(peer)
+		out = peer.RunSuccess("mpool", "ls", "--wait-for-count=2").ReadStdout()
+		assert.Contains(t, out, c1)
+		assert.Contains(t, out, c2)
+
+		// The next message follows the queued ones.
+		c3 := sendMessage(d, fixtures.TestAddresses[0], fixtures.TestAddresses[2]).ReadStdoutTrimNewlines()
+		out = d.RunSuccess("outbox", "ls").ReadStdout()
+		assert.Contains(t, out, c3)
+	})
 }
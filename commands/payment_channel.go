@@ -3,7 +3,9 @@ package commands
 import (
 	"fmt"
 	"io"
+	"math/big"
 	"strconv"
+	"strings"
 
 	"github.com/filecoin-project/go-filecoin/actor/builtin/paymentbroker"
 	"github.com/filecoin-project/go-filecoin/address"
@@ -25,6 +27,7 @@ var paymentChannelCmd = &cmds.Command{
 		"ls":      lsCmd,
 		"reclaim": reclaimCmd,
 		"redeem":  redeemCmd,
+		"settle":  settleCmd,
 		"voucher": voucherCmd,
 	},
 }
@@ -49,8 +52,9 @@ message to be mined to get the channelID.`,
 	},
 	Options: []cmdkit.Option{
 		cmdkit.StringOption("from", "Address to send from"),
-		priceOption,
-		limitOption,
+		cmdkit.Uint64Option("settle-period", "Number of blocks the target has to redeem vouchers once the channel settles").WithDefault(uint64(paymentbroker.DefaultSettlePeriod)),
+		estimatedPriceOption,
+		estimatedLimitOption,
 		previewOption,
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
@@ -74,7 +78,9 @@ message to be mined to get the channelID.`,
 			return ErrInvalidBlockHeight
 		}
 
-		gasPrice, gasLimit, preview, err := parseGasOptions(req)
+		settlePeriod := types.NewBlockHeight(req.Options["settle-period"].(uint64))
+
+		gasPrice, gasLimit, preview, err := parseGasOptionsOrEstimate(req, env, fromAddr, address.PaymentBrokerAddress, amount, "createChannel", target, eol, settlePeriod)
 		if err != nil {
 			return err
 		}
@@ -85,7 +91,7 @@ message to be mined to get the channelID.`,
 				fromAddr,
 				address.PaymentBrokerAddress,
 				"createChannel",
-				target, eol,
+				target, eol, settlePeriod,
 			)
 			if err != nil {
 				return err
@@ -107,6 +113,7 @@ message to be mined to get the channelID.`,
 			"createChannel",
 			target,
 			eol,
+			settlePeriod,
 		)
 		if err != nil {
 			return err
@@ -139,6 +146,7 @@ var lsCmd = &cmds.Command{
 	Options: []cmdkit.Option{
 		cmdkit.StringOption("from", "Address for which message is sent"),
 		cmdkit.StringOption("payer", "Address for which to retrieve channels (defaults to from if omitted)"),
+		atOption,
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		fromAddr, err := fromAddrOrDefault(req, env)
@@ -152,7 +160,12 @@ var lsCmd = &cmds.Command{
 			return err
 		}
 
-		channels, err := GetPorcelainAPI(env).PaymentChannelLs(req.Context, fromAddr, payerAddr)
+		baseKey, err := parseAtOption(req, env)
+		if err != nil {
+			return err
+		}
+
+		channels, err := GetPorcelainAPI(env).PaymentChannelLs(req.Context, fromAddr, payerAddr, baseKey)
 		if err != nil {
 			return err
 		}
@@ -168,7 +181,17 @@ var lsCmd = &cmds.Command{
 			}
 
 			for chid, pc := range *pcs {
-				_, err := fmt.Fprintf(w, "%s: target: %v, amt: %v, amt redeemed: %v, eol: %v\n", chid, pc.Target.String(), pc.Amount, pc.AmountRedeemed, pc.Eol)
+				_, err := fmt.Fprintf(w, "%s: target: %v, amt: %v, amt redeemed: %v, eol: %v", chid, pc.Target.String(), pc.Amount, pc.AmountRedeemed, pc.Eol)
+				if err != nil {
+					return err
+				}
+				if pc.SettlingAt != nil {
+					_, err = fmt.Fprintf(w, ", settling at: %v", pc.SettlingAt)
+					if err != nil {
+						return err
+					}
+				}
+				_, err = fmt.Fprintln(w)
 				if err != nil {
 					return err
 				}
@@ -190,6 +213,9 @@ var voucherCmd = &cmds.Command{
 	Options: []cmdkit.Option{
 		cmdkit.StringOption("from", "Address for which to retrieve channels"),
 		cmdkit.StringOption("validat", "Smallest block height at which target can redeem"),
+		cmdkit.Uint64Option("lane", "Lane of the channel the voucher pays on").WithDefault(uint64(0)),
+		cmdkit.Uint64Option("nonce", "Nonce of the voucher within its lane").WithDefault(uint64(0)),
+		cmdkit.StringOption("merge", "Comma separated lane:nonce pairs of lanes to merge into the voucher's lane"),
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		fromAddr, err := fromAddrOrDefault(req, env)
@@ -212,7 +238,18 @@ var voucherCmd = &cmds.Command{
 			return err
 		}
 
-		voucher, err := GetPorcelainAPI(env).PaymentChannelVoucher(req.Context, fromAddr, channel, amount, validAt, nil)
+		lane, _ := req.Options["lane"].(uint64)
+		nonce, _ := req.Options["nonce"].(uint64)
+
+		var merges []types.Merge
+		if o, ok := req.Options["merge"].(string); ok {
+			merges, err = parseMerges(o)
+			if err != nil {
+				return err
+			}
+		}
+
+		voucher, err := GetPorcelainAPI(env).PaymentChannelVoucher(req.Context, fromAddr, channel, lane, nonce, amount, validAt, merges, nil)
 		if err != nil {
 			return err
 		}
@@ -239,6 +276,27 @@ type RedeemResult struct {
 	Preview bool
 }
 
+// parseMerges parses a comma separated list of lane:nonce pairs.
+func parseMerges(s string) ([]types.Merge, error) {
+	var merges []types.Merge
+	for _, m := range strings.Split(s, ",") {
+		parts := strings.Split(strings.TrimSpace(m), ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid merge %q, expected lane:nonce", m)
+		}
+		lane, err := strconv.ParseUint(parts[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid merge lane %q", parts[0])
+		}
+		nonce, err := strconv.ParseUint(parts[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid merge nonce %q", parts[1])
+		}
+		merges = append(merges, types.Merge{Lane: lane, Nonce: nonce})
+	}
+	return merges, nil
+}
+
 var redeemCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Redeem a payment voucher against a payment channel",
@@ -248,8 +306,8 @@ var redeemCmd = &cmds.Command{
 	},
 	Options: []cmdkit.Option{
 		cmdkit.StringOption("from", "Address of the channel target"),
-		priceOption,
-		limitOption,
+		estimatedPriceOption,
+		estimatedLimitOption,
 		previewOption,
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
@@ -258,28 +316,31 @@ var redeemCmd = &cmds.Command{
 			return err
 		}
 
-		gasPrice, gasLimit, preview, err := parseGasOptions(req)
-		if err != nil {
-			return err
-		}
-
 		voucher, err := types.DecodeVoucher(req.Arguments[0])
 		if err != nil {
 			return err
 		}
 
-		result := &ReclaimResult{Preview: preview}
-
 		params := []interface{}{
 			voucher.Payer,
 			&voucher.Channel,
+			new(big.Int).SetUint64(voucher.Lane),
+			new(big.Int).SetUint64(voucher.Nonce),
 			voucher.Amount,
 			&voucher.ValidAt,
+			voucher.Merges,
 			voucher.Condition,
 			[]byte(voucher.Signature),
 			[]interface{}{},
 		}
 
+		gasPrice, gasLimit, preview, err := parseGasOptionsOrEstimate(req, env, fromAddr, address.PaymentBrokerAddress, types.ZeroAttoFIL, "redeem", params...)
+		if err != nil {
+			return err
+		}
+
+		result := &ReclaimResult{Preview: preview}
+
 		if preview {
 			result.GasUsed, err = GetPorcelainAPI(env).MessagePreview(
 				req.Context,
@@ -336,8 +397,8 @@ var reclaimCmd = &cmds.Command{
 	},
 	Options: []cmdkit.Option{
 		cmdkit.StringOption("from", "Address of the channel creator"),
-		priceOption,
-		limitOption,
+		estimatedPriceOption,
+		estimatedLimitOption,
 		previewOption,
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
@@ -351,7 +412,7 @@ var reclaimCmd = &cmds.Command{
 			return fmt.Errorf("invalid channel id")
 		}
 
-		gasPrice, gasLimit, preview, err := parseGasOptions(req)
+		gasPrice, gasLimit, preview, err := parseGasOptionsOrEstimate(req, env, fromAddr, address.PaymentBrokerAddress, types.ZeroAttoFIL, "reclaim", channel)
 		if err != nil {
 			return err
 		}
@@ -423,8 +484,8 @@ var closeCmd = &cmds.Command{
 	},
 	Options: []cmdkit.Option{
 		cmdkit.StringOption("from", "Address of the channel target"),
-		priceOption,
-		limitOption,
+		estimatedPriceOption,
+		estimatedLimitOption,
 		previewOption,
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
@@ -433,28 +494,31 @@ var closeCmd = &cmds.Command{
 			return err
 		}
 
-		gasPrice, gasLimit, preview, err := parseGasOptions(req)
-		if err != nil {
-			return err
-		}
-
 		voucher, err := types.DecodeVoucher(req.Arguments[0])
 		if err != nil {
 			return err
 		}
 
-		result := &CloseResult{Preview: preview}
-
 		params := []interface{}{
 			voucher.Payer,
 			&voucher.Channel,
+			new(big.Int).SetUint64(voucher.Lane),
+			new(big.Int).SetUint64(voucher.Nonce),
 			voucher.Amount,
 			&voucher.ValidAt,
+			voucher.Merges,
 			voucher.Condition,
 			[]byte(voucher.Signature),
 			[]interface{}{},
 		}
 
+		gasPrice, gasLimit, preview, err := parseGasOptionsOrEstimate(req, env, fromAddr, address.PaymentBrokerAddress, types.ZeroAttoFIL, "close", params...)
+		if err != nil {
+			return err
+		}
+
+		result := &CloseResult{Preview: preview}
+
 		if preview {
 			result.GasUsed, err = GetPorcelainAPI(env).MessagePreview(
 				req.Context,
@@ -513,8 +577,8 @@ var extendCmd = &cmds.Command{
 	},
 	Options: []cmdkit.Option{
 		cmdkit.StringOption("from", "Address of the channel creator"),
-		priceOption,
-		limitOption,
+		estimatedPriceOption,
+		estimatedLimitOption,
 		previewOption,
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
@@ -538,7 +602,7 @@ var extendCmd = &cmds.Command{
 			return ErrInvalidBlockHeight
 		}
 
-		gasPrice, gasLimit, preview, err := parseGasOptions(req)
+		gasPrice, gasLimit, preview, err := parseGasOptionsOrEstimate(req, env, fromAddr, address.PaymentBrokerAddress, amount, "extend", channel, eol)
 		if err != nil {
 			return err
 		}
@@ -610,8 +674,8 @@ var cancelCmd = &cmds.Command{
 	},
 	Options: []cmdkit.Option{
 		cmdkit.StringOption("from", "address of the channel creator"),
-		priceOption,
-		limitOption,
+		estimatedPriceOption,
+		estimatedLimitOption,
 		previewOption,
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
@@ -625,7 +689,7 @@ var cancelCmd = &cmds.Command{
 			return fmt.Errorf("invalid channel id")
 		}
 
-		gasPrice, gasLimit, preview, err := parseGasOptions(req)
+		gasPrice, gasLimit, preview, err := parseGasOptionsOrEstimate(req, env, fromAddr, address.PaymentBrokerAddress, types.ZeroAttoFIL, "cancel", channel)
 		if err != nil {
 			return err
 		}
@@ -680,3 +744,104 @@ var cancelCmd = &cmds.Command{
 		}),
 	},
 }
+
+// SettleResult type returned from Settle
+type SettleResult struct {
+	Cid     cid.Cid
+	GasUsed types.GasUnits
+	Preview bool
+}
+
+var settleCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Start settling a payment channel",
+		ShortDescription: `Starts the settle period of a payment channel. The target may redeem vouchers until
+the settle period has passed, after which the payer may reclaim the remaining funds.
+Either the payer or the target of the channel may settle it.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("channel", true, false, "id of channel to settle"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "address of the channel payer or target"),
+		cmdkit.StringOption("payer", "address of the channel payer (defaults to from if omitted)"),
+		estimatedPriceOption,
+		estimatedLimitOption,
+		previewOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fromAddr, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		payerAddr, err := optionalAddr(req.Options["payer"])
+		if err != nil {
+			return err
+		}
+		if payerAddr.Empty() {
+			payerAddr = fromAddr
+		}
+
+		channel, ok := types.NewChannelIDFromString(req.Arguments[0], 10)
+		if !ok {
+			return fmt.Errorf("invalid channel id")
+		}
+
+		gasPrice, gasLimit, preview, err := parseGasOptionsOrEstimate(req, env, fromAddr, address.PaymentBrokerAddress, types.ZeroAttoFIL, "settle", payerAddr, channel)
+		if err != nil {
+			return err
+		}
+
+		if preview {
+			usedGas, err := GetPorcelainAPI(env).MessagePreview(
+				req.Context,
+				fromAddr,
+				address.PaymentBrokerAddress,
+				"settle",
+				payerAddr,
+				channel,
+			)
+			if err != nil {
+				return err
+			}
+			return re.Emit(&SettleResult{
+				Cid:     cid.Cid{},
+				GasUsed: usedGas,
+				Preview: true,
+			})
+		}
+
+		c, err := GetPorcelainAPI(env).MessageSend(
+			req.Context,
+			fromAddr,
+			address.PaymentBrokerAddress,
+			types.NewAttoFILFromFIL(0),
+			gasPrice,
+			gasLimit,
+			"settle",
+			payerAddr,
+			channel,
+		)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(&SettleResult{
+			Cid:     c,
+			GasUsed: types.NewGasUnits(0),
+			Preview: false,
+		})
+	},
+	Type: &SettleResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *SettleResult) error {
+			if res.Preview {
+				output := strconv.FormatUint(uint64(res.GasUsed), 10)
+				_, err := w.Write([]byte(output))
+				return err
+			}
+			return PrintString(w, res.Cid)
+		}),
+	},
+}
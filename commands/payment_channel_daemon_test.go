@@ -356,13 +356,38 @@ func TestPaymentChannelCloseSuccess(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 0, int(resp.Receipt.ExitCode))
 
+	// the channel settles before the remaining funds may be reclaimed
 	channels, err := rsrc.target.PaychLs(ctx, fast.AOFromAddr(rsrc.payerAddr))
 	require.NoError(t, err)
+	channel := channels[chanid.String()]
+	require.NotNil(t, channel)
+	require.NotNil(t, channel.SettlingAt)
+
+	for {
+		bh, err := series.GetHeadBlockHeight(ctx, env.GenesisMiner)
+		require.NoError(t, err)
+		if bh.GreaterEqual(channel.SettlingAt) {
+			break
+		}
+		series.CtxMiningOnce(ctx)
+	}
+
+	mcid, err = rsrc.payer.PaychReclaim(ctx, chanid, fast.AOFromAddr(rsrc.payerAddr), fast.AOPrice(big.NewFloat(1)), fast.AOLimit(300))
+	require.NoError(t, err)
+
+	series.CtxMiningOnce(ctx)
+
+	reclaimResp, err := rsrc.payer.MessageWait(ctx, mcid)
+	require.NoError(t, err)
+	assert.Equal(t, 0, int(reclaimResp.Receipt.ExitCode))
+
+	channels, err = rsrc.target.PaychLs(ctx, fast.AOFromAddr(rsrc.payerAddr))
+	require.NoError(t, err)
 	require.Len(t, channels, 0)
 
 	payerBalanceAfter, err := rsrc.payer.WalletBalance(ctx, rsrc.payerAddr)
 	require.NoError(t, err)
-	assert.Equal(t, payerBalanceBefore.Sub(voucherAmount).Sub(gasReceiptForPaychCreate), payerBalanceAfter)
+	assert.Equal(t, payerBalanceBefore.Sub(voucherAmount).Sub(gasReceiptForPaychCreate).Sub(reclaimResp.Receipt.GasAttoFIL), payerBalanceAfter)
 
 	targetBalanceAfter, err := rsrc.target.WalletBalance(ctx, rsrc.targetAddr)
 	require.NoError(t, err)
@@ -41,6 +41,13 @@ var protocolCmd = &cmds.Command{
 				}
 			}
 
+			gs := pp.GasSchedule
+			_, err = fmt.Fprintf(w, "Gas Schedule:\n\tStorage Put: %d per byte\n\tStorage Get: %d per block\n\tSend: %d per call\n\tCreate Actor: %d per call\n",
+				gs.StoragePutPerByte, gs.StorageGetPerBlock, gs.SendPerCall, gs.CreateActorPerCall)
+			if err != nil {
+				return err
+			}
+
 			return nil
 		}),
 	},
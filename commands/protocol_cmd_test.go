@@ -32,4 +32,5 @@ func TestProtocol(t *testing.T) {
 	out := cmd.RunSuccess(ctx, "protocol").ReadStdout()
 	assert.Contains(t, out, "Network: go-filecoin-test")
 	assert.Contains(t, out, "Auto-Seal Interval: 120 seconds")
+	assert.Contains(t, out, "Gas Schedule:")
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/ipfs/go-ipfs-cmds"
 
 	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/vm"
 )
 
 var showCmd = &cmds.Command{
@@ -21,6 +22,7 @@ var showCmd = &cmds.Command{
 		"header":   showHeaderCmd,
 		"messages": showMessagesCmd,
 		"receipts": showReceiptsCmd,
+		"trace":    showTraceCmd,
 	},
 }
 
@@ -212,3 +214,55 @@ field of the filecoin block header.`,
 		}),
 	},
 }
+
+var showTraceCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show the execution trace of a message by its CID",
+		ShortDescription: `Replays the tipset that included the message and prints
+every send it made between actors, with the method, value, exit code and gas
+used by each, and the storage chunks written. Params and return values are
+included with --enc=json.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("cid", true, false, "CID of message to trace"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		msgCid, err := cid.Decode(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		trace, err := GetPorcelainAPI(env).MessageTrace(req.Context, msgCid)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(trace)
+	},
+	Type: vm.ExecutionTrace{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, trace *vm.ExecutionTrace) error {
+			sw := NewSilentWriter(w)
+			writeExecutionTrace(sw, trace, "")
+			return sw.Error()
+		}),
+	},
+}
+
+func writeExecutionTrace(sw *SilentWriter, trace *vm.ExecutionTrace, indent string) {
+	method := trace.MethodName
+	if trace.Method == types.SendMethodID {
+		method = "send"
+	}
+	sw.Printf("%s%s -> %s %s(%s) value: %s exit: %d gas: %d\n",
+		indent, trace.From, trace.To, method, trace.Method, trace.Value, trace.ExitCode, trace.GasUsed)
+	if trace.Error != "" {
+		sw.Printf("%s  error: %s\n", indent, trace.Error)
+	}
+	for _, c := range trace.StorageWrites {
+		sw.Printf("%s  wrote: %s\n", indent, c)
+	}
+	for _, subcall := range trace.Subcalls {
+		writeExecutionTrace(sw, subcall, indent+"  ")
+	}
+}
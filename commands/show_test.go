@@ -12,6 +12,7 @@ import (
 	th "github.com/filecoin-project/go-filecoin/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
 	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/vm"
 )
 
 func TestBlockDaemon(t *testing.T) {
@@ -195,4 +196,42 @@ func TestBlockDaemon(t *testing.T) {
 		require.NoError(t, json.Unmarshal([]byte(receiptsGetLine), &receipts))
 		assert.Equal(t, blockGetBlock.Receipts, receipts)
 	})
+
+	t.Run("show trace", func(t *testing.T) {
+		d := th.NewDaemon(
+			t,
+			th.DefaultAddress(fixtures.TestAddresses[0]),
+			th.KeyFile(fixtures.KeyFilePaths()[1]),
+			th.WithMiner(fixtures.TestMiners[0]),
+			th.KeyFile(fixtures.KeyFilePaths()[0]),
+		).Start()
+		defer d.ShutdownSuccess()
+
+		d.RunSuccess("mining", "once")
+
+		from := d.GetDefaultAddress()
+
+		msgCid := d.RunSuccess("message", "send",
+			"--from", from,
+			"--gas-price", "1",
+			"--gas-limit", "300",
+			"--value", "10",
+			fixtures.TestAddresses[3],
+		).ReadStdoutTrimNewlines()
+
+		th.RunSuccessFirstLine(d, "mining", "once")
+
+		traceLine := th.RunSuccessFirstLine(d, "show", "trace", msgCid, "--enc", "json")
+		var trace vm.ExecutionTrace
+		require.NoError(t, json.Unmarshal([]byte(traceLine), &trace))
+
+		assert.Equal(t, from, trace.From.String())
+		assert.Equal(t, fixtures.TestAddresses[3], trace.To.String())
+		assert.Equal(t, types.NewAttoFILFromFIL(10), trace.Value)
+		assert.Equal(t, uint8(0), trace.ExitCode)
+		assert.Empty(t, trace.Subcalls)
+
+		output := d.RunSuccess("show", "trace", msgCid).ReadStdoutTrimNewlines()
+		assert.Contains(t, output, from+" -> "+fixtures.TestAddresses[3])
+	})
 }
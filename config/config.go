@@ -19,6 +19,7 @@ import (
 type Config struct {
 	API           *APIConfig           `json:"api"`
 	Bootstrap     *BootstrapConfig     `json:"bootstrap"`
+	Chain         *ChainConfig         `json:"chain"`
 	Datastore     *DatastoreConfig     `json:"datastore"`
 	Heartbeat     *HeartbeatConfig     `json:"heartbeat"`
 	Mining        *MiningConfig        `json:"mining"`
@@ -101,6 +102,36 @@ func newDefaultBootstrapConfig() *BootstrapConfig {
 	}
 }
 
+// ChainConfig holds all configuration options related to the chain store.
+type ChainConfig struct {
+	// AutoPrune enables periodic pruning of the state of old tipsets.
+	AutoPrune bool `json:"autoPrune"`
+	// KeepStateRoots is the number of most recent finalized tipsets of the
+	// chain whose state is kept when pruning. The state of tipsets above the
+	// finalized height is always kept.
+	KeepStateRoots uint `json:"keepStateRoots"`
+	// FinalityDepth is the number of tipsets below the head after which a
+	// tipset is considered finalized when pruning, even if no later tipset
+	// is checkpointed.
+	FinalityDepth uint `json:"finalityDepth"`
+	// PrunePeriod represents how frequently the state is pruned when AutoPrune is set.
+	// Golang duration units are accepted.
+	PrunePeriod string `json:"prunePeriod"`
+	// Checkpoints are keys of tipsets the chain must include. The node
+	// refuses to reorg past a checkpoint once it has synced its tipset.
+	Checkpoints []types.TipSetKey `json:"checkpoints"`
+}
+
+func newDefaultChainConfig() *ChainConfig {
+	return &ChainConfig{
+		AutoPrune:      false,
+		KeepStateRoots: 1000,
+		FinalityDepth:  900,
+		PrunePeriod:    "1h",
+		Checkpoints:    []types.TipSetKey{},
+	}
+}
+
 // MiningConfig holds all configuration options related to mining.
 type MiningConfig struct {
 	MinerAddress            address.Address `json:"minerAddress"`
@@ -206,12 +237,19 @@ type MessagePoolConfig struct {
 	MaxPoolSize uint `json:"maxPoolSize"`
 	// MaxNonceGap is the maximum nonce of a message past the last received on chain
 	MaxNonceGap types.Uint64 `json:"maxNonceGap"`
+	// ReplaceByFeePercent is the minimum percentage by which the gas price of a message
+	// must exceed that of a pending message with the same actor and nonce to replace it
+	ReplaceByFeePercent uint `json:"replaceByFeePercent"`
+	// MaxPendingPerSender is the maximum number of pending messages from a single actor
+	MaxPendingPerSender uint `json:"maxPendingPerSender"`
 }
 
 func newDefaultMessagePoolConfig() *MessagePoolConfig {
 	return &MessagePoolConfig{
-		MaxPoolSize: 10000,
-		MaxNonceGap: 100,
+		MaxPoolSize:         10000,
+		MaxNonceGap:         100,
+		ReplaceByFeePercent: 10,
+		MaxPendingPerSender: 1000,
 	}
 }
 
@@ -235,6 +273,7 @@ func NewDefaultConfig() *Config {
 	return &Config{
 		API:           newDefaultAPIConfig(),
 		Bootstrap:     newDefaultBootstrapConfig(),
+		Chain:         newDefaultChainConfig(),
 		Datastore:     newDefaultDatastoreConfig(),
 		Swarm:         newDefaultSwarmConfig(),
 		Mining:        newDefaultMiningConfig(),
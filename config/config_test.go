@@ -62,6 +62,13 @@ func TestWriteFile(t *testing.T) {
 		"minPeerThreshold": 0,
 		"period": "1m"
 	},
+	"chain": {
+		"autoPrune": false,
+		"keepStateRoots": 1000,
+		"finalityDepth": 900,
+		"prunePeriod": "1h",
+		"checkpoints": []
+	},
 	"datastore": {
 		"type": "badgerds",
 		"path": "badger"
@@ -79,7 +86,9 @@ func TestWriteFile(t *testing.T) {
 	},
 	"mpool": {
 		"maxPoolSize": 10000,
-		"maxNonceGap": "100"
+		"maxNonceGap": "100",
+		"replaceByFeePercent": 10,
+		"maxPendingPerSender": 1000
 	},
 	"observability": {
 		"metrics": {
@@ -11,6 +11,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/state"
 	"github.com/filecoin-project/go-filecoin/types"
+	wutil "github.com/filecoin-project/go-filecoin/wallet/util"
 )
 
 // CompareElectionPower return true if the input electionProof is below the
@@ -94,13 +95,23 @@ func (tm TicketMachine) NotarizeTime(ticket *types.Ticket) error {
 // IsValidTicket verifies that the ticket's proof of randomness and delay are
 // valid with respect to its parent.
 func (tm TicketMachine) IsValidTicket(parent, ticket types.Ticket, signerAddr address.Address) bool {
-	vrfPi := types.Signature(ticket.VRFProof)
-	if valid := types.IsValidSignature(parent.VDFResult[:], signerAddr, vrfPi); !valid {
-		return false
-	}
+	signer, err := ticketSigner(parent, ticket)
+	return err == nil && signer == signerAddr
+}
 
+// ticketSigner verifies that the ticket's proof of delay is valid and returns
+// the address of the key whose proof of randomness derived it from its parent.
+func ticketSigner(parent, ticket types.Ticket) (address.Address, error) {
 	// TODO #2119, decide if we are going to keep the VDF.  If so fill
 	// in the implementation, removing this equality check and actually
 	// validating the VDFProof.
-	return bytes.Equal(ticket.VDFResult, ticket.VRFProof)
+	if !bytes.Equal(ticket.VDFResult, ticket.VRFProof) {
+		return address.Undef, errors.New("ticket is not notarized")
+	}
+
+	pk, err := wutil.Ecrecover(parent.VDFResult, types.Signature(ticket.VRFProof))
+	if err != nil {
+		return address.Undef, errors.Wrap(err, "could not recover ticket signer")
+	}
+	return address.NewSecp256k1Address(pk)
 }
@@ -24,6 +24,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/state"
 	"github.com/filecoin-project/go-filecoin/types"
 	"github.com/filecoin-project/go-filecoin/vm"
+	vmerrors "github.com/filecoin-project/go-filecoin/vm/errors"
 )
 
 var (
@@ -48,6 +49,30 @@ var (
 	ErrUnorderedTipSets = errors.New("trying to order two identical tipsets")
 )
 
+// invalidTipSetError is an error of a tipset that breaks the rules of
+// consensus, as opposed to a failure to read the chain or state needed to
+// validate it.
+type invalidTipSetError struct {
+	err error
+}
+
+func (e *invalidTipSetError) Error() string {
+	return e.err.Error()
+}
+
+// NewInvalidTipSetError marks err as reporting a tipset that breaks the rules
+// of consensus.
+func NewInvalidTipSetError(err error) error {
+	return &invalidTipSetError{err: err}
+}
+
+// IsInvalidTipSet returns true if err, or the error it wraps, was returned by
+// NewInvalidTipSetError. Validating such a tipset again always fails.
+func IsInvalidTipSet(err error) bool {
+	_, ok := errors.Cause(err).(*invalidTipSetError)
+	return ok
+}
+
 var MarkMessagesInBlock func()
 
 // DefaultBlockTime is the estimated proving period time.
@@ -262,7 +287,7 @@ func (c *Expected) RunStateTransition(ctx context.Context, ts types.TipSet, tsMe
 
 	for i := 0; i < ts.Len(); i++ {
 		if err := c.BlockValidator.ValidateSemantic(ctx, ts.At(i), &ancestors[0]); err != nil {
-			return cid.Undef, err
+			return cid.Undef, NewInvalidTipSetError(err)
 		}
 	}
 
@@ -316,7 +341,7 @@ func (c *Expected) validateMining(ctx context.Context, st state.Tree, ts types.T
 		}
 		// Validate block signature
 		if valid := types.IsValidSignature(blk.SignatureData(), workerAddr, blk.BlockSig); !valid {
-			return errors.New("block signature invalid")
+			return NewInvalidTipSetError(errors.New("block signature invalid"))
 		}
 
 		// Validate ElectionProof
@@ -326,20 +351,20 @@ func (c *Expected) validateMining(ctx context.Context, st state.Tree, ts types.T
 			return errors.Wrap(err, "failed checking election proof")
 		}
 		if !result {
-			return errors.New("block author did not win election")
+			return NewInvalidTipSetError(errors.New("block author did not win election"))
 		}
 
 		// Validate ticket array
 		// Block has same number of tickets as increase in height
 		if uint64(len(blk.Tickets)) != uint64(blk.Height)-prevHeight {
-			return errors.Errorf("invalid ticket array length. Expected: %d, actual %d", uint64(blk.Height)-prevHeight, len(blk.Tickets))
+			return NewInvalidTipSetError(errors.Errorf("invalid ticket array length. Expected: %d, actual %d", uint64(blk.Height)-prevHeight, len(blk.Tickets)))
 		}
 
 		// All tickets were correctly generated by miner
 		prevTickets := append([]types.Ticket{prevTicket}, blk.Tickets[:len(blk.Tickets)-1]...)
 		for i := 0; i < len(blk.Tickets); i++ {
 			if !c.IsValidTicket(prevTickets[i], blk.Tickets[i], workerAddr) {
-				return errors.Errorf("invalid ticket: %s in position %d in block %s", blk.Tickets[i].String(), i, blk.Cid().String())
+				return NewInvalidTipSetError(errors.Errorf("invalid ticket: %s in position %d in block %s", blk.Tickets[i].String(), i, blk.Cid().String()))
 			}
 		}
 	}
@@ -376,11 +401,15 @@ func (c *Expected) runMessages(ctx context.Context, st state.Tree, vms vm.Storag
 
 		receipts, err := c.processor.ProcessBlock(ctx, cpySt, vms, blk, tsMessages[i], ancestors)
 		if err != nil {
+			// Only faults are failures of the node rather than of the block.
+			if !vmerrors.IsFault(err) {
+				err = NewInvalidTipSetError(err)
+			}
 			return nil, errors.Wrap(err, "error validating block state")
 		}
 		// TODO: check that receipts actually match
 		if len(receipts) != len(tsReceipts[i]) {
-			return nil, errors.Errorf("found invalid message receipts: %v %v", receipts, blk.MessageReceipts)
+			return nil, NewInvalidTipSetError(errors.Errorf("found invalid message receipts: %v %v", receipts, blk.MessageReceipts))
 		}
 
 		outCid, err := cpySt.Flush(ctx)
@@ -389,7 +418,7 @@ func (c *Expected) runMessages(ctx context.Context, st state.Tree, vms vm.Storag
 		}
 
 		if !outCid.Equals(blk.StateRoot) {
-			return nil, ErrStateRootMismatch
+			return nil, NewInvalidTipSetError(ErrStateRootMismatch)
 		}
 	}
 	if ts.Len() <= 1 { // block validation state == aggregate parent state
@@ -147,6 +147,7 @@ func TestExpected_RunStateTransition_validateMining(t *testing.T) {
 
 		_, err = exp.RunStateTransition(ctx, tipSet, emptyMessages, emptyReceipts, []types.TipSet{pTipSet}, genesisBlock.StateRoot)
 		assert.EqualError(t, err, "block author did not win election")
+		assert.True(t, consensus.IsInvalidTipSet(err))
 	})
 
 	t.Run("returns nil + mining error when ticket validation fails", func(t *testing.T) {
@@ -227,6 +228,7 @@ func TestExpected_RunStateTransition_validateMining(t *testing.T) {
 
 		_, err = exp.RunStateTransition(ctx, tipSet, emptyMessages, emptyReceipts, []types.TipSet{pTipSet}, blocks[0].StateRoot)
 		assert.EqualError(t, err, "block signature invalid")
+		assert.True(t, consensus.IsInvalidTipSet(err))
 	})
 }
 
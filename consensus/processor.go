@@ -46,6 +46,8 @@ type BlockRewarder interface {
 type ApplicationResult struct {
 	Receipt        *types.MessageReceipt
 	ExecutionError error
+	// Trace is only recorded by processors created with NewTracingProcessor.
+	Trace *vm.ExecutionTrace
 }
 
 // ProcessTipSetResponse records the results of successfully applied messages,
@@ -62,6 +64,7 @@ type ProcessTipSetResponse struct {
 type DefaultProcessor struct {
 	signedMessageValidator SignedMessageValidator
 	blockRewarder          BlockRewarder
+	recordTraces           bool
 }
 
 var _ Processor = (*DefaultProcessor)(nil)
@@ -74,6 +77,14 @@ func NewDefaultProcessor() *DefaultProcessor {
 	}
 }
 
+// NewTracingProcessor creates a default processor that records the execution
+// trace of every message it applies.
+func NewTracingProcessor() *DefaultProcessor {
+	p := NewDefaultProcessor()
+	p.recordTraces = true
+	return p
+}
+
 // NewConfiguredProcessor creates a default processor with custom validation and rewards.
 func NewConfiguredProcessor(validator SignedMessageValidator, rewarder BlockRewarder) *DefaultProcessor {
 	return &DefaultProcessor{
@@ -294,8 +305,8 @@ func (p *DefaultProcessor) ApplyMessage(ctx context.Context, st state.Tree, vms
 		return nil, errors.FaultErrorWrap(err, "could not get message cid")
 	}
 
-	tagMethod := msg.Method
-	if tagMethod == "" {
+	tagMethod := msg.Method.String()
+	if msg.Method == types.SendMethodID {
 		tagMethod = "sendFIL"
 	}
 	ctx, err = tag.New(ctx, tag.Insert(msgMethodKey, tagMethod))
@@ -313,7 +324,12 @@ func (p *DefaultProcessor) ApplyMessage(ctx context.Context, st state.Tree, vms
 
 	cachedStateTree := state.NewCachedStateTree(st)
 
-	r, err := p.attemptApplyMessage(ctx, cachedStateTree, vms, msg, bh, gasTracker, ancestors)
+	var executionTrace *vm.ExecutionTrace
+	if p.recordTraces {
+		executionTrace = &vm.ExecutionTrace{}
+	}
+
+	r, err := p.attemptApplyMessage(ctx, cachedStateTree, vms, msg, bh, gasTracker, ancestors, executionTrace)
 	if err == nil {
 		err = cachedStateTree.Commit(ctx)
 		if err != nil {
@@ -359,7 +375,7 @@ func (p *DefaultProcessor) ApplyMessage(ctx context.Context, st state.Tree, vms
 		return nil, errors.FaultErrorWrap(err, "could not set from actor after inc nonce")
 	}
 
-	return &ApplicationResult{Receipt: r, ExecutionError: executionError}, nil
+	return &ApplicationResult{Receipt: r, ExecutionError: executionError, Trace: executionTrace}, nil
 }
 
 var (
@@ -391,12 +407,17 @@ func CallQueryMethod(ctx context.Context, st state.Tree, vms vm.StorageMap, to a
 	// not committing or flushing storage structures guarantees changes won't make it to stored state tree or datastore
 	cachedSt := state.NewCachedStateTree(st)
 
+	methodID, code, err := vm.LookupMethod(st, toActor.Code, method)
+	if err != nil {
+		return nil, code, err
+	}
+
 	msg := &types.Message{
 		From:   from,
 		To:     to,
 		Nonce:  0,
 		Value:  types.ZeroAttoFIL,
-		Method: method,
+		Method: methodID,
 		Params: params,
 	}
 
@@ -410,6 +431,7 @@ func CallQueryMethod(ctx context.Context, st state.Tree, vms vm.StorageMap, to a
 		State:       cachedSt,
 		StorageMap:  vms,
 		GasTracker:  gasTracker,
+		GasSchedule: types.DefaultGasSchedule,
 		BlockHeight: optBh,
 	}
 
@@ -429,12 +451,17 @@ func PreviewQueryMethod(ctx context.Context, st state.Tree, vms vm.StorageMap, t
 	// not committing or flushing storage structures guarantees changes won't make it to stored state tree or datastore
 	cachedSt := state.NewCachedStateTree(st)
 
+	methodID, _, err := vm.LookupMethod(st, toActor.Code, method)
+	if err != nil {
+		return types.NewGasUnits(0), err
+	}
+
 	msg := &types.Message{
 		From:   from,
 		To:     to,
 		Nonce:  0,
 		Value:  types.ZeroAttoFIL,
-		Method: method,
+		Method: methodID,
 		Params: params,
 	}
 
@@ -448,6 +475,7 @@ func PreviewQueryMethod(ctx context.Context, st state.Tree, vms vm.StorageMap, t
 		State:       cachedSt,
 		StorageMap:  vms,
 		GasTracker:  gasTracker,
+		GasSchedule: types.DefaultGasSchedule,
 		BlockHeight: optBh,
 	}
 	vmCtx := vm.NewVMContext(vmCtxParams)
@@ -456,12 +484,66 @@ func PreviewQueryMethod(ctx context.Context, st state.Tree, vms vm.StorageMap, t
 	return vmCtx.GasUnits(), err
 }
 
+// EstimateMessageGas applies msg to st as ApplyMessage would, without validating it or charging
+// for gas, and returns the amount of gas it uses. The changes of a message applied successfully
+// are set in st, but not flushed, so that messages following it can be estimated on top of them.
+// Block height bh is optional; some methods will ignore it. Ancestors are the recent tipsets of
+// the chain the message is applied on, as passed to ApplyMessage.
+func EstimateMessageGas(ctx context.Context, st state.Tree, vms vm.StorageMap, msg *types.Message, bh *types.BlockHeight, ancestors []types.TipSet) (types.GasUnits, error) {
+	cachedSt := state.NewCachedStateTree(st)
+
+	fromActor, err := cachedSt.GetActor(ctx, msg.From)
+	if err != nil {
+		return types.NewGasUnits(0), errors.ApplyErrorPermanentWrapf(err, "failed to get From actor")
+	}
+
+	// Processing an external message from an empty actor upgrades it to an account actor.
+	if fromActor.Empty() {
+		if err := account.UpgradeActor(fromActor); err != nil {
+			return types.NewGasUnits(0), errors.FaultErrorWrap(err, "failed to upgrade empty actor")
+		}
+	}
+
+	toActor, err := cachedSt.GetOrCreateActor(ctx, msg.To, func() (*actor.Actor, error) {
+		return &actor.Actor{}, nil
+	})
+	if err != nil {
+		return types.NewGasUnits(0), errors.FaultErrorWrap(err, "failed to get To actor")
+	}
+
+	// Set the gas limit to the max to measure the gas the message needs.
+	gasTracker := vm.NewGasTracker()
+	gasTracker.MsgGasLimit = types.BlockGasLimit
+
+	vmCtxParams := vm.NewContextParams{
+		From:        fromActor,
+		To:          toActor,
+		Message:     msg,
+		State:       cachedSt,
+		StorageMap:  vms,
+		GasTracker:  gasTracker,
+		GasSchedule: types.DefaultGasSchedule,
+		BlockHeight: bh,
+		Ancestors:   ancestors,
+	}
+	vmCtx := vm.NewVMContext(vmCtxParams)
+	if _, _, err = vm.Send(ctx, vmCtx); err != nil {
+		return vmCtx.GasUnits(), err
+	}
+
+	fromActor.IncNonce()
+	if err := cachedSt.Commit(ctx); err != nil {
+		return vmCtx.GasUnits(), err
+	}
+	return vmCtx.GasUnits(), nil
+}
+
 // attemptApplyMessage encapsulates the work of trying to apply the message in order
 // to make ApplyMessage more readable. The distinction is that attemptApplyMessage
 // should deal with trying to apply the message to the state tree whereas
 // ApplyMessage should deal with any side effects and how it should be presented
 // to the caller. attemptApplyMessage should only be called from ApplyMessage.
-func (p *DefaultProcessor) attemptApplyMessage(ctx context.Context, st *state.CachedTree, store vm.StorageMap, msg *types.SignedMessage, bh *types.BlockHeight, gasTracker *vm.GasTracker, ancestors []types.TipSet) (*types.MessageReceipt, error) {
+func (p *DefaultProcessor) attemptApplyMessage(ctx context.Context, st *state.CachedTree, store vm.StorageMap, msg *types.SignedMessage, bh *types.BlockHeight, gasTracker *vm.GasTracker, ancestors []types.TipSet, executionTrace *vm.ExecutionTrace) (*types.MessageReceipt, error) {
 	gasTracker.ResetForNewMessage(msg.MeteredMessage)
 	if err := blockGasLimitError(gasTracker); err != nil {
 		return &types.MessageReceipt{
@@ -513,8 +595,10 @@ func (p *DefaultProcessor) attemptApplyMessage(ctx context.Context, st *state.Ca
 		State:       st,
 		StorageMap:  store,
 		GasTracker:  gasTracker,
+		GasSchedule: types.DefaultGasSchedule,
 		BlockHeight: bh,
 		Ancestors:   ancestors,
+		Trace:       executionTrace,
 	}
 	vmCtx := vm.NewVMContext(vmCtxParams)
 
@@ -15,6 +15,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/actor"
 	"github.com/filecoin-project/go-filecoin/actor/builtin"
 	"github.com/filecoin-project/go-filecoin/actor/builtin/account"
+	"github.com/filecoin-project/go-filecoin/actor/builtin/miner"
 	"github.com/filecoin-project/go-filecoin/address"
 	. "github.com/filecoin-project/go-filecoin/consensus"
 	"github.com/filecoin-project/go-filecoin/state"
@@ -64,7 +65,7 @@ func TestProcessBlockSuccess(t *testing.T) {
 		fromAddr:               fromAct,
 	})
 
-	msg := types.NewMessage(fromAddr, toAddr, 0, types.NewAttoFILFromFIL(550), "", nil)
+	msg := types.NewMessage(fromAddr, toAddr, 0, types.NewAttoFILFromFIL(550), types.SendMethodID, nil)
 	smsg, err := types.NewSignedMessage(*msg, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
 
@@ -124,7 +125,7 @@ func TestProcessTipSetSuccess(t *testing.T) {
 	require.NoError(t, err)
 	stCid, miner := mustCreateStorageMiner(ctx, t, st, vms, minerAddr, minerOwner)
 
-	msg1 := types.NewMessage(fromAddr1, toAddr, 0, types.NewAttoFILFromFIL(550), "", nil)
+	msg1 := types.NewMessage(fromAddr1, toAddr, 0, types.NewAttoFILFromFIL(550), types.SendMethodID, nil)
 	smsg1, err := types.NewSignedMessage(*msg1, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
 	msgs1 := []*types.SignedMessage{smsg1}
@@ -137,7 +138,7 @@ func TestProcessTipSetSuccess(t *testing.T) {
 		Tickets:   []types.Ticket{{VRFProof: []byte{0x1}}},
 	}
 
-	msg2 := types.NewMessage(fromAddr2, toAddr, 0, types.NewAttoFILFromFIL(50), "", nil)
+	msg2 := types.NewMessage(fromAddr2, toAddr, 0, types.NewAttoFILFromFIL(50), types.SendMethodID, nil)
 	smsg2, err := types.NewSignedMessage(*msg2, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
 	msgs2 := []*types.SignedMessage{smsg2}
@@ -197,7 +198,7 @@ func TestProcessTipsConflicts(t *testing.T) {
 	require.NoError(t, err)
 	stCid, miner := mustCreateStorageMiner(ctx, t, st, vms, minerAddr, minerOwner)
 
-	msg1 := types.NewMessage(fromAddr, toAddr, 0, types.NewAttoFILFromFIL(501), "", nil)
+	msg1 := types.NewMessage(fromAddr, toAddr, 0, types.NewAttoFILFromFIL(501), types.SendMethodID, nil)
 	smsg1, err := types.NewSignedMessage(*msg1, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
 	msgs1 := []*types.SignedMessage{smsg1}
@@ -208,7 +209,7 @@ func TestProcessTipsConflicts(t *testing.T) {
 		Miner:     minerAddr,
 	}
 
-	msg2 := types.NewMessage(fromAddr, toAddr, 0, types.NewAttoFILFromFIL(502), "", nil)
+	msg2 := types.NewMessage(fromAddr, toAddr, 0, types.NewAttoFILFromFIL(502), types.SendMethodID, nil)
 	smsg2, err := types.NewSignedMessage(*msg2, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
 	msgs2 := []*types.SignedMessage{smsg2}
@@ -265,7 +266,7 @@ func TestProcessBlockBadMsgSig(t *testing.T) {
 	require.NoError(t, err)
 	stCid, _ := mustCreateStorageMiner(ctx, t, st, vms, minerAddr, minerOwner)
 
-	msg := types.NewMessage(fromAddr, toAddr, 0, types.NewAttoFILFromFIL(550), "", nil)
+	msg := types.NewMessage(fromAddr, toAddr, 0, types.NewAttoFILFromFIL(550), types.SendMethodID, nil)
 	smsg, err := types.NewSignedMessage(*msg, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
 	// corrupt the message data
@@ -353,7 +354,7 @@ func TestProcessBlockVMErrors(t *testing.T) {
 
 	stCid, miner := mustCreateStorageMiner(ctx, t, st, vms, minerAddr, minerOwnerAddr)
 
-	msg := types.NewMessage(fromAddr, toAddr, 0, types.ZeroAttoFIL, "returnRevertError", nil)
+	msg := types.NewMessage(fromAddr, toAddr, 0, types.ZeroAttoFIL, actor.FakeMethodReturnRevertError, nil)
 	smsg, err := types.NewSignedMessage(*msg, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
 	msgs := []*types.SignedMessage{smsg}
@@ -410,7 +411,7 @@ func TestProcessBlockParamsLengthError(t *testing.T) {
 	assert.NoError(t, err)
 	badParams, err := abi.EncodeValues(params)
 	assert.NoError(t, err)
-	msg := types.NewMessage(addr1, addr2, 0, types.NewAttoFILFromFIL(550), "getPower", badParams)
+	msg := types.NewMessage(addr1, addr2, 0, types.NewAttoFILFromFIL(550), miner.MethodGetPower, badParams)
 
 	rct, err := th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(0))
 	assert.NoError(t, err) // No error means definitely no fault error, which is what we're especially testing here.
@@ -434,7 +435,7 @@ func TestProcessBlockParamsError(t *testing.T) {
 		addr2: act2,
 	})
 	badParams := []byte{1, 2, 3, 4, 5}
-	msg := types.NewMessage(addr1, addr2, 0, types.NewAttoFILFromFIL(550), "getPower", badParams)
+	msg := types.NewMessage(addr1, addr2, 0, types.NewAttoFILFromFIL(550), miner.MethodGetPower, badParams)
 
 	rct, err := th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(0))
 	assert.NoError(t, err) // No error means definitely no fault error, which is what we're especially testing here.
@@ -461,7 +462,7 @@ func TestApplyMessagesValidation(t *testing.T) {
 			addr1: act1,
 			addr2: act2,
 		})
-		msg := types.NewMessage(addr1, addr2, 5, types.NewAttoFILFromFIL(550), "", []byte{})
+		msg := types.NewMessage(addr1, addr2, 5, types.NewAttoFILFromFIL(550), types.SendMethodID, []byte{})
 		smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 		require.NoError(t, err)
 
@@ -486,7 +487,7 @@ func TestApplyMessagesValidation(t *testing.T) {
 			addr1: act1,
 			addr2: act2,
 		})
-		msg := types.NewMessage(addr1, addr2, 0, types.NewAttoFILFromFIL(550), "", []byte{})
+		msg := types.NewMessage(addr1, addr2, 0, types.NewAttoFILFromFIL(550), types.SendMethodID, []byte{})
 		smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 		require.NoError(t, err)
 
@@ -497,7 +498,7 @@ func TestApplyMessagesValidation(t *testing.T) {
 
 	t.Run("errors when specifying a gas limit in excess of balance", func(t *testing.T) {
 		addr1, _, addr2, _, st, mockSigner := mustSetup2Actors(t, types.NewAttoFILFromFIL(1000), types.NewAttoFILFromFIL(10000))
-		msg := types.NewMessage(addr1, addr2, 0, types.NewAttoFILFromFIL(550), "", []byte{})
+		msg := types.NewMessage(addr1, addr2, 0, types.NewAttoFILFromFIL(550), types.SendMethodID, []byte{})
 		smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewAttoFILFromFIL(10), types.NewGasUnits(50))
 		require.NoError(t, err)
 
@@ -516,7 +517,7 @@ func TestApplyMessagesValidation(t *testing.T) {
 		err := st.SetActor(ctx, addr1, act1)
 		require.NoError(t, err)
 
-		msg := types.NewMessage(addr1, addr2, 0, types.ZeroAttoFIL, "", []byte{})
+		msg := types.NewMessage(addr1, addr2, 0, types.ZeroAttoFIL, types.SendMethodID, []byte{})
 		smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewAttoFILFromFIL(10), types.NewGasUnits(50))
 		require.NoError(t, err)
 
@@ -535,7 +536,7 @@ func TestApplyMessagesValidation(t *testing.T) {
 
 		_, st := requireMakeStateTree(t, cst, map[address.Address]*actor.Actor{addr2: act2})
 
-		msg := types.NewMessage(addr1, addr2, 0, types.ZeroAttoFIL, "", []byte{})
+		msg := types.NewMessage(addr1, addr2, 0, types.ZeroAttoFIL, types.SendMethodID, []byte{})
 		smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewAttoFILFromFIL(10), types.NewGasUnits(50))
 		require.NoError(t, err)
 
@@ -564,7 +565,7 @@ func TestApplyMessagesValidation(t *testing.T) {
 		someval, ok := types.NewAttoFILFromString("-500", 10)
 		require.True(t, ok)
 
-		msg := types.NewMessage(addr1, addr2, 0, someval, "", []byte{})
+		msg := types.NewMessage(addr1, addr2, 0, someval, types.SendMethodID, []byte{})
 		smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 		require.NoError(t, err)
 
@@ -575,7 +576,7 @@ func TestApplyMessagesValidation(t *testing.T) {
 
 	t.Run("errors when attempting to send to self", func(t *testing.T) {
 		addr1, _, addr2, _, st, mockSigner := mustSetup2Actors(t, types.NewAttoFILFromFIL(1000), types.NewAttoFILFromFIL(10000))
-		msg := types.NewMessage(addr1, addr1, 0, types.NewAttoFILFromFIL(550), "", []byte{})
+		msg := types.NewMessage(addr1, addr1, 0, types.NewAttoFILFromFIL(550), types.SendMethodID, []byte{})
 		smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewAttoFILFromFIL(10), types.NewGasUnits(0))
 		require.NoError(t, err)
 
@@ -587,7 +588,7 @@ func TestApplyMessagesValidation(t *testing.T) {
 
 	t.Run("errors when specifying a gas limit in excess of balance", func(t *testing.T) {
 		addr1, _, addr2, _, st, mockSigner := mustSetup2Actors(t, types.NewAttoFILFromFIL(1000), types.NewAttoFILFromFIL(10000))
-		msg := types.NewMessage(addr1, addr2, 0, types.NewAttoFILFromFIL(550), "", []byte{})
+		msg := types.NewMessage(addr1, addr2, 0, types.NewAttoFILFromFIL(550), types.SendMethodID, []byte{})
 		smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewAttoFILFromFIL(10), types.NewGasUnits(50))
 		require.NoError(t, err)
 
@@ -631,7 +632,7 @@ func TestNestedSendBalance(t *testing.T) {
 	// send 100 from addr1 -> addr2, by sending a message from addr0 to addr1
 	params1, err := abi.ToEncodedValues(addr2)
 	assert.NoError(t, err)
-	msg1 := types.NewMessage(addr0, addr1, 0, types.ZeroAttoFIL, "nestedBalance", params1)
+	msg1 := types.NewMessage(addr0, addr1, 0, types.ZeroAttoFIL, actor.FakeMethodNestedBalance, params1)
 
 	_, err = th.ApplyTestMessage(st, th.VMStorage(), msg1, types.NewBlockHeight(0))
 	assert.NoError(t, err)
@@ -681,7 +682,7 @@ func TestReentrantTransferDoesntAllowMultiSpending(t *testing.T) {
 	// addr1 will attempt to double spend to addr2 by sending a reentrant message that spends twice
 	params, err := abi.ToEncodedValues(addr1, addr2)
 	assert.NoError(t, err)
-	msg := types.NewMessage(addr0, addr1, 0, types.ZeroAttoFIL, "attemptMultiSpend1", params)
+	msg := types.NewMessage(addr0, addr1, 0, types.ZeroAttoFIL, actor.FakeMethodAttemptMultiSpend1, params)
 	_, err = th.ApplyTestMessage(st, th.VMStorage(), msg, types.NewBlockHeight(0))
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "second callSendTokens")
@@ -690,7 +691,7 @@ func TestReentrantTransferDoesntAllowMultiSpending(t *testing.T) {
 	// addr1 will attempt to double spend to addr2 by sending a reentrant message that spends and then spending directly
 	params, err = abi.ToEncodedValues(addr1, addr2)
 	assert.NoError(t, err)
-	msg = types.NewMessage(addr0, addr1, 0, types.ZeroAttoFIL, "attemptMultiSpend2", params)
+	msg = types.NewMessage(addr0, addr1, 0, types.ZeroAttoFIL, actor.FakeMethodAttemptMultiSpend2, params)
 	_, err = th.ApplyTestMessage(st, th.VMStorage(), msg, types.NewBlockHeight(0))
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed sendTokens")
@@ -713,14 +714,14 @@ func TestSendToNonexistentAddressThenSpendFromIt(t *testing.T) {
 	})
 
 	// send 500 from addr1 to addr2
-	msg := types.NewMessage(addr1, addr2, 0, types.NewAttoFILFromFIL(500), "", []byte{})
+	msg := types.NewMessage(addr1, addr2, 0, types.NewAttoFILFromFIL(500), types.SendMethodID, []byte{})
 	smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
 	_, err = NewDefaultProcessor().ApplyMessage(ctx, st, th.VMStorage(), smsg, addr4, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
 	require.NoError(t, err)
 
 	// send 250 along from addr2 to addr3
-	msg = types.NewMessage(addr2, addr3, 0, types.NewAttoFILFromFIL(300), "", []byte{})
+	msg = types.NewMessage(addr2, addr3, 0, types.NewAttoFILFromFIL(300), types.SendMethodID, []byte{})
 	smsg, err = types.NewSignedMessage(*msg, mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
 	_, err = NewDefaultProcessor().ApplyMessage(ctx, st, th.VMStorage(), smsg, addr4, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
@@ -801,7 +802,7 @@ func TestApplyMessageChargesGas(t *testing.T) {
 		addr1 := addresses[1]
 		minerAddr := addresses[2]
 
-		msg := types.NewMessage(addr0, addr1, 0, types.ZeroAttoFIL, "hasReturnValue", nil)
+		msg := types.NewMessage(addr0, addr1, 0, types.ZeroAttoFIL, actor.FakeMethodHasReturnValue, nil)
 		gasPrice := types.NewAttoFILFromFIL(uint64(3))
 		gasLimit := types.NewGasUnits(200)
 
@@ -826,7 +827,7 @@ func TestApplyMessageChargesGas(t *testing.T) {
 		addr1 := addresses[1]
 		minerAddr := addresses[2]
 
-		msg := types.NewMessage(addr0, addr1, 0, types.ZeroAttoFIL, "chargeGasAndRevertError", nil)
+		msg := types.NewMessage(addr0, addr1, 0, types.ZeroAttoFIL, actor.FakeMethodChargeGasAndRevertError, nil)
 
 		gasPrice := types.NewAttoFILFromFIL(uint64(3))
 		gasLimit := types.NewGasUnits(200)
@@ -853,7 +854,7 @@ func TestApplyMessageChargesGas(t *testing.T) {
 		addr0 := addresses[0]
 		addr1 := addresses[1]
 		minerAddr := addresses[2]
-		msg := types.NewMessage(addr0, addr1, 0, types.ZeroAttoFIL, "hasReturnValue", nil)
+		msg := types.NewMessage(addr0, addr1, 0, types.ZeroAttoFIL, actor.FakeMethodHasReturnValue, nil)
 
 		gasPrice := types.NewAttoFILFromFIL(uint64(3))
 		gasLimit := types.NewGasUnits(50)
@@ -885,7 +886,7 @@ func TestApplyMessageChargesGas(t *testing.T) {
 		params, err := abi.ToEncodedValues(addr2)
 		assert.NoError(t, err)
 
-		msg := types.NewMessage(addr0, addr1, 0, types.ZeroAttoFIL, "runsAnotherMessage", params)
+		msg := types.NewMessage(addr0, addr1, 0, types.ZeroAttoFIL, actor.FakeMethodRunsAnotherMessage, params)
 
 		gasPrice := types.NewAttoFILFromFIL(uint64(3))
 		gasLimit := types.NewGasUnits(600)
@@ -897,13 +898,13 @@ func TestApplyMessageChargesGas(t *testing.T) {
 		minerActor, err := st.GetActor(ctx, minerAddr)
 		require.NoError(t, err)
 
-		// miner receives (3 FIL/gas * 100 gas * 2 messages)
-		assert.Equal(t, types.NewAttoFILFromFIL(1600), minerActor.Balance)
+		// miner receives (3 FIL/gas * (100 gas * 2 messages + 10 gas for the send))
+		assert.Equal(t, types.NewAttoFILFromFIL(1630), minerActor.Balance)
 
 		accountActor, err := st.GetActor(ctx, addr0)
 		require.NoError(t, err)
 		// sender's resulting balance of FIL
-		assert.Equal(t, types.NewAttoFILFromFIL(1400), accountActor.Balance)
+		assert.Equal(t, types.NewAttoFILFromFIL(1370), accountActor.Balance)
 	})
 
 	t.Run("ApplyMessage when it sends another message with insufficient gas fails with correct message", func(t *testing.T) {
@@ -918,7 +919,7 @@ func TestApplyMessageChargesGas(t *testing.T) {
 		params, err := abi.ToEncodedValues(addr2)
 		assert.NoError(t, err)
 
-		msg := types.NewMessage(addr0, addr1, 0, types.ZeroAttoFIL, "runsAnotherMessage", params)
+		msg := types.NewMessage(addr0, addr1, 0, types.ZeroAttoFIL, actor.FakeMethodRunsAnotherMessage, params)
 
 		gasPrice := types.NewAttoFILFromFIL(uint64(3))
 		gasLimit := types.NewGasUnits(50)
@@ -941,6 +942,57 @@ func TestApplyMessageChargesGas(t *testing.T) {
 	})
 }
 
+func TestApplyMessageRecordsTrace(t *testing.T) {
+	tf.BadUnitTestWithSideEffects(t)
+
+	ctx := context.Background()
+	vms := th.VMStorage()
+
+	fakeActorCodeCid := types.NewCidForTestGetter()()
+	builtin.Actors[fakeActorCodeCid] = &actor.FakeActor{}
+	defer delete(builtin.Actors, fakeActorCodeCid)
+
+	addresses, st, mockSigner := setupActorsForGasTest(t, vms, fakeActorCodeCid, 2000)
+	addr0, addr1, addr2, minerAddr := addresses[0], addresses[1], addresses[2], addresses[3]
+
+	params, err := abi.ToEncodedValues(addr2)
+	require.NoError(t, err)
+	msg := types.NewMessage(addr0, addr1, 0, types.ZeroAttoFIL, actor.FakeMethodRunsAnotherMessage, params)
+	smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewAttoFILFromFIL(1), types.NewGasUnits(600))
+	require.NoError(t, err)
+
+	t.Run("default processor records no trace", func(t *testing.T) {
+		res, err := NewDefaultProcessor().ApplyMessage(ctx, st, vms, smsg, minerAddr, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
+		require.NoError(t, err)
+		assert.Nil(t, res.Trace)
+	})
+
+	t.Run("tracing processor records nested sends", func(t *testing.T) {
+		msg.Nonce = 1
+		smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewAttoFILFromFIL(1), types.NewGasUnits(600))
+		require.NoError(t, err)
+
+		res, err := NewTracingProcessor().ApplyMessage(ctx, st, vms, smsg, minerAddr, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
+		require.NoError(t, err)
+		require.NoError(t, res.ExecutionError)
+		require.NotNil(t, res.Trace)
+
+		assert.Equal(t, addr0, res.Trace.From)
+		assert.Equal(t, addr1, res.Trace.To)
+		assert.Equal(t, actor.FakeMethodRunsAnotherMessage, res.Trace.Method)
+		assert.Equal(t, "runsAnotherMessage", res.Trace.MethodName)
+		assert.Equal(t, types.NewGasUnits(210), res.Trace.GasUsed)
+
+		require.Len(t, res.Trace.Subcalls, 1)
+		inner := res.Trace.Subcalls[0]
+		assert.Equal(t, addr1, inner.From)
+		assert.Equal(t, addr2, inner.To)
+		assert.Equal(t, "hasReturnValue", inner.MethodName)
+		assert.Equal(t, types.NewGasUnits(100), inner.GasUsed)
+		assert.Equal(t, uint8(0), inner.ExitCode)
+	})
+}
+
 func TestBlockGasLimitBehavior(t *testing.T) {
 	tf.BadUnitTestWithSideEffects(t)
 
@@ -955,7 +1007,7 @@ func TestBlockGasLimitBehavior(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("A single message whose gas limit is greater than the block gas limit fails permanently", func(t *testing.T) {
-		msg := types.NewMessage(sender, receiver, 0, types.ZeroAttoFIL, "blockLimitTestMethod", []byte{})
+		msg := types.NewMessage(sender, receiver, 0, types.ZeroAttoFIL, actor.FakeMethodBlockLimitTestMethod, []byte{})
 		sgnedMsg, err := types.NewSignedMessage(*msg, signer, types.ZeroAttoFIL, types.BlockGasLimit*2)
 		require.NoError(t, err)
 
@@ -966,11 +1018,11 @@ func TestBlockGasLimitBehavior(t *testing.T) {
 	})
 
 	t.Run("2 msgs both succeed when sum of limits > block limit, but 1st usage + 2nd limit < block limit", func(t *testing.T) {
-		msg1 := types.NewMessage(sender, receiver, 0, types.ZeroAttoFIL, "blockLimitTestMethod", []byte{})
+		msg1 := types.NewMessage(sender, receiver, 0, types.ZeroAttoFIL, actor.FakeMethodBlockLimitTestMethod, []byte{})
 		sgnedMsg1, err := types.NewSignedMessage(*msg1, signer, types.ZeroAttoFIL, types.BlockGasLimit*5/8)
 		require.NoError(t, err)
 
-		msg2 := types.NewMessage(sender, receiver, 1, types.ZeroAttoFIL, "blockLimitTestMethod", []byte{})
+		msg2 := types.NewMessage(sender, receiver, 1, types.ZeroAttoFIL, actor.FakeMethodBlockLimitTestMethod, []byte{})
 		sgnedMsg2, err := types.NewSignedMessage(*msg2, signer, types.ZeroAttoFIL, types.BlockGasLimit*5/8)
 		require.NoError(t, err)
 
@@ -982,11 +1034,11 @@ func TestBlockGasLimitBehavior(t *testing.T) {
 	})
 
 	t.Run("2nd message delayed when 1st usage + 2nd limit > block limit", func(t *testing.T) {
-		msg1 := types.NewMessage(sender, receiver, 0, types.ZeroAttoFIL, "blockLimitTestMethod", []byte{})
+		msg1 := types.NewMessage(sender, receiver, 0, types.ZeroAttoFIL, actor.FakeMethodBlockLimitTestMethod, []byte{})
 		sgnedMsg1, err := types.NewSignedMessage(*msg1, signer, types.ZeroAttoFIL, types.BlockGasLimit*3/8)
 		require.NoError(t, err)
 
-		msg2 := types.NewMessage(sender, receiver, 1, types.ZeroAttoFIL, "blockLimitTestMethod", []byte{})
+		msg2 := types.NewMessage(sender, receiver, 1, types.ZeroAttoFIL, actor.FakeMethodBlockLimitTestMethod, []byte{})
 		sgnedMsg2, err := types.NewSignedMessage(*msg2, signer, types.ZeroAttoFIL, types.BlockGasLimit*7/8)
 		require.NoError(t, err)
 
@@ -998,15 +1050,15 @@ func TestBlockGasLimitBehavior(t *testing.T) {
 	})
 
 	t.Run("message with high gas limit does not block messages with lower limits from being included in block", func(t *testing.T) {
-		msg1 := types.NewMessage(sender, receiver, 0, types.ZeroAttoFIL, "blockLimitTestMethod", []byte{})
+		msg1 := types.NewMessage(sender, receiver, 0, types.ZeroAttoFIL, actor.FakeMethodBlockLimitTestMethod, []byte{})
 		sgnedMsg1, err := types.NewSignedMessage(*msg1, signer, types.ZeroAttoFIL, types.BlockGasLimit*3/8)
 		require.NoError(t, err)
 
-		msg2 := types.NewMessage(sender, receiver, 1, types.ZeroAttoFIL, "blockLimitTestMethod", []byte{})
+		msg2 := types.NewMessage(sender, receiver, 1, types.ZeroAttoFIL, actor.FakeMethodBlockLimitTestMethod, []byte{})
 		sgnedMsg2, err := types.NewSignedMessage(*msg2, signer, types.ZeroAttoFIL, types.BlockGasLimit*7/8)
 		require.NoError(t, err)
 
-		msg3 := types.NewMessage(sender, receiver, 2, types.ZeroAttoFIL, "blockLimitTestMethod", []byte{})
+		msg3 := types.NewMessage(sender, receiver, 2, types.ZeroAttoFIL, actor.FakeMethodBlockLimitTestMethod, []byte{})
 		sgnedMsg3, err := types.NewSignedMessage(*msg3, signer, types.ZeroAttoFIL, types.BlockGasLimit*3/8)
 		require.NoError(t, err)
 
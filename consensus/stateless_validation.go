@@ -0,0 +1,90 @@
+package consensus
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+// StatelessValidator validates the parts of a tipset that do not depend on
+// the state it is applied to: the syntax of its blocks, the signatures of its
+// messages and the shape of its ticket arrays. These checks can run for many
+// tipsets concurrently, ahead of their state transitions.
+type StatelessValidator struct {
+	syntax BlockSyntaxValidator
+}
+
+// NewStatelessValidator returns a StatelessValidator checking block syntax
+// with bv.
+func NewStatelessValidator(bv BlockSyntaxValidator) *StatelessValidator {
+	return &StatelessValidator{syntax: bv}
+}
+
+// ValidateTipSet validates ts, whose parent tipset is parent and whose blocks
+// contain tsMessages. It returns an error if any block is syntactically
+// invalid, contains a message not validly signed by its sender, has a ticket
+// array whose length differs from its increase in height, or has tickets that
+// are not notarized or not signed by a single key over their predecessor.
+// Checking that the tickets are signed by the miner's worker key requires the
+// parent state and is left to the state transition. Errors of invalid blocks
+// satisfy IsInvalidTipSet.
+func (sv *StatelessValidator) ValidateTipSet(ctx context.Context, ts, parent types.TipSet, tsMessages [][]*types.SignedMessage) error {
+	if len(tsMessages) != ts.Len() {
+		return errors.Errorf("expected messages of %d blocks, got %d", ts.Len(), len(tsMessages))
+	}
+	prevTicket, err := parent.MinTicket()
+	if err != nil {
+		return errors.Wrap(err, "failed to read parent min ticket")
+	}
+	prevHeight, err := parent.Height()
+	if err != nil {
+		return errors.Wrap(err, "failed to read parent height")
+	}
+
+	for i := 0; i < ts.Len(); i++ {
+		blk := ts.At(i)
+		if err := sv.syntax.ValidateSyntax(ctx, blk); err != nil {
+			return NewInvalidTipSetError(err)
+		}
+		for _, msg := range tsMessages[i] {
+			if !msg.VerifySignature() {
+				c, _ := msg.Cid()
+				return NewInvalidTipSetError(errors.Errorf("invalid signature of message %s in block %s", c, blk.Cid()))
+			}
+		}
+		if err := validateTickets(blk, prevTicket, prevHeight); err != nil {
+			return NewInvalidTipSetError(err)
+		}
+	}
+	return nil
+}
+
+// validateTickets checks the tickets of blk extend prevTicket, the min ticket
+// of its parent at prevHeight.
+func validateTickets(blk *types.Block, prevTicket types.Ticket, prevHeight uint64) error {
+	if uint64(blk.Height) <= prevHeight {
+		return errors.Errorf("block %s has invalid height %d", blk.Cid(), blk.Height)
+	}
+	// Block has same number of tickets as increase in height
+	if uint64(len(blk.Tickets)) != uint64(blk.Height)-prevHeight {
+		return errors.Errorf("invalid ticket array length. Expected: %d, actual %d", uint64(blk.Height)-prevHeight, len(blk.Tickets))
+	}
+
+	var signer address.Address
+	prev := prevTicket
+	for i, ticket := range blk.Tickets {
+		addr, err := ticketSigner(prev, ticket)
+		if err != nil {
+			return errors.Wrapf(err, "invalid ticket: %s in position %d in block %s", ticket.String(), i, blk.Cid())
+		}
+		if i > 0 && addr != signer {
+			return errors.Errorf("ticket %s in position %d in block %s has a different signer", ticket.String(), i, blk.Cid())
+		}
+		signer = addr
+		prev = ticket
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package consensus_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/consensus"
+	th "github.com/filecoin-project/go-filecoin/testhelpers"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+func TestStatelessValidator(t *testing.T) {
+	tf.UnitTest(t)
+
+	ts := time.Unix(1234567890, 0)
+	ctx := context.Background()
+	validator := consensus.NewStatelessValidator(consensus.NewDefaultBlockValidator(consensus.DefaultBlockTime, th.NewFakeSystemClock(ts)))
+
+	signer, _ := types.NewMockSignersAndKeyInfo(2)
+	worker := signer.Addresses[0]
+	tm := consensus.TicketMachine{}
+	nextTicket := func(parent types.Ticket, addr address.Address) types.Ticket {
+		ticket, err := tm.NextTicket(parent, addr, signer)
+		require.NoError(t, err)
+		require.NoError(t, tm.NotarizeTime(&ticket))
+		return ticket
+	}
+
+	parentTicket := consensus.MakeFakeTicketForTest()
+	parent := consensus.RequireNewTipSet(require.New(t), &types.Block{
+		Height:    1,
+		Timestamp: types.Uint64(ts.Unix()),
+		Tickets:   []types.Ticket{parentTicket},
+	})
+	newTipSet := func(height uint64, tickets ...types.Ticket) types.TipSet {
+		return consensus.RequireNewTipSet(require.New(t), &types.Block{
+			Height:    types.Uint64(height),
+			Timestamp: types.Uint64(ts.Unix()),
+			StateRoot: types.NewCidForTestGetter()(),
+			Miner:     address.NewForTestGetter()(),
+			Tickets:   tickets,
+		})
+	}
+
+	t1 := nextTicket(parentTicket, worker)
+	t2 := nextTicket(t1, worker)
+	msg := types.NewSignedMessageForTestGetter(signer)()
+
+	t.Run("accepts valid tipsets", func(t *testing.T) {
+		assert.NoError(t, validator.ValidateTipSet(ctx, newTipSet(2, t1), parent, [][]*types.SignedMessage{{msg}}))
+		// One ticket per null round.
+		assert.NoError(t, validator.ValidateTipSet(ctx, newTipSet(3, t1, t2), parent, [][]*types.SignedMessage{{}}))
+	})
+
+	t.Run("rejects invalid block syntax", func(t *testing.T) {
+		blk := newTipSet(2, t1).At(0)
+		blk.Miner = address.Undef
+		err := validator.ValidateTipSet(ctx, consensus.RequireNewTipSet(require.New(t), blk), parent, [][]*types.SignedMessage{{}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "nil miner address")
+	})
+
+	t.Run("rejects invalid message signatures", func(t *testing.T) {
+		forged := *msg
+		forged.Nonce++
+		err := validator.ValidateTipSet(ctx, newTipSet(2, t1), parent, [][]*types.SignedMessage{{&forged}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid signature of message")
+		assert.True(t, consensus.IsInvalidTipSet(err))
+	})
+
+	t.Run("rejects invalid tickets", func(t *testing.T) {
+		err := validator.ValidateTipSet(ctx, newTipSet(3, t1), parent, [][]*types.SignedMessage{{}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid ticket array length")
+
+		unnotarized := t1
+		unnotarized.VDFResult = types.VDFY([]byte{1})
+		err = validator.ValidateTipSet(ctx, newTipSet(2, unnotarized), parent, [][]*types.SignedMessage{{}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not notarized")
+
+		otherSigner := nextTicket(t1, signer.Addresses[1])
+		err = validator.ValidateTipSet(ctx, newTipSet(3, t1, otherSigner), parent, [][]*types.SignedMessage{{}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "different signer")
+	})
+}
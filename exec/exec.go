@@ -35,15 +35,29 @@ var Errors = map[uint8]error{
 	ErrStaleHead:       errors.NewCodedRevertError(ErrStaleHead, "Expected head is stale"),
 }
 
-// Exports describe the public methods of an actor.
-type Exports map[string]*FunctionSignature
+// Exports describe the public methods of an actor, keyed by their method id.
+type Exports map[types.MethodID]*FunctionSignature
 
 // Has checks if the given method is an exported method.
-func (e Exports) Has(method string) bool {
+func (e Exports) Has(method types.MethodID) bool {
 	_, ok := e[method]
 	return ok
 }
 
+// Lookup finds an exported method by its name and returns its id and signature.
+// The empty name refers to a plain value transfer, which has no signature.
+func (e Exports) Lookup(name string) (types.MethodID, *FunctionSignature, bool) {
+	if name == "" {
+		return types.SendMethodID, nil, true
+	}
+	for id, signature := range e {
+		if signature.Name == name {
+			return id, signature, true
+		}
+	}
+	return 0, nil, false
+}
+
 // TODO fritz require actors to define their exit codes and associate
 // an error string with them.
 
@@ -59,6 +73,9 @@ type ExportedFunc func(ctx VMContext) ([]byte, uint8, error)
 // FunctionSignature describes the signature of a single function.
 // TODO: convert signatures into non go types, but rather low level agreed up types
 type FunctionSignature struct {
+	// Name is the name of the method. Messages address methods by id, the
+	// name is kept for tooling and to find the method's implementation.
+	Name string
 	// Params is a list of the types of the parameters the function expects.
 	Params []abi.Type
 	// Return is the type of the return value of the function.
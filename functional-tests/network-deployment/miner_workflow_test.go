@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	minerActor "github.com/filecoin-project/go-filecoin/actor/builtin/miner"
 	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/protocol/storage/storagedeal"
 	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
@@ -161,7 +162,7 @@ func TestMinerWorkflow(t *testing.T) {
 		//
 
 		minfo, err := series.WaitForChainMessage(ctx, miner, func(ctx context.Context, node *fast.Filecoin, msg *types.SignedMessage) (bool, error) {
-			if msg.Method == "submitPoSt" && msg.To == ask.Miner {
+			if msg.Method == minerActor.MethodSubmitPoSt && msg.To == ask.Miner {
 				return true, nil
 			}
 
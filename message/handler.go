@@ -3,49 +3,45 @@ package message
 import (
 	"context"
 
-	"github.com/pkg/errors"
-
 	"github.com/filecoin-project/go-filecoin/chain"
 	"github.com/filecoin-project/go-filecoin/types"
 )
 
-// HeadHandler wires up new head tipset handling to the message inbox and outbox.
+// HeadHandler wires up head change handling to the message inbox and outbox.
 type HeadHandler struct {
 	// Inbox and outbox exported for testing.
 	Inbox  *Inbox
 	Outbox *Outbox
-	chain  chainProvider
-
-	prevHead types.TipSet
 }
 
-// NewHeadHandler build a new new-head handler.
-func NewHeadHandler(inbox *Inbox, outbox *Outbox, chain chainProvider, head types.TipSet) *HeadHandler {
-	return &HeadHandler{inbox, outbox, chain, head}
+// NewHeadHandler build a new head change handler.
+func NewHeadHandler(inbox *Inbox, outbox *Outbox) *HeadHandler {
+	return &HeadHandler{inbox, outbox}
 }
 
-// HandleNewHead computes the chain delta implied by a new head and updates the inbox and outbox.
-func (h *HeadHandler) HandleNewHead(ctx context.Context, newHead types.TipSet) error {
-	if !newHead.Defined() {
-		log.Warning("received empty tipset, ignoring")
-		return nil
+// HandleHeadChange updates the inbox and outbox with the tipsets reverted and
+// applied by a change of the head, as published by the chain store.
+func (h *HeadHandler) HandleHeadChange(ctx context.Context, changes []chain.HeadChange) error {
+	// The inbox and outbox expect both lists ordered by decreasing height.
+	var oldTips, newTips []types.TipSet
+	for _, change := range changes {
+		switch change.Type {
+		case chain.HCRevert:
+			oldTips = append(oldTips, change.TipSet)
+		case chain.HCApply:
+			newTips = append([]types.TipSet{change.TipSet}, newTips...)
+		}
 	}
-	if newHead.Equals(h.prevHead) {
-		log.Warningf("received non-new head tipset, ignoring %s", newHead.Key())
+	if len(oldTips) == 0 && len(newTips) == 0 {
+		log.Warning("received head change without tipsets, ignoring")
 		return nil
 	}
 
-	oldTips, newTips, err := chain.CollectTipsToCommonAncestor(ctx, h.chain, h.prevHead, newHead)
-	if err != nil {
-		return errors.Errorf("traversing chain with new head %s, prev %s: %s", newHead.Key(), h.prevHead.Key(), err)
-	}
 	if err := h.Outbox.HandleNewHead(ctx, oldTips, newTips); err != nil {
-		log.Errorf("updating outbound message queue for tipset %s, prev %s: %s", newHead.Key(), h.prevHead.Key(), err)
+		log.Errorf("updating outbound message queue for head change: %s", err)
 	}
 	if err := h.Inbox.HandleNewHead(ctx, oldTips, newTips); err != nil {
-		log.Errorf("updating message pool for tipset %s, prev %s: %s", newHead.Key(), h.prevHead.Key(), err)
+		log.Errorf("updating message pool for head change: %s", err)
 	}
-
-	h.prevHead = newHead
 	return nil
 }
@@ -31,7 +31,7 @@ func TestNewHeadHandlerIntegration(t *testing.T) {
 	gasPrice := types.NewGasPrice(1)
 	gasUnits := types.NewGasUnits(1000)
 
-	makeHandler := func(provider *message.FakeProvider, root types.TipSet) *message.HeadHandler {
+	makeHandler := func(provider *message.FakeProvider) *message.HeadHandler {
 		mpool := message.NewPool(config.NewDefaultConfig().Mpool, th.NewMockMessagePoolValidator())
 		inbox := message.NewInbox(mpool, maxAge, provider, provider)
 		queue := message.NewQueue()
@@ -39,7 +39,12 @@ func TestNewHeadHandlerIntegration(t *testing.T) {
 		policy := message.NewMessageQueuePolicy(provider, maxAge)
 		outbox := message.NewOutbox(signer, &message.FakeValidator{}, queue, publisher, policy, provider, provider)
 
-		return message.NewHeadHandler(inbox, outbox, provider, root)
+		return message.NewHeadHandler(inbox, outbox)
+	}
+	headChanges := func(provider *message.FakeProvider, oldHead, newHead types.TipSet) []chain.HeadChange {
+		changes, err := chain.HeadChanges(ctx, provider, oldHead, newHead)
+		require.NoError(t, err)
+		return changes
 	}
 
 	t.Run("test send after reverted message", func(t *testing.T) {
@@ -49,7 +54,7 @@ func TestNewHeadHandlerIntegration(t *testing.T) {
 		actr.Nonce = 42
 		provider.SetHeadAndActor(t, root.Key(), sender, actr)
 
-		handler := makeHandler(provider, root)
+		handler := makeHandler(provider)
 		outbox := handler.Outbox
 		inbox := handler.Inbox
 
@@ -65,7 +70,7 @@ func TestNewHeadHandlerIntegration(t *testing.T) {
 		left := provider.BuildOneOn(root, func(b *chain.BlockBuilder) {
 			b.AddMessages([]*types.SignedMessage{msg1}, types.EmptyReceipts(1))
 		})
-		require.NoError(t, handler.HandleNewHead(ctx, left))
+		require.NoError(t, handler.HandleHeadChange(ctx, headChanges(provider, root, left)))
 		assert.Equal(t, 0, len(outbox.Queue().List(sender))) // Gone from queue.
 		_, found = inbox.Pool().Get(mid1)
 		assert.False(t, found) // Gone from pool.
@@ -74,7 +79,7 @@ func TestNewHeadHandlerIntegration(t *testing.T) {
 		right := provider.BuildOneOn(root, func(b *chain.BlockBuilder) {
 			// No messages.
 		})
-		require.NoError(t, handler.HandleNewHead(ctx, right))
+		require.NoError(t, handler.HandleHeadChange(ctx, headChanges(provider, left, right)))
 		assert.Equal(t, 1, len(outbox.Queue().List(sender))) // Message returns to queue.
 		_, found = inbox.Pool().Get(mid1)
 		assert.True(t, found) // Message returns to pool to be mined again.
@@ -99,23 +104,23 @@ func TestNewHeadHandlerIntegration(t *testing.T) {
 		assert.True(t, msg2.Equals(restoredQueue[1].Msg))
 	})
 
-	t.Run("ignores empty tipset", func(t *testing.T) {
+	t.Run("ignores empty change", func(t *testing.T) {
 		provider := message.NewFakeProvider(t)
 		root := provider.NewGenesis()
 		provider.SetHead(root.Key())
 
-		handler := makeHandler(provider, root)
-		err := handler.HandleNewHead(ctx, types.UndefTipSet)
+		handler := makeHandler(provider)
+		err := handler.HandleHeadChange(ctx, nil)
 		assert.NoError(t, err)
 	})
 
-	t.Run("ignores duplicate tipset", func(t *testing.T) {
+	t.Run("ignores unchanged head", func(t *testing.T) {
 		provider := message.NewFakeProvider(t)
 		root := provider.NewGenesis()
 		provider.SetHead(root.Key())
 
-		handler := makeHandler(provider, root)
-		err := handler.HandleNewHead(ctx, root)
+		handler := makeHandler(provider)
+		err := handler.HandleHeadChange(ctx, headChanges(provider, root, root))
 		assert.NoError(t, err)
 	})
 }
@@ -376,10 +376,10 @@ func requireAdd(t *testing.T, ib *message.Inbox, msgs ...*types.SignedMessage) {
 
 func msgAsString(msg *types.SignedMessage) string {
 	// When using NewMessageForTestGetter msg.Method is set
-	// to "msgN" so we print that (it will correspond
-	// to a variable of the same name in the tests
+	// to N+1 for the Nth message so we print "msgN" (it will
+	// correspond to a variable of the same name in the tests
 	// below).
-	return msg.Message.Method
+	return fmt.Sprintf("msg%d", msg.Message.Method-1)
 }
 
 func msgsAsString(msgs []*types.SignedMessage) string {
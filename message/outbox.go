@@ -9,6 +9,7 @@ import (
 
 	"github.com/filecoin-project/go-filecoin/abi"
 	"github.com/filecoin-project/go-filecoin/actor"
+	"github.com/filecoin-project/go-filecoin/actor/builtin"
 	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/consensus"
 	"github.com/filecoin-project/go-filecoin/metrics"
@@ -101,7 +102,12 @@ func (ob *Outbox) Send(ctx context.Context, from, to address.Address, value type
 		return cid.Undef, errors.Wrapf(err, "failed calculating nonce for actor at %s", from)
 	}
 
-	rawMsg := types.NewMessage(from, to, nonce, value, method, encodedParams)
+	methodID, err := ob.lookupMethod(ctx, head, to, method)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	rawMsg := types.NewMessage(from, to, nonce, value, methodID, encodedParams)
 	signed, err := types.NewSignedMessage(*rawMsg, ob.signer, gasPrice, gasLimit)
 	if err != nil {
 		return cid.Undef, errors.Wrap(err, "failed to sign message")
@@ -130,6 +136,174 @@ func (ob *Outbox) Send(ctx context.Context, from, to address.Address, value type
 	return signed.Cid()
 }
 
+// Replace replaces the message with cid msgCid in the outbound message queue with a copy
+// paying gasPrice per unit of gas, and publishes the replacement. The message pool accepts
+// the replacement only if it pays sufficiently more than the message it replaces.
+// If bcast is true, the publisher broadcasts the replacement to the network.
+func (ob *Outbox) Replace(ctx context.Context, msgCid cid.Cid, gasPrice types.AttoFIL, bcast bool) (out cid.Cid, err error) {
+	defer func() {
+		if err != nil {
+			msgSendErrCt.Inc(ctx, 1)
+		}
+	}()
+
+	// Lock to avoid a race with Send appending to the queue.
+	ob.nonceLock.Lock()
+	defer ob.nonceLock.Unlock()
+
+	queued, err := ob.findQueued(msgCid)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	head := ob.chains.GetHead()
+
+	fromActor, err := ob.actors.GetActorAt(ctx, head, queued.Msg.From)
+	if err != nil {
+		return cid.Undef, errors.Wrapf(err, "no actor at address %s", queued.Msg.From)
+	}
+
+	signed, err := types.NewSignedMessage(queued.Msg.Message, ob.signer, gasPrice, queued.Msg.GasLimit)
+	if err != nil {
+		return cid.Undef, errors.Wrap(err, "failed to sign message")
+	}
+
+	err = ob.validator.Validate(ctx, signed, fromActor)
+	if err != nil {
+		return cid.Undef, errors.Wrap(err, "invalid message")
+	}
+
+	height, err := tipsetHeight(ob.chains, head)
+	if err != nil {
+		return cid.Undef, errors.Wrap(err, "failed to get block height")
+	}
+
+	if _, found := ob.queue.Replace(ctx, signed, height); !found {
+		return cid.Undef, errors.Errorf("message %s is no longer in the outbound queue", msgCid)
+	}
+	err = ob.publisher.Publish(ctx, signed, height, bcast)
+	if err != nil {
+		// Restore the message the rejected replacement was to replace.
+		ob.queue.Replace(ctx, queued.Msg, queued.Stamp)
+		return cid.Undef, err
+	}
+
+	return signed.Cid()
+}
+
+// findQueued returns the message with cid msgCid in the outbound message queue.
+func (ob *Outbox) findQueued(msgCid cid.Cid) (*Queued, error) {
+	for _, sender := range ob.queue.Queues() {
+		for _, qm := range ob.queue.List(sender) {
+			c, err := qm.Msg.Cid()
+			if err != nil {
+				return nil, err
+			}
+			if c.Equals(msgCid) {
+				return qm, nil
+			}
+		}
+	}
+	return nil, errors.Errorf("message %s not found in outbound queue", msgCid)
+}
+
+// lookupMethod resolves the name of a method exported by the actor at addr to
+// the id the message addresses it by.
+func (ob *Outbox) lookupMethod(ctx context.Context, head types.TipSetKey, addr address.Address, method string) (types.MethodID, error) {
+	if method == "" {
+		return types.SendMethodID, nil
+	}
+
+	toActor, err := ob.actors.GetActorAt(ctx, head, addr)
+	if err != nil {
+		return 0, errors.Wrapf(err, "no actor at address %s", addr)
+	}
+
+	executable, ok := builtin.Actors[toActor.Code]
+	if !ok {
+		return 0, errors.Errorf("no builtin code for actor at address %s", addr)
+	}
+
+	id, _, ok := executable.Exports().Lookup(method)
+	if !ok {
+		return 0, errors.Errorf("actor at address %s does not export method %s", addr, method)
+	}
+	return id, nil
+}
+
+// Reconcile brings the outbound message queue, as loaded after the node restarted, up to date with
+// the head and adds the messages that are still pending, stamped with the head height, to the
+// message pool. It does not broadcast them, see Rebroadcast.
+// Messages with nonces below their sender's nonce at the head have been mined and are removed.
+// The messages of a sender whose first queued nonce is above its nonce at the head, or that is not
+// an account at the head, can never be mined and are cleared.
+func (ob *Outbox) Reconcile(ctx context.Context) error {
+	// Lock to avoid a race with Send appending to the queue.
+	ob.nonceLock.Lock()
+	defer ob.nonceLock.Unlock()
+
+	head := ob.chains.GetHead()
+	height, err := tipsetHeight(ob.chains, head)
+	if err != nil {
+		return errors.Wrap(err, "failed to get block height")
+	}
+
+	for _, sender := range ob.queue.Queues() {
+		fromActor, err := ob.actors.GetActorAt(ctx, head, sender)
+		if err != nil {
+			log.Warningf("Clearing outbound queue of %s, no actor at head: %s", sender, err)
+			ob.queue.Clear(ctx, sender)
+			continue
+		}
+		actorNonce, err := actor.NextNonce(fromActor)
+		if err != nil {
+			log.Warningf("Clearing outbound queue of %s: %s", sender, err)
+			ob.queue.Clear(ctx, sender)
+			continue
+		}
+
+		for _, qm := range ob.queue.List(sender) {
+			if uint64(qm.Msg.Nonce) >= actorNonce {
+				break
+			}
+			if _, _, err := ob.queue.RemoveNext(ctx, sender, uint64(qm.Msg.Nonce)); err != nil {
+				return errors.Wrapf(err, "failed to remove mined message from outbound queue of %s", sender)
+			}
+		}
+
+		pending := ob.queue.List(sender)
+		if len(pending) > 0 && uint64(pending[0].Msg.Nonce) > actorNonce {
+			log.Warningf("Clearing outbound queue of %s, next queued nonce %d is above actor nonce %d", sender, pending[0].Msg.Nonce, actorNonce)
+			ob.queue.Clear(ctx, sender)
+			continue
+		}
+		for _, qm := range pending {
+			// Restamp the message so it does not expire before it has a chance to be mined.
+			ob.queue.Replace(ctx, qm.Msg, height)
+			if err := ob.publisher.Publish(ctx, qm.Msg, height, false); err != nil {
+				log.Warningf("Failed to publish queued message from %s with nonce %d: %s", sender, qm.Msg.Nonce, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Rebroadcast publishes all messages in the outbound message queue to the network, so that
+// messages queued while the node had no peers, or before it restarted, reach the network.
+func (ob *Outbox) Rebroadcast(ctx context.Context) {
+	// Lock to avoid a race with Send and Replace publishing messages.
+	ob.nonceLock.Lock()
+	defer ob.nonceLock.Unlock()
+
+	for _, sender := range ob.queue.Queues() {
+		for _, qm := range ob.queue.List(sender) {
+			if err := ob.publisher.Publish(ctx, qm.Msg, qm.Stamp, true); err != nil {
+				log.Warningf("Failed to rebroadcast queued message from %s with nonce %d: %s", sender, qm.Msg.Nonce, err)
+			}
+		}
+	}
+}
+
 // HandleNewHead maintains the message queue in response to a new head tipset.
 func (ob *Outbox) HandleNewHead(ctx context.Context, oldTips, newTips []types.TipSet) error {
 	return ob.policy.HandleNewHead(ctx, ob.queue, oldTips, newTips)
@@ -2,10 +2,10 @@ package message_test
 
 import (
 	"context"
-	"fmt"
 	"sync"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -95,11 +95,10 @@ func TestOutbox(t *testing.T) {
 		s := message.NewOutbox(w, message.FakeValidator{}, queue, publisher, message.NullPolicy{}, provider, provider)
 
 		var wg sync.WaitGroup
-		addTwentyMessages := func(batch int) {
+		addTwentyMessages := func() {
 			defer wg.Done()
 			for i := 0; i < msgCount; i++ {
-				method := fmt.Sprintf("%d-%d", batch, i)
-				_, err := s.Send(ctx, sender, toAddr, types.ZeroAttoFIL, types.NewGasPrice(0), types.NewGasUnits(0), bcast, method, []byte{})
+				_, err := s.Send(ctx, sender, toAddr, types.ZeroAttoFIL, types.NewGasPrice(0), types.NewGasUnits(0), bcast, "", []byte{})
 				require.NoError(t, err)
 			}
 		}
@@ -107,7 +106,7 @@ func TestOutbox(t *testing.T) {
 		// Add messages concurrently.
 		for i := 0; i < sendConcurrent; i++ {
 			wg.Add(1)
-			go addTwentyMessages(i)
+			go addTwentyMessages()
 		}
 		wg.Wait()
 
@@ -129,6 +128,127 @@ func TestOutbox(t *testing.T) {
 		}
 	})
 
+	t.Run("replace re-signs and publishes a queued message", func(t *testing.T) {
+		ctx := context.Background()
+		w, _ := types.NewMockSignersAndKeyInfo(1)
+		sender := w.Addresses[0]
+		toAddr := address.NewForTestGetter()()
+		queue := message.NewQueue()
+		publisher := &message.MockPublisher{}
+		provider := message.NewFakeProvider(t)
+
+		head := provider.BuildOneOn(types.UndefTipSet, func(b *chain.BlockBuilder) {
+			b.IncHeight(1000)
+		})
+		actr, _ := account.NewActor(types.ZeroAttoFIL)
+		provider.SetHeadAndActor(t, head.Key(), sender, actr)
+
+		ob := message.NewOutbox(w, message.FakeValidator{}, queue, publisher, message.NullPolicy{}, provider, provider)
+		c, err := ob.Send(ctx, sender, toAddr, types.ZeroAttoFIL, types.NewGasPrice(1), types.NewGasUnits(300), true, "")
+		require.NoError(t, err)
+		original := publisher.Message
+
+		replacement, err := ob.Replace(ctx, c, types.NewGasPrice(2), true)
+		require.NoError(t, err)
+		assert.NotEqual(t, c, replacement)
+		assert.Equal(t, original.Nonce, publisher.Message.Nonce)
+		assert.Equal(t, original.GasLimit, publisher.Message.GasLimit)
+		assert.Equal(t, types.NewGasPrice(2), publisher.Message.GasPrice)
+		assert.True(t, publisher.Message.VerifySignature())
+
+		queued := queue.List(sender)
+		require.Len(t, queued, 1)
+		assert.Equal(t, publisher.Message, queued[0].Msg)
+
+		// The original is no longer queued.
+		_, err = ob.Replace(ctx, c, types.NewGasPrice(3), true)
+		assert.Error(t, err)
+
+		// A replacement the publisher rejects leaves the queued message in place.
+		publisher.ReturnError = errors.New("rejected")
+		_, err = ob.Replace(ctx, replacement, types.NewGasPrice(3), true)
+		assert.Error(t, err)
+		queued = queue.List(sender)
+		require.Len(t, queued, 1)
+		assert.Equal(t, types.NewGasPrice(2), queued[0].Msg.GasPrice)
+	})
+
+	t.Run("reconcile removes mined messages and pools pending ones", func(t *testing.T) {
+		ctx := context.Background()
+		w, _ := types.NewMockSignersAndKeyInfo(3)
+		sender := w.Addresses[0]
+		unknownSender := w.Addresses[1]
+		toAddr := w.Addresses[2]
+		queue := message.NewQueue()
+		publisher := &message.MockPublisher{}
+		provider := message.NewFakeProvider(t)
+
+		head := provider.BuildOneOn(types.UndefTipSet, func(b *chain.BlockBuilder) {
+			b.IncHeight(1000)
+		})
+		actr, _ := account.NewActor(types.ZeroAttoFIL)
+		actr.Nonce = 42
+		provider.SetHeadAndActor(t, head.Key(), sender, actr)
+
+		enqueue := func(from address.Address, nonce uint64) *types.SignedMessage {
+			msg := types.NewMessage(from, toAddr, nonce, types.ZeroAttoFIL, types.SendMethodID, nil)
+			smsg, err := types.NewSignedMessage(*msg, w, types.NewGasPrice(1), types.NewGasUnits(300))
+			require.NoError(t, err)
+			require.NoError(t, queue.Enqueue(ctx, smsg, 5))
+			return smsg
+		}
+		for nonce := uint64(40); nonce < 44; nonce++ {
+			enqueue(sender, nonce)
+		}
+		last := enqueue(sender, 44)
+		// The provider has no actor for unknownSender at the head.
+		enqueue(unknownSender, 3)
+
+		ob := message.NewOutbox(w, message.FakeValidator{}, queue, publisher, message.NullPolicy{}, provider, provider)
+		require.NoError(t, ob.Reconcile(ctx))
+
+		queued := queue.List(sender)
+		require.Len(t, queued, 3)
+		for i, qm := range queued {
+			assert.Equal(t, types.Uint64(42+i), qm.Msg.Nonce)
+			assert.Equal(t, uint64(1000), qm.Stamp)
+		}
+		assert.Empty(t, queue.List(unknownSender))
+		assert.Equal(t, last, publisher.Message)
+		assert.Equal(t, uint64(1000), publisher.Height)
+		assert.False(t, publisher.Bcast)
+
+		// Messages following a nonce gap can never be mined.
+		actr.Nonce = 41
+		queue.Clear(ctx, sender)
+		enqueue(sender, 42)
+		require.NoError(t, ob.Reconcile(ctx))
+		assert.Empty(t, queue.List(sender))
+	})
+
+	t.Run("rebroadcast broadcasts queued messages", func(t *testing.T) {
+		ctx := context.Background()
+		w, _ := types.NewMockSignersAndKeyInfo(2)
+		sender := w.Addresses[0]
+		toAddr := w.Addresses[1]
+		queue := message.NewQueue()
+		publisher := &message.MockPublisher{}
+		provider := message.NewFakeProvider(t)
+
+		msg := types.NewMessage(sender, toAddr, 1, types.ZeroAttoFIL, types.SendMethodID, nil)
+		smsg, err := types.NewSignedMessage(*msg, w, types.NewGasPrice(1), types.NewGasUnits(300))
+		require.NoError(t, err)
+		require.NoError(t, queue.Enqueue(ctx, smsg, 5))
+
+		ob := message.NewOutbox(w, message.FakeValidator{}, queue, publisher, message.NullPolicy{}, provider, provider)
+		ob.Rebroadcast(ctx)
+
+		assert.Equal(t, smsg, publisher.Message)
+		assert.Equal(t, uint64(5), publisher.Height)
+		assert.True(t, publisher.Bcast)
+		assert.Len(t, queue.List(sender), 1)
+	})
+
 	t.Run("fails with non-account actor", func(t *testing.T) {
 		w, _ := types.NewMockSignersAndKeyInfo(1)
 		sender := w.Addresses[0]
@@ -1,7 +1,10 @@
 package message
 
 import (
+	"container/heap"
 	"context"
+	"math/big"
+	"sort"
 	"sync"
 
 	"github.com/ipfs/go-cid"
@@ -25,6 +28,12 @@ type PoolValidator interface {
 // exists is a nop. We use a Pool to store all messages received by this node
 // via network or directly created via user command that have yet to be included
 // in a block. Messages are removed as they are processed.
+// A message with the same actor and nonce as a pending message replaces it if
+// its gas price is higher by at least the configured ReplaceByFeePercent.
+// When the pool is full a message evicts the pending message with the lowest
+// gas price among the messages with the largest nonce of each actor, if it
+// pays more, so that no actor is left with a nonce gap. The number of pending
+// messages from a single actor is capped.
 //
 // Pool is safe for concurrent access.
 type Pool struct {
@@ -32,13 +41,17 @@ type Pool struct {
 
 	cfg           *config.MessagePoolConfig
 	validator     PoolValidator
-	pending       map[cid.Cid]*timedmessage // all pending messages
-	addressNonces map[addressNonce]bool     // set of address nonce pairs used to efficiently validate duplicate nonces
+	pending       map[cid.Cid]*timedmessage           // all pending messages
+	addressNonces map[addressNonce]cid.Cid            // cids of pending messages by address nonce pair, used to efficiently validate duplicate nonces
+	bySender      map[address.Address][]*timedmessage // pending messages by sender, in nonce order
+	tails         evictionQueue                       // pending message with the largest nonce of each sender, lowest priority first
 }
 
 type timedmessage struct {
 	message *types.SignedMessage
+	cid     cid.Cid
 	addedAt uint64
+	index   int // position in the pool's eviction queue, if the message is its sender's last
 }
 
 type addressNonce struct {
@@ -56,12 +69,15 @@ func NewPool(cfg *config.MessagePoolConfig, validator PoolValidator) *Pool {
 		cfg:           cfg,
 		validator:     validator,
 		pending:       make(map[cid.Cid]*timedmessage),
-		addressNonces: make(map[addressNonce]bool),
+		addressNonces: make(map[addressNonce]cid.Cid),
+		bySender:      make(map[address.Address][]*timedmessage),
 	}
 }
 
 // Add adds a message to the pool, tagged with the block height at which it was received.
-// Does nothing if the message is already in the pool.
+// Does nothing if the message is already in the pool. If the message replaces
+// a pending message with the same actor and nonce, or the pool is full and the
+// message evicts a pending message with a lower gas price, that message is removed.
 func (pool *Pool) Add(ctx context.Context, msg *types.SignedMessage, height uint64) (cid.Cid, error) {
 	pool.lk.Lock()
 	defer pool.lk.Unlock()
@@ -77,12 +93,31 @@ func (pool *Pool) Add(ctx context.Context, msg *types.SignedMessage, height uint
 		return c, nil
 	}
 
-	if err = pool.validateMessage(ctx, msg); err != nil {
+	displaced, err := pool.validateMessage(ctx, msg)
+	if err != nil {
 		return cid.Undef, errors.Wrap(err, "validation error adding message to pool")
 	}
+	if displaced.Defined() {
+		pool.remove(displaced)
+	}
 
-	pool.pending[c] = &timedmessage{message: msg, addedAt: height}
-	pool.addressNonces[newAddressNonce(msg)] = true
+	tm := &timedmessage{message: msg, cid: c, addedAt: height}
+	pool.pending[c] = tm
+	pool.addressNonces[newAddressNonce(msg)] = c
+
+	// Insert the message in nonce order and track it if it is the sender's last.
+	msgs := pool.bySender[msg.From]
+	i := sort.Search(len(msgs), func(j int) bool { return msgs[j].message.Nonce > msg.Nonce })
+	msgs = append(msgs, nil)
+	copy(msgs[i+1:], msgs[i:])
+	msgs[i] = tm
+	pool.bySender[msg.From] = msgs
+	if i == len(msgs)-1 {
+		if i > 0 {
+			heap.Remove(&pool.tails, msgs[i-1].index)
+		}
+		heap.Push(&pool.tails, tm)
+	}
 	mpSize.Set(ctx, int64(len(pool.pending)))
 	return c, nil
 }
@@ -117,14 +152,42 @@ func (pool *Pool) Remove(c cid.Cid) {
 	pool.lk.Lock()
 	defer pool.lk.Unlock()
 
-	msg, ok := pool.pending[c]
-	if ok {
-		delete(pool.addressNonces, newAddressNonce(msg.message))
-		delete(pool.pending, c)
-	}
+	pool.remove(c)
 	mpSize.Set(context.TODO(), int64(len(pool.pending)))
 }
 
+// remove removes the message by CID from the pending pool. The caller must hold the lock.
+func (pool *Pool) remove(c cid.Cid) {
+	tm, ok := pool.pending[c]
+	if !ok {
+		return
+	}
+	delete(pool.pending, c)
+	an := newAddressNonce(tm.message)
+	if pool.addressNonces[an].Equals(c) {
+		delete(pool.addressNonces, an)
+	}
+
+	msgs := pool.bySender[tm.message.From]
+	i := sort.Search(len(msgs), func(j int) bool { return msgs[j].message.Nonce >= tm.message.Nonce })
+	for msgs[i] != tm {
+		i++
+	}
+	last := i == len(msgs)-1
+	msgs = append(msgs[:i], msgs[i+1:]...)
+	if last {
+		heap.Remove(&pool.tails, tm.index)
+		if len(msgs) > 0 {
+			heap.Push(&pool.tails, msgs[len(msgs)-1])
+		}
+	}
+	if len(msgs) == 0 {
+		delete(pool.bySender, tm.message.From)
+	} else {
+		pool.bySender[tm.message.From] = msgs
+	}
+}
+
 // LargestNonce returns the largest nonce used by a message from address in the pool.
 // If no messages from address are found, found will be false.
 func (pool *Pool) LargestNonce(address address.Address) (largest uint64, found bool) {
@@ -139,6 +202,68 @@ func (pool *Pool) LargestNonce(address address.Address) (largest uint64, found b
 	return
 }
 
+// PoolStats summarizes the messages pending in a pool.
+type PoolStats struct {
+	Size      int
+	Senders   []SenderStats
+	GasPrices []GasPriceBucket
+}
+
+// SenderStats is the number of messages pending from a sender.
+type SenderStats struct {
+	Address address.Address
+	Count   uint
+}
+
+// GasPriceBucket is the number of pending messages with a gas price of at least Min and less
+// than ten times Min. The bucket with a zero Min counts messages with a zero gas price.
+type GasPriceBucket struct {
+	Min   types.AttoFIL
+	Count uint
+}
+
+// Stats returns the number of pending messages by sender, in address order, and a histogram
+// of their gas prices, in increasing gas price order.
+func (pool *Pool) Stats() *PoolStats {
+	pool.lk.RLock()
+	defer pool.lk.RUnlock()
+
+	stats := &PoolStats{Size: len(pool.pending)}
+	for addr, msgs := range pool.bySender {
+		stats.Senders = append(stats.Senders, SenderStats{Address: addr, Count: uint(len(msgs))})
+	}
+	sort.Slice(stats.Senders, func(i, j int) bool {
+		return stats.Senders[i].Address.String() < stats.Senders[j].Address.String()
+	})
+
+	buckets := make(map[string]*GasPriceBucket)
+	for _, tm := range pool.pending {
+		low := gasPriceBucketMin(tm.message.GasPrice)
+		bucket, ok := buckets[low.String()]
+		if !ok {
+			bucket = &GasPriceBucket{Min: low}
+			buckets[low.String()] = bucket
+		}
+		bucket.Count++
+	}
+	for _, bucket := range buckets {
+		stats.GasPrices = append(stats.GasPrices, *bucket)
+	}
+	sort.Slice(stats.GasPrices, func(i, j int) bool {
+		return stats.GasPrices[i].Min.LessThan(stats.GasPrices[j].Min)
+	})
+	return stats
+}
+
+// gasPriceBucketMin returns the largest power of ten not greater than price, or zero if price is zero.
+func gasPriceBucketMin(price types.AttoFIL) types.AttoFIL {
+	if !price.IsPositive() {
+		return types.ZeroAttoFIL
+	}
+	digits := len(price.AsBigInt().String())
+	return types.NewAttoFIL(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits-1)), nil))
+}
+
 // PendingBefore returns the CIDs of messages added with height less than `minimumHeight`.
 func (pool *Pool) PendingBefore(minimumHeight uint64) []cid.Cid {
 	pool.lk.RLock()
@@ -154,18 +279,83 @@ func (pool *Pool) PendingBefore(minimumHeight uint64) []cid.Cid {
 }
 
 // validateMessage validates that too many messages aren't added to the pool and the ones that are
-// have a high probability of making it through processing.
-func (pool *Pool) validateMessage(ctx context.Context, message *types.SignedMessage) error {
-	if uint(len(pool.pending)) >= pool.cfg.MaxPoolSize {
-		return errors.Errorf("message pool is full (%d messages)", pool.cfg.MaxPoolSize)
-	}
-
-	// check that message with this nonce does not already exist
-	_, found := pool.addressNonces[newAddressNonce(message)]
+// have a high probability of making it through processing. If the message replaces a pending
+// message with the same actor and nonce, or evicts the lowest priority message of those with the
+// largest nonce of their sender from a full pool, it returns the cid of the message it displaces.
+func (pool *Pool) validateMessage(ctx context.Context, message *types.SignedMessage) (cid.Cid, error) {
+	// check that a message with this nonce does not already exist, unless this one pays enough to replace it
+	displaced, found := pool.addressNonces[newAddressNonce(message)]
 	if found {
-		return errors.Errorf("message pool contains message with same actor and nonce but different cid")
+		minPrice := pool.minReplacementGasPrice(pool.pending[displaced].message.GasPrice)
+		if message.GasPrice.LessThan(minPrice) {
+			return cid.Undef, errors.Errorf("message pool contains message with same actor and nonce but different cid, a replacement must have a gas price of at least %s", minPrice)
+		}
+	} else {
+		if uint(len(pool.bySender[message.From])) >= pool.cfg.MaxPendingPerSender {
+			return cid.Undef, errors.Errorf("message pool contains too many messages from %s (%d messages)", message.From, pool.cfg.MaxPendingPerSender)
+		}
+		if uint(len(pool.pending)) >= pool.cfg.MaxPoolSize {
+			if len(pool.tails) == 0 || !message.GasPrice.GreaterThan(pool.tails[0].message.GasPrice) {
+				return cid.Undef, errors.Errorf("message pool is full (%d messages) and the message does not pay more than the lowest gas price pending", pool.cfg.MaxPoolSize)
+			}
+			evicted := pool.tails[0].message
+			if evicted.From == message.From && evicted.Nonce < message.Nonce {
+				return cid.Undef, errors.Errorf("message pool is full (%d messages) and the message would evict a message with a lower nonce from %s", pool.cfg.MaxPoolSize, message.From)
+			}
+			displaced = pool.tails[0].cid
+		}
 	}
 
 	// check that the message is likely to succeed in processing
-	return pool.validator.Validate(ctx, message)
+	if err := pool.validator.Validate(ctx, message); err != nil {
+		return cid.Undef, err
+	}
+	return displaced, nil
+}
+
+// minReplacementGasPrice returns the lowest gas price of a message replacing a pending
+// message with gas price price: price increased by ReplaceByFeePercent, and by at least
+// one attoFIL.
+func (pool *Pool) minReplacementGasPrice(price types.AttoFIL) types.AttoFIL {
+	minPrice := price.MulBigInt(big.NewInt(int64(100 + pool.cfg.ReplaceByFeePercent))).DivCeil(types.NewAttoFIL(big.NewInt(100)))
+	if minPrice.LessEqual(price) {
+		return price.Add(types.NewAttoFIL(big.NewInt(1)))
+	}
+	return minPrice
+}
+
+// evictionQueue is a heap of pending messages ordered by priority, lowest first. Messages pay
+// for priority with their gas price. Among messages with the same gas price, those with larger
+// nonces have lower priority.
+type evictionQueue []*timedmessage
+
+func (eq evictionQueue) Len() int { return len(eq) }
+
+func (eq evictionQueue) Less(i, j int) bool {
+	pi, pj := eq[i].message.GasPrice, eq[j].message.GasPrice
+	if !pi.Equal(pj) {
+		return pi.LessThan(pj)
+	}
+	return eq[i].message.Nonce > eq[j].message.Nonce
+}
+
+func (eq evictionQueue) Swap(i, j int) {
+	eq[i], eq[j] = eq[j], eq[i]
+	eq[i].index = i
+	eq[j].index = j
+}
+
+func (eq *evictionQueue) Push(x interface{}) {
+	tm := x.(*timedmessage)
+	tm.index = len(*eq)
+	*eq = append(*eq, tm)
+}
+
+func (eq *evictionQueue) Pop() interface{} {
+	old := *eq
+	n := len(old)
+	tm := old[n-1]
+	old[n-1] = nil
+	*eq = old[:n-1]
+	return tm
 }
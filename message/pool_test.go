@@ -66,6 +66,8 @@ func TestMessagePoolValidate(t *testing.T) {
 		// pull the default size from the default config value
 		mpoolCfg := config.NewDefaultConfig().Mpool
 		maxMessagePoolSize := mpoolCfg.MaxPoolSize
+		// allow the single sender of the messages to fill the pool
+		mpoolCfg.MaxPendingPerSender = maxMessagePoolSize
 		ctx := context.Background()
 		pool := message.NewPool(mpoolCfg, th.NewMockMessagePoolValidator())
 
@@ -99,6 +101,136 @@ func TestMessagePoolValidate(t *testing.T) {
 		assert.Contains(t, err.Error(), "message with same actor and nonce")
 	})
 
+	t.Run("replaces a message with same nonce and a sufficiently higher gas price", func(t *testing.T) {
+		ctx := context.Background()
+		mpoolCfg := config.NewDefaultConfig().Mpool
+		mpoolCfg.MaxPoolSize = 1
+		pool := message.NewPool(mpoolCfg, th.NewMockMessagePoolValidator())
+
+		withGasPrice := func(msg *types.SignedMessage, price int64) *types.SignedMessage {
+			smsg, err := types.NewSignedMessage(msg.Message, mockSigner, types.NewGasPrice(price), msg.GasLimit)
+			require.NoError(t, err)
+			return smsg
+		}
+
+		smsg1 := withGasPrice(newSignedMessage(), 100)
+		c1, err := pool.Add(ctx, smsg1, 0)
+		require.NoError(t, err)
+
+		// The default config requires a 10% increase.
+		_, err = pool.Add(ctx, withGasPrice(smsg1, 109), 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "gas price of at least "+types.NewGasPrice(110).String())
+
+		smsg2 := withGasPrice(smsg1, 110)
+		c2, err := pool.Add(ctx, smsg2, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []*types.SignedMessage{smsg2}, pool.Pending())
+		_, found := pool.Get(c1)
+		assert.False(t, found)
+
+		// Removing the replaced message does not affect its replacement.
+		pool.Remove(c1)
+		m, found := pool.Get(c2)
+		assert.True(t, found)
+		assert.Equal(t, smsg2, m)
+
+		// Without a required percentage the gas price must still increase.
+		mpoolCfg.ReplaceByFeePercent = 0
+		samePrice := smsg2.Message
+		samePrice.Value = types.NewAttoFILFromFIL(1)
+		smsg3, err := types.NewSignedMessage(samePrice, mockSigner, smsg2.GasPrice, smsg2.GasLimit)
+		require.NoError(t, err)
+		_, err = pool.Add(ctx, smsg3, 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "gas price of at least")
+		_, err = pool.Add(ctx, withGasPrice(smsg1, 111), 0)
+		assert.NoError(t, err)
+	})
+
+	t.Run("full message pool evicts the message with the lowest gas price", func(t *testing.T) {
+		ctx := context.Background()
+		mpoolCfg := config.NewDefaultConfig().Mpool
+		mpoolCfg.MaxPoolSize = 3
+		pool := message.NewPool(mpoolCfg, th.NewMockMessagePoolValidator())
+
+		alice, bob := mockSigner.Addresses[0], mockSigner.Addresses[1]
+		a0 := newMessageWithGasPrice(t, alice, 0, 2)
+		a1 := newMessageWithGasPrice(t, alice, 1, 2)
+		b0 := newMessageWithGasPrice(t, bob, 0, 3)
+		reqAdd(t, pool, 0, a0, a1, b0)
+
+		// Messages not paying more than the lowest gas price are rejected.
+		_, err := pool.Add(ctx, newMessageWithGasPrice(t, bob, 1, 2), 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "message pool is full")
+
+		// Of alice's messages with the lowest gas price, the one with the largest nonce is evicted.
+		b1 := newMessageWithGasPrice(t, bob, 1, 4)
+		reqAdd(t, pool, 0, b1)
+		assert.ElementsMatch(t, []*types.SignedMessage{a0, b0, b1}, pool.Pending())
+
+		b2 := newMessageWithGasPrice(t, bob, 2, 5)
+		reqAdd(t, pool, 0, b2)
+		assert.ElementsMatch(t, []*types.SignedMessage{b0, b1, b2}, pool.Pending())
+	})
+
+	t.Run("full message pool only evicts the last message of a sender", func(t *testing.T) {
+		ctx := context.Background()
+		mpoolCfg := config.NewDefaultConfig().Mpool
+		mpoolCfg.MaxPoolSize = 3
+		pool := message.NewPool(mpoolCfg, th.NewMockMessagePoolValidator())
+
+		alice, bob, carol := mockSigner.Addresses[0], mockSigner.Addresses[1], mockSigner.Addresses[2]
+		a0 := newMessageWithGasPrice(t, alice, 0, 1)
+		a1 := newMessageWithGasPrice(t, alice, 1, 5)
+		b0 := newMessageWithGasPrice(t, bob, 0, 3)
+		reqAdd(t, pool, 0, a0, a1, b0)
+
+		// Evicting a0 would leave a1 with a nonce gap.
+		c0 := newMessageWithGasPrice(t, carol, 0, 4)
+		reqAdd(t, pool, 0, c0)
+		assert.ElementsMatch(t, []*types.SignedMessage{a0, a1, c0}, pool.Pending())
+
+		// A message does not evict a message with a lower nonce from its sender.
+		_, err := pool.Add(ctx, newMessageWithGasPrice(t, carol, 1, 6), 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "lower nonce")
+
+		// Once a1 is removed, a0 is alice's last message and the first evicted.
+		a1Cid, err := a1.Cid()
+		require.NoError(t, err)
+		pool.Remove(a1Cid)
+		b0 = newMessageWithGasPrice(t, bob, 0, 2)
+		d0 := newMessageWithGasPrice(t, mockSigner.Addresses[3], 0, 3)
+		reqAdd(t, pool, 0, b0, d0)
+		assert.ElementsMatch(t, []*types.SignedMessage{b0, c0, d0}, pool.Pending())
+	})
+
+	t.Run("caps the number of pending messages from a sender", func(t *testing.T) {
+		ctx := context.Background()
+		mpoolCfg := config.NewDefaultConfig().Mpool
+		mpoolCfg.MaxPendingPerSender = 2
+		pool := message.NewPool(mpoolCfg, th.NewMockMessagePoolValidator())
+
+		alice, bob := mockSigner.Addresses[0], mockSigner.Addresses[1]
+		a0 := newMessageWithGasPrice(t, alice, 0, 1)
+		reqAdd(t, pool, 0, a0, newMessageWithGasPrice(t, alice, 1, 1))
+
+		_, err := pool.Add(ctx, newMessageWithGasPrice(t, alice, 2, 1), 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "too many messages from")
+
+		// Other senders and replacements are not affected.
+		reqAdd(t, pool, 0, newMessageWithGasPrice(t, bob, 0, 1), newMessageWithGasPrice(t, alice, 1, 2))
+
+		// Removing a message makes room for another.
+		c0, err := a0.Cid()
+		require.NoError(t, err)
+		pool.Remove(c0)
+		reqAdd(t, pool, 0, newMessageWithGasPrice(t, alice, 2, 1))
+	})
+
 	t.Run("validates using supplied validator", func(t *testing.T) {
 		ctx := context.Background()
 		validator := th.NewMockMessagePoolValidator()
@@ -158,6 +290,36 @@ func TestMessagePoolAsync(t *testing.T) {
 	assert.Len(t, pool.Pending(), int(count))
 }
 
+func TestMessagePoolStats(t *testing.T) {
+	tf.UnitTest(t)
+
+	pool := message.NewPool(config.NewDefaultConfig().Mpool, th.NewMockMessagePoolValidator())
+	alice, bob := mockSigner.Addresses[0], mockSigner.Addresses[1]
+
+	stats := pool.Stats()
+	assert.Equal(t, 0, stats.Size)
+	assert.Empty(t, stats.Senders)
+	assert.Empty(t, stats.GasPrices)
+
+	reqAdd(t, pool, 0,
+		newMessageWithGasPrice(t, alice, 0, 0),
+		newMessageWithGasPrice(t, alice, 1, 1),
+		newMessageWithGasPrice(t, alice, 2, 9),
+		newMessageWithGasPrice(t, bob, 0, 10),
+		newMessageWithGasPrice(t, bob, 1, 250),
+	)
+
+	stats = pool.Stats()
+	assert.Equal(t, 5, stats.Size)
+	assert.ElementsMatch(t, []message.SenderStats{{Address: alice, Count: 3}, {Address: bob, Count: 2}}, stats.Senders)
+	assert.Equal(t, []message.GasPriceBucket{
+		{Min: types.ZeroAttoFIL, Count: 1},
+		{Min: types.NewGasPrice(1), Count: 2},
+		{Min: types.NewGasPrice(10), Count: 1},
+		{Min: types.NewGasPrice(100), Count: 1},
+	}, stats.GasPrices)
+}
+
 func TestLargestNonce(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -206,6 +368,13 @@ func TestLargestNonce(t *testing.T) {
 	})
 }
 
+func newMessageWithGasPrice(t *testing.T, from address.Address, nonce uint64, gasPrice int64) *types.SignedMessage {
+	msg := types.NewMessage(from, address.NewForTestGetter()(), nonce, types.ZeroAttoFIL, types.SendMethodID, nil)
+	smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewGasPrice(gasPrice), types.NewGasUnits(0))
+	require.NoError(t, err)
+	return smsg
+}
+
 func mustSetNonce(signer types.Signer, message *types.SignedMessage, nonce types.Uint64) *types.SignedMessage {
 	return mustResignMessage(signer, message, func(m *types.Message) {
 		m.Nonce = nonce
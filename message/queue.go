@@ -4,13 +4,24 @@ import (
 	"context"
 	"sync"
 
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	cbor "github.com/ipfs/go-ipld-cbor"
 	"github.com/pkg/errors"
 
 	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/metrics"
+	"github.com/filecoin-project/go-filecoin/repo"
 	"github.com/filecoin-project/go-filecoin/types"
 )
 
+func init() {
+	cbor.RegisterCborType(Queued{})
+}
+
+// queuePrefix is the datastore prefix of persisted message queues.
+const queuePrefix = "/message/queue"
+
 var (
 	mqSizeGa   = metrics.NewInt64Gauge("message_queue_size", "The size of the message queue")
 	mqOldestGa = metrics.NewInt64Gauge("message_queue_oldest", "The age of the oldest message in the queue or zero when empty")
@@ -23,9 +34,12 @@ var (
 // not enforced.
 // A message queue is intended to record outbound messages that have been transmitted but not yet appeared in a block,
 // where the stamp could be block height.
+// A queue with a datastore persists its messages so they survive restarts.
 // Queue is safe for concurrent access.
 type Queue struct {
 	lk sync.RWMutex
+	// Persists the message queues, if not nil
+	ds repo.Datastore
 	// Message queues keyed by sending actor address, in nonce order
 	queues map[address.Address][]*Queued
 }
@@ -43,6 +57,34 @@ func NewQueue() *Queue {
 	}
 }
 
+// NewPersistentQueue constructs a queue persisting its messages in ds, loaded with the messages
+// previously persisted there.
+func NewPersistentQueue(ds repo.Datastore) (*Queue, error) {
+	mq := &Queue{
+		ds:     ds,
+		queues: make(map[address.Address][]*Queued),
+	}
+
+	results, err := ds.Query(query.Query{Prefix: queuePrefix + "/"})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query message queues")
+	}
+	entries, err := results.Rest()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query message queues")
+	}
+	for _, entry := range entries {
+		var q []*Queued
+		if err := cbor.DecodeInto(entry.Value, &q); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode message queue %s", entry.Key)
+		}
+		if len(q) > 0 {
+			mq.queues[q[0].Msg.From] = q
+		}
+	}
+	return mq, nil
+}
+
 // Enqueue appends a new message for an address. If the queue already contains any messages for
 // from same address, the new message's nonce must be exactly one greater than the largest nonce
 // present.
@@ -62,7 +104,11 @@ func (mq *Queue) Enqueue(ctx context.Context, msg *types.SignedMessage, stamp ui
 			return errors.Errorf("Invalid nonce in %d in enqueue, expected %d", msg.Nonce, nextNonce)
 		}
 	}
-	mq.queues[msg.From] = append(q, &Queued{msg, stamp})
+	q = append(q, &Queued{msg, stamp})
+	if err := mq.persist(msg.From, q); err != nil {
+		return err
+	}
+	mq.queues[msg.From] = q
 	return nil
 }
 
@@ -84,10 +130,37 @@ func (mq *Queue) Requeue(ctx context.Context, msg *types.SignedMessage, stamp ui
 			return errors.Errorf("Invalid nonce %d in requeue, expected %d", msg.Nonce, prevNonce)
 		}
 	}
-	mq.queues[msg.From] = append([]*Queued{{msg, stamp}}, q...)
+	q = append([]*Queued{{msg, stamp}}, q...)
+	if err := mq.persist(msg.From, q); err != nil {
+		return err
+	}
+	mq.queues[msg.From] = q
 	return nil
 }
 
+// Replace replaces the message in the queue with the same sender and nonce as msg, giving it a new
+// stamp. It returns the replaced message, with found = true. Returns found = false if the queue
+// holds no message from msg's sender with its nonce.
+func (mq *Queue) Replace(ctx context.Context, msg *types.SignedMessage, stamp uint64) (replaced *types.SignedMessage, found bool) {
+	defer func() {
+		mqOldestGa.Set(ctx, int64(mq.Oldest()))
+	}()
+
+	mq.lk.Lock()
+	defer mq.lk.Unlock()
+
+	for _, qm := range mq.queues[msg.From] {
+		if qm.Msg.Nonce == msg.Nonce {
+			replaced = qm.Msg
+			qm.Msg = msg
+			qm.Stamp = stamp
+			mq.persistOrWarn(msg.From)
+			return replaced, true
+		}
+	}
+	return nil, false
+}
+
 // RemoveNext removes and returns a single message from the queue, if it bears the expected nonce value, with found = true.
 // Returns found = false if the queue is empty or the expected nonce is less than any in the queue for that address
 // (indicating the message had already been removed).
@@ -106,6 +179,9 @@ func (mq *Queue) RemoveNext(ctx context.Context, sender address.Address, expecte
 	if len(q) > 0 {
 		head := q[0]
 		if expectedNonce == uint64(head.Msg.Nonce) {
+			if err = mq.persist(sender, q[1:]); err != nil {
+				return
+			}
 			mq.queues[sender] = q[1:] // pop the head
 			msg = head.Msg
 			found = true
@@ -130,6 +206,7 @@ func (mq *Queue) Clear(ctx context.Context, sender address.Address) bool {
 
 	q := mq.queues[sender]
 	delete(mq.queues, sender)
+	mq.persistOrWarn(sender)
 	return len(q) > 0
 }
 
@@ -156,6 +233,7 @@ func (mq *Queue) ExpireBefore(ctx context.Context, stamp uint64) map[address.Add
 			}
 
 			mq.queues[sender] = []*Queued{}
+			mq.persistOrWarn(sender)
 		}
 	}
 	return expired
@@ -235,3 +313,35 @@ func (mq *Queue) List(sender address.Address) []*Queued {
 	}
 	return out
 }
+
+// persist stores q as the message queue of sender in the datastore, if the queue has one.
+// The caller must hold the lock.
+func (mq *Queue) persist(sender address.Address, q []*Queued) error {
+	if mq.ds == nil {
+		return nil
+	}
+	key := datastore.NewKey(queuePrefix).ChildString(sender.String())
+	if len(q) == 0 {
+		if err := mq.ds.Delete(key); err != nil && err != datastore.ErrNotFound {
+			return errors.Wrapf(err, "failed to persist message queue of %s", sender)
+		}
+		return nil
+	}
+	val, err := cbor.DumpObject(q)
+	if err != nil {
+		return err
+	}
+	if err := mq.ds.Put(key, val); err != nil {
+		return errors.Wrapf(err, "failed to persist message queue of %s", sender)
+	}
+	return nil
+}
+
+// persistOrWarn persists the message queue of sender, for the methods that cannot return an
+// error. A queue that fails to persist is reloaded stale after a restart, and reconciled with
+// the chain then. The caller must hold the lock.
+func (mq *Queue) persistOrWarn(sender address.Address) {
+	if err := mq.persist(sender, mq.queues[sender]); err != nil {
+		log.Warning(err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/message"
+	"github.com/filecoin-project/go-filecoin/repo"
 	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
 	"github.com/filecoin-project/go-filecoin/types"
 )
@@ -141,6 +142,34 @@ func TestMessageQueue(t *testing.T) {
 		requireRequeue(q, mm.NewSignedMessage(alice, 3), 0)
 	})
 
+	t.Run("replace", func(t *testing.T) {
+		msgs := []*types.SignedMessage{
+			mm.NewSignedMessage(alice, 0),
+			mm.NewSignedMessage(alice, 1),
+		}
+		q := message.NewQueue()
+		requireEnqueue(q, msgs[0], 0)
+		requireEnqueue(q, msgs[1], 0)
+
+		// Can't replace a message not in the queue
+		_, found := q.Replace(ctx, mm.NewSignedMessage(alice, 2), 1)
+		assert.False(t, found)
+		_, found = q.Replace(ctx, mm.NewSignedMessage(bob, 0), 1)
+		assert.False(t, found)
+
+		replacement := mm.NewSignedMessage(alice, 1)
+		replaced, found := q.Replace(ctx, replacement, 1)
+		assert.True(t, found)
+		assert.Equal(t, msgs[1], replaced)
+
+		qd := q.List(alice)
+		require.Len(t, qd, 2)
+		assert.Equal(t, msgs[0], qd[0].Msg)
+		assert.Equal(t, replacement, qd[1].Msg)
+		assert.Equal(t, uint64(1), qd[1].Stamp)
+		assertLargestNonce(q, alice, 1)
+	})
+
 	t.Run("invalid nonce sequence", func(t *testing.T) {
 		msgs := []*types.SignedMessage{
 			mm.NewSignedMessage(alice, 0),
@@ -351,4 +380,49 @@ func TestMessageQueue(t *testing.T) {
 		assert.Equal(t, uint64(1), q.Oldest())
 
 	})
+
+	t.Run("persists messages", func(t *testing.T) {
+		fromAlice := []*types.SignedMessage{
+			mm.NewSignedMessage(alice, 1),
+			mm.NewSignedMessage(alice, 2),
+			mm.NewSignedMessage(alice, 3),
+		}
+		fromBob := []*types.SignedMessage{
+			mm.NewSignedMessage(bob, 5),
+		}
+
+		assertReloaded := func(ds repo.Datastore, sender address.Address, expected []*types.SignedMessage, stamps ...uint64) {
+			q, err := message.NewPersistentQueue(ds)
+			require.NoError(t, err)
+			reloaded := q.List(sender)
+			require.Len(t, reloaded, len(expected))
+			for i, qm := range reloaded {
+				assert.True(t, expected[i].Equals(qm.Msg))
+				assert.Equal(t, stamps[i], qm.Stamp)
+			}
+		}
+
+		ds := repo.NewInMemoryRepo().Datastore()
+		q, err := message.NewPersistentQueue(ds)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), q.Size())
+
+		requireEnqueue(q, fromAlice[1], 10)
+		requireEnqueue(q, fromAlice[2], 11)
+		requireRequeue(q, fromAlice[0], 9)
+		requireEnqueue(q, fromBob[0], 20)
+		assertReloaded(ds, alice, fromAlice, 9, 10, 11)
+		assertReloaded(ds, bob, fromBob, 20)
+
+		requireRemoveNext(q, alice, 1)
+		_, found := q.Replace(ctx, fromAlice[1], 12)
+		require.True(t, found)
+		assertReloaded(ds, alice, fromAlice[1:], 12, 11)
+
+		q.Clear(ctx, alice)
+		assertReloaded(ds, alice, nil)
+
+		q.ExpireBefore(ctx, 21)
+		assertReloaded(ds, bob, nil)
+	})
 }
@@ -53,8 +53,7 @@ func (w *DefaultWorker) Generate(ctx context.Context,
 	}
 
 	pending := w.messageSource.Pending()
-	mq := NewMessageQueue(pending)
-	messages := mq.Drain()
+	messages := w.packer.Pack(pending, types.BlockGasLimit)
 
 	vms := vm.NewStorageMap(w.blockstore)
 	res, err := w.processor.ApplyMessagesAndPayRewards(ctx, stateTree, vms, messages, w.minerOwnerAddr, types.NewBlockHeight(blockHeight), ancestors)
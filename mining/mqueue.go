@@ -14,10 +14,11 @@ import (
 // always in increasing nonce order.
 // All messages for a queue are inserted at construction, after which messages may only
 // be popped.
-// Potential improvements include:
-// - deprioritising messages after a gap in nonce value, which can never be mined (see Ethereum)
-// - attempting to pack messages into a fixed gas limit (i.e. 0/1 knapsack subject to nonce ordering),
-//   see https://en.wikipedia.org/wiki/Knapsack_problem
+//
+// A potential improvement is deprioritising messages after a gap in nonce value, which can
+// never be mined (see Ethereum).
+//
+// MessageQueue does not pack messages into a fixed gas limit; ChainPacker does that.
 type MessageQueue struct {
 	// A heap of nonce-ordered queues, one per sender.
 	senderQueues queueHeap
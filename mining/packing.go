@@ -0,0 +1,160 @@
+package mining
+
+import (
+	"bytes"
+	"container/heap"
+	"math/big"
+	"sort"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+// PackingStrategy selects the messages a miner includes in a block.
+type PackingStrategy interface {
+	// Pack returns messages from pending, in the order they are to be applied, whose gas limits
+	// sum to at most gasLimit. Messages from a single actor are returned in increasing nonce order.
+	Pack(pending []*types.SignedMessage, gasLimit types.GasUnits) []*types.SignedMessage
+}
+
+// GasPricePacker packs messages in MessageQueue order, by decreasing gas price of each actor's
+// next message, until the first message that does not fit in the block.
+type GasPricePacker struct{}
+
+var _ PackingStrategy = GasPricePacker{}
+
+// Pack implements PackingStrategy.
+func (GasPricePacker) Pack(pending []*types.SignedMessage, gasLimit types.GasUnits) []*types.SignedMessage {
+	mq := NewMessageQueue(pending)
+	out := []*types.SignedMessage{}
+	var used types.GasUnits
+	for msg, ok := mq.Pop(); ok; msg, ok = mq.Pop() {
+		if used+msg.GasLimit > gasLimit {
+			break
+		}
+		used += msg.GasLimit
+		out = append(out, msg)
+	}
+	return out
+}
+
+// ChainPacker packs messages to maximize the gas reward of a block: the sum over its messages
+// of gas price times gas limit, the most a message can pay the miner.
+// Since an actor's messages can only be mined in nonce order, it selects chains of messages,
+// consecutive in nonce from the lowest pending nonce of an actor. Repeatedly, it takes the chain
+// with the highest reward per unit of gas among the chains that fit in the remaining block gas,
+// then considers the chains following it. This is the greedy approximation to the knapsack
+// problem, see https://en.wikipedia.org/wiki/Knapsack_problem.
+type ChainPacker struct{}
+
+var _ PackingStrategy = ChainPacker{}
+
+// Pack implements PackingStrategy.
+func (ChainPacker) Pack(pending []*types.SignedMessage, gasLimit types.GasUnits) []*types.SignedMessage {
+	// Group messages by sender, in nonce order, up to the first nonce gap.
+	bySender := make(map[address.Address][]*types.SignedMessage)
+	for _, m := range pending {
+		bySender[m.From] = append(bySender[m.From], m)
+	}
+	chains := chainHeap{}
+	for _, msgs := range bySender {
+		sort.Slice(msgs, func(i, j int) bool { return msgs[i].Nonce < msgs[j].Nonce })
+		end := 1
+		for end < len(msgs) && msgs[end].Nonce == msgs[end-1].Nonce+1 {
+			end++
+		}
+		if c, ok := bestChain(msgs[:end], gasLimit); ok {
+			chains = append(chains, c)
+		}
+	}
+	heap.Init(&chains)
+
+	out := []*types.SignedMessage{}
+	remaining := gasLimit
+	for len(chains) > 0 {
+		c := heap.Pop(&chains).(*msgChain)
+		if c.gas > remaining {
+			// The chain no longer fits, consider its prefixes that do.
+			if trimmed, ok := bestChain(c.msgs, remaining); ok {
+				heap.Push(&chains, trimmed)
+			}
+			continue
+		}
+		out = append(out, c.msgs[:c.n]...)
+		remaining -= c.gas
+		if next, ok := bestChain(c.msgs[c.n:], remaining); ok {
+			heap.Push(&chains, next)
+		}
+	}
+	return out
+}
+
+// msgChain is the prefix of length n of msgs, an actor's remaining minable messages, with the
+// sum of the gas limits and gas rewards of its messages.
+type msgChain struct {
+	msgs   []*types.SignedMessage
+	n      int
+	gas    types.GasUnits
+	reward types.AttoFIL
+}
+
+// bestChain returns the prefix of msgs fitting in gasLimit with the highest reward per unit of
+// gas, preferring longer prefixes on ties. It returns false if no message fits.
+func bestChain(msgs []*types.SignedMessage, gasLimit types.GasUnits) (*msgChain, bool) {
+	var best *msgChain
+	var gas types.GasUnits
+	reward := types.ZeroAttoFIL
+	for i, m := range msgs {
+		if gas+m.GasLimit > gasLimit {
+			break
+		}
+		gas += m.GasLimit
+		reward = reward.Add(gasReward(m))
+		c := &msgChain{msgs: msgs, n: i + 1, gas: gas, reward: reward}
+		if best == nil || !c.lessProfitable(best) {
+			best = c
+		}
+	}
+	return best, best != nil
+}
+
+// gasReward returns the most a message pays for gas.
+func gasReward(m *types.SignedMessage) types.AttoFIL {
+	return m.GasPrice.MulBigInt(big.NewInt(int64(m.GasLimit)))
+}
+
+// lessProfitable returns whether c pays less per unit of gas than other.
+func (c *msgChain) lessProfitable(other *msgChain) bool {
+	return c.reward.MulBigInt(big.NewInt(int64(other.gas))).LessThan(other.reward.MulBigInt(big.NewInt(int64(c.gas))))
+}
+
+// Implements heap.Interface to hold a priority queue of chains, most profitable first.
+type chainHeap []*msgChain
+
+func (ch chainHeap) Len() int { return len(ch) }
+
+func (ch chainHeap) Less(i, j int) bool {
+	if ch[j].lessProfitable(ch[i]) {
+		return true
+	}
+	if ch[i].lessProfitable(ch[j]) {
+		return false
+	}
+	// Secondarily order by address to give a stable ordering.
+	return bytes.Compare(ch[i].msgs[0].From.Bytes(), ch[j].msgs[0].From.Bytes()) < 0
+}
+
+func (ch chainHeap) Swap(i, j int) {
+	ch[i], ch[j] = ch[j], ch[i]
+}
+
+func (ch *chainHeap) Push(x interface{}) {
+	*ch = append(*ch, x.(*msgChain))
+}
+
+func (ch *chainHeap) Pop() interface{} {
+	n := len(*ch)
+	item := (*ch)[n-1]
+	*ch = (*ch)[0 : n-1]
+	return item
+}
@@ -0,0 +1,116 @@
+package mining_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/mining"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+func TestPackingStrategies(t *testing.T) {
+	tf.UnitTest(t)
+
+	var ki = types.MustGenerateKeyInfo(10, 42)
+	var mockSigner = types.NewMockSigner(ki)
+
+	a0 := mockSigner.Addresses[0]
+	a1 := mockSigner.Addresses[1]
+	a2 := mockSigner.Addresses[2]
+	to := mockSigner.Addresses[9]
+
+	sign := func(from address.Address, nonce uint64, units uint64, price int64) *types.SignedMessage {
+		msg := types.Message{
+			From:  from,
+			To:    to,
+			Nonce: types.Uint64(nonce),
+		}
+		s, err := types.NewSignedMessage(msg, &mockSigner, types.NewGasPrice(price), types.NewGasUnits(units))
+		require.NoError(t, err)
+		return s
+	}
+
+	reward := func(msgs []*types.SignedMessage) types.AttoFIL {
+		total := types.ZeroAttoFIL
+		for _, m := range msgs {
+			total = total.Add(m.GasPrice.MulBigInt(big.NewInt(int64(m.GasLimit))))
+		}
+		return total
+	}
+
+	strategies := map[string]mining.PackingStrategy{
+		"gas price": mining.GasPricePacker{},
+		"chain":     mining.ChainPacker{},
+	}
+
+	t.Run("respects gas limit and nonce order", func(t *testing.T) {
+		pending := []*types.SignedMessage{
+			sign(a0, 2, 10, 3),
+			sign(a0, 0, 10, 1),
+			sign(a1, 0, 20, 2),
+			sign(a0, 1, 10, 2),
+			sign(a2, 0, 5, 5),
+			sign(a1, 1, 20, 1),
+		}
+		for name, strategy := range strategies {
+			packed := strategy.Pack(pending, types.NewGasUnits(50))
+
+			var gas types.GasUnits
+			nonces := map[address.Address]types.Uint64{}
+			for _, m := range packed {
+				gas += m.GasLimit
+				assert.Equal(t, nonces[m.From], m.Nonce, name)
+				nonces[m.From] = m.Nonce + 1
+			}
+			assert.True(t, gas <= types.NewGasUnits(50), name)
+			assert.NotEmpty(t, packed, name)
+		}
+	})
+
+	t.Run("chain packer takes low priced messages ahead of high priced ones", func(t *testing.T) {
+		pending := []*types.SignedMessage{
+			sign(a0, 0, 10, 1),
+			sign(a0, 1, 10, 100),
+			sign(a1, 0, 10, 10),
+		}
+		byPrice := mining.GasPricePacker{}.Pack(pending, types.NewGasUnits(20))
+		assert.Equal(t, []*types.SignedMessage{pending[2], pending[0]}, byPrice)
+
+		byChain := mining.ChainPacker{}.Pack(pending, types.NewGasUnits(20))
+		assert.Equal(t, []*types.SignedMessage{pending[0], pending[1]}, byChain)
+		assert.True(t, reward(byChain).GreaterThan(reward(byPrice)))
+	})
+
+	t.Run("chain packer fills the block past messages that do not fit", func(t *testing.T) {
+		pending := []*types.SignedMessage{
+			sign(a0, 0, 30, 5),
+			sign(a1, 0, 10, 2),
+			sign(a1, 1, 10, 2),
+			sign(a2, 0, 10, 1),
+		}
+		assert.Empty(t, mining.GasPricePacker{}.Pack(pending, types.NewGasUnits(25)))
+
+		byChain := mining.ChainPacker{}.Pack(pending, types.NewGasUnits(25))
+		assert.Equal(t, []*types.SignedMessage{pending[1], pending[2]}, byChain)
+	})
+
+	t.Run("chain packer skips messages after a nonce gap", func(t *testing.T) {
+		pending := []*types.SignedMessage{
+			sign(a0, 0, 10, 1),
+			sign(a0, 2, 10, 100),
+		}
+		byChain := mining.ChainPacker{}.Pack(pending, types.BlockGasLimit)
+		assert.Equal(t, []*types.SignedMessage{pending[0]}, byChain)
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		for name, strategy := range strategies {
+			assert.Empty(t, strategy.Pack([]*types.SignedMessage{}, types.BlockGasLimit), name)
+		}
+	})
+}
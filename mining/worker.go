@@ -102,6 +102,7 @@ type DefaultWorker struct {
 
 	// core filecoin things
 	messageSource MessageSource
+	packer        PackingStrategy
 	processor     MessageApplier
 	messageStore  chain.MessageWriter // nolint: structcheck
 	powerTable    consensus.PowerTableView
@@ -126,6 +127,7 @@ type WorkerParameters struct {
 
 	// core filecoin things
 	MessageSource MessageSource
+	Packer        PackingStrategy // selects the messages to include in blocks, ChainPacker if nil
 	Processor     MessageApplier
 	PowerTable    consensus.PowerTableView
 	MessageStore  chain.MessageWriter
@@ -135,12 +137,17 @@ type WorkerParameters struct {
 
 // NewDefaultWorker instantiates a new Worker.
 func NewDefaultWorker(parameters WorkerParameters) *DefaultWorker {
+	packer := parameters.Packer
+	if packer == nil {
+		packer = ChainPacker{}
+	}
 	return &DefaultWorker{
 		api:            parameters.API,
 		getStateTree:   parameters.GetStateTree,
 		getWeight:      parameters.GetWeight,
 		getAncestors:   parameters.GetAncestors,
 		messageSource:  parameters.MessageSource,
+		packer:         packer,
 		messageStore:   parameters.MessageStore,
 		processor:      parameters.Processor,
 		powerTable:     parameters.PowerTable,
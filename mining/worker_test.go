@@ -229,21 +229,21 @@ func TestApplyMessagesForSuccessTempAndPermFailures(t *testing.T) {
 	// If a given message's category changes in the future, it needs to be replaced here in tests by another so we fully
 	// exercise the categorization.
 	// addr2 doesn't correspond to an extant account, so this will trigger errAccountNotFound -- a temporary failure.
-	msg1 := types.NewMessage(addr2, addr1, 0, types.ZeroAttoFIL, "", nil)
+	msg1 := types.NewMessage(addr2, addr1, 0, types.ZeroAttoFIL, types.SendMethodID, nil)
 	smsg1, err := types.NewSignedMessage(*msg1, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
 
 	// This is actually okay and should result in a receipt
-	msg2 := types.NewMessage(addr1, addr2, 0, types.ZeroAttoFIL, "", nil)
+	msg2 := types.NewMessage(addr1, addr2, 0, types.ZeroAttoFIL, types.SendMethodID, nil)
 	smsg2, err := types.NewSignedMessage(*msg2, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
 
 	// The following two are sending to self -- errSelfSend, a permanent error.
-	msg3 := types.NewMessage(addr1, addr1, 1, types.ZeroAttoFIL, "", nil)
+	msg3 := types.NewMessage(addr1, addr1, 1, types.ZeroAttoFIL, types.SendMethodID, nil)
 	smsg3, err := types.NewSignedMessage(*msg3, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
 
-	msg4 := types.NewMessage(addr2, addr2, 1, types.ZeroAttoFIL, "", nil)
+	msg4 := types.NewMessage(addr2, addr2, 1, types.ZeroAttoFIL, types.SendMethodID, nil)
 	smsg4, err := types.NewSignedMessage(*msg4, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
 
@@ -363,21 +363,21 @@ func TestGeneratePoolBlockResults(t *testing.T) {
 	})
 
 	// addr3 doesn't correspond to an extant account, so this will trigger errAccountNotFound -- a temporary failure.
-	msg1 := types.NewMessage(addrs[2], addrs[0], 0, types.ZeroAttoFIL, "", nil)
+	msg1 := types.NewMessage(addrs[2], addrs[0], 0, types.ZeroAttoFIL, types.SendMethodID, nil)
 	smsg1, err := types.NewSignedMessage(*msg1, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
 
 	// This is actually okay and should result in a receipt
-	msg2 := types.NewMessage(addrs[0], addrs[1], 0, types.ZeroAttoFIL, "", nil)
+	msg2 := types.NewMessage(addrs[0], addrs[1], 0, types.ZeroAttoFIL, types.SendMethodID, nil)
 	smsg2, err := types.NewSignedMessage(*msg2, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
 
 	// add the following and then increment the actor nonce at addrs[1], nonceTooLow, a permanent error.
-	msg3 := types.NewMessage(addrs[1], addrs[0], 0, types.ZeroAttoFIL, "", nil)
+	msg3 := types.NewMessage(addrs[1], addrs[0], 0, types.ZeroAttoFIL, types.SendMethodID, nil)
 	smsg3, err := types.NewSignedMessage(*msg3, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
 
-	msg4 := types.NewMessage(addrs[1], addrs[2], 1, types.ZeroAttoFIL, "", nil)
+	msg4 := types.NewMessage(addrs[1], addrs[2], 1, types.ZeroAttoFIL, types.SendMethodID, nil)
 	smsg4, err := types.NewSignedMessage(*msg4, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
 
@@ -587,7 +587,7 @@ func TestGenerateError(t *testing.T) {
 	})
 
 	// This is actually okay and should result in a receipt
-	msg := types.NewMessage(addrs[0], addrs[1], 0, types.ZeroAttoFIL, "", nil)
+	msg := types.NewMessage(addrs[0], addrs[1], 0, types.ZeroAttoFIL, types.SendMethodID, nil)
 	smsg, err := types.NewSignedMessage(*msg, &mockSigner, types.NewGasPrice(0), types.NewGasUnits(0))
 	require.NoError(t, err)
 	_, err = pool.Add(ctx, smsg, 0)
@@ -31,6 +31,10 @@ type PeerTracker struct {
 	peers    map[peer.ID]*types.ChainInfo
 	trusted  map[peer.ID]struct{}
 	updateFn updatePeerFn
+
+	// badChains counts the invalid chains received from each peer. Peers
+	// that sent fewer invalid chains are preferred.
+	badChains map[peer.ID]uint
 }
 
 type updatePeerFn func(ctx context.Context, p peer.ID) (*types.ChainInfo, error)
@@ -42,9 +46,10 @@ func NewPeerTracker(self peer.ID, trust ...peer.ID) *PeerTracker {
 		trustedSet[t] = struct{}{}
 	}
 	return &PeerTracker{
-		peers:   make(map[peer.ID]*types.ChainInfo),
-		trusted: trustedSet,
-		self:    self,
+		peers:     make(map[peer.ID]*types.ChainInfo),
+		trusted:   trustedSet,
+		self:      self,
+		badChains: make(map[peer.ID]uint),
 	}
 }
 
@@ -54,17 +59,46 @@ func (tracker *PeerTracker) SetUpdateFn(f updatePeerFn) {
 	tracker.updateFn = f
 }
 
-// SelectHead returns the chain info from trusted peers with the greatest height.
+// SelectHead returns the chain info from trusted peers with the greatest height,
+// preferring peers that sent fewer invalid chains.
 // An error is returned if no peers are in the tracker.
 func (tracker *PeerTracker) SelectHead() (*types.ChainInfo, error) {
 	heads := tracker.listTrusted()
 	if len(heads) == 0 {
 		return nil, errors.New("no peers tracked")
 	}
-	sort.Slice(heads, func(i, j int) bool { return heads[i].Height > heads[j].Height })
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	sort.Slice(heads, func(i, j int) bool {
+		iBad, jBad := tracker.badChains[heads[i].Peer], tracker.badChains[heads[j].Peer]
+		if iBad != jBad {
+			return iBad < jBad
+		}
+		return heads[i].Height > heads[j].Height
+	})
 	return heads[0], nil
 }
 
+// RecordBadChain records that a peer sent an invalid chain. The peer is
+// deprioritized when selecting heads and listing peers.
+func (tracker *PeerTracker) RecordBadChain(pid peer.ID) {
+	if pid == tracker.self {
+		return
+	}
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	tracker.badChains[pid]++
+	logPeerTracker.Warningf("Peer %s sent an invalid chain, count=%d", pid.Pretty(), tracker.badChains[pid])
+}
+
+// BadChains returns the number of invalid chains recorded for a peer.
+func (tracker *PeerTracker) BadChains(pid peer.ID) uint {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+	return tracker.badChains[pid]
+}
+
 // UpdateTrusted updates ChainInfo for all trusted peers.
 func (tracker *PeerTracker) UpdateTrusted(ctx context.Context) error {
 	return tracker.updatePeers(ctx, tracker.trustedPeers()...)
@@ -86,8 +120,8 @@ func (tracker *PeerTracker) Self() peer.ID {
 	return tracker.self
 }
 
-// List returns the chain info of the currently tracked peers (both trusted and untrusted).
-// The info tracked by the tracker can change arbitrarily after this is called -- there is no
+// List returns the chain info of the currently tracked peers (both trusted and untrusted),
+// peers that sent fewer invalid chains first. The info tracked by the tracker can change arbitrarily after this is called -- there is no
 // guarantee that the peers returned will be tracked when they are used by the caller and no
 // guarantee that the chain info is up to date.
 func (tracker *PeerTracker) List() []*types.ChainInfo {
@@ -98,6 +132,9 @@ func (tracker *PeerTracker) List() []*types.ChainInfo {
 	for _, ci := range tracker.peers {
 		tracked = append(tracked, ci)
 	}
+	sort.SliceStable(tracked, func(i, j int) bool {
+		return tracker.badChains[tracked[i].Peer] < tracker.badChains[tracked[j].Peer]
+	})
 	out := make([]*types.ChainInfo, len(tracked))
 	copy(out, tracked)
 	return out
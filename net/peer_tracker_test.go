@@ -70,6 +70,32 @@ func TestPeerTrackerSelectHead(t *testing.T) {
 	assert.Equal(t, head.Head, ci3.Head)
 }
 
+func TestPeerTrackerRecordBadChain(t *testing.T) {
+	tf.UnitTest(t)
+
+	self := th.RequireIntPeerID(t, 0)
+	pid1 := th.RequireIntPeerID(t, 1)
+	pid2 := th.RequireIntPeerID(t, 2)
+
+	ci1 := types.NewChainInfo(pid1, types.NewTipSetKey(types.CidFromString(t, "somecid1")), 10)
+	ci2 := types.NewChainInfo(pid2, types.NewTipSetKey(types.CidFromString(t, "somecid2")), 7)
+
+	tracker := net.NewPeerTracker(self, pid1, pid2)
+	tracker.Track(ci1)
+	tracker.Track(ci2)
+
+	tracker.RecordBadChain(pid1)
+	tracker.RecordBadChain(self)
+	assert.Equal(t, uint(1), tracker.BadChains(pid1))
+	assert.Equal(t, uint(0), tracker.BadChains(self))
+
+	// the highest head comes from a peer that sent a bad chain
+	head, err := tracker.SelectHead()
+	require.NoError(t, err)
+	assert.Equal(t, ci2.Head, head.Head)
+	assert.Equal(t, []*types.ChainInfo{ci2, ci1}, tracker.List())
+}
+
 func TestPeerTrackerUpdateTrusted(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -168,7 +168,9 @@ func (nc *Builder) build(ctx context.Context) (*Node, error) {
 	// set up chain and message stores
 	chainStore := chain.NewStore(nc.Repo.ChainDatastore(), &ipldCborStore, &state.TreeStateLoader{}, chainStatusReporter, genCid)
 	messageStore := chain.NewMessageStore(&ipldCborStore)
-	chainState := cst.NewChainStateProvider(chainStore, messageStore, &ipldCborStore)
+	chainStore.SetMessageIndex(chain.NewMessageIndex(nc.Repo.ChainDatastore(), messageStore))
+	chainStore.PinCheckpoints(nc.Repo.Config().Chain.Checkpoints...)
+	chainState := cst.NewChainStateProvider(chainStore, messageStore, &ipldCborStore, bs)
 	powerTable := &consensus.MarketView{}
 
 	// create protocol upgrade table
@@ -264,14 +266,26 @@ func (nc *Builder) build(ctx context.Context) (*Node, error) {
 
 	// only the syncer gets the storage which is online connected
 	chainSyncer := chain.NewSyncer(nodeConsensus, chainStore, messageStore, fetcher, chainStatusReporter, nc.Clock)
+	badTipSets, err := chain.NewBadTipSetCache(nc.Repo.ChainDatastore())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load bad tipsets")
+	}
+	chainSyncer.SetBadTipSetCache(badTipSets)
+	chainSyncer.SetBadPeerReporter(peerTracker)
+	chainSyncer.SetStatelessValidator(consensus.NewStatelessValidator(blkValid))
 	msgPool := message.NewPool(nc.Repo.Config().Mpool, consensus.NewIngestionValidator(chainState, nc.Repo.Config().Mpool))
 	inbox := message.NewInbox(msgPool, message.InboxMaxAgeTipsets, chainStore, messageStore)
 
-	msgQueue := message.NewQueue()
+	msgQueue, err := message.NewPersistentQueue(nc.Repo.Datastore())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load outbound message queue")
+	}
 	outboxPolicy := message.NewMessageQueuePolicy(messageStore, message.OutboxMaxAgeRounds)
 	msgPublisher := message.NewDefaultPublisher(pubsub.NewPublisher(fsub), net.MessageTopic(network), msgPool)
 	outbox := message.NewOutbox(fcWallet, consensus.NewOutboundMessageValidator(), msgQueue, msgPublisher, outboxPolicy, chainStore, chainState)
 
+	chainGC := chain.NewGarbageCollector(chainStore, bs)
+
 	nd := &Node{
 		blockservice: bservice,
 		Blockstore:   bs,
@@ -279,6 +293,7 @@ func (nc *Builder) build(ctx context.Context) (*Node, error) {
 		Clock:        nc.Clock,
 		Consensus:    nodeConsensus,
 		ChainReader:  chainStore,
+		ChainGC:      chainGC,
 		ChainSynced:  moresync.NewLatch(1),
 		MessageStore: messageStore,
 		Syncer:       chainSyncer,
@@ -300,6 +315,7 @@ func (nc *Builder) build(ctx context.Context) (*Node, error) {
 	nd.PorcelainAPI = porcelain.New(plumbing.New(&plumbing.APIDeps{
 		Bitswap:       bswap,
 		Chain:         chainState,
+		ChainGC:       chainGC,
 		Sync:          cst.NewChainSyncProvider(chainSyncer),
 		Config:        cfg.NewConfig(nc.Repo),
 		DAG:           dag.NewDAG(merkledag.NewDAGService(bservice)),
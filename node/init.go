@@ -24,6 +24,7 @@ const defaultPeerKeyBits = 2048
 type initCfg struct {
 	peerKey    crypto.PrivKey
 	defaultKey *types.KeyInfo
+	snapshot   *chain.SnapshotHeader
 }
 
 // InitOpt is an option for initialization of a node's repo.
@@ -45,6 +46,15 @@ func DefaultKeyOpt(ki *types.KeyInfo) InitOpt {
 	}
 }
 
+// SnapshotOpt sets the head of the chain to the head of a snapshot whose
+// blocks have been imported in the repo's blockstore.
+// If unspecified, the chain is left at genesis.
+func SnapshotOpt(header *chain.SnapshotHeader) InitOpt {
+	return func(opts *initCfg) {
+		opts.snapshot = header
+	}
+}
+
 // Init initializes a Filecoin repo with genesis state and keys.
 // This will always set the configuration for wallet default address (to the specified default
 // key or a newly generated one), but otherwise leave the repo's config object intact.
@@ -57,9 +67,15 @@ func Init(ctx context.Context, r repo.Repo, gen consensus.GenesisInitFunc, opts
 
 	bs := bstore.NewBlockstore(r.Datastore())
 	cst := &hamt.CborIpldStore{Blocks: bserv.New(bs, offline.Exchange(bs))}
-	if _, err := chain.Init(ctx, r, bs, cst, gen); err != nil {
+	chainStore, err := chain.Init(ctx, r, bs, cst, gen)
+	if err != nil {
 		return errors.Wrap(err, "Could not Init Node")
 	}
+	if cfg.snapshot != nil {
+		if err := chainStore.LoadSnapshot(ctx, cfg.snapshot); err != nil {
+			return errors.Wrap(err, "failed to load chain snapshot")
+		}
+	}
 
 	if err := initPeerKey(r.Keystore(), cfg.peerKey); err != nil {
 		return err
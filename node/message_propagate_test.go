@@ -70,7 +70,7 @@ func TestMessagePropagation(t *testing.T) {
 			types.NewAttoFILFromFIL(1),
 			types.NewGasPrice(1),
 			types.NewGasUnits(0),
-			"foo",
+			"",
 		)
 		require.NoError(t, err)
 
@@ -80,8 +80,8 @@ func TestMessagePropagation(t *testing.T) {
 				len(nodes[2].Inbox.Pool().Pending()) == 1, nil
 		}), "failed to propagate messages")
 
-		assert.True(t, nodes[0].Inbox.Pool().Pending()[0].Message.Method == "foo")
-		assert.True(t, nodes[1].Inbox.Pool().Pending()[0].Message.Method == "foo")
-		assert.True(t, nodes[2].Inbox.Pool().Pending()[0].Message.Method == "foo")
+		assert.True(t, nodes[0].Inbox.Pool().Pending()[0].Message.Method == types.SendMethodID)
+		assert.True(t, nodes[1].Inbox.Pool().Pending()[0].Message.Method == types.SendMethodID)
+		assert.True(t, nodes[2].Inbox.Pool().Pending()[0].Message.Method == types.SendMethodID)
 	})
 }
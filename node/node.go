@@ -85,6 +85,7 @@ type Node struct {
 
 	Consensus    consensus.Protocol
 	ChainReader  nodeChainReader
+	ChainGC      *chain.GarbageCollector
 	MessageStore *chain.MessageStore
 	Syncer       nodeChainSyncer
 	PowerTable   consensus.PowerTableView
@@ -96,7 +97,7 @@ type Node struct {
 	RetrievalAPI   *retrieval.API
 	StorageAPI     *storage.API
 
-	// HeavyTipSetCh is a subscription to the heaviest tipset topic on the chain.
+	// HeaviestTipSetCh is a subscription to the head change topic on the chain.
 	// https://github.com/filecoin-project/go-filecoin/issues/2309
 	HeaviestTipSetCh chan interface{}
 	// cancelChainSync cancels the context for chain sync subscriptions and handlers.
@@ -213,6 +214,12 @@ func (node *Node) Start(ctx context.Context) error {
 		return err
 	}
 
+	// Bring the outbound messages queued before the node restarted up to date with the head
+	// and add those still pending to the message pool. They are broadcast once the chain syncs.
+	if err := node.Outbox.Reconcile(ctx); err != nil {
+		return errors.Wrap(err, "failed to reconcile outbound message queue")
+	}
+
 	// Only set these up if there is a miner configured.
 	if _, err := node.MiningAddress(); err == nil {
 		if err := node.setupMining(ctx); err != nil {
@@ -232,12 +239,19 @@ func (node *Node) Start(ctx context.Context) error {
 	var syncCtx context.Context
 	syncCtx, node.cancelChainSync = context.WithCancel(context.Background())
 
-	// Wire up propagation of new chain heads from the chain store to other components.
-	head, err := node.PorcelainAPI.ChainHead()
-	if err != nil {
-		return errors.Wrap(err, "failed to get chain head")
+	// Wire up propagation of head changes from the chain store to other components.
+	// Subscribe before returning so that no change is missed.
+	node.HeaviestTipSetCh = node.ChainReader.HeadEvents().Sub(chain.HeadChangeTopic)
+	go node.handleHeadChanges(syncCtx)
+
+	// Prune old chain state periodically if configured to.
+	if chainCfg := node.Repo.Config().Chain; chainCfg.AutoPrune {
+		period, err := time.ParseDuration(chainCfg.PrunePeriod)
+		if err != nil {
+			return errors.Wrapf(err, "couldn't parse chain prune period %s", chainCfg.PrunePeriod)
+		}
+		go node.ChainGC.Run(syncCtx, chainCfg.KeepStateRoots, chainCfg.FinalityDepth, period)
 	}
-	go node.handleNewChainHeads(syncCtx, head)
 
 	if !node.OfflineMode {
 		// Start bootstrapper.
@@ -292,6 +306,9 @@ func (node *Node) Start(ctx context.Context) error {
 			}
 
 			if syncCtx.Err() == nil {
+				// Now that the node has peers, broadcast the messages it queued before it restarted.
+				node.Outbox.Rebroadcast(syncCtx)
+
 				// Subscribe to block pubsub topic to learn about new chain heads.
 				node.BlockSub, err = node.pubsubscribe(syncCtx, net.BlockTopic(node.NetworkName), node.processBlock)
 				if err != nil {
@@ -407,26 +424,32 @@ func (node *Node) handleNewMiningOutput(ctx context.Context, miningOutCh <-chan
 
 }
 
-func (node *Node) handleNewChainHeads(ctx context.Context, prevHead types.TipSet) {
-	node.HeaviestTipSetCh = node.ChainReader.HeadEvents().Sub(chain.NewHeadTopic)
-	handler := message.NewHeadHandler(node.Inbox, node.Outbox, node.ChainReader, prevHead)
+func (node *Node) handleHeadChanges(ctx context.Context) {
+	handler := message.NewHeadHandler(node.Inbox, node.Outbox)
 
 	for {
 		select {
-		case ts, ok := <-node.HeaviestTipSetCh:
+		case e, ok := <-node.HeaviestTipSetCh:
 			if !ok {
 				return
 			}
-			newHead, ok := ts.(types.TipSet)
+			changes, ok := e.([]chain.HeadChange)
 			if !ok {
-				log.Warning("non-tipset published on heaviest tipset channel")
+				log.Warning("non-head change published on head change channel")
 				continue
 			}
 
-			if err := handler.HandleNewHead(ctx, newHead); err != nil {
+			if err := handler.HandleHeadChange(ctx, changes); err != nil {
 				log.Error(err)
 			}
 
+			// Applies come last, the last one is the new head.
+			last := changes[len(changes)-1]
+			if last.Type != chain.HCApply {
+				continue
+			}
+			newHead := last.TipSet
+
 			if node.StorageMiner != nil {
 				if _, err := node.StorageMiner.OnNewHeaviestTipSet(newHead); err != nil {
 					log.Error(err)
@@ -525,7 +548,7 @@ func (node *Node) SetupMining(ctx context.Context) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to get mining address")
 	}
-	_, err = node.PorcelainAPI.ActorGet(ctx, minerAddr)
+	_, err = node.PorcelainAPI.ActorGet(ctx, minerAddr, node.ChainReader.GetHead())
 	if err != nil {
 		return errors.Wrap(err, "failed to get miner actor")
 	}
@@ -573,7 +596,7 @@ func (node *Node) StartMining(ctx context.Context) error {
 		return errors.Wrap(err, "failed to get mining address")
 	}
 
-	minerOwnerAddr, err := node.PorcelainAPI.MinerGetOwnerAddress(ctx, minerAddr)
+	minerOwnerAddr, err := node.PorcelainAPI.MinerGetOwnerAddress(ctx, minerAddr, node.ChainReader.GetHead())
 	if err != nil {
 		return errors.Wrapf(err, "failed to get mining owner address for miner %s", minerAddr)
 	}
@@ -615,7 +638,7 @@ func (node *Node) StartMining(ctx context.Context) error {
 
 					// TODO: determine these algorithmically by simulating call and querying historical prices
 					gasPrice := types.NewGasPrice(1)
-					gasUnits := types.NewGasUnits(300)
+					gasUnits := types.NewGasUnits(1000)
 
 					val := result.SealingResult
 
@@ -768,7 +791,7 @@ func initStorageMinerForNode(ctx context.Context, node *Node) (*storage.Miner, a
 		return nil, address.Undef, errors.Wrap(err, "failed to get node's mining address")
 	}
 
-	ownerAddress, err := node.PorcelainAPI.MinerGetOwnerAddress(ctx, minerAddr)
+	ownerAddress, err := node.PorcelainAPI.MinerGetOwnerAddress(ctx, minerAddr, node.ChainReader.GetHead())
 	if err != nil {
 		return nil, address.Undef, errors.Wrap(err, "no mining owner available, skipping storage miner setup")
 	}
@@ -884,7 +907,7 @@ func (node *Node) CreateMiningWorker(ctx context.Context) (mining.Worker, error)
 		return nil, errors.Wrap(err, "failed to get mining address")
 	}
 
-	minerOwnerAddr, err := node.PorcelainAPI.MinerGetOwnerAddress(ctx, minerAddr)
+	minerOwnerAddr, err := node.PorcelainAPI.MinerGetOwnerAddress(ctx, minerAddr, node.ChainReader.GetHead())
 	if err != nil {
 		log.Errorf("could not get owner address of miner actor")
 		return nil, err
@@ -33,6 +33,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/protocol/storage/storagedeal"
 	"github.com/filecoin-project/go-filecoin/state"
 	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/vm"
 	"github.com/filecoin-project/go-filecoin/wallet"
 )
 
@@ -46,6 +47,7 @@ type API struct {
 
 	bitswap       exchange.Interface
 	chain         *cst.ChainStateProvider
+	chainGC       *chain.GarbageCollector
 	syncer        *cst.ChainSyncProvider
 	config        *cfg.Config
 	dag           *dag.DAG
@@ -65,6 +67,7 @@ type API struct {
 type APIDeps struct {
 	Bitswap       exchange.Interface
 	Chain         *cst.ChainStateProvider
+	ChainGC       *chain.GarbageCollector
 	Sync          *cst.ChainSyncProvider
 	Config        *cfg.Config
 	DAG           *dag.DAG
@@ -87,6 +90,7 @@ func New(deps *APIDeps) *API {
 
 		bitswap:       deps.Bitswap,
 		chain:         deps.Chain,
+		chainGC:       deps.ChainGC,
 		syncer:        deps.Sync,
 		config:        deps.Config,
 		dag:           deps.DAG,
@@ -103,9 +107,9 @@ func New(deps *APIDeps) *API {
 	}
 }
 
-// ActorGet returns an actor from the latest state on the chain
-func (api *API) ActorGet(ctx context.Context, addr address.Address) (*actor.Actor, error) {
-	return api.chain.GetActor(ctx, addr)
+// ActorGet returns an actor from the state of the tipset with key baseKey.
+func (api *API) ActorGet(ctx context.Context, addr address.Address, baseKey types.TipSetKey) (*actor.Actor, error) {
+	return api.chain.GetActorAt(ctx, baseKey, addr)
 }
 
 // ActorGetSignature returns the signature of the given actor's given method.
@@ -115,9 +119,16 @@ func (api *API) ActorGetSignature(ctx context.Context, actorAddr address.Address
 	return api.chain.GetActorSignature(ctx, actorAddr, method)
 }
 
-// ActorLs returns a channel with actors from the latest state on the chain
-func (api *API) ActorLs(ctx context.Context) (<-chan state.GetAllActorsResult, error) {
-	return api.chain.LsActors(ctx)
+// ActorGetMethodSignature returns the signature of the method with the given id
+// of the given actor, as addressed by a message.
+func (api *API) ActorGetMethodSignature(ctx context.Context, actorAddr address.Address, method types.MethodID) (_ *exec.FunctionSignature, err error) {
+	return api.chain.GetActorMethodSignature(ctx, actorAddr, method)
+}
+
+// ActorLs returns a channel with actors from the state of the tipset with key
+// baseKey.
+func (api *API) ActorLs(ctx context.Context, baseKey types.TipSetKey) (<-chan state.GetAllActorsResult, error) {
+	return api.chain.LsActors(ctx, baseKey)
 }
 
 // BlockTime returns the block time used by the consensus protocol.
@@ -171,6 +182,46 @@ func (api *API) ChainLs(ctx context.Context) (*chain.TipsetIterator, error) {
 	return api.chain.Ls(ctx)
 }
 
+// ChainStateDiff compares the states of two tipsets and lists the actors
+// that were added, removed or modified between them.
+func (api *API) ChainStateDiff(ctx context.Context, before, after types.TipSetKey) (*state.TreeDiff, error) {
+	return api.chain.StateDiff(ctx, before, after)
+}
+
+// ChainExport writes a snapshot archive of the chain ending at the tipset
+// with the given key to out, including the state of the recentStates most
+// recent tipsets.
+func (api *API) ChainExport(ctx context.Context, key types.TipSetKey, recentStates uint, out io.Writer) error {
+	return api.chain.Export(ctx, key, recentStates, out)
+}
+
+// ChainNotify returns a channel receiving the current head, then the ordered
+// reverts and applies of every change of the head until ctx is done. The
+// channel is closed early if the receiver falls behind.
+func (api *API) ChainNotify(ctx context.Context) <-chan []chain.HeadChange {
+	return api.chain.HeadChanges(ctx)
+}
+
+// ChainSetCheckpoint checkpoints a tipset of the head chain, so the chain
+// will not reorg past it.
+func (api *API) ChainSetCheckpoint(ctx context.Context, key types.TipSetKey) error {
+	return api.chain.SetCheckpoint(ctx, key)
+}
+
+// ChainCheckpoints returns the keys of the checkpointed tipsets, both pinned
+// in the config and set with ChainSetCheckpoint.
+func (api *API) ChainCheckpoints() []types.TipSetKey {
+	return api.chain.Checkpoints()
+}
+
+// ChainGC deletes the state of every tipset except the unfinalized tipsets of
+// the head chain, the keepStateRoots most recent finalized tipsets of the head
+// chain and genesis. Tipsets at least finalityDepth below the head are
+// finalized, as are checkpointed tipsets and their ancestors.
+func (api *API) ChainGC(ctx context.Context, keepStateRoots, finalityDepth uint) (*chain.GCStats, error) {
+	return api.chainGC.Collect(ctx, keepStateRoots, finalityDepth)
+}
+
 // ChainSampleRandomness produces a slice of random bytes sampled from a TipSet
 // in the blockchain at a given height, useful for things like PoSt challenge seed
 // generation.
@@ -183,6 +234,18 @@ func (api *API) ChainStatus() chain.Status {
 	return api.syncer.Status()
 }
 
+// ChainBadTipSets returns the tipsets the syncer rejected, with the reason they
+// were rejected and the peer they came from.
+func (api *API) ChainBadTipSets() []*chain.BadTipSet {
+	return api.syncer.BadTipSets()
+}
+
+// ChainClearBadTipSets forgets the tipsets the syncer rejected, so they are
+// validated again when next received.
+func (api *API) ChainClearBadTipSets() error {
+	return api.syncer.ClearBadTipSets()
+}
+
 // ChainSyncHandleNewTipSet submits a chain head to the syncer for processing. If the head is trusted
 // the syncer will attempt to sync the new head regardless of length.
 func (api *API) ChainSyncHandleNewTipSet(ctx context.Context, ci *types.ChainInfo, trusted bool) error {
@@ -224,6 +287,11 @@ func (api *API) MessagePoolGet(cid cid.Cid) (value *types.SignedMessage, ok bool
 	return api.msgPool.Get(cid)
 }
 
+// MessagePoolStats summarizes the messages in the message pool.
+func (api *API) MessagePoolStats() *message.PoolStats {
+	return api.msgPool.Stats()
+}
+
 // MessagePoolRemove removes a message from the message pool.
 func (api *API) MessagePoolRemove(cid cid.Cid) {
 	api.msgPool.Remove(cid)
@@ -235,9 +303,15 @@ func (api *API) MessagePreview(ctx context.Context, from, to address.Address, me
 	return api.msgPreviewer.Preview(ctx, from, to, method, params...)
 }
 
-// MessageQuery calls an actor's method using the most recent chain state. It is read-only,
-// it does not change any state. It is use to interrogate actor state. The from address
-// is optional; if not provided, an address will be chosen from the node's wallet.
+// MessageEstimateGas estimates the Gas used by a message by applying it locally on top of the
+// head state, after pending messages from the same sender that are to be applied ahead of it.
+func (api *API) MessageEstimateGas(ctx context.Context, pending []*types.SignedMessage, from, to address.Address, value types.AttoFIL, method string, params ...interface{}) (types.GasUnits, error) {
+	return api.msgPreviewer.Estimate(ctx, pending, from, to, value, method, params...)
+}
+
+// MessageQuery calls an actor's method using the state of the tipset with key baseKey. It is
+// read-only, it does not change any state. It is use to interrogate actor state. The from
+// address is optional; if not provided, an address will be chosen from the node's wallet.
 func (api *API) MessageQuery(ctx context.Context, optFrom, to address.Address, method string, baseKey types.TipSetKey, params ...interface{}) ([][]byte, error) {
 	return api.msgQueryer.Query(ctx, optFrom, to, method, baseKey, params...)
 }
@@ -250,11 +324,24 @@ func (api *API) MessageSend(ctx context.Context, from, to address.Address, value
 	return api.outbox.Send(ctx, from, to, value, gasPrice, gasLimit, true, method, params...)
 }
 
+// MessageReplace replaces a message sent from this node and not yet mined with a copy paying
+// gasPrice, and broadcasts the replacement to the network. The replacement has the same nonce
+// and is accepted by message pools only if it pays sufficiently more than the message it replaces.
+func (api *API) MessageReplace(ctx context.Context, msgCid cid.Cid, gasPrice types.AttoFIL) (cid.Cid, error) {
+	return api.outbox.Replace(ctx, msgCid, gasPrice, true)
+}
+
 // MessageFind returns a message and receipt from the blockchain, if it exists.
 func (api *API) MessageFind(ctx context.Context, msgCid cid.Cid) (*msg.ChainMessage, bool, error) {
 	return api.msgWaiter.Find(ctx, msgCid)
 }
 
+// MessageTrace replays the tipset that included the message with the given cid
+// and returns the execution trace of the message.
+func (api *API) MessageTrace(ctx context.Context, msgCid cid.Cid) (*vm.ExecutionTrace, error) {
+	return api.msgWaiter.Trace(ctx, msgCid)
+}
+
 // MessageWait invokes the callback when a message with the given cid appears on chain.
 // It will find the message in both the case that it is already on chain and
 // the case that it appears in a newly mined block. An error is returned if one is
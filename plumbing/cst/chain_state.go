@@ -3,9 +3,11 @@ package cst
 import (
 	"context"
 	"fmt"
+	"io"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-hamt-ipld"
+	bstore "github.com/ipfs/go-ipfs-blockstore"
 	"github.com/pkg/errors"
 
 	"github.com/filecoin-project/go-filecoin/actor"
@@ -21,6 +23,10 @@ type chainReader interface {
 	GetHead() types.TipSetKey
 	GetTipSet(types.TipSetKey) (types.TipSet, error)
 	GetTipSetState(context.Context, types.TipSetKey) (state.Tree, error)
+	GetTipSetStateRoot(types.TipSetKey) (cid.Cid, error)
+	SetCheckpoint(context.Context, types.TipSetKey) error
+	Checkpoints() []types.TipSetKey
+	SubscribeHeadChanges(context.Context) <-chan []chain.HeadChange
 }
 
 // ChainStateProvider composes a chain and a state store to provide access to
@@ -28,6 +34,7 @@ type chainReader interface {
 type ChainStateProvider struct {
 	reader          chainReader         // Provides chain tipsets and state roots.
 	cst             *hamt.CborIpldStore // Provides chain blocks and state trees.
+	bs              bstore.Blockstore   // Provides raw blocks for export.
 	messageProvider chain.MessageProvider
 }
 
@@ -42,10 +49,11 @@ var (
 )
 
 // NewChainStateProvider returns a new ChainStateProvider.
-func NewChainStateProvider(chainReader chainReader, messages chain.MessageProvider, cst *hamt.CborIpldStore) *ChainStateProvider {
+func NewChainStateProvider(chainReader chainReader, messages chain.MessageProvider, cst *hamt.CborIpldStore, bs bstore.Blockstore) *ChainStateProvider {
 	return &ChainStateProvider{
 		reader:          chainReader,
 		cst:             cst,
+		bs:              bs,
 		messageProvider: messages,
 	}
 }
@@ -110,7 +118,7 @@ func (chn *ChainStateProvider) GetActor(ctx context.Context, addr address.Addres
 func (chn *ChainStateProvider) GetActorAt(ctx context.Context, tipKey types.TipSetKey, addr address.Address) (*actor.Actor, error) {
 	st, err := chn.reader.GetTipSetState(ctx, tipKey)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to load latest state")
+		return nil, errors.Wrapf(err, "failed to load state of %s", tipKey)
 	}
 
 	actr, err := st.GetActor(ctx, addr)
@@ -120,15 +128,57 @@ func (chn *ChainStateProvider) GetActorAt(ctx context.Context, tipKey types.TipS
 	return actr, nil
 }
 
-// LsActors returns a channel with actors from the latest state on the chain
-func (chn *ChainStateProvider) LsActors(ctx context.Context) (<-chan state.GetAllActorsResult, error) {
-	st, err := chn.reader.GetTipSetState(ctx, chn.reader.GetHead())
+// LsActors returns a channel with the actors of the state at a specified
+// tipset key.
+func (chn *ChainStateProvider) LsActors(ctx context.Context, tipKey types.TipSetKey) (<-chan state.GetAllActorsResult, error) {
+	st, err := chn.reader.GetTipSetState(ctx, tipKey)
 	if err != nil {
 		return nil, err
 	}
 	return state.GetAllActors(ctx, st), nil
 }
 
+// StateDiff compares the states of two tipsets.
+func (chn *ChainStateProvider) StateDiff(ctx context.Context, before, after types.TipSetKey) (*state.TreeDiff, error) {
+	beforeState, err := chn.reader.GetTipSetState(ctx, before)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load state of %s", before)
+	}
+	afterState, err := chn.reader.GetTipSetState(ctx, after)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load state of %s", after)
+	}
+	return state.Diff(ctx, beforeState, afterState)
+}
+
+// Export writes a snapshot archive of the chain ending at the tipset with the
+// given key, with the state of its recentStates most recent tipsets, to out.
+func (chn *ChainStateProvider) Export(ctx context.Context, key types.TipSetKey, recentStates uint, out io.Writer) error {
+	ts, err := chn.reader.GetTipSet(key)
+	if err != nil {
+		return err
+	}
+	return chain.ExportSnapshot(ctx, chn.reader, chn.bs, ts, recentStates, out)
+}
+
+// HeadChanges returns a channel receiving the current head, then the ordered
+// reverts and applies of every change of the head until ctx is done. The
+// channel is closed early if the receiver falls behind.
+func (chn *ChainStateProvider) HeadChanges(ctx context.Context) <-chan []chain.HeadChange {
+	return chn.reader.SubscribeHeadChanges(ctx)
+}
+
+// SetCheckpoint checkpoints the tipset with the given key, which must be on
+// the head chain. The chain will not reorg past it.
+func (chn *ChainStateProvider) SetCheckpoint(ctx context.Context, key types.TipSetKey) error {
+	return chn.reader.SetCheckpoint(ctx, key)
+}
+
+// Checkpoints returns the keys of the checkpointed tipsets.
+func (chn *ChainStateProvider) Checkpoints() []types.TipSetKey {
+	return chn.reader.Checkpoints()
+}
+
 // GetActorSignature returns the signature of the given actor's given method.
 // The function signature is typically used to enable a caller to decode the
 // output of an actor method call (message).
@@ -137,6 +187,40 @@ func (chn *ChainStateProvider) GetActorSignature(ctx context.Context, actorAddr
 		return nil, ErrNoMethod
 	}
 
+	exports, err := chn.getActorExports(ctx, actorAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	_, export, ok := exports.Lookup(method)
+	if !ok {
+		return nil, fmt.Errorf("missing export: %s", method)
+	}
+
+	return export, nil
+}
+
+// GetActorMethodSignature returns the signature of the method of the given
+// actor with the given id, as addressed by a message.
+func (chn *ChainStateProvider) GetActorMethodSignature(ctx context.Context, actorAddr address.Address, method types.MethodID) (*exec.FunctionSignature, error) {
+	if method == types.SendMethodID {
+		return nil, ErrNoMethod
+	}
+
+	exports, err := chn.getActorExports(ctx, actorAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	export, ok := exports[method]
+	if !ok {
+		return nil, fmt.Errorf("missing export: %s", method)
+	}
+
+	return export, nil
+}
+
+func (chn *ChainStateProvider) getActorExports(ctx context.Context, actorAddr address.Address) (exec.Exports, error) {
 	actor, err := chn.GetActor(ctx, actorAddr)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get actor")
@@ -154,10 +238,5 @@ func (chn *ChainStateProvider) GetActorSignature(ctx context.Context, actorAddr
 		return nil, errors.Wrap(err, "failed to load actor code")
 	}
 
-	export, ok := executable.Exports()[method]
-	if !ok {
-		return nil, fmt.Errorf("missing export: %s", method)
-	}
-
-	return export, nil
+	return executable.Exports(), nil
 }
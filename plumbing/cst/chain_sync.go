@@ -10,6 +10,8 @@ import (
 type chainSync interface {
 	HandleNewTipSet(context.Context, *types.ChainInfo, bool) error
 	Status() chain.Status
+	BadTipSets() []*chain.BadTipSet
+	ClearBadTipSets() error
 }
 
 // ChainSyncProvider provides access to chain sync operations and their status.
@@ -30,6 +32,17 @@ func (chs *ChainSyncProvider) Status() chain.Status {
 	return chs.sync.Status()
 }
 
+// BadTipSets returns the tipsets the syncer rejected, with the reason they
+// were rejected and the peer they came from.
+func (chs *ChainSyncProvider) BadTipSets() []*chain.BadTipSet {
+	return chs.sync.BadTipSets()
+}
+
+// ClearBadTipSets forgets the tipsets the syncer rejected.
+func (chs *ChainSyncProvider) ClearBadTipSets() error {
+	return chs.sync.ClearBadTipSets()
+}
+
 // HandleNewTipSet extends the Syncer's chain store with the given tipset if they
 // represent a valid extension. It limits the length of new chains it will
 // attempt to validate and caches invalid blocks it has encountered to
@@ -9,6 +9,7 @@ import (
 
 	"github.com/filecoin-project/go-filecoin/abi"
 	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/chain"
 	"github.com/filecoin-project/go-filecoin/consensus"
 	"github.com/filecoin-project/go-filecoin/state"
 	"github.com/filecoin-project/go-filecoin/types"
@@ -64,3 +65,64 @@ func (p *Previewer) Preview(ctx context.Context, optFrom, to address.Address, me
 	}
 	return usedGas, nil
 }
+
+// Estimate returns the gas used by a message from from sending method of to with value and
+// params, applied to the head state after pending, the messages from the same sender to be
+// applied ahead of it. It returns an error if a pending message fails, as the estimated message
+// would then not be applied with the nonce it is estimated with.
+func (p *Previewer) Estimate(ctx context.Context, pending []*types.SignedMessage, from, to address.Address, value types.AttoFIL, method string, params ...interface{}) (types.GasUnits, error) {
+	encodedParams, err := abi.ToEncodedValues(params...)
+	if err != nil {
+		return types.NewGasUnits(0), errors.Wrap(err, "failed to encode message params")
+	}
+
+	st, err := p.chainReader.GetTipSetState(ctx, p.chainReader.GetHead())
+	if err != nil {
+		return types.NewGasUnits(0), errors.Wrap(err, "failed to load tree for latest state root")
+	}
+	head, err := p.chainReader.GetTipSet(p.chainReader.GetHead())
+	if err != nil {
+		return types.NewGasUnits(0), errors.Wrap(err, "failed to get head tipset ")
+	}
+	h, err := head.Height()
+	if err != nil {
+		return types.NewGasUnits(0), errors.Wrap(err, "failed to get head tipset height")
+	}
+	bh := types.NewBlockHeight(h)
+	ancestors, err := chain.GetRecentAncestors(ctx, head, p.chainReader, bh.Sub(types.NewBlockHeight(consensus.AncestorRoundsNeeded)))
+	if err != nil {
+		return types.NewGasUnits(0), errors.Wrap(err, "failed to get head tipset ancestors")
+	}
+
+	vms := vm.NewStorageMap(p.bs)
+	for _, msg := range pending {
+		if _, err := consensus.EstimateMessageGas(ctx, st, vms, &msg.Message, bh, ancestors); err != nil {
+			c, _ := msg.Cid()
+			return types.NewGasUnits(0), errors.Wrapf(err, "pending message %s failed", c)
+		}
+	}
+
+	methodID := types.SendMethodID
+	if method != "" {
+		toActor, err := st.GetActor(ctx, to)
+		if err != nil {
+			return types.NewGasUnits(0), errors.Wrapf(err, "no actor at address %s", to)
+		}
+		methodID, _, err = vm.LookupMethod(st, toActor.Code, method)
+		if err != nil {
+			return types.NewGasUnits(0), errors.Wrapf(err, "failed to look up method %s of actor at %s", method, to)
+		}
+	}
+
+	fromActor, err := st.GetActor(ctx, from)
+	if err != nil {
+		return types.NewGasUnits(0), errors.Wrapf(err, "no actor at address %s", from)
+	}
+
+	msg := types.NewMessage(from, to, uint64(fromActor.Nonce), value, methodID, encodedParams)
+	usedGas, err := consensus.EstimateMessageGas(ctx, st, vms, msg, bh, ancestors)
+	if err != nil {
+		return types.NewGasUnits(0), errors.Wrap(err, "message execution failed")
+	}
+	return usedGas, nil
+}
@@ -54,3 +54,57 @@ func TestPreview(t *testing.T) {
 		assert.Equal(t, types.NewGasUnits(100), returnValue)
 	})
 }
+
+func TestEstimate(t *testing.T) {
+	tf.BadUnitTestWithSideEffects(t)
+
+	newAddr := address.NewForTestGetter()
+	ctx := context.Background()
+	r := repo.NewInMemoryRepo()
+	bs := bstore.NewBlockstore(r.Datastore())
+
+	signer, _ := types.NewMockSignersAndKeyInfo(1)
+	fromAddr := signer.Addresses[0]
+	fakeActorCodeCid := types.NewCidForTestGetter()()
+	fakeActorAddr := newAddr()
+	toAddr := newAddr()
+	vms := vm.NewStorageMap(bs)
+	fakeActor := th.RequireNewFakeActor(t, vms, fakeActorAddr, fakeActorCodeCid)
+	builtin.Actors[fakeActorCodeCid] = &actor.FakeActor{}
+	defer delete(builtin.Actors, fakeActorCodeCid)
+	testGen := consensus.MakeGenesisFunc(
+		consensus.AddActor(fakeActorAddr, fakeActor),
+		consensus.ActorAccount(fromAddr, types.NewAttoFILFromFIL(10)),
+	)
+	deps := requireCommonDepsWithGifAndBlockstore(t, testGen, r, bs)
+	previewer := NewPreviewer(deps.chainStore, deps.cst, deps.blockstore)
+
+	t.Run("returns gas used by method", func(t *testing.T) {
+		usedGas, err := previewer.Estimate(ctx, nil, fromAddr, fakeActorAddr, types.ZeroAttoFIL, "hasReturnValue")
+		require.NoError(t, err)
+		assert.Equal(t, types.NewGasUnits(100), usedGas)
+	})
+
+	t.Run("applies pending messages first", func(t *testing.T) {
+		_, err := previewer.Estimate(ctx, nil, fromAddr, toAddr, types.NewAttoFILFromFIL(10), "")
+		require.NoError(t, err)
+
+		msg := types.NewMessage(fromAddr, toAddr, 0, types.NewAttoFILFromFIL(10), types.SendMethodID, nil)
+		pending, err := types.NewSignedMessage(*msg, &signer, types.NewGasPrice(1), types.NewGasUnits(100))
+		require.NoError(t, err)
+
+		_, err = previewer.Estimate(ctx, []*types.SignedMessage{pending}, fromAddr, toAddr, types.NewAttoFILFromFIL(10), "")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "message execution failed")
+	})
+
+	t.Run("fails if a pending message fails", func(t *testing.T) {
+		msg := types.NewMessage(fromAddr, toAddr, 0, types.NewAttoFILFromFIL(20), types.SendMethodID, nil)
+		pending, err := types.NewSignedMessage(*msg, &signer, types.NewGasPrice(1), types.NewGasUnits(100))
+		require.NoError(t, err)
+
+		_, err = previewer.Estimate(ctx, []*types.SignedMessage{pending}, fromAddr, fakeActorAddr, types.ZeroAttoFIL, "hasReturnValue")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pending message")
+	})
+}
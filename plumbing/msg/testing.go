@@ -39,6 +39,7 @@ func requireCommonDepsWithGifAndBlockstore(t *testing.T, gif consensus.GenesisIn
 	chainStore, err := chain.Init(context.Background(), r, bs, cst, gif)
 	require.NoError(t, err)
 	messageStore := chain.NewMessageStore(cst)
+	chainStore.SetMessageIndex(chain.NewMessageIndex(r.ChainDatastore(), messageStore))
 	backend, err := wallet.NewDSBackend(r.WalletDatastore())
 	require.NoError(t, err)
 	wallet := wallet.New(backend)
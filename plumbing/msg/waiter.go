@@ -25,6 +25,7 @@ type waiterChainReader interface {
 	GetHead() types.TipSetKey
 	GetTipSet(types.TipSetKey) (types.TipSet, error)
 	GetTipSetState(context.Context, types.TipSetKey) (state.Tree, error)
+	GetMessageLocation(cid.Cid) (*chain.MessageLocation, bool, error)
 	HeadEvents() *pubsub.PubSub
 }
 
@@ -70,10 +71,6 @@ func (w *Waiter) Find(ctx context.Context, msgCid cid.Cid) (*ChainMessage, bool,
 // if in fact that's what it wants to do, using something like receiptFromTipset.
 // Something like receiptFromTipset is necessary because not every message in
 // a block will have a receipt in the tipset: it might be a duplicate message.
-//
-// TODO: This implementation will become prohibitively expensive since it
-// traverses the entire chain. We should use an index instead.
-// https://github.com/filecoin-project/go-filecoin/issues/1518
 func (w *Waiter) Wait(ctx context.Context, msgCid cid.Cid, cb func(*types.Block, *types.SignedMessage, *types.MessageReceipt) error) error {
 	ctx = log.Start(ctx, "Waiter.Wait")
 	defer log.Finish(ctx)
@@ -97,38 +94,123 @@ func (w *Waiter) Wait(ctx context.Context, msgCid cid.Cid, cb func(*types.Block,
 	return err
 }
 
+// Trace replays the tipset that included the message with msgCid and returns
+// the execution trace of the message.
+func (w *Waiter) Trace(ctx context.Context, msgCid cid.Cid) (*vm.ExecutionTrace, error) {
+	headTipSet, err := w.chainReader.GetTipSet(w.chainReader.GetHead())
+	if err != nil {
+		return nil, err
+	}
+	ts, _, _, found, err := w.findMessageTipSet(ctx, headTipSet, msgCid)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("message %s not found in chain", msgCid)
+	}
+
+	res, err := w.processTipSet(ctx, ts, consensus.NewTracingProcessor())
+	if err != nil {
+		return nil, errors.Wrap(err, "error replaying tipset")
+	}
+	if _, failed := res.Failures[msgCid]; failed {
+		return nil, fmt.Errorf("message %s conflicts with another message of its tipset and was not applied", msgCid)
+	}
+
+	j, err := w.msgIndexOfTipSet(ctx, msgCid, ts, res.Failures)
+	if err != nil {
+		return nil, err
+	}
+	if j >= len(res.Results) {
+		return nil, fmt.Errorf("no result for message %s in its tipset", msgCid)
+	}
+	return res.Results[j].Trace, nil
+}
+
 // findMessage looks for a message CID in the chain and returns the message,
 // block and receipt, when it is found. Returns the found message/block or nil
 // if now block with the given CID exists in the chain.
 func (w *Waiter) findMessage(ctx context.Context, ts types.TipSet, msgCid cid.Cid) (*ChainMessage, bool, error) {
+	msgTs, blk, msg, found, err := w.findMessageTipSet(ctx, ts, msgCid)
+	if err != nil || !found {
+		return nil, found, err
+	}
+
+	recpt, err := w.receiptFromTipSet(ctx, msgCid, msgTs)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "error retrieving receipt from tipset")
+	}
+	return &ChainMessage{msg, blk, recpt}, true, nil
+}
+
+// findMessageTipSet looks for a message CID in the chain starting at ts and
+// returns the tipset and block that included it and the message itself.
+// The message index of the chain store is used when there is one, otherwise
+// the chain is traversed.
+func (w *Waiter) findMessageTipSet(ctx context.Context, ts types.TipSet, msgCid cid.Cid) (types.TipSet, *types.Block, *types.SignedMessage, bool, error) {
+	loc, found, err := w.chainReader.GetMessageLocation(msgCid)
+	switch {
+	case err == chain.ErrNoMessageIndex:
+		return w.scanMessageTipSet(ctx, ts, msgCid)
+	case err != nil:
+		return types.UndefTipSet, nil, nil, false, err
+	case !found:
+		return types.UndefTipSet, nil, nil, false, nil
+	}
+	return w.loadMessageLocation(ctx, loc, msgCid)
+}
+
+// scanMessageTipSet traverses the chain starting at ts looking for a message
+// CID.
+func (w *Waiter) scanMessageTipSet(ctx context.Context, ts types.TipSet, msgCid cid.Cid) (types.TipSet, *types.Block, *types.SignedMessage, bool, error) {
 	var err error
 	for iterator := chain.IterAncestors(ctx, w.chainReader, ts); !iterator.Complete(); err = iterator.Next() {
 		if err != nil {
 			log.Errorf("Waiter.Wait: %s", err)
-			return nil, false, err
+			return types.UndefTipSet, nil, nil, false, err
 		}
 		for i := 0; i < iterator.Value().Len(); i++ {
 			blk := iterator.Value().At(i)
 			msgs, err := w.messageProvider.LoadMessages(ctx, blk.Messages)
 			if err != nil {
-				return nil, false, err
+				return types.UndefTipSet, nil, nil, false, err
 			}
 			for _, msg := range msgs {
 				c, err := msg.Cid()
 				if err != nil {
-					return nil, false, err
+					return types.UndefTipSet, nil, nil, false, err
 				}
 				if c.Equals(msgCid) {
-					recpt, err := w.receiptFromTipSet(ctx, msgCid, iterator.Value())
-					if err != nil {
-						return nil, false, errors.Wrap(err, "error retrieving receipt from tipset")
-					}
-					return &ChainMessage{msg, blk, recpt}, true, nil
+					return iterator.Value(), blk, msg, true, nil
 				}
 			}
 		}
 	}
-	return nil, false, nil
+	return types.UndefTipSet, nil, nil, false, nil
+}
+
+// loadMessageLocation loads the tipset, block and message at an indexed
+// message location.
+func (w *Waiter) loadMessageLocation(ctx context.Context, loc *chain.MessageLocation, msgCid cid.Cid) (types.TipSet, *types.Block, *types.SignedMessage, bool, error) {
+	ts, err := w.chainReader.GetTipSet(loc.TipSet)
+	if err != nil {
+		return types.UndefTipSet, nil, nil, false, errors.Wrapf(err, "failed to load tipset of message %s", msgCid)
+	}
+	for i := 0; i < ts.Len(); i++ {
+		blk := ts.At(i)
+		if !blk.Cid().Equals(loc.Block) {
+			continue
+		}
+		msgs, err := w.messageProvider.LoadMessages(ctx, blk.Messages)
+		if err != nil {
+			return types.UndefTipSet, nil, nil, false, err
+		}
+		if loc.Index >= len(msgs) {
+			break
+		}
+		return ts, blk, msgs[loc.Index], true, nil
+	}
+	return types.UndefTipSet, nil, nil, false, fmt.Errorf("indexed location of message %s is not in tipset %s", msgCid, loc.TipSet)
 }
 
 // waitForMessage looks for a message CID in a channel of tipsets and returns
@@ -206,6 +288,31 @@ func (w *Waiter) receiptFromTipSet(ctx context.Context, msgCid cid.Cid, ts types
 	}
 
 	// Apply all the tipset's messages to determine the correct receipts.
+	res, err := w.processTipSet(ctx, ts, consensus.NewDefaultProcessor())
+	if err != nil {
+		return nil, err
+	}
+
+	// If this is a failing conflict message there is no application receipt.
+	_, failed := res.Failures[msgCid]
+	if failed {
+		return nil, nil
+	}
+
+	j, err := w.msgIndexOfTipSet(ctx, msgCid, ts, res.Failures)
+	if err != nil {
+		return nil, err
+	}
+	// TODO #3194: out of bounds receipt index should return an error.
+	if j < len(res.Results) {
+		rcpt = res.Results[j].Receipt
+	}
+	return rcpt, nil
+}
+
+// processTipSet applies the messages of ts on top of the state of its parent
+// with the given processor.
+func (w *Waiter) processTipSet(ctx context.Context, ts types.TipSet, processor *consensus.DefaultProcessor) (*consensus.ProcessTipSetResponse, error) {
 	ids, err := ts.Parents()
 	if err != nil {
 		return nil, err
@@ -239,26 +346,7 @@ func (w *Waiter) receiptFromTipSet(ctx context.Context, msgCid cid.Cid, ts types
 		tsMessages = append(tsMessages, msgs)
 	}
 
-	res, err := consensus.NewDefaultProcessor().ProcessTipSet(ctx, st, vm.NewStorageMap(w.bs), ts, tsMessages, ancestors)
-	if err != nil {
-		return nil, err
-	}
-
-	// If this is a failing conflict message there is no application receipt.
-	_, failed := res.Failures[msgCid]
-	if failed {
-		return nil, nil
-	}
-
-	j, err := w.msgIndexOfTipSet(ctx, msgCid, ts, res.Failures)
-	if err != nil {
-		return nil, err
-	}
-	// TODO #3194: out of bounds receipt index should return an error.
-	if j < len(res.Results) {
-		rcpt = res.Results[j].Receipt
-	}
-	return rcpt, nil
+	return processor.ProcessTipSet(ctx, st, vm.NewStorageMap(w.bs), ts, tsMessages, ancestors)
 }
 
 // msgIndexOfTipSet returns the order in which msgCid appears in the canonical
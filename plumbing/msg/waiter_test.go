@@ -149,11 +149,11 @@ func TestWaitConflicting(t *testing.T) {
 	cst, chainStore, msgStore, waiter := setupTestWithGif(t, testGen)
 
 	// Create conflicting messages
-	m1 := types.NewMessage(addr1, addr3, 0, types.NewAttoFILFromFIL(6000), "", nil)
+	m1 := types.NewMessage(addr1, addr3, 0, types.NewAttoFILFromFIL(6000), types.SendMethodID, nil)
 	sm1, err := types.NewSignedMessage(*m1, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
 
-	m2 := types.NewMessage(addr1, addr2, 0, types.NewAttoFILFromFIL(6000), "", nil)
+	m2 := types.NewMessage(addr1, addr2, 0, types.NewAttoFILFromFIL(6000), types.SendMethodID, nil)
 	sm2, err := types.NewSignedMessage(*m2, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
 
@@ -11,7 +11,9 @@ import (
 	"github.com/libp2p/go-libp2p-core/peer"
 
 	minerActor "github.com/filecoin-project/go-filecoin/actor/builtin/miner"
+	"github.com/filecoin-project/go-filecoin/actor/builtin/multisig"
 	"github.com/filecoin-project/go-filecoin/actor/builtin/paymentbroker"
+	"github.com/filecoin-project/go-filecoin/actor/builtin/storagemarket"
 	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/plumbing"
 	"github.com/filecoin-project/go-filecoin/protocol/storage/storagedeal"
@@ -88,6 +90,12 @@ func (a *API) DealsLs(ctx context.Context) (<-chan *StorageDealLsResult, error)
 	return DealsLs(ctx, a)
 }
 
+// GasEstimate recommends a gas price and gas limit for a message from from sending method of to
+// with value and params.
+func (a *API) GasEstimate(ctx context.Context, from, to address.Address, value types.AttoFIL, method string, params ...interface{}) (types.AttoFIL, types.GasUnits, error) {
+	return GasEstimate(ctx, a, from, to, value, method, params...)
+}
+
 // MessagePoolWait waits for the message pool to have at least messageCount unmined messages.
 // It's useful for integration testing.
 func (a *API) MessagePoolWait(ctx context.Context, messageCount uint) ([]*types.SignedMessage, error) {
@@ -117,9 +125,10 @@ func (a *API) MinerPreviewCreate(
 	return MinerPreviewCreate(ctx, a, fromAddr, sectorSize, pid)
 }
 
-// MinerGetAsk queries for an ask of the given miner
-func (a *API) MinerGetState(ctx context.Context, minerAddr address.Address) (minerActor.State, error) {
-	return MinerGetState(ctx, a, minerAddr)
+// MinerGetState queries for the state of the given miner at the tipset with
+// key baseKey.
+func (a *API) MinerGetState(ctx context.Context, minerAddr address.Address, baseKey types.TipSetKey) (minerActor.State, error) {
+	return MinerGetState(ctx, a, minerAddr, baseKey)
 }
 
 // MinerGetAsk queries for an ask of the given miner
@@ -128,8 +137,8 @@ func (a *API) MinerGetAsk(ctx context.Context, minerAddr address.Address, askID
 }
 
 // MinerGetOwnerAddress queries for the owner address of the given miner
-func (a *API) MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address) (address.Address, error) {
-	return MinerGetOwnerAddress(ctx, a, minerAddr)
+func (a *API) MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address, baseKey types.TipSetKey) (address.Address, error) {
+	return MinerGetOwnerAddress(ctx, a, minerAddr, baseKey)
 }
 
 // MinerGetWorkerAddress queries for the worker address of the given miner
@@ -163,18 +172,18 @@ func (a *API) MinerSetPrice(ctx context.Context, from address.Address, miner add
 }
 
 // MinerGetPower queries for the power of the given miner
-func (a *API) MinerGetPower(ctx context.Context, minerAddr address.Address) (MinerPower, error) {
-	return MinerGetPower(ctx, a, minerAddr)
+func (a *API) MinerGetPower(ctx context.Context, minerAddr address.Address, baseKey types.TipSetKey) (MinerPower, error) {
+	return MinerGetPower(ctx, a, minerAddr, baseKey)
 }
 
 // MinerGetProvingWindow queries for the proving period of the given miner
-func (a *API) MinerGetProvingWindow(ctx context.Context, minerAddr address.Address) (MinerProvingWindow, error) {
-	return MinerGetProvingWindow(ctx, a, minerAddr)
+func (a *API) MinerGetProvingWindow(ctx context.Context, minerAddr address.Address, baseKey types.TipSetKey) (MinerProvingWindow, error) {
+	return MinerGetProvingWindow(ctx, a, minerAddr, baseKey)
 }
 
 // MinerGetCollateral queries for the proving period of the given miner
-func (a *API) MinerGetCollateral(ctx context.Context, minerAddr address.Address) (types.AttoFIL, error) {
-	return MinerGetCollateral(ctx, a, minerAddr)
+func (a *API) MinerGetCollateral(ctx context.Context, minerAddr address.Address, baseKey types.TipSetKey) (types.AttoFIL, error) {
+	return MinerGetCollateral(ctx, a, minerAddr, baseKey)
 }
 
 // MinerPreviewSetPrice calculates the amount of Gas needed for a call to MinerSetPrice.
@@ -194,9 +203,10 @@ func (a *API) ProtocolParameters(ctx context.Context) (*ProtocolParams, error) {
 	return ProtocolParameters(ctx, a)
 }
 
-// WalletBalance returns the current balance of the given wallet address.
-func (a *API) WalletBalance(ctx context.Context, address address.Address) (types.AttoFIL, error) {
-	return WalletBalance(ctx, a, address)
+// WalletBalance returns the balance of the given wallet address at the tipset
+// with key baseKey.
+func (a *API) WalletBalance(ctx context.Context, address address.Address, baseKey types.TipSetKey) (types.AttoFIL, error) {
+	return WalletBalance(ctx, a, address, baseKey)
 }
 
 // WalletDefaultAddress returns a default wallet address from the config.
@@ -210,8 +220,9 @@ func (a *API) PaymentChannelLs(
 	ctx context.Context,
 	fromAddr address.Address,
 	payerAddr address.Address,
+	baseKey types.TipSetKey,
 ) (map[string]*paymentbroker.PaymentChannel, error) {
-	return PaymentChannelLs(ctx, a, fromAddr, payerAddr)
+	return PaymentChannelLs(ctx, a, fromAddr, payerAddr, baseKey)
 }
 
 // PaymentChannelVoucher returns a signed payment channel voucher
@@ -219,11 +230,14 @@ func (a *API) PaymentChannelVoucher(
 	ctx context.Context,
 	fromAddr address.Address,
 	channel *types.ChannelID,
+	lane uint64,
+	nonce uint64,
 	amount types.AttoFIL,
 	validAt *types.BlockHeight,
+	merges []types.Merge,
 	condition *types.Predicate,
 ) (voucher *types.PaymentVoucher, err error) {
-	return PaymentChannelVoucher(ctx, a, fromAddr, channel, amount, validAt, condition)
+	return PaymentChannelVoucher(ctx, a, fromAddr, channel, lane, nonce, amount, validAt, merges, condition)
 }
 
 // ClientListAsks returns a channel with asks from the latest chain state
@@ -265,3 +279,118 @@ func (a *API) PingMinerWithTimeout(
 func (a *API) MinerSetWorkerAddress(ctx context.Context, toAddr address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits) (cid.Cid, error) {
 	return MinerSetWorkerAddress(ctx, a, toAddr, gasPrice, gasLimit)
 }
+
+// MinerWithdraw withdraws balance in excess of the pledge requirement from the miner to its owner
+func (a *API) MinerWithdraw(ctx context.Context, minerAddr address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, amount types.AttoFIL) (cid.Cid, error) {
+	return MinerWithdraw(ctx, a, minerAddr, gasPrice, gasLimit, amount)
+}
+
+// MinerPreviewWithdraw calculates the amount of Gas needed for a call to MinerWithdraw.
+func (a *API) MinerPreviewWithdraw(ctx context.Context, minerAddr address.Address, amount types.AttoFIL) (types.GasUnits, error) {
+	return MinerPreviewWithdraw(ctx, a, minerAddr, amount)
+}
+
+// MinerProposeOwner proposes a new owner for the miner, to be confirmed with MinerAcceptOwner
+func (a *API) MinerProposeOwner(ctx context.Context, minerAddr address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, newOwner address.Address) (cid.Cid, error) {
+	return MinerProposeOwner(ctx, a, minerAddr, gasPrice, gasLimit, newOwner)
+}
+
+// MinerAcceptOwner accepts a proposed ownership transfer of the miner
+func (a *API) MinerAcceptOwner(ctx context.Context, from address.Address, minerAddr address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits) (cid.Cid, error) {
+	return MinerAcceptOwner(ctx, a, from, minerAddr, gasPrice, gasLimit)
+}
+
+// MultisigCreate creates a multisig wallet and returns its address
+func (a *API) MultisigCreate(
+	ctx context.Context,
+	from address.Address,
+	gasPrice types.AttoFIL,
+	gasLimit types.GasUnits,
+	signers []address.Address,
+	required uint64,
+	unlockDuration *types.BlockHeight,
+	value types.AttoFIL,
+) (address.Address, error) {
+	return MultisigCreate(ctx, a, from, gasPrice, gasLimit, signers, required, unlockDuration, value)
+}
+
+// MultisigPropose proposes a transaction from a multisig wallet and returns its id
+func (a *API) MultisigPropose(
+	ctx context.Context,
+	from address.Address,
+	msigAddr address.Address,
+	gasPrice types.AttoFIL,
+	gasLimit types.GasUnits,
+	to address.Address,
+	value types.AttoFIL,
+	method string,
+	params ...interface{},
+) (uint64, error) {
+	return MultisigPropose(ctx, a, from, msigAddr, gasPrice, gasLimit, to, value, method, params...)
+}
+
+// MultisigApprove approves a pending multisig transaction
+func (a *API) MultisigApprove(ctx context.Context, from address.Address, msigAddr address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, txID uint64) (cid.Cid, error) {
+	return MultisigApprove(ctx, a, from, msigAddr, gasPrice, gasLimit, txID)
+}
+
+// MultisigCancel cancels a pending multisig transaction
+func (a *API) MultisigCancel(ctx context.Context, from address.Address, msigAddr address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, txID uint64) (cid.Cid, error) {
+	return MultisigCancel(ctx, a, from, msigAddr, gasPrice, gasLimit, txID)
+}
+
+// MultisigGetState queries the state of a multisig wallet
+func (a *API) MultisigGetState(ctx context.Context, msigAddr address.Address, baseKey types.TipSetKey) (*multisig.State, error) {
+	return MultisigGetState(ctx, a, msigAddr, baseKey)
+}
+
+// MarketProposeDeal publishes a storage deal to the storage market and returns its id
+func (a *API) MarketProposeDeal(
+	ctx context.Context,
+	from address.Address,
+	gasPrice types.AttoFIL,
+	gasLimit types.GasUnits,
+	minerAddr address.Address,
+	commP []byte,
+	size *types.BytesAmount,
+	duration *types.BlockHeight,
+	totalPrice types.AttoFIL,
+	collateral types.AttoFIL,
+) (uint64, error) {
+	return MarketProposeDeal(ctx, a, from, gasPrice, gasLimit, minerAddr, commP, size, duration, totalPrice, collateral)
+}
+
+// MarketAcceptDeal accepts a published storage deal as the deal's miner
+func (a *API) MarketAcceptDeal(ctx context.Context, from address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, dealID uint64) (cid.Cid, error) {
+	return MarketAcceptDeal(ctx, a, from, gasPrice, gasLimit, dealID)
+}
+
+// MarketCancelDeal cancels a published storage deal that has not been accepted
+func (a *API) MarketCancelDeal(ctx context.Context, from address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, dealID uint64) (cid.Cid, error) {
+	return MarketCancelDeal(ctx, a, from, gasPrice, gasLimit, dealID)
+}
+
+// MarketSealDeal reports the sector an accepted storage deal has been sealed into
+func (a *API) MarketSealDeal(ctx context.Context, from address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, dealID uint64, sectorID uint64) (cid.Cid, error) {
+	return MarketSealDeal(ctx, a, from, gasPrice, gasLimit, dealID, sectorID)
+}
+
+// MarketGetDeal queries a published storage deal
+func (a *API) MarketGetDeal(ctx context.Context, dealID uint64, baseKey types.TipSetKey) (*storagemarket.Deal, error) {
+	return MarketGetDeal(ctx, a, dealID, baseKey)
+}
+
+// MarketAddBalance adds funds to a storage market escrow balance
+func (a *API) MarketAddBalance(ctx context.Context, from address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, addr address.Address, amount types.AttoFIL) (cid.Cid, error) {
+	return MarketAddBalance(ctx, a, from, gasPrice, gasLimit, addr, amount)
+}
+
+// MarketWithdrawBalance withdraws available funds from a storage market escrow balance
+func (a *API) MarketWithdrawBalance(ctx context.Context, from address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, addr address.Address, amount types.AttoFIL) (cid.Cid, error) {
+	return MarketWithdrawBalance(ctx, a, from, gasPrice, gasLimit, addr, amount)
+}
+
+// MarketGetBalance queries a storage market escrow balance
+func (a *API) MarketGetBalance(ctx context.Context, addr address.Address, baseKey types.TipSetKey) (*storagemarket.Balance, error) {
+	return MarketGetBalance(ctx, a, addr, baseKey)
+}
@@ -27,7 +27,7 @@ type Ask struct {
 }
 
 type claPlubming interface {
-	ActorLs(ctx context.Context) (<-chan state.GetAllActorsResult, error)
+	ActorLs(ctx context.Context, baseKey types.TipSetKey) (<-chan state.GetAllActorsResult, error)
 	ChainHeadKey() types.TipSetKey
 	MessageQuery(ctx context.Context, optFrom, to address.Address, method string, baseKey types.TipSetKey, params ...interface{}) ([][]byte, error)
 }
@@ -38,7 +38,7 @@ func ClientListAsks(ctx context.Context, plumbing claPlubming) <-chan Ask {
 
 	go func() {
 		defer close(out)
-		actorCh, err := plumbing.ActorLs(ctx)
+		actorCh, err := plumbing.ActorLs(ctx, plumbing.ChainHeadKey())
 		if err != nil {
 			out <- Ask{
 				Error: err,
@@ -26,7 +26,7 @@ type claPlumbing struct {
 	MinerAddress address.Address
 }
 
-func (cla *claPlumbing) ActorLs(ctx context.Context) (<-chan state.GetAllActorsResult, error) {
+func (cla *claPlumbing) ActorLs(ctx context.Context, _ types.TipSetKey) (<-chan state.GetAllActorsResult, error) {
 	out := make(chan state.GetAllActorsResult)
 
 	if cla.actorFail {
@@ -0,0 +1,91 @@
+package porcelain
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/message"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+const (
+	// GasLimitHeadroomPercent is the percentage by which an estimated gas limit exceeds the gas
+	// the message uses applied to the head state, to allow for the state to change before it is mined.
+	GasLimitHeadroomPercent = 25
+	// GasPriceSampleTipSets is the number of tipsets, from the head back, whose messages an estimated
+	// gas price is derived from.
+	GasPriceSampleTipSets = 10
+)
+
+// MinGasPrice is the gas price estimated when recent blocks contain no messages.
+var MinGasPrice = types.NewGasPrice(1)
+
+// gasEstimatePlumbing is the subset of the plumbing.API that GasEstimate uses.
+type gasEstimatePlumbing interface {
+	ChainHeadKey() types.TipSetKey
+	ChainTipSet(key types.TipSetKey) (types.TipSet, error)
+	ChainGetMessages(context.Context, cid.Cid) ([]*types.SignedMessage, error)
+	MessageEstimateGas(ctx context.Context, pending []*types.SignedMessage, from, to address.Address, value types.AttoFIL, method string, params ...interface{}) (types.GasUnits, error)
+	OutboxQueueLs(sender address.Address) []*message.Queued
+}
+
+// GasEstimate recommends a gas price and gas limit for a message from from sending method of to
+// with value and params. The gas limit is the gas used by the message applied to the head state,
+// after the messages from the same sender in the outbound queue, with GasLimitHeadroomPercent
+// headroom. The gas price is the median gas price of the messages in recent blocks.
+func GasEstimate(ctx context.Context, plumbing gasEstimatePlumbing, from, to address.Address, value types.AttoFIL, method string, params ...interface{}) (types.AttoFIL, types.GasUnits, error) {
+	var pending []*types.SignedMessage
+	for _, qm := range plumbing.OutboxQueueLs(from) {
+		pending = append(pending, qm.Msg)
+	}
+
+	usedGas, err := plumbing.MessageEstimateGas(ctx, pending, from, to, value, method, params...)
+	if err != nil {
+		return types.ZeroAttoFIL, types.NewGasUnits(0), errors.Wrap(err, "failed to estimate gas limit")
+	}
+	gasLimit := types.NewGasUnits((uint64(usedGas)*(100+GasLimitHeadroomPercent) + 99) / 100)
+	if gasLimit > types.BlockGasLimit {
+		gasLimit = types.BlockGasLimit
+	}
+
+	gasPrice, err := recentGasPrice(ctx, plumbing)
+	if err != nil {
+		return types.ZeroAttoFIL, types.NewGasUnits(0), errors.Wrap(err, "failed to estimate gas price")
+	}
+	return gasPrice, gasLimit, nil
+}
+
+// recentGasPrice returns the median gas price of the messages in the GasPriceSampleTipSets
+// tipsets ending at the head, or MinGasPrice if they contain no messages.
+func recentGasPrice(ctx context.Context, plumbing gasEstimatePlumbing) (types.AttoFIL, error) {
+	var prices []types.AttoFIL
+	key := plumbing.ChainHeadKey()
+	for i := 0; i < GasPriceSampleTipSets && !key.Empty(); i++ {
+		ts, err := plumbing.ChainTipSet(key)
+		if err != nil {
+			return types.ZeroAttoFIL, err
+		}
+		for j := 0; j < ts.Len(); j++ {
+			msgs, err := plumbing.ChainGetMessages(ctx, ts.At(j).Messages)
+			if err != nil {
+				return types.ZeroAttoFIL, err
+			}
+			for _, msg := range msgs {
+				prices = append(prices, msg.GasPrice)
+			}
+		}
+		if key, err = ts.Parents(); err != nil {
+			return types.ZeroAttoFIL, err
+		}
+	}
+
+	if len(prices) == 0 {
+		return MinGasPrice, nil
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i].LessThan(prices[j]) })
+	return prices[len(prices)/2], nil
+}
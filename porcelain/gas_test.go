@@ -0,0 +1,143 @@
+package porcelain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/message"
+	"github.com/filecoin-project/go-filecoin/porcelain"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+type fakeGasEstimatePlumbing struct {
+	head     types.TipSetKey
+	tipSets  map[string]types.TipSet
+	messages map[cid.Cid][]*types.SignedMessage
+	queued   []*message.Queued
+	usedGas  types.GasUnits
+	newCid   func() cid.Cid
+
+	estimatedPending []*types.SignedMessage
+}
+
+func newFakeGasEstimatePlumbing() *fakeGasEstimatePlumbing {
+	return &fakeGasEstimatePlumbing{
+		tipSets:  map[string]types.TipSet{},
+		messages: map[cid.Cid][]*types.SignedMessage{},
+		newCid:   types.NewCidForTestGetter(),
+	}
+}
+
+// addTipSet adds a tipset of a single block containing msgs on top of the head.
+func (p *fakeGasEstimatePlumbing) addTipSet(t *testing.T, msgs ...*types.SignedMessage) {
+	messagesCid := p.newCid()
+	p.messages[messagesCid] = msgs
+	ts := types.RequireNewTipSet(t, &types.Block{
+		Height:   types.Uint64(len(p.tipSets)),
+		Parents:  p.head,
+		Messages: messagesCid,
+	})
+	p.tipSets[ts.Key().String()] = ts
+	p.head = ts.Key()
+}
+
+func (p *fakeGasEstimatePlumbing) ChainHeadKey() types.TipSetKey {
+	return p.head
+}
+
+func (p *fakeGasEstimatePlumbing) ChainTipSet(key types.TipSetKey) (types.TipSet, error) {
+	ts, ok := p.tipSets[key.String()]
+	if !ok {
+		return types.UndefTipSet, errors.New("no such tipset")
+	}
+	return ts, nil
+}
+
+func (p *fakeGasEstimatePlumbing) ChainGetMessages(ctx context.Context, c cid.Cid) ([]*types.SignedMessage, error) {
+	return p.messages[c], nil
+}
+
+func (p *fakeGasEstimatePlumbing) MessageEstimateGas(ctx context.Context, pending []*types.SignedMessage, from, to address.Address, value types.AttoFIL, method string, params ...interface{}) (types.GasUnits, error) {
+	p.estimatedPending = pending
+	return p.usedGas, nil
+}
+
+func (p *fakeGasEstimatePlumbing) OutboxQueueLs(sender address.Address) []*message.Queued {
+	return p.queued
+}
+
+func TestGasEstimate(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	signer, _ := types.NewMockSignersAndKeyInfo(2)
+	from := signer.Addresses[0]
+	to := signer.Addresses[1]
+
+	msgWithGasPrice := func(nonce uint64, price int64) *types.SignedMessage {
+		msg := types.NewMessage(from, to, nonce, types.ZeroAttoFIL, types.SendMethodID, nil)
+		smsg, err := types.NewSignedMessage(*msg, &signer, types.NewGasPrice(price), types.NewGasUnits(100))
+		require.NoError(t, err)
+		return smsg
+	}
+
+	t.Run("adds headroom to the gas used", func(t *testing.T) {
+		plumbing := newFakeGasEstimatePlumbing()
+		plumbing.addTipSet(t)
+		plumbing.usedGas = types.NewGasUnits(101)
+
+		_, gasLimit, err := porcelain.GasEstimate(ctx, plumbing, from, to, types.ZeroAttoFIL, "")
+		require.NoError(t, err)
+		assert.Equal(t, types.NewGasUnits(127), gasLimit)
+
+		plumbing.usedGas = types.BlockGasLimit
+		_, gasLimit, err = porcelain.GasEstimate(ctx, plumbing, from, to, types.ZeroAttoFIL, "")
+		require.NoError(t, err)
+		assert.Equal(t, types.BlockGasLimit, gasLimit)
+	})
+
+	t.Run("applies queued messages first", func(t *testing.T) {
+		plumbing := newFakeGasEstimatePlumbing()
+		plumbing.addTipSet(t)
+		queued := []*types.SignedMessage{msgWithGasPrice(0, 1), msgWithGasPrice(1, 1)}
+		for i, msg := range queued {
+			plumbing.queued = append(plumbing.queued, &message.Queued{Msg: msg, Stamp: uint64(i)})
+		}
+
+		_, _, err := porcelain.GasEstimate(ctx, plumbing, from, to, types.ZeroAttoFIL, "")
+		require.NoError(t, err)
+		assert.Equal(t, queued, plumbing.estimatedPending)
+	})
+
+	t.Run("gas price is the median of recent messages", func(t *testing.T) {
+		plumbing := newFakeGasEstimatePlumbing()
+		plumbing.addTipSet(t, msgWithGasPrice(0, 1000))
+		for i := 0; i < porcelain.GasPriceSampleTipSets-1; i++ {
+			plumbing.addTipSet(t)
+		}
+		plumbing.addTipSet(t, msgWithGasPrice(1, 5), msgWithGasPrice(2, 2))
+		plumbing.addTipSet(t, msgWithGasPrice(3, 3))
+
+		// The message paying 1000 is older than the sampled tipsets.
+		gasPrice, _, err := porcelain.GasEstimate(ctx, plumbing, from, to, types.ZeroAttoFIL, "")
+		require.NoError(t, err)
+		assert.Equal(t, types.NewGasPrice(3), gasPrice)
+	})
+
+	t.Run("gas price defaults to the minimum", func(t *testing.T) {
+		plumbing := newFakeGasEstimatePlumbing()
+		plumbing.addTipSet(t)
+		plumbing.addTipSet(t)
+
+		gasPrice, _, err := porcelain.GasEstimate(ctx, plumbing, from, to, types.ZeroAttoFIL, "")
+		require.NoError(t, err)
+		assert.Equal(t, porcelain.MinGasPrice, gasPrice)
+	})
+}
@@ -0,0 +1,236 @@
+package porcelain
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/actor/builtin/storagemarket"
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/types"
+	vmErrors "github.com/filecoin-project/go-filecoin/vm/errors"
+)
+
+// marketSendAPI is the subset of the plumbing.API that the storage market
+// calls which send messages use.
+type marketSendAPI interface {
+	MessageSend(ctx context.Context, from, to address.Address, value types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method string, params ...interface{}) (cid.Cid, error)
+	MessageWait(ctx context.Context, msgCid cid.Cid, cb func(*types.Block, *types.SignedMessage, *types.MessageReceipt) error) error
+	WalletDefaultAddress() (address.Address, error)
+}
+
+// marketDealAPI is the subset of the plumbing.API that the storage market
+// calls which draw on escrow balances use.
+type marketDealAPI interface {
+	marketSendAPI
+	marketQueryAPI
+}
+
+// MarketProposeDeal publishes a storage deal with minerAddr to the storage
+// market and returns the id assigned to it once the proposal has been mined.
+// The total price of the deal is locked in the client's escrow balance until
+// the deal is paid out or cancelled. Only the part of the price not covered
+// by the client's available escrow balance is sent with the message.
+func MarketProposeDeal(
+	ctx context.Context,
+	plumbing marketDealAPI,
+	from address.Address,
+	gasPrice types.AttoFIL,
+	gasLimit types.GasUnits,
+	minerAddr address.Address,
+	commP []byte,
+	size *types.BytesAmount,
+	duration *types.BlockHeight,
+	totalPrice types.AttoFIL,
+	collateral types.AttoFIL,
+) (_ uint64, err error) {
+	if from.Empty() {
+		from, err = plumbing.WalletDefaultAddress()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	value, err := marketTopUp(ctx, plumbing, from, totalPrice)
+	if err != nil {
+		return 0, err
+	}
+
+	msgCid, err := plumbing.MessageSend(
+		ctx,
+		from,
+		address.StorageMarketAddress,
+		value,
+		gasPrice,
+		gasLimit,
+		"proposeDeal",
+		minerAddr,
+		commP,
+		size,
+		duration,
+		totalPrice,
+		collateral,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var dealID uint64
+	err = plumbing.MessageWait(ctx, msgCid, func(blk *types.Block, smsg *types.SignedMessage, receipt *types.MessageReceipt) error {
+		if receipt.ExitCode != uint8(0) {
+			return vmErrors.VMExitCodeToError(receipt.ExitCode, storagemarket.Errors)
+		}
+		dealID = big.NewInt(0).SetBytes(receipt.Return[0]).Uint64()
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return dealID, nil
+}
+
+// MarketAcceptDeal accepts a published storage deal on behalf of the deal's
+// miner and returns the cid of the accepting message. The deal collateral is
+// locked in the miner's escrow balance, and only the part of it not covered by
+// the miner's available escrow balance is sent with the message. from must be
+// the worker of the deal's miner.
+func MarketAcceptDeal(ctx context.Context, plumbing marketDealAPI, from address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, dealID uint64) (_ cid.Cid, err error) {
+	if from.Empty() {
+		from, err = plumbing.WalletDefaultAddress()
+		if err != nil {
+			return cid.Undef, err
+		}
+	}
+
+	deal, err := MarketGetDeal(ctx, plumbing, dealID, plumbing.ChainHeadKey())
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	value, err := marketTopUp(ctx, plumbing, deal.Miner, deal.Collateral)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	return plumbing.MessageSend(ctx, from, address.StorageMarketAddress, value, gasPrice, gasLimit, "acceptDeal", big.NewInt(0).SetUint64(dealID))
+}
+
+// MarketCancelDeal cancels a published storage deal that has not yet been
+// accepted and returns the cid of the cancelling message.
+func MarketCancelDeal(ctx context.Context, plumbing marketSendAPI, from address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, dealID uint64) (_ cid.Cid, err error) {
+	if from.Empty() {
+		from, err = plumbing.WalletDefaultAddress()
+		if err != nil {
+			return cid.Undef, err
+		}
+	}
+
+	return plumbing.MessageSend(ctx, from, address.StorageMarketAddress, types.ZeroAttoFIL, gasPrice, gasLimit, "cancelDeal", big.NewInt(0).SetUint64(dealID))
+}
+
+// MarketSealDeal reports the committed sector an accepted storage deal's piece
+// has been sealed into and returns the cid of the message. Payment for the deal
+// is only released to the miner while that sector is proven. from must be the
+// worker of the deal's miner.
+func MarketSealDeal(ctx context.Context, plumbing marketSendAPI, from address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, dealID uint64, sectorID uint64) (_ cid.Cid, err error) {
+	if from.Empty() {
+		from, err = plumbing.WalletDefaultAddress()
+		if err != nil {
+			return cid.Undef, err
+		}
+	}
+
+	return plumbing.MessageSend(ctx, from, address.StorageMarketAddress, types.ZeroAttoFIL, gasPrice, gasLimit, "sealDeal", big.NewInt(0).SetUint64(dealID), sectorID)
+}
+
+// MarketAddBalance adds amount to the storage market escrow balance of addr
+// and returns the cid of the message. If addr is empty the balance of the
+// sender is credited.
+func MarketAddBalance(ctx context.Context, plumbing marketSendAPI, from address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, addr address.Address, amount types.AttoFIL) (_ cid.Cid, err error) {
+	if from.Empty() {
+		from, err = plumbing.WalletDefaultAddress()
+		if err != nil {
+			return cid.Undef, err
+		}
+	}
+
+	if addr.Empty() {
+		addr = from
+	}
+
+	return plumbing.MessageSend(ctx, from, address.StorageMarketAddress, amount, gasPrice, gasLimit, "addBalance", addr)
+}
+
+// MarketWithdrawBalance withdraws amount from the available storage market
+// escrow balance of addr and returns the cid of the message. If addr is empty
+// the balance of the sender is withdrawn. The balance of a miner may only be
+// withdrawn by its owner.
+func MarketWithdrawBalance(ctx context.Context, plumbing marketSendAPI, from address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, addr address.Address, amount types.AttoFIL) (_ cid.Cid, err error) {
+	if from.Empty() {
+		from, err = plumbing.WalletDefaultAddress()
+		if err != nil {
+			return cid.Undef, err
+		}
+	}
+
+	if addr.Empty() {
+		addr = from
+	}
+
+	return plumbing.MessageSend(ctx, from, address.StorageMarketAddress, types.ZeroAttoFIL, gasPrice, gasLimit, "withdrawBalance", addr, amount)
+}
+
+// marketQueryAPI is the subset of the plumbing.API that the storage market
+// queries use.
+type marketQueryAPI interface {
+	ChainHeadKey() types.TipSetKey
+	MessageQuery(ctx context.Context, optFrom, to address.Address, method string, baseKey types.TipSetKey, params ...interface{}) ([][]byte, error)
+}
+
+// MarketGetDeal queries the storage market for the published deal with the
+// given id at the tipset with key baseKey.
+func MarketGetDeal(ctx context.Context, plumbing marketQueryAPI, dealID uint64, baseKey types.TipSetKey) (*storagemarket.Deal, error) {
+	ret, err := plumbing.MessageQuery(ctx, address.Undef, address.StorageMarketAddress, "getDeal", baseKey, big.NewInt(0).SetUint64(dealID))
+	if err != nil {
+		return nil, errors.Wrap(err, "'getDeal' query message failed")
+	}
+
+	var deal storagemarket.Deal
+	if err := cbor.DecodeInto(ret[0], &deal); err != nil {
+		return nil, errors.Wrap(err, "could not decode deal")
+	}
+
+	return &deal, nil
+}
+
+// MarketGetBalance queries the storage market for the escrow balance of addr
+// at the tipset with key baseKey.
+func MarketGetBalance(ctx context.Context, plumbing marketQueryAPI, addr address.Address, baseKey types.TipSetKey) (*storagemarket.Balance, error) {
+	ret, err := plumbing.MessageQuery(ctx, address.Undef, address.StorageMarketAddress, "getBalance", baseKey, addr)
+	if err != nil {
+		return nil, errors.Wrap(err, "'getBalance' query message failed")
+	}
+
+	return &storagemarket.Balance{
+		Available: types.NewAttoFILFromBytes(ret[0]),
+		Locked:    types.NewAttoFILFromBytes(ret[1]),
+	}, nil
+}
+
+// marketTopUp returns the amount that must be added to the escrow balance of
+// addr for its available balance to cover amount.
+func marketTopUp(ctx context.Context, plumbing marketQueryAPI, addr address.Address, amount types.AttoFIL) (types.AttoFIL, error) {
+	balance, err := MarketGetBalance(ctx, plumbing, addr, plumbing.ChainHeadKey())
+	if err != nil {
+		return types.ZeroAttoFIL, err
+	}
+
+	if balance.Available.GreaterEqual(amount) {
+		return types.ZeroAttoFIL, nil
+	}
+	return amount.Sub(balance.Available), nil
+}
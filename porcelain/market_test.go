@@ -0,0 +1,193 @@
+package porcelain_test
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/actor/builtin/storagemarket"
+	"github.com/filecoin-project/go-filecoin/address"
+	. "github.com/filecoin-project/go-filecoin/porcelain"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+type marketPlumbing struct {
+	testing *testing.T
+
+	receipt *types.MessageReceipt
+	msgCid  cid.Cid
+
+	balance *storagemarket.Balance
+	deal    *storagemarket.Deal
+
+	sentTo     address.Address
+	sentValue  types.AttoFIL
+	sentMethod string
+	sentParams []interface{}
+}
+
+func (mp *marketPlumbing) MessageSend(ctx context.Context, from, to address.Address, value types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method string, params ...interface{}) (cid.Cid, error) {
+	mp.sentTo = to
+	mp.sentValue = value
+	mp.sentMethod = method
+	mp.sentParams = params
+	mp.msgCid = types.NewCidForTestGetter()()
+	return mp.msgCid, nil
+}
+
+func (mp *marketPlumbing) MessageWait(ctx context.Context, msgCid cid.Cid, cb func(*types.Block, *types.SignedMessage, *types.MessageReceipt) error) error {
+	require.True(mp.testing, msgCid.Equals(mp.msgCid))
+	return cb(&types.Block{}, &types.SignedMessage{}, mp.receipt)
+}
+
+func (mp *marketPlumbing) WalletDefaultAddress() (address.Address, error) {
+	return address.TestAddress, nil
+}
+
+func (mp *marketPlumbing) ChainHeadKey() types.TipSetKey {
+	return types.NewTipSetKey()
+}
+
+func (mp *marketPlumbing) MessageQuery(ctx context.Context, optFrom, to address.Address, method string, baseKey types.TipSetKey, params ...interface{}) ([][]byte, error) {
+	switch method {
+	case "getBalance":
+		return [][]byte{mp.balance.Available.Bytes(), mp.balance.Locked.Bytes()}, nil
+	case "getDeal":
+		dealBytes, err := cbor.DumpObject(mp.deal)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{dealBytes}, nil
+	}
+	return nil, fmt.Errorf("unexpected query %s", method)
+}
+
+func TestMarketProposeDeal(t *testing.T) {
+	tf.UnitTest(t)
+
+	minerAddr := address.NewForTestGetter()()
+	commP := make([]byte, types.CommitmentBytesLen)
+
+	t.Run("sends the price not covered by escrow and returns the deal id", func(t *testing.T) {
+		plumbing := &marketPlumbing{
+			testing: t,
+			receipt: &types.MessageReceipt{ExitCode: 0, Return: [][]byte{big.NewInt(3).Bytes()}},
+			balance: &storagemarket.Balance{Available: types.NewAttoFILFromFIL(2), Locked: types.ZeroAttoFIL},
+		}
+
+		dealID, err := MarketProposeDeal(context.Background(), plumbing, address.Undef, types.NewGasPrice(0), types.NewGasUnits(0), minerAddr, commP, types.NewBytesAmount(1024), types.NewBlockHeight(10), types.NewAttoFILFromFIL(5), types.NewAttoFILFromFIL(1))
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), dealID)
+		assert.Equal(t, address.StorageMarketAddress, plumbing.sentTo)
+		assert.Equal(t, "proposeDeal", plumbing.sentMethod)
+		assert.Equal(t, types.NewAttoFILFromFIL(3), plumbing.sentValue)
+		assert.Equal(t, minerAddr, plumbing.sentParams[0])
+		assert.Equal(t, types.NewAttoFILFromFIL(5), plumbing.sentParams[4])
+	})
+
+	t.Run("sends no value when escrow covers the price", func(t *testing.T) {
+		plumbing := &marketPlumbing{
+			testing: t,
+			receipt: &types.MessageReceipt{ExitCode: 0, Return: [][]byte{big.NewInt(3).Bytes()}},
+			balance: &storagemarket.Balance{Available: types.NewAttoFILFromFIL(8), Locked: types.ZeroAttoFIL},
+		}
+
+		_, err := MarketProposeDeal(context.Background(), plumbing, address.Undef, types.NewGasPrice(0), types.NewGasUnits(0), minerAddr, commP, types.NewBytesAmount(1024), types.NewBlockHeight(10), types.NewAttoFILFromFIL(5), types.NewAttoFILFromFIL(1))
+		require.NoError(t, err)
+		assert.Equal(t, types.ZeroAttoFIL, plumbing.sentValue)
+	})
+
+	t.Run("reports actor errors", func(t *testing.T) {
+		plumbing := &marketPlumbing{
+			testing: t,
+			receipt: &types.MessageReceipt{ExitCode: storagemarket.ErrUnknownMiner},
+			balance: &storagemarket.Balance{Available: types.ZeroAttoFIL, Locked: types.ZeroAttoFIL},
+		}
+
+		_, err := MarketProposeDeal(context.Background(), plumbing, address.Undef, types.NewGasPrice(0), types.NewGasUnits(0), minerAddr, commP, types.NewBytesAmount(1024), types.NewBlockHeight(10), types.NewAttoFILFromFIL(5), types.NewAttoFILFromFIL(1))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown miner")
+	})
+}
+
+func TestMarketAcceptDeal(t *testing.T) {
+	tf.UnitTest(t)
+
+	plumbing := &marketPlumbing{
+		testing: t,
+		balance: &storagemarket.Balance{Available: types.NewAttoFILFromFIL(1), Locked: types.ZeroAttoFIL},
+		deal: &storagemarket.Deal{
+			ID:              7,
+			Client:          address.TestAddress,
+			Miner:           address.TestAddress2,
+			CommP:           make([]byte, types.CommitmentBytesLen),
+			Size:            types.NewBytesAmount(1024),
+			Duration:        types.NewBlockHeight(10),
+			TotalPrice:      types.NewAttoFILFromFIL(5),
+			Collateral:      types.NewAttoFILFromFIL(3),
+			StartHeight:     types.NewBlockHeight(0),
+			PaidThrough:     types.NewBlockHeight(0),
+			PaymentReleased: types.ZeroAttoFIL,
+		},
+	}
+	_, err := MarketAcceptDeal(context.Background(), plumbing, address.Undef, types.NewGasPrice(0), types.NewGasUnits(0), 7)
+	require.NoError(t, err)
+	assert.Equal(t, "acceptDeal", plumbing.sentMethod)
+	assert.Equal(t, types.NewAttoFILFromFIL(2), plumbing.sentValue)
+	assert.Equal(t, big.NewInt(7), plumbing.sentParams[0])
+}
+
+func TestMarketAddBalance(t *testing.T) {
+	tf.UnitTest(t)
+
+	plumbing := &marketPlumbing{testing: t}
+	_, err := MarketAddBalance(context.Background(), plumbing, address.Undef, types.NewGasPrice(0), types.NewGasUnits(0), address.Undef, types.NewAttoFILFromFIL(4))
+	require.NoError(t, err)
+	assert.Equal(t, "addBalance", plumbing.sentMethod)
+	assert.Equal(t, types.NewAttoFILFromFIL(4), plumbing.sentValue)
+	assert.Equal(t, address.TestAddress, plumbing.sentParams[0])
+}
+
+func TestMarketGetBalance(t *testing.T) {
+	tf.UnitTest(t)
+
+	plumbing := &marketPlumbing{
+		testing: t,
+		balance: &storagemarket.Balance{Available: types.NewAttoFILFromFIL(1), Locked: types.NewAttoFILFromFIL(2)},
+	}
+	balance, err := MarketGetBalance(context.Background(), plumbing, address.TestAddress, types.NewTipSetKey())
+	require.NoError(t, err)
+	assert.True(t, types.NewAttoFILFromFIL(1).Equal(balance.Available))
+	assert.True(t, types.NewAttoFILFromFIL(2).Equal(balance.Locked))
+}
+
+func TestMarketGetDeal(t *testing.T) {
+	tf.UnitTest(t)
+
+	expected := &storagemarket.Deal{
+		ID:              4,
+		Client:          address.TestAddress,
+		Miner:           address.TestAddress2,
+		CommP:           make([]byte, types.CommitmentBytesLen),
+		Size:            types.NewBytesAmount(1024),
+		Duration:        types.NewBlockHeight(10),
+		TotalPrice:      types.NewAttoFILFromFIL(5),
+		Collateral:      types.NewAttoFILFromFIL(1),
+		StartHeight:     types.NewBlockHeight(0),
+		PaidThrough:     types.NewBlockHeight(0),
+		PaymentReleased: types.ZeroAttoFIL,
+	}
+
+	deal, err := MarketGetDeal(context.Background(), &marketPlumbing{testing: t, deal: expected}, 4, types.NewTipSetKey())
+	require.NoError(t, err)
+	assert.Equal(t, expected.ID, deal.ID)
+	assert.Equal(t, expected.Miner, deal.Miner)
+	assert.Equal(t, expected.TotalPrice, deal.TotalPrice)
+}
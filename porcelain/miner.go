@@ -272,9 +272,10 @@ type minerQueryAndDeserialize interface {
 	ActorGetSignature(ctx context.Context, actorAddr address.Address, method string) (*exec.FunctionSignature, error)
 }
 
-// MinerGetOwnerAddress queries for the owner address of the given miner
-func MinerGetOwnerAddress(ctx context.Context, plumbing minerQueryAndDeserialize, minerAddr address.Address) (address.Address, error) {
-	res, err := plumbing.MessageQuery(ctx, address.Undef, minerAddr, "getOwner", plumbing.ChainHeadKey())
+// MinerGetOwnerAddress queries for the owner address of the given miner at
+// the tipset with key baseKey.
+func MinerGetOwnerAddress(ctx context.Context, plumbing minerQueryAndDeserialize, minerAddr address.Address, baseKey types.TipSetKey) (address.Address, error) {
+	res, err := plumbing.MessageQuery(ctx, address.Undef, minerAddr, "getOwner", baseKey)
 	if err != nil {
 		return address.Undef, err
 	}
@@ -366,9 +367,10 @@ type mgaAPI interface {
 	MessageQuery(ctx context.Context, optFrom, to address.Address, method string, baseKey types.TipSetKey, params ...interface{}) ([][]byte, error)
 }
 
-// MinerGetState queries for a state of the given miner
-func MinerGetState(ctx context.Context, plumbing mgaAPI, minerAddr address.Address) (minerActor.State, error) {
-	ret, err := plumbing.MessageQuery(ctx, address.Undef, minerAddr, "getState", plumbing.ChainHeadKey())
+// MinerGetState queries for a state of the given miner at the tipset with key
+// baseKey.
+func MinerGetState(ctx context.Context, plumbing mgaAPI, minerAddr address.Address, baseKey types.TipSetKey) (minerActor.State, error) {
+	ret, err := plumbing.MessageQuery(ctx, address.Undef, minerAddr, "getState", baseKey)
 	if err != nil {
 		return minerActor.State{}, err
 	}
@@ -424,14 +426,15 @@ type MinerProvingWindow struct {
 	ProvingSet map[string]types.Commitments
 }
 
-// MinerGetProvingWindow gets the proving period and commitments for miner `minerAddr`.
-func MinerGetProvingWindow(ctx context.Context, plumbing minerQueryAndDeserialize, minerAddr address.Address) (MinerProvingWindow, error) {
+// MinerGetProvingWindow gets the proving period and commitments for miner
+// `minerAddr` at the tipset with key baseKey.
+func MinerGetProvingWindow(ctx context.Context, plumbing minerQueryAndDeserialize, minerAddr address.Address, baseKey types.TipSetKey) (MinerProvingWindow, error) {
 	res, err := plumbing.MessageQuery(
 		ctx,
 		address.Undef,
 		minerAddr,
 		"getProvingWindow",
-		plumbing.ChainHeadKey(),
+		baseKey,
 	)
 	if err != nil {
 		return MinerProvingWindow{}, errors.Wrap(err, "query ProvingPeriod method failed")
@@ -443,7 +446,7 @@ func MinerGetProvingWindow(ctx context.Context, plumbing minerQueryAndDeserializ
 		address.Undef,
 		minerAddr,
 		"getProvingSetCommitments",
-		plumbing.ChainHeadKey(),
+		baseKey,
 	)
 	if err != nil {
 		return MinerProvingWindow{}, errors.Wrap(err, "query SetCommitments method failed")
@@ -476,14 +479,15 @@ type MinerPower struct {
 	Total types.BytesAmount
 }
 
-// MinerGetPower queries the power of a given miner.
-func MinerGetPower(ctx context.Context, plumbing mgaAPI, minerAddr address.Address) (MinerPower, error) {
+// MinerGetPower queries the power of a given miner at the tipset with key
+// baseKey.
+func MinerGetPower(ctx context.Context, plumbing mgaAPI, minerAddr address.Address, baseKey types.TipSetKey) (MinerPower, error) {
 	bytes, err := plumbing.MessageQuery(
 		ctx,
 		address.Undef,
 		minerAddr,
 		"getPower",
-		plumbing.ChainHeadKey(),
+		baseKey,
 	)
 	if err != nil {
 		return MinerPower{}, err
@@ -495,7 +499,7 @@ func MinerGetPower(ctx context.Context, plumbing mgaAPI, minerAddr address.Addre
 		address.Undef,
 		address.StorageMarketAddress,
 		"getTotalStorage",
-		plumbing.ChainHeadKey(),
+		baseKey,
 	)
 	if err != nil {
 		return MinerPower{}, err
@@ -508,14 +512,15 @@ func MinerGetPower(ctx context.Context, plumbing mgaAPI, minerAddr address.Addre
 	}, nil
 }
 
-// MinerGetCollateral queries the collateral of a given miner.
-func MinerGetCollateral(ctx context.Context, plumbing mgaAPI, minerAddr address.Address) (types.AttoFIL, error) {
+// MinerGetCollateral queries the collateral of a given miner at the tipset
+// with key baseKey.
+func MinerGetCollateral(ctx context.Context, plumbing mgaAPI, minerAddr address.Address, baseKey types.TipSetKey) (types.AttoFIL, error) {
 	rets, err := plumbing.MessageQuery(
 		ctx,
 		address.Undef,
 		minerAddr,
 		"getActiveCollateral",
-		plumbing.ChainHeadKey(),
+		baseKey,
 	)
 	if err != nil {
 		return types.AttoFIL{}, err
@@ -525,9 +530,10 @@ func MinerGetCollateral(ctx context.Context, plumbing mgaAPI, minerAddr address.
 
 // mwapi is the subset of the plumbing.API that MinerSetWorkerAddress use.
 type mwapi interface {
+	ChainHeadKey() types.TipSetKey
 	ConfigGet(dottedPath string) (interface{}, error)
 	MessageSend(ctx context.Context, from, to address.Address, value types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method string, params ...interface{}) (cid.Cid, error)
-	MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address) (address.Address, error)
+	MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address, baseKey types.TipSetKey) (address.Address, error)
 }
 
 // MinerSetWorkerAddress sets the worker address of the miner actor to the provided new address,
@@ -549,7 +555,7 @@ func MinerSetWorkerAddress(
 		return cid.Undef, errors.New("problem converting miner address")
 	}
 
-	minerOwnerAddr, err := plumbing.MinerGetOwnerAddress(ctx, minerAddr)
+	minerOwnerAddr, err := plumbing.MinerGetOwnerAddress(ctx, minerAddr, plumbing.ChainHeadKey())
 	if err != nil {
 		return cid.Undef, errors.Wrap(err, "could not get miner owner address")
 	}
@@ -564,3 +570,153 @@ func MinerSetWorkerAddress(
 		"changeWorker",
 		workerAddr)
 }
+
+// mwbAPI is the subset of the plumbing.API that MinerWithdraw uses.
+type mwbAPI interface {
+	ChainHeadKey() types.TipSetKey
+	ConfigGet(dottedPath string) (interface{}, error)
+	MessageSend(ctx context.Context, from, to address.Address, value types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method string, params ...interface{}) (cid.Cid, error)
+	MessageWait(ctx context.Context, msgCid cid.Cid, cb func(*types.Block, *types.SignedMessage, *types.MessageReceipt) error) error
+	MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address, baseKey types.TipSetKey) (address.Address, error)
+}
+
+// MinerWithdraw withdraws amount from the miner's balance to its owner and waits for the
+// withdrawal to be mined. Only the balance in excess of the miner's pledge collateral
+// requirement can be withdrawn. If minerAddr is empty, the default miner will be used.
+func MinerWithdraw(
+	ctx context.Context,
+	plumbing mwbAPI,
+	minerAddr address.Address,
+	gasPrice types.AttoFIL,
+	gasLimit types.GasUnits,
+	amount types.AttoFIL,
+) (cid.Cid, error) {
+	minerAddr, err := minerAddrOrDefault(plumbing, minerAddr)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	minerOwnerAddr, err := plumbing.MinerGetOwnerAddress(ctx, minerAddr, plumbing.ChainHeadKey())
+	if err != nil {
+		return cid.Undef, errors.Wrap(err, "could not get miner owner address")
+	}
+
+	msgCid, err := plumbing.MessageSend(ctx, minerOwnerAddr, minerAddr, types.ZeroAttoFIL, gasPrice, gasLimit, "withdrawBalance", amount)
+	if err != nil {
+		return cid.Undef, errors.Wrap(err, "couldn't send message")
+	}
+
+	err = plumbing.MessageWait(ctx, msgCid, func(blk *types.Block, smsg *types.SignedMessage, receipt *types.MessageReceipt) error {
+		if receipt.ExitCode != uint8(0) {
+			return vmErrors.VMExitCodeToError(receipt.ExitCode, minerActor.Errors)
+		}
+		return nil
+	})
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	return msgCid, nil
+}
+
+// mpwbAPI is the subset of the plumbing.API that MinerPreviewWithdraw uses.
+type mpwbAPI interface {
+	ChainHeadKey() types.TipSetKey
+	ConfigGet(dottedPath string) (interface{}, error)
+	MessagePreview(ctx context.Context, optFrom, to address.Address, method string, params ...interface{}) (types.GasUnits, error)
+	MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address, baseKey types.TipSetKey) (address.Address, error)
+}
+
+// MinerPreviewWithdraw calculates the amount of Gas needed for a call to MinerWithdraw.
+// This method accepts all the same arguments as MinerWithdraw.
+func MinerPreviewWithdraw(ctx context.Context, plumbing mpwbAPI, minerAddr address.Address, amount types.AttoFIL) (types.GasUnits, error) {
+	minerAddr, err := minerAddrOrDefault(plumbing, minerAddr)
+	if err != nil {
+		return types.NewGasUnits(0), err
+	}
+
+	minerOwnerAddr, err := plumbing.MinerGetOwnerAddress(ctx, minerAddr, plumbing.ChainHeadKey())
+	if err != nil {
+		return types.NewGasUnits(0), errors.Wrap(err, "could not get miner owner address")
+	}
+
+	usedGas, err := plumbing.MessagePreview(ctx, minerOwnerAddr, minerAddr, "withdrawBalance", amount)
+	if err != nil {
+		return types.NewGasUnits(0), errors.Wrap(err, "couldn't preview message")
+	}
+
+	return usedGas, nil
+}
+
+// cfgGetAPI is the subset of the plumbing.API that minerAddrOrDefault uses.
+type cfgGetAPI interface {
+	ConfigGet(dottedPath string) (interface{}, error)
+}
+
+// minerAddrOrDefault returns minerAddr, or the configured miner address if minerAddr is empty.
+func minerAddrOrDefault(plumbing cfgGetAPI, minerAddr address.Address) (address.Address, error) {
+	if !minerAddr.Empty() {
+		return minerAddr, nil
+	}
+
+	minerValue, err := plumbing.ConfigGet("mining.minerAddress")
+	if err != nil {
+		return address.Undef, errors.Wrap(err, "Could not get miner address in config")
+	}
+	configured, ok := minerValue.(address.Address)
+	if !ok {
+		return address.Undef, errors.New("Configured miner is not an address")
+	}
+	return configured, nil
+}
+
+// mpoAPI is the subset of the plumbing.API that MinerProposeOwner uses.
+type mpoAPI interface {
+	ChainHeadKey() types.TipSetKey
+	MessageSend(ctx context.Context, from, to address.Address, value types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method string, params ...interface{}) (cid.Cid, error)
+	MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address, baseKey types.TipSetKey) (address.Address, error)
+}
+
+// MinerProposeOwner sends a message from the current owner of the miner proposing newOwner
+// as its owner. Ownership changes once newOwner accepts with MinerAcceptOwner.
+func MinerProposeOwner(
+	ctx context.Context,
+	plumbing mpoAPI,
+	minerAddr address.Address,
+	gasPrice types.AttoFIL,
+	gasLimit types.GasUnits,
+	newOwner address.Address,
+) (cid.Cid, error) {
+	minerOwnerAddr, err := plumbing.MinerGetOwnerAddress(ctx, minerAddr, plumbing.ChainHeadKey())
+	if err != nil {
+		return cid.Undef, errors.Wrap(err, "could not get miner owner address")
+	}
+
+	return plumbing.MessageSend(ctx, minerOwnerAddr, minerAddr, types.ZeroAttoFIL, gasPrice, gasLimit, "proposeOwner", newOwner)
+}
+
+// maoAPI is the subset of the plumbing.API that MinerAcceptOwner uses.
+type maoAPI interface {
+	MessageSend(ctx context.Context, from, to address.Address, value types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method string, params ...interface{}) (cid.Cid, error)
+	WalletDefaultAddress() (address.Address, error)
+}
+
+// MinerAcceptOwner sends a message from the proposed owner of the miner accepting the
+// ownership transfer. If from is empty, the default wallet address will be used.
+func MinerAcceptOwner(
+	ctx context.Context,
+	plumbing maoAPI,
+	from address.Address,
+	minerAddr address.Address,
+	gasPrice types.AttoFIL,
+	gasLimit types.GasUnits,
+) (_ cid.Cid, err error) {
+	if from.Empty() {
+		from, err = plumbing.WalletDefaultAddress()
+		if err != nil {
+			return cid.Undef, err
+		}
+	}
+
+	return plumbing.MessageSend(ctx, from, minerAddr, types.ZeroAttoFIL, gasPrice, gasLimit, "acceptOwner")
+}
@@ -447,7 +447,7 @@ func (mgop *minerQueryAndDeserializePlumbing) ActorGetSignature(ctx context.Cont
 func TestMinerGetOwnerAddress(t *testing.T) {
 	tf.UnitTest(t)
 
-	addr, err := MinerGetOwnerAddress(context.Background(), &minerQueryAndDeserializePlumbing{}, address.TestAddress2)
+	addr, err := MinerGetOwnerAddress(context.Background(), &minerQueryAndDeserializePlumbing{}, address.TestAddress2, types.NewTipSetKey())
 	assert.NoError(t, err)
 	assert.Equal(t, address.TestAddress, addr)
 }
@@ -463,7 +463,7 @@ func TestMinerGetWorkerAddress(t *testing.T) {
 func TestMinerGetPower(t *testing.T) {
 	tf.UnitTest(t)
 
-	power, err := MinerGetPower(context.Background(), &minerQueryAndDeserializePlumbing{}, address.TestAddress2)
+	power, err := MinerGetPower(context.Background(), &minerQueryAndDeserializePlumbing{}, address.TestAddress2, types.NewTipSetKey())
 	assert.NoError(t, err)
 	assert.Equal(t, "4", power.Total.String())
 	assert.Equal(t, "2", power.Power.String())
@@ -506,7 +506,7 @@ func (mpp *minerGetProvingPeriodPlumbing) ActorGetSignature(ctx context.Context,
 func TestMinerProvingPeriod(t *testing.T) {
 	tf.UnitTest(t)
 
-	pp, err := MinerGetProvingWindow(context.Background(), &minerGetProvingPeriodPlumbing{}, address.TestAddress2)
+	pp, err := MinerGetProvingWindow(context.Background(), &minerGetProvingPeriodPlumbing{}, address.TestAddress2, types.NewTipSetKey())
 	assert.NoError(t, err)
 	assert.Equal(t, "10", pp.Start.String())
 	assert.Equal(t, "20", pp.End.String())
@@ -653,7 +653,11 @@ func (mswap *minerSetWorkerAddressPlumbing) ConfigGet(dottedKey string) (interfa
 	return address.Undef, fmt.Errorf("unknown config %s", dottedKey)
 }
 
-func (mswap *minerSetWorkerAddressPlumbing) MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address) (address.Address, error) {
+func (mswap *minerSetWorkerAddressPlumbing) ChainHeadKey() types.TipSetKey {
+	return types.NewTipSetKey()
+}
+
+func (mswap *minerSetWorkerAddressPlumbing) MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address, _ types.TipSetKey) (address.Address, error) {
 	if mswap.getOwnerFail {
 		return address.Undef, errors.New("MinerGetOwnerAddress failed")
 	}
@@ -711,3 +715,137 @@ func TestMinerSetWorkerAddress(t *testing.T) {
 		})
 	}
 }
+
+type minerWithdrawPlumbing struct {
+	minerAddr, ownerAddr address.Address
+	exitCode             uint8
+
+	sentFrom, sentTo address.Address
+	sentMethod       string
+	sentParams       []interface{}
+}
+
+func (mwp *minerWithdrawPlumbing) ConfigGet(dottedKey string) (interface{}, error) {
+	if dottedKey == "mining.minerAddress" {
+		return mwp.minerAddr, nil
+	}
+	return address.Undef, fmt.Errorf("unknown config %s", dottedKey)
+}
+
+func (mwp *minerWithdrawPlumbing) MessageSend(ctx context.Context, from, to address.Address, value types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method string, params ...interface{}) (cid.Cid, error) {
+	mwp.sentFrom = from
+	mwp.sentTo = to
+	mwp.sentMethod = method
+	mwp.sentParams = params
+	return types.EmptyMessagesCID, nil
+}
+
+func (mwp *minerWithdrawPlumbing) MessageWait(ctx context.Context, msgCid cid.Cid, cb func(*types.Block, *types.SignedMessage, *types.MessageReceipt) error) error {
+	return cb(&types.Block{}, &types.SignedMessage{}, &types.MessageReceipt{ExitCode: mwp.exitCode})
+}
+
+func (mwp *minerWithdrawPlumbing) MessagePreview(ctx context.Context, from, to address.Address, method string, params ...interface{}) (types.GasUnits, error) {
+	mwp.sentFrom = from
+	mwp.sentTo = to
+	mwp.sentMethod = method
+	return types.NewGasUnits(7), nil
+}
+
+func (mwp *minerWithdrawPlumbing) ChainHeadKey() types.TipSetKey {
+	return types.NewTipSetKey()
+}
+
+func (mwp *minerWithdrawPlumbing) MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address, _ types.TipSetKey) (address.Address, error) {
+	return mwp.ownerAddr, nil
+}
+
+func TestMinerWithdraw(t *testing.T) {
+	tf.UnitTest(t)
+
+	minerAddr := address.NewForTestGetter()()
+	amount := types.NewAttoFILFromFIL(5)
+
+	t.Run("sends withdrawBalance from the owner to the configured miner", func(t *testing.T) {
+		plumbing := &minerWithdrawPlumbing{minerAddr: minerAddr, ownerAddr: address.TestAddress}
+
+		_, err := MinerWithdraw(context.Background(), plumbing, address.Undef, types.ZeroAttoFIL, types.NewGasUnits(0), amount)
+		require.NoError(t, err)
+		assert.Equal(t, address.TestAddress, plumbing.sentFrom)
+		assert.Equal(t, minerAddr, plumbing.sentTo)
+		assert.Equal(t, "withdrawBalance", plumbing.sentMethod)
+		assert.Equal(t, []interface{}{amount}, plumbing.sentParams)
+	})
+
+	t.Run("reports actor errors", func(t *testing.T) {
+		plumbing := &minerWithdrawPlumbing{minerAddr: minerAddr, ownerAddr: address.TestAddress, exitCode: miner.ErrInsufficientFunds}
+
+		_, err := MinerWithdraw(context.Background(), plumbing, minerAddr, types.ZeroAttoFIL, types.NewGasUnits(0), amount)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "insufficient funds")
+	})
+}
+
+func TestMinerPreviewWithdraw(t *testing.T) {
+	tf.UnitTest(t)
+
+	minerAddr := address.NewForTestGetter()()
+	plumbing := &minerWithdrawPlumbing{minerAddr: minerAddr, ownerAddr: address.TestAddress}
+
+	usedGas, err := MinerPreviewWithdraw(context.Background(), plumbing, address.Undef, types.NewAttoFILFromFIL(5))
+	require.NoError(t, err)
+	assert.Equal(t, types.NewGasUnits(7), usedGas)
+	assert.Equal(t, address.TestAddress, plumbing.sentFrom)
+	assert.Equal(t, "withdrawBalance", plumbing.sentMethod)
+}
+
+type minerOwnerTransferPlumbing struct {
+	ownerAddr address.Address
+
+	sentFrom   address.Address
+	sentMethod string
+	sentParams []interface{}
+}
+
+func (motp *minerOwnerTransferPlumbing) MessageSend(ctx context.Context, from, to address.Address, value types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method string, params ...interface{}) (cid.Cid, error) {
+	motp.sentFrom = from
+	motp.sentMethod = method
+	motp.sentParams = params
+	return types.EmptyMessagesCID, nil
+}
+
+func (motp *minerOwnerTransferPlumbing) ChainHeadKey() types.TipSetKey {
+	return types.NewTipSetKey()
+}
+
+func (motp *minerOwnerTransferPlumbing) MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address, _ types.TipSetKey) (address.Address, error) {
+	return motp.ownerAddr, nil
+}
+
+func (motp *minerOwnerTransferPlumbing) WalletDefaultAddress() (address.Address, error) {
+	return address.TestAddress2, nil
+}
+
+func TestMinerOwnerTransfer(t *testing.T) {
+	tf.UnitTest(t)
+
+	minerAddr := address.NewForTestGetter()()
+
+	t.Run("propose is sent from the current owner", func(t *testing.T) {
+		plumbing := &minerOwnerTransferPlumbing{ownerAddr: address.TestAddress}
+
+		_, err := MinerProposeOwner(context.Background(), plumbing, minerAddr, types.ZeroAttoFIL, types.NewGasUnits(0), address.TestAddress2)
+		require.NoError(t, err)
+		assert.Equal(t, address.TestAddress, plumbing.sentFrom)
+		assert.Equal(t, "proposeOwner", plumbing.sentMethod)
+		assert.Equal(t, []interface{}{address.TestAddress2}, plumbing.sentParams)
+	})
+
+	t.Run("accept defaults to the wallet address", func(t *testing.T) {
+		plumbing := &minerOwnerTransferPlumbing{ownerAddr: address.TestAddress}
+
+		_, err := MinerAcceptOwner(context.Background(), plumbing, address.Undef, minerAddr, types.ZeroAttoFIL, types.NewGasUnits(0))
+		require.NoError(t, err)
+		assert.Equal(t, address.TestAddress2, plumbing.sentFrom)
+		assert.Equal(t, "acceptOwner", plumbing.sentMethod)
+	})
+}
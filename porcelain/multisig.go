@@ -0,0 +1,169 @@
+package porcelain
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/actor/builtin/multisig"
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/types"
+	vmErrors "github.com/filecoin-project/go-filecoin/vm/errors"
+)
+
+// msigSendAPI is the subset of the plumbing.API that the multisig calls
+// which send messages use.
+type msigSendAPI interface {
+	MessageSend(ctx context.Context, from, to address.Address, value types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method string, params ...interface{}) (cid.Cid, error)
+	MessageWait(ctx context.Context, msgCid cid.Cid, cb func(*types.Block, *types.SignedMessage, *types.MessageReceipt) error) error
+	WalletDefaultAddress() (address.Address, error)
+}
+
+// MultisigCreate creates a new multisig wallet funded with value and returns
+// its address once the creating message has been mined. The value vests
+// linearly over unlockDuration blocks; a zero duration leaves it unlocked.
+func MultisigCreate(
+	ctx context.Context,
+	plumbing msigSendAPI,
+	from address.Address,
+	gasPrice types.AttoFIL,
+	gasLimit types.GasUnits,
+	signers []address.Address,
+	required uint64,
+	unlockDuration *types.BlockHeight,
+	value types.AttoFIL,
+) (_ address.Address, err error) {
+	if from.Empty() {
+		from, err = plumbing.WalletDefaultAddress()
+		if err != nil {
+			return address.Undef, err
+		}
+	}
+
+	msgCid, err := plumbing.MessageSend(
+		ctx,
+		from,
+		address.InitAddress,
+		value,
+		gasPrice,
+		gasLimit,
+		"createMultisig",
+		signers,
+		big.NewInt(0).SetUint64(required),
+		unlockDuration,
+	)
+	if err != nil {
+		return address.Undef, err
+	}
+
+	var msigAddr address.Address
+	err = plumbing.MessageWait(ctx, msgCid, func(blk *types.Block, smsg *types.SignedMessage, receipt *types.MessageReceipt) (err error) {
+		if receipt.ExitCode != uint8(0) {
+			return vmErrors.VMExitCodeToError(receipt.ExitCode, multisig.Errors)
+		}
+		msigAddr, err = address.NewFromBytes(receipt.Return[0])
+		return err
+	})
+	if err != nil {
+		return address.Undef, err
+	}
+
+	return msigAddr, nil
+}
+
+// MultisigPropose proposes a transaction from the multisig wallet and returns
+// the id assigned to it once the proposal has been mined. If the wallet only
+// requires a single approval the transaction executes immediately.
+func MultisigPropose(
+	ctx context.Context,
+	plumbing msigSendAPI,
+	from address.Address,
+	msigAddr address.Address,
+	gasPrice types.AttoFIL,
+	gasLimit types.GasUnits,
+	to address.Address,
+	value types.AttoFIL,
+	method string,
+	params ...interface{},
+) (_ uint64, err error) {
+	if from.Empty() {
+		from, err = plumbing.WalletDefaultAddress()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if params == nil {
+		params = []interface{}{}
+	}
+
+	msgCid, err := plumbing.MessageSend(ctx, from, msigAddr, types.ZeroAttoFIL, gasPrice, gasLimit, "propose", to, value, method, params)
+	if err != nil {
+		return 0, err
+	}
+
+	var txID uint64
+	err = plumbing.MessageWait(ctx, msgCid, func(blk *types.Block, smsg *types.SignedMessage, receipt *types.MessageReceipt) error {
+		if receipt.ExitCode != uint8(0) {
+			return vmErrors.VMExitCodeToError(receipt.ExitCode, multisig.Errors)
+		}
+		txID = big.NewInt(0).SetBytes(receipt.Return[0]).Uint64()
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return txID, nil
+}
+
+// MultisigApprove approves a pending multisig transaction and returns the cid
+// of the approving message.
+func MultisigApprove(ctx context.Context, plumbing msigSendAPI, from address.Address, msigAddr address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, txID uint64) (_ cid.Cid, err error) {
+	if from.Empty() {
+		from, err = plumbing.WalletDefaultAddress()
+		if err != nil {
+			return cid.Undef, err
+		}
+	}
+
+	return plumbing.MessageSend(ctx, from, msigAddr, types.ZeroAttoFIL, gasPrice, gasLimit, "approve", big.NewInt(0).SetUint64(txID))
+}
+
+// MultisigCancel cancels a pending multisig transaction proposed by from and
+// returns the cid of the cancelling message.
+func MultisigCancel(ctx context.Context, plumbing msigSendAPI, from address.Address, msigAddr address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, txID uint64) (_ cid.Cid, err error) {
+	if from.Empty() {
+		from, err = plumbing.WalletDefaultAddress()
+		if err != nil {
+			return cid.Undef, err
+		}
+	}
+
+	return plumbing.MessageSend(ctx, from, msigAddr, types.ZeroAttoFIL, gasPrice, gasLimit, "cancel", big.NewInt(0).SetUint64(txID))
+}
+
+// msigQueryAPI is the subset of the plumbing.API that MultisigGetState uses.
+type msigQueryAPI interface {
+	ChainHeadKey() types.TipSetKey
+	MessageQuery(ctx context.Context, optFrom, to address.Address, method string, baseKey types.TipSetKey, params ...interface{}) ([][]byte, error)
+}
+
+// MultisigGetState queries the signers, threshold, vesting schedule and
+// pending transactions of a multisig wallet at the tipset with key baseKey.
+func MultisigGetState(ctx context.Context, plumbing msigQueryAPI, msigAddr address.Address, baseKey types.TipSetKey) (*multisig.State, error) {
+	ret, err := plumbing.MessageQuery(ctx, address.Undef, msigAddr, "getState", baseKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "'getState' query message failed")
+	}
+
+	var state multisig.State
+	if err := cbor.DecodeInto(ret[0], &state); err != nil {
+		return nil, errors.Wrap(err, "could not decode multisig state")
+	}
+
+	return &state, nil
+}
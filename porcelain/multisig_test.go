@@ -0,0 +1,123 @@
+package porcelain_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/actor/builtin/multisig"
+	"github.com/filecoin-project/go-filecoin/address"
+	. "github.com/filecoin-project/go-filecoin/porcelain"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+type multisigPlumbing struct {
+	testing *testing.T
+
+	receipt *types.MessageReceipt
+	msgCid  cid.Cid
+
+	sentTo     address.Address
+	sentMethod string
+	sentParams []interface{}
+}
+
+func (mp *multisigPlumbing) MessageSend(ctx context.Context, from, to address.Address, value types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method string, params ...interface{}) (cid.Cid, error) {
+	mp.sentTo = to
+	mp.sentMethod = method
+	mp.sentParams = params
+	mp.msgCid = types.NewCidForTestGetter()()
+	return mp.msgCid, nil
+}
+
+func (mp *multisigPlumbing) MessageWait(ctx context.Context, msgCid cid.Cid, cb func(*types.Block, *types.SignedMessage, *types.MessageReceipt) error) error {
+	require.True(mp.testing, msgCid.Equals(mp.msgCid))
+	return cb(&types.Block{}, &types.SignedMessage{}, mp.receipt)
+}
+
+func (mp *multisigPlumbing) WalletDefaultAddress() (address.Address, error) {
+	return address.TestAddress, nil
+}
+
+func TestMultisigCreate(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("returns the address of the created wallet", func(t *testing.T) {
+		expected := address.NewForTestGetter()()
+		plumbing := &multisigPlumbing{
+			testing: t,
+			receipt: &types.MessageReceipt{ExitCode: 0, Return: [][]byte{expected.Bytes()}},
+		}
+
+		signers := []address.Address{address.TestAddress, address.TestAddress2}
+		addr, err := MultisigCreate(context.Background(), plumbing, address.Undef, types.NewGasPrice(0), types.NewGasUnits(0), signers, 2, types.NewBlockHeight(0), types.NewAttoFILFromFIL(10))
+		require.NoError(t, err)
+		assert.Equal(t, expected, addr)
+		assert.Equal(t, address.InitAddress, plumbing.sentTo)
+		assert.Equal(t, "createMultisig", plumbing.sentMethod)
+		assert.Equal(t, signers, plumbing.sentParams[0])
+	})
+
+	t.Run("reports actor errors", func(t *testing.T) {
+		plumbing := &multisigPlumbing{
+			testing: t,
+			receipt: &types.MessageReceipt{ExitCode: multisig.ErrInvalidRequirement},
+		}
+
+		_, err := MultisigCreate(context.Background(), plumbing, address.Undef, types.NewGasPrice(0), types.NewGasUnits(0), nil, 2, types.NewBlockHeight(0), types.ZeroAttoFIL)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "required approvals")
+	})
+}
+
+func TestMultisigPropose(t *testing.T) {
+	tf.UnitTest(t)
+
+	plumbing := &multisigPlumbing{
+		testing: t,
+		receipt: &types.MessageReceipt{ExitCode: 0, Return: [][]byte{big.NewInt(7).Bytes()}},
+	}
+
+	msigAddr := address.NewForTestGetter()()
+	txID, err := MultisigPropose(context.Background(), plumbing, address.Undef, msigAddr, types.NewGasPrice(0), types.NewGasUnits(0), address.TestAddress2, types.NewAttoFILFromFIL(1), "")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), txID)
+	assert.Equal(t, msigAddr, plumbing.sentTo)
+	assert.Equal(t, "propose", plumbing.sentMethod)
+	assert.Equal(t, []interface{}{}, plumbing.sentParams[3])
+}
+
+type multisigQueryPlumbing struct {
+	state *multisig.State
+}
+
+func (mqp *multisigQueryPlumbing) ChainHeadKey() types.TipSetKey {
+	return types.NewTipSetKey()
+}
+
+func (mqp *multisigQueryPlumbing) MessageQuery(ctx context.Context, optFrom, to address.Address, method string, baseKey types.TipSetKey, params ...interface{}) ([][]byte, error) {
+	stateBytes, err := cbor.DumpObject(mqp.state)
+	if err != nil {
+		return nil, err
+	}
+	return [][]byte{stateBytes}, nil
+}
+
+func TestMultisigGetState(t *testing.T) {
+	tf.UnitTest(t)
+
+	expected, err := multisig.NewState([]address.Address{address.TestAddress}, 1, types.NewAttoFILFromFIL(3), types.NewBlockHeight(0), types.NewBlockHeight(10))
+	require.NoError(t, err)
+
+	state, err := MultisigGetState(context.Background(), &multisigQueryPlumbing{state: expected}, address.NewForTestGetter()(), types.NewTipSetKey())
+	require.NoError(t, err)
+	assert.Equal(t, expected.Signers, state.Signers)
+	assert.Equal(t, expected.Required, state.Required)
+	assert.Equal(t, expected.UnlockDuration, state.UnlockDuration)
+}
@@ -2,6 +2,7 @@ package porcelain
 
 import (
 	"context"
+	"math/big"
 
 	cbor "github.com/ipfs/go-ipld-cbor"
 
@@ -16,12 +17,14 @@ type pclPlumbing interface {
 	WalletDefaultAddress() (address.Address, error)
 }
 
-// PaymentChannelLs lists payments for a given payer
+// PaymentChannelLs lists payments for a given payer at the tipset with key
+// baseKey.
 func PaymentChannelLs(
 	ctx context.Context,
 	plumbing pclPlumbing,
 	fromAddr address.Address,
 	payerAddr address.Address,
+	baseKey types.TipSetKey,
 ) (channels map[string]*paymentbroker.PaymentChannel, err error) {
 	if fromAddr.Empty() {
 		fromAddr, err = plumbing.WalletDefaultAddress()
@@ -39,7 +42,7 @@ func PaymentChannelLs(
 		fromAddr,
 		address.PaymentBrokerAddress,
 		"ls",
-		plumbing.ChainHeadKey(),
+		baseKey,
 		payerAddr,
 	)
 	if err != nil {
@@ -66,8 +69,11 @@ func PaymentChannelVoucher(
 	plumbing pcvPlumbing,
 	fromAddr address.Address,
 	channel *types.ChannelID,
+	lane uint64,
+	nonce uint64,
 	amount types.AttoFIL,
 	validAt *types.BlockHeight,
+	merges []types.Merge,
 	condition *types.Predicate,
 ) (voucher *types.PaymentVoucher, err error) {
 	if fromAddr.Empty() {
@@ -83,7 +89,7 @@ func PaymentChannelVoucher(
 		address.PaymentBrokerAddress,
 		"voucher",
 		plumbing.ChainHeadKey(),
-		channel, amount, validAt, condition,
+		channel, new(big.Int).SetUint64(lane), new(big.Int).SetUint64(nonce), amount, validAt, merges, condition,
 	)
 	if err != nil {
 		return nil, err
@@ -93,7 +99,7 @@ func PaymentChannelVoucher(
 		return nil, err
 	}
 
-	sig, err := paymentbroker.SignVoucher(channel, amount, validAt, fromAddr, condition, plumbing)
+	sig, err := paymentbroker.SignVoucher(channel, lane, nonce, amount, validAt, merges, fromAddr, condition, plumbing)
 	if err != nil {
 		return nil, err
 	}
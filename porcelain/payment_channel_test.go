@@ -47,7 +47,7 @@ func TestPaymentChannelLs(t *testing.T) {
 		}
 		ctx := context.Background()
 
-		channels, err := porcelain.PaymentChannelLs(ctx, plumbing, address.Undef, address.Undef)
+		channels, err := porcelain.PaymentChannelLs(ctx, plumbing, address.Undef, address.Undef, types.NewTipSetKey())
 		require.NoError(t, err)
 		assert.Equal(t, expectedChannels, channels)
 	})
@@ -105,8 +105,11 @@ func TestPaymentChannelVoucher(t *testing.T) {
 			plumbing,
 			address.Undef,
 			types.NewChannelID(5),
+			0,
+			0,
 			types.NewAttoFILFromFIL(10),
 			types.NewBlockHeight(0),
+			nil,
 			&types.Predicate{
 				To:     address.Undef,
 				Method: "someMethod",
@@ -134,7 +134,8 @@ func CreatePayments(ctx context.Context, plumbing cpPlumbing, config CreatePayme
 		config.GasLimit,
 		"createChannel",
 		config.To,
-		&config.ChannelExpiry)
+		&config.ChannelExpiry,
+		types.NewBlockHeight(paymentbroker.DefaultSettlePeriod))
 	if err != nil {
 		return response, err
 	}
@@ -247,8 +248,11 @@ func createPayment(ctx context.Context, plumbing cpPlumbing, baseKey types.TipSe
 		"voucher",
 		baseKey,
 		response.Channel,
+		big.NewInt(0),
+		big.NewInt(0),
 		amount,
 		validAt,
+		[]types.Merge{},
 		condition,
 	)
 	if err != nil {
@@ -260,7 +264,7 @@ func createPayment(ctx context.Context, plumbing cpPlumbing, baseKey types.TipSe
 		return err
 	}
 
-	sig, err := paymentbroker.SignVoucher(&voucher.Channel, amount, validAt, voucher.Payer, condition, plumbing)
+	sig, err := paymentbroker.SignVoucher(&voucher.Channel, voucher.Lane, voucher.Nonce, amount, validAt, voucher.Merges, voucher.Payer, condition, plumbing)
 	if err != nil {
 		return err
 	}
@@ -59,9 +59,9 @@ func newTestCreatePaymentsPlumbing() *paymentsTestPlumbing {
 				Channel:   *channelID,
 				Payer:     payer,
 				Target:    target,
-				Amount:    params[1].(types.AttoFIL),
-				ValidAt:   *params[2].(*types.BlockHeight),
-				Condition: params[3].(*types.Predicate),
+				Amount:    params[3].(types.AttoFIL),
+				ValidAt:   *params[4].(*types.BlockHeight),
+				Condition: params[6].(*types.Predicate),
 			}
 			voucherBytes, err := actor.MarshalStorage(voucher)
 			if err != nil {
@@ -25,6 +25,7 @@ type ProtocolParams struct {
 	BlockTime        time.Duration
 	ProofsMode       types.ProofsMode
 	SupportedSectors []SectorInfo
+	GasSchedule      types.GasSchedule
 }
 
 type protocolParamsPlumbing interface {
@@ -73,6 +74,7 @@ func ProtocolParameters(ctx context.Context, plumbing protocolParamsPlumbing) (*
 		BlockTime:        plumbing.BlockTime(),
 		ProofsMode:       proofsMode,
 		SupportedSectors: supportedSectors,
+		GasSchedule:      types.DefaultGasSchedule,
 	}, nil
 }
 
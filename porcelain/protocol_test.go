@@ -64,6 +64,7 @@ func TestProtocolParams(t *testing.T) {
 			ProofsMode:       types.TestProofsMode,
 			SupportedSectors: []porcelain.SectorInfo{{sectorSize, maxUserBytes}},
 			BlockTime:        protocolTestParamBlockTime,
+			GasSchedule:      types.DefaultGasSchedule,
 		}
 
 		out, err := porcelain.ProtocolParameters(context.TODO(), plumbing)
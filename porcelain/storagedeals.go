@@ -2,6 +2,7 @@ package porcelain
 
 import (
 	"context"
+	"math/big"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-datastore/query"
@@ -165,8 +166,11 @@ func buildDealRedeemParams(ctx context.Context, plumbing dealRedeemPlumbing, dea
 	return []interface{}{
 		voucher.Payer,
 		&voucher.Channel,
+		new(big.Int).SetUint64(voucher.Lane),
+		new(big.Int).SetUint64(voucher.Nonce),
 		voucher.Amount,
 		&voucher.ValidAt,
+		voucher.Merges,
 		voucher.Condition,
 		[]byte(voucher.Signature),
 		[]interface{}{},
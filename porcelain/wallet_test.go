@@ -19,7 +19,8 @@ import (
 )
 
 type wbTestPlumbing struct {
-	balance types.AttoFIL
+	balance  types.AttoFIL
+	balances map[string]types.AttoFIL
 }
 
 type wdaTestPlumbing struct {
@@ -37,8 +38,12 @@ func newWdaTestPlumbing(t *testing.T) *wdaTestPlumbing {
 	}
 }
 
-func (wbtp *wbTestPlumbing) ActorGet(ctx context.Context, addr address.Address) (*actor.Actor, error) {
-	testActor := actor.NewActor(cid.Undef, wbtp.balance)
+func (wbtp *wbTestPlumbing) ActorGet(ctx context.Context, addr address.Address, baseKey types.TipSetKey) (*actor.Actor, error) {
+	balance := wbtp.balance
+	if b, ok := wbtp.balances[baseKey.String()]; ok {
+		balance = b
+	}
+	testActor := actor.NewActor(cid.Undef, balance)
 	return testActor, nil
 }
 
@@ -68,11 +73,25 @@ func TestWalletBalance(t *testing.T) {
 		plumbing := &wbTestPlumbing{
 			balance: expectedBalance,
 		}
-		balance, err := porcelain.WalletBalance(ctx, plumbing, address.Undef)
+		balance, err := porcelain.WalletBalance(ctx, plumbing, address.Undef, types.NewTipSetKey())
 		require.NoError(t, err)
 
 		assert.Equal(t, expectedBalance, balance)
 	})
+
+	t.Run("Returns the wallet balance at the given tipset", func(t *testing.T) {
+		ctx := context.Background()
+
+		key := types.NewTipSetKey(types.CidFromString(t, "somecid"))
+		plumbing := &wbTestPlumbing{
+			balance:  types.NewAttoFILFromFIL(20),
+			balances: map[string]types.AttoFIL{key.String(): types.NewAttoFILFromFIL(5)},
+		}
+		balance, err := porcelain.WalletBalance(ctx, plumbing, address.Undef, key)
+		require.NoError(t, err)
+
+		assert.Equal(t, types.NewAttoFILFromFIL(5), balance)
+	})
 }
 
 func TestWalletDefaultAddress(t *testing.T) {
@@ -49,7 +49,7 @@ const (
 	CreateChannelGasPrice = 1
 
 	// CreateChannelGasLimit is the gas limit of the message used to create the payment channel
-	CreateChannelGasLimit = 300
+	CreateChannelGasLimit = 1000
 )
 
 type clientPorcelainAPI interface {
@@ -64,7 +64,7 @@ type clientPorcelainAPI interface {
 	DealsLs(context.Context) (<-chan *porcelain.StorageDealLsResult, error)
 	MinerGetAsk(ctx context.Context, minerAddr address.Address, askID uint64) (miner.Ask, error)
 	MinerGetSectorSize(ctx context.Context, minerAddr address.Address) (*types.BytesAmount, error)
-	MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address) (address.Address, error)
+	MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address, baseKey types.TipSetKey) (address.Address, error)
 	MinerGetWorkerAddress(ctx context.Context, minerAddr address.Address, baseKey types.TipSetKey) (address.Address, error)
 	MinerGetPeerID(ctx context.Context, minerAddr address.Address) (peer.ID, error)
 	types.Signer
@@ -158,7 +158,7 @@ func (smc *Client) ProposeDeal(ctx context.Context, miner address.Address, data
 		return nil, err
 	}
 
-	minerOwner, err := smc.api.MinerGetOwnerAddress(ctx, miner)
+	minerOwner, err := smc.api.MinerGetOwnerAddress(ctx, miner, headKey)
 	if err != nil {
 		return nil, err
 	}
@@ -349,7 +349,7 @@ func (ctp *clientTestAPI) MinerGetAsk(ctx context.Context, minerAddr address.Add
 	}, nil
 }
 
-func (ctp *clientTestAPI) MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address) (address.Address, error) {
+func (ctp *clientTestAPI) MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address, _ types.TipSetKey) (address.Address, error) {
 	return address.TestAddress, nil
 }
 
@@ -16,7 +16,7 @@ import (
 var DefaultFaultSlasherGasPrice = types.NewAttoFILFromFIL(1)
 
 // DefaultFaultSlasherGasLimit is the default gas limit to be used when sending messages
-var DefaultFaultSlasherGasLimit = types.NewGasUnits(300)
+var DefaultFaultSlasherGasLimit = types.NewGasUnits(1000)
 
 // monitorPlumbing is an interface for the functionality FaultSlasher needs
 type monitorPlumbing interface {
@@ -252,7 +252,7 @@ func (sm *Miner) validateDealPayment(ctx context.Context, p *storagedeal.SignedP
 	lastValidAt := expectedFirstPayment
 	for _, v := range p.Payment.Vouchers {
 		// confirm signature is valid against expected actor and channel id
-		if !paymentbroker.VerifyVoucherSignature(p.Payment.Payer, p.Payment.Channel, v.Amount, &v.ValidAt, v.Condition, v.Signature) {
+		if !paymentbroker.VerifyVoucherSignature(p.Payment.Payer, p.Payment.Channel, v.Lane, v.Nonce, v.Amount, &v.ValidAt, v.Merges, v.Condition, v.Signature) {
 			return errors.New("invalid signature in voucher")
 		}
 
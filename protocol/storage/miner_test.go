@@ -633,7 +633,8 @@ func newMinerTestPorcelain(t *testing.T, minerPriceString string) *minerTestPorc
 }
 
 func (mtp *minerTestPorcelain) ActorGetSignature(ctx context.Context, actorAddr address.Address, method string) (_ *exec.FunctionSignature, err error) {
-	return builtin.Actors[types.MinerActorCodeCid].Exports()[method], nil
+	_, signature, _ := builtin.Actors[types.MinerActorCodeCid].Exports().Lookup(method)
+	return signature, nil
 }
 
 func (mtp *minerTestPorcelain) MessageSend(ctx context.Context, from, to address.Address, val types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method string, params ...interface{}) (cid.Cid, error) {
@@ -712,7 +713,7 @@ func (mtp *minerTestPorcelain) MessageWait(ctx context.Context, msgCid cid.Cid,
 	return nil
 }
 
-func (mtp *minerTestPorcelain) WalletBalance(ctx context.Context, address address.Address) (types.AttoFIL, error) {
+func (mtp *minerTestPorcelain) WalletBalance(ctx context.Context, address address.Address, _ types.TipSetKey) (types.AttoFIL, error) {
 	return mtp.walletBalance, nil
 }
 
@@ -789,7 +790,7 @@ func testPaymentVouchers(porcelainAPI *minerTestPorcelain, voucherInterval int,
 	for i := 0; i < 10; i++ {
 		validAt := porcelainAPI.paymentStart.Add(types.NewBlockHeight(uint64((i + 1) * voucherInterval)))
 		amount := types.NewAttoFILFromFIL(uint64(i+1) * amountInc)
-		signature, err := paymentbroker.SignVoucher(porcelainAPI.channelID, amount, validAt, porcelainAPI.payerAddress, nil, porcelainAPI.signer)
+		signature, err := paymentbroker.SignVoucher(porcelainAPI.channelID, 0, 0, amount, validAt, nil, porcelainAPI.payerAddress, nil, porcelainAPI.signer)
 		require.NoError(porcelainAPI.testing, err, "could not sign valid proposal")
 
 		vouchers[i] = &types.PaymentVoucher{
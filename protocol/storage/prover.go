@@ -21,7 +21,7 @@ const (
 	postSubmissionDelayBufferRounds = 10
 
 	// This will likely depend on the sector size and proving period.
-	submitPostGasLimit = 300
+	submitPostGasLimit = 1000
 )
 
 // ProofReader provides information about the blockchain to the proving process.
@@ -35,7 +35,7 @@ type ProofReader interface {
 	// MinerCalculateLateFee calculates the fee due for a proof submitted at some height.
 	MinerCalculateLateFee(ctx context.Context, addr address.Address, height *types.BlockHeight) (types.AttoFIL, error)
 	// WalletBalance returns the balance for an actor.
-	WalletBalance(ctx context.Context, addr address.Address) (types.AttoFIL, error)
+	WalletBalance(ctx context.Context, addr address.Address, baseKey types.TipSetKey) (types.AttoFIL, error)
 	// MinerGetWorkerAddress returns the current worker address for a miner
 	MinerGetWorkerAddress(ctx context.Context, minerAddr address.Address, baseKey types.TipSetKey) (address.Address, error)
 }
@@ -113,7 +113,7 @@ func (p *Prover) CalculatePoSt(ctx context.Context, start, end *types.BlockHeigh
 		return nil, errors.Wrapf(err, "failed to read miner worker address for miner %s", p.actorAddress)
 	}
 
-	balance, err := p.chain.WalletBalance(ctx, workerAddr)
+	balance, err := p.chain.WalletBalance(ctx, workerAddr, headKey)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to check wallet balance for %s", workerAddr)
 	}
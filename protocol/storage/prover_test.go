@@ -144,7 +144,7 @@ func (f *fakeProverContext) MinerCalculateLateFee(ctx context.Context, addr addr
 	return f.lateFee, nil
 }
 
-func (f *fakeProverContext) WalletBalance(ctx context.Context, addr address.Address) (types.AttoFIL, error) {
+func (f *fakeProverContext) WalletBalance(ctx context.Context, addr address.Address, _ types.TipSetKey) (types.AttoFIL, error) {
 	if addr == f.workerAddress && !f.balance.IsZero() {
 		return f.balance, nil
 	}
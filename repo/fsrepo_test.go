@@ -40,6 +40,13 @@ const (
 		"minPeerThreshold": 0,
 		"period": "1m"
 	},
+	"chain": {
+		"autoPrune": false,
+		"keepStateRoots": 1000,
+		"finalityDepth": 900,
+		"prunePeriod": "1h",
+		"checkpoints": []
+	},
 	"datastore": {
 		"type": "badgerds",
 		"path": "badger"
@@ -57,7 +64,9 @@ const (
 	},
 	"mpool": {
 		"maxPoolSize": 10000,
-		"maxNonceGap": "100"
+		"maxNonceGap": "100",
+		"replaceByFeePercent": 10,
+		"maxPendingPerSender": 1000
 	},
 	"observability": {
 		"metrics": {
@@ -121,7 +121,7 @@ func BuildMessage(m types.SignedMessage, r types.MessageReceipt) Message {
 		Nonce:       uint64(m.Nonce),
 		Value:       attoToFloat64(m.Value),
 		ValueBytes:  m.Value.Bytes(),
-		Method:      m.Method,
+		Method:      m.Method.String(),
 		Params:      m.Params,
 
 		GasPrice: attoToFloat64(m.GasPrice),
@@ -172,7 +172,7 @@ func BuildSendMessage(m *types.Message) SendMessage {
 		To:     m.To.String(),
 		From:   m.From.String(),
 		Value:  attoToFloat64(m.Value),
-		Method: m.Method,
+		Method: m.Method.String(),
 	}
 }
 
@@ -90,7 +90,7 @@ func Persist(ctx context.Context) error {
 		return err
 	}
 	for _, a := range existingActors {
-		actor, err := p.ActorGet(ctx, address.Address(a.Address))
+		actor, err := p.ActorGet(ctx, address.Address(a.Address), p.ChainHeadKey())
 		if err == nil {
 			addActors = append(addActors, BuildActor(address.Address(a.Address), actor))
 		} else if strings.Contains(err.Error(), "no actor at address") { // TODO:
@@ -137,7 +137,7 @@ func Persist(ctx context.Context) error {
 		return err
 	}
 	for _, m := range existingMiners {
-		state, err := p.MinerGetState(ctx, address.Address(m.Miner))
+		state, err := p.MinerGetState(ctx, address.Address(m.Miner), p.ChainHeadKey())
 		if err == nil {
 			addMiners = append(addMiners, BuildMiner(address.Address(m.Miner), &state))
 		} else if strings.Contains(err.Error(), "failed to get To actor") {
@@ -17,9 +17,10 @@ import (
 )
 
 type PorcelainAPI interface {
-	ActorGet(ctx context.Context, addr address.Address) (*actor.Actor, error)
+	ChainHeadKey() types.TipSetKey
+	ActorGet(ctx context.Context, addr address.Address, baseKey types.TipSetKey) (*actor.Actor, error)
 	MinerGetAsk(ctx context.Context, minerAddr address.Address, askID uint64) (miner.Ask, error)
-	MinerGetState(ctx context.Context, minerAddr address.Address) (miner.State, error)
+	MinerGetState(ctx context.Context, minerAddr address.Address, baseKey types.TipSetKey) (miner.State, error)
 }
 
 type Sink struct {
@@ -0,0 +1,275 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-hamt-ipld"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/actor"
+	"github.com/filecoin-project/go-filecoin/address"
+)
+
+// TreeDiff lists the actors that differ between two state trees, each sorted
+// by address.
+type TreeDiff struct {
+	Added    []*ActorDiff `json:"added"`
+	Removed  []*ActorDiff `json:"removed"`
+	Modified []*ActorDiff `json:"modified"`
+}
+
+// ActorDiff describes the change of the actor at an address. Before is nil for
+// added actors and After is nil for removed ones.
+type ActorDiff struct {
+	Address address.Address `json:"address"`
+	Before  *actor.Actor    `json:"before"`
+	After   *actor.Actor    `json:"after"`
+	// Storage lists the changed fields of the state of a builtin actor whose
+	// head changed.
+	Storage []*StorageFieldDiff `json:"storage,omitempty"`
+}
+
+// StorageFieldDiff describes the change of one field of an actor's state.
+type StorageFieldDiff struct {
+	Field  string `json:"field"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// ChangedFields returns the names of the actor fields that differ.
+func (d *ActorDiff) ChangedFields() []string {
+	if d.Before == nil || d.After == nil {
+		return nil
+	}
+
+	var fields []string
+	if !d.Before.Code.Equals(d.After.Code) {
+		fields = append(fields, "code")
+	}
+	if !d.Before.Head.Equals(d.After.Head) {
+		fields = append(fields, "head")
+	}
+	if d.Before.Nonce != d.After.Nonce {
+		fields = append(fields, "nonce")
+	}
+	if !d.Before.Balance.Equal(d.After.Balance) {
+		fields = append(fields, "balance")
+	}
+	return fields
+}
+
+// Diff compares the state trees before and after. It walks both HAMTs
+// together and skips every subtree the trees share, so the cost is
+// proportional to the size of the change rather than to the size of the state.
+func Diff(ctx context.Context, before, after Tree) (*TreeDiff, error) {
+	b, ok := before.(*tree)
+	if !ok {
+		return nil, errors.New("can't diff non-stateTree")
+	}
+	a, ok := after.(*tree)
+	if !ok {
+		return nil, errors.New("can't diff non-stateTree")
+	}
+
+	beforeKVs := map[string][]byte{}
+	afterKVs := map[string][]byte{}
+	if err := diffNodes(ctx, b, a, b.root, a.root, beforeKVs, afterKVs); err != nil {
+		return nil, err
+	}
+
+	diff := &TreeDiff{}
+	for key, beforeRaw := range beforeKVs {
+		afterRaw, ok := afterKVs[key]
+		if ok && bytes.Equal(beforeRaw, afterRaw) {
+			continue
+		}
+
+		actorDiff, err := newActorDiff(key, beforeRaw, afterRaw)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			diff.Removed = append(diff.Removed, actorDiff)
+			continue
+		}
+
+		if !actorDiff.Before.Head.Equals(actorDiff.After.Head) && actorDiff.Before.Code.Equals(actorDiff.After.Code) {
+			if _, err := a.GetBuiltinActorCode(actorDiff.After.Code); err == nil {
+				actorDiff.Storage, err = diffStorage(ctx, a.store, actorDiff.Before.Head, actorDiff.After.Head)
+				if err != nil {
+					return nil, errors.Wrapf(err, "failed to diff storage of %s", key)
+				}
+			}
+		}
+		diff.Modified = append(diff.Modified, actorDiff)
+	}
+	for key, afterRaw := range afterKVs {
+		if _, ok := beforeKVs[key]; ok {
+			continue
+		}
+		actorDiff, err := newActorDiff(key, nil, afterRaw)
+		if err != nil {
+			return nil, err
+		}
+		diff.Added = append(diff.Added, actorDiff)
+	}
+
+	sortActorDiffs(diff.Added)
+	sortActorDiffs(diff.Removed)
+	sortActorDiffs(diff.Modified)
+	return diff, nil
+}
+
+// diffNodes collects the actors of the subtrees that differ between the two
+// nodes. Pointers are compared position by position in the bitfield, equal
+// links are skipped and differing links are descended into together.
+func diffNodes(ctx context.Context, bt, at *tree, before, after *hamt.Node, beforeKVs, afterKVs map[string][]byte) error {
+	for i := 0; i < 1<<TreeBitWidth; i++ {
+		bp := pointerAt(before, i)
+		ap := pointerAt(after, i)
+
+		if bp != nil && ap != nil && bp.Link.Defined() && ap.Link.Defined() {
+			if bp.Link.Equals(ap.Link) {
+				continue
+			}
+			bn, err := hamt.LoadNode(ctx, bt.store, bp.Link, hamt.UseTreeBitWidth(TreeBitWidth))
+			if err != nil {
+				return err
+			}
+			an, err := hamt.LoadNode(ctx, at.store, ap.Link, hamt.UseTreeBitWidth(TreeBitWidth))
+			if err != nil {
+				return err
+			}
+			if err := diffNodes(ctx, bt, at, bn, an, beforeKVs, afterKVs); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := collectKVs(ctx, bt, bp, beforeKVs); err != nil {
+			return err
+		}
+		if err := collectKVs(ctx, at, ap, afterKVs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pointerAt returns the pointer of nd at the given bitfield position, if any.
+func pointerAt(nd *hamt.Node, pos int) *hamt.Pointer {
+	if nd.Bitfield.Bit(pos) == 0 {
+		return nil
+	}
+	idx := 0
+	for i := 0; i < pos; i++ {
+		if nd.Bitfield.Bit(i) == 1 {
+			idx++
+		}
+	}
+	return nd.Pointers[idx]
+}
+
+// collectKVs adds every key value pair under p to kvs.
+func collectKVs(ctx context.Context, t *tree, p *hamt.Pointer, kvs map[string][]byte) error {
+	if p == nil {
+		return nil
+	}
+	for _, kv := range p.KVs {
+		kvs[kv.Key] = kv.Value.Raw
+	}
+	if !p.Link.Defined() {
+		return nil
+	}
+
+	n, err := hamt.LoadNode(ctx, t.store, p.Link, hamt.UseTreeBitWidth(TreeBitWidth))
+	if err != nil {
+		return err
+	}
+	for _, child := range n.Pointers {
+		if err := collectKVs(ctx, t, child, kvs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newActorDiff(key string, beforeRaw, afterRaw []byte) (*ActorDiff, error) {
+	addr, err := address.NewFromString(key)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &ActorDiff{Address: addr}
+	if beforeRaw != nil {
+		diff.Before = &actor.Actor{}
+		if err := cbor.DecodeInto(beforeRaw, diff.Before); err != nil {
+			return nil, err
+		}
+	}
+	if afterRaw != nil {
+		diff.After = &actor.Actor{}
+		if err := cbor.DecodeInto(afterRaw, diff.After); err != nil {
+			return nil, err
+		}
+	}
+	return diff, nil
+}
+
+// diffStorage compares the top level fields of two states of an actor.
+func diffStorage(ctx context.Context, store *hamt.CborIpldStore, before, after cid.Cid) ([]*StorageFieldDiff, error) {
+	beforeFields, err := loadStorageFields(ctx, store, before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := loadStorageFields(ctx, store, after)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []*StorageFieldDiff
+	for field, b := range beforeFields {
+		a, ok := afterFields[field]
+		if ok && reflect.DeepEqual(a, b) {
+			continue
+		}
+		fieldDiff := &StorageFieldDiff{Field: field, Before: fmt.Sprintf("%v", b)}
+		if ok {
+			fieldDiff.After = fmt.Sprintf("%v", a)
+		}
+		diffs = append(diffs, fieldDiff)
+	}
+	for field, a := range afterFields {
+		if _, ok := beforeFields[field]; !ok {
+			diffs = append(diffs, &StorageFieldDiff{Field: field, After: fmt.Sprintf("%v", a)})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Field < diffs[j].Field
+	})
+	return diffs, nil
+}
+
+func loadStorageFields(ctx context.Context, store *hamt.CborIpldStore, head cid.Cid) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	if !head.Defined() {
+		return fields, nil
+	}
+	if err := store.Get(ctx, head, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func sortActorDiffs(diffs []*ActorDiff) {
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Address.String() < diffs[j].Address.String()
+	})
+}
@@ -0,0 +1,119 @@
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-hamt-ipld"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/actor"
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/exec"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+func TestDiff(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	cst := hamt.NewCborStore()
+	addrGetter := address.NewForTestGetter()
+
+	fakeCode := types.NewCidForTestGetter()()
+	builtinActors := map[cid.Cid]exec.ExecutableActor{fakeCode: &actor.FakeActor{}}
+
+	// enough actors for the HAMT to link out to child nodes
+	before := NewEmptyStateTreeWithActors(cst, builtinActors)
+	var addrs []address.Address
+	for i := 0; i < 100; i++ {
+		addr := addrGetter()
+		addrs = append(addrs, addr)
+		require.NoError(t, before.SetActor(ctx, addr, actor.NewActor(types.AccountActorCodeCid, types.NewAttoFILFromFIL(uint64(i)))))
+	}
+
+	oldHead, err := cst.Put(ctx, map[string]interface{}{"count": 1, "name": "fake"})
+	require.NoError(t, err)
+	newHead, err := cst.Put(ctx, map[string]interface{}{"count": 2, "name": "fake"})
+	require.NoError(t, err)
+	fakeAddr := addrGetter()
+	fakeActor := actor.NewActor(fakeCode, types.ZeroAttoFIL)
+	fakeActor.Head = oldHead
+	require.NoError(t, before.SetActor(ctx, fakeAddr, fakeActor))
+
+	beforeRoot, err := before.Flush(ctx)
+	require.NoError(t, err)
+
+	after, err := LoadStateTree(ctx, cst, beforeRoot, builtinActors)
+	require.NoError(t, err)
+
+	modified := actor.NewActor(types.AccountActorCodeCid, types.NewAttoFILFromFIL(1000))
+	modified.IncNonce()
+	require.NoError(t, after.SetActor(ctx, addrs[42], modified))
+
+	addedAddr := addrGetter()
+	added := actor.NewActor(types.AccountActorCodeCid, types.NewAttoFILFromFIL(7))
+	require.NoError(t, after.SetActor(ctx, addedAddr, added))
+
+	changedFake := actor.NewActor(fakeCode, types.ZeroAttoFIL)
+	changedFake.Head = newHead
+	require.NoError(t, after.SetActor(ctx, fakeAddr, changedFake))
+
+	_, err = after.Flush(ctx)
+	require.NoError(t, err)
+
+	t.Run("reports added and modified actors", func(t *testing.T) {
+		diff, err := Diff(ctx, before, after)
+		require.NoError(t, err)
+
+		require.Len(t, diff.Added, 1)
+		assert.Equal(t, addedAddr, diff.Added[0].Address)
+		assert.Nil(t, diff.Added[0].Before)
+		assert.Equal(t, added, diff.Added[0].After)
+
+		assert.Empty(t, diff.Removed)
+
+		require.Len(t, diff.Modified, 2)
+		byAddr := map[address.Address]*ActorDiff{}
+		for _, d := range diff.Modified {
+			byAddr[d.Address] = d
+		}
+
+		accountDiff := byAddr[addrs[42]]
+		require.NotNil(t, accountDiff)
+		assert.Equal(t, []string{"nonce", "balance"}, accountDiff.ChangedFields())
+		assert.Equal(t, modified, accountDiff.After)
+		assert.Empty(t, accountDiff.Storage)
+
+		fakeDiff := byAddr[fakeAddr]
+		require.NotNil(t, fakeDiff)
+		assert.Equal(t, []string{"head"}, fakeDiff.ChangedFields())
+		require.Len(t, fakeDiff.Storage, 1)
+		assert.Equal(t, "count", fakeDiff.Storage[0].Field)
+		assert.Equal(t, "1", fakeDiff.Storage[0].Before)
+		assert.Equal(t, "2", fakeDiff.Storage[0].After)
+	})
+
+	t.Run("reports removed actors in reverse", func(t *testing.T) {
+		diff, err := Diff(ctx, after, before)
+		require.NoError(t, err)
+
+		assert.Empty(t, diff.Added)
+		require.Len(t, diff.Removed, 1)
+		assert.Equal(t, addedAddr, diff.Removed[0].Address)
+		assert.Nil(t, diff.Removed[0].After)
+		assert.Len(t, diff.Modified, 2)
+	})
+
+	t.Run("identical trees have no diff", func(t *testing.T) {
+		diff, err := Diff(ctx, before, before)
+		require.NoError(t, err)
+
+		assert.Empty(t, diff.Added)
+		assert.Empty(t, diff.Removed)
+		assert.Empty(t, diff.Modified)
+	})
+}
@@ -224,10 +224,27 @@ func CreateAndApplyTestMessageFrom(t *testing.T, st state.Tree, vms vm.StorageMa
 	t.Helper()
 
 	pdata := actor.MustConvertParams(params...)
-	msg := types.NewMessage(from, to, 0, types.NewAttoFILFromFIL(val), method, pdata)
+	msg := types.NewMessage(from, to, 0, types.NewAttoFILFromFIL(val), RequireLookupMethod(t, st, to, method), pdata)
 	return applyTestMessageWithAncestors(st, vms, msg, types.NewBlockHeight(bh), ancestors)
 }
 
+// RequireLookupMethod resolves the name of a method exported by the actor at
+// addr to the id messages address it by.
+func RequireLookupMethod(t *testing.T, st state.Tree, addr address.Address, method string) types.MethodID {
+	t.Helper()
+
+	if method == "" {
+		return types.SendMethodID
+	}
+
+	act, err := st.GetActor(context.Background(), addr)
+	require.NoError(t, err)
+
+	id, _, err := vm.LookupMethod(st, act.Code, method)
+	require.NoError(t, err)
+	return id
+}
+
 // CreateAndApplyTestMessage wraps the given parameters in a message and calls
 // CreateAndApplyTestMessageFrom sending the message from address.TestAddress
 func CreateAndApplyTestMessage(t *testing.T, st state.Tree, vms vm.StorageMap, to address.Address, val, bh uint64, method string, ancestors []types.TipSet, params ...interface{}) (*consensus.ApplicationResult, error) {
@@ -235,7 +252,7 @@ func CreateAndApplyTestMessage(t *testing.T, st state.Tree, vms vm.StorageMap, t
 }
 
 func applyTestMessageWithAncestors(st state.Tree, store vm.StorageMap, msg *types.Message, bh *types.BlockHeight, ancestors []types.TipSet) (*consensus.ApplicationResult, error) {
-	smsg, err := types.NewSignedMessage(*msg, testSigner{}, types.NewGasPrice(1), types.NewGasUnits(300))
+	smsg, err := types.NewSignedMessage(*msg, testSigner{}, types.NewGasPrice(1), types.NewGasUnits(10000))
 	if err != nil {
 		panic(err)
 	}
@@ -16,6 +16,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/actor/builtin"
 	"github.com/filecoin-project/go-filecoin/actor/builtin/account"
 	"github.com/filecoin-project/go-filecoin/actor/builtin/miner"
+	"github.com/filecoin-project/go-filecoin/actor/builtin/storagemarket"
 	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/consensus"
 	"github.com/filecoin-project/go-filecoin/exec"
@@ -137,7 +138,7 @@ func CreateTestMinerWith(
 ) address.Address {
 	pdata := actor.MustConvertParams(types.OneKiBSectorSize, pid)
 	nonce := RequireGetNonce(t, stateTree, address.TestAddress)
-	msg := types.NewMessage(minerOwnerAddr, address.StorageMarketAddress, nonce, collateral, "createStorageMiner", pdata)
+	msg := types.NewMessage(minerOwnerAddr, address.StorageMarketAddress, nonce, collateral, storagemarket.MethodCreateStorageMiner, pdata)
 
 	result, err := ApplyTestMessage(stateTree, vms, msg, types.NewBlockHeight(height))
 	require.NoError(t, err)
@@ -57,6 +57,7 @@ func RunSuccessLines(td *TestDaemon, args ...string) []string {
 type TestDaemon struct {
 	containerDir     string // Path to directory containing repo and sectors
 	genesisFile      string
+	snapshotFile     string
 	keyFiles         []string
 	withMiner        string
 	autoSealInterval string
@@ -409,7 +410,7 @@ func (td *TestDaemon) CreateStorageMinerAddr(peer *TestDaemon, fromAddr string)
 	var minerAddr address.Address
 	wg.Add(1)
 	go func() {
-		miner := td.RunSuccess("miner", "create", "--from", fromAddr, "--gas-price", "1", "--gas-limit", "100", "20")
+		miner := td.RunSuccess("miner", "create", "--from", fromAddr, "--gas-price", "1", "--gas-limit", "1000", "20")
 		addr, err := address.NewFromString(strings.Trim(miner.ReadStdout(), "\n"))
 		require.NoError(td.test, err)
 		require.NotEqual(td.test, addr, address.Undef)
@@ -428,7 +429,7 @@ func (td *TestDaemon) MinerSetPrice(minerAddr string, fromAddr string, price str
 		"--from", fromAddr,
 		"--miner", minerAddr,
 		"--gas-price", "1",
-		"--gas-limit", "300",
+		"--gas-limit", "1000",
 		"--enc", "json",
 		price, expiry).ReadStdout()
 
@@ -450,7 +451,7 @@ func (td *TestDaemon) UpdatePeerID() {
 	peerIDJSON := td.RunSuccess("id").ReadStdout()
 	err := json.Unmarshal([]byte(peerIDJSON), &idOutput)
 	require.NoError(td.test, err)
-	updateCidStr := td.RunSuccess("miner", "update-peerid", "--gas-price=1", "--gas-limit=300", td.GetMinerAddress().String(), idOutput["ID"].(string)).ReadStdoutTrimNewlines()
+	updateCidStr := td.RunSuccess("miner", "update-peerid", "--gas-price=1", "--gas-limit=1000", td.GetMinerAddress().String(), idOutput["ID"].(string)).ReadStdoutTrimNewlines()
 	updateCid, err := cid.Parse(updateCidStr)
 	require.NoError(td.test, err)
 	assert.NotNil(td.test, updateCid)
@@ -656,6 +657,15 @@ func GenesisFile(a string) func(*TestDaemon) {
 	}
 }
 
+// ImportSnapshot initializes the daemon from the given chain snapshot
+// archive instead of a genesis file.
+func ImportSnapshot(path string) func(*TestDaemon) {
+	return func(td *TestDaemon) {
+		td.genesisFile = ""
+		td.snapshotFile = path
+	}
+}
+
 // WithMiner allows setting the --with-miner flag on init.
 func WithMiner(m string) func(*TestDaemon) {
 	return func(td *TestDaemon) {
@@ -706,6 +716,10 @@ func NewDaemon(t *testing.T, options ...func(*TestDaemon)) *TestDaemon {
 		initopts = append(initopts, fmt.Sprintf("--genesisfile=%s", td.genesisFile))
 	}
 
+	if td.snapshotFile != "" {
+		initopts = append(initopts, fmt.Sprintf("--import-snapshot=%s", td.snapshotFile))
+	}
+
 	if td.withMiner != "" {
 		initopts = append(initopts, fmt.Sprintf("--with-miner=%s", td.withMiner))
 	}
@@ -132,6 +132,22 @@ func (f *Filecoin) PaychRedeem(ctx context.Context, voucher string, options ...A
 	return out.Cid, nil
 }
 
+// PaychSettle runs the `paych settle` command against the filecoin process.
+func (f *Filecoin) PaychSettle(ctx context.Context, channel *types.ChannelID, options ...ActionOption) (cid.Cid, error) {
+	var out commands.SettleResult
+	args := []string{"go-filecoin", "paych", "settle", channel.String()}
+
+	for _, option := range options {
+		args = append(args, option()...)
+	}
+
+	if err := f.RunCmdJSONWithStdin(ctx, nil, &out, args...); err != nil {
+		return cid.Undef, err
+	}
+
+	return out.Cid, nil
+}
+
 // PaychVoucher runs the `paych voucher` command against the filecoin process.
 func (f *Filecoin) PaychVoucher(ctx context.Context, channel *types.ChannelID, amount types.AttoFIL, options ...ActionOption) (string, error) {
 	var out string
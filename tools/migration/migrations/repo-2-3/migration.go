@@ -0,0 +1,139 @@
+package migration23
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	bstore "github.com/ipfs/go-ipfs-blockstore"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/repo"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+// duplicate keys here to protect against future changes
+var headKey = datastore.NewKey("/chain/heaviestTipSet")
+var genesisKey = datastore.NewKey("/consensus/genesisCid")
+
+// MessageMethodIDs is the migration from version 2 to 3.
+type MessageMethodIDs struct{}
+
+// Describe describes the steps this migration will take.
+func (m *MessageMethodIDs) Describe() string {
+	return `MessageMethodIDs migrates the storage repo from version 2 to 3.
+
+    Messages now address actor methods by numeric id instead of by name, which
+    changes the encoding, and so the cid, of every message. Messages already on
+    chain were signed over their old encoding and cannot be rewritten, so this
+    migration resets the head of the chain store to the genesis tipset. The node
+    syncs the chain from its peers again when it next starts. The genesis block
+    and state, as well as all other repo data, are unchanged.
+`
+}
+
+// Migrate performs the migration steps
+func (m *MessageMethodIDs) Migrate(newRepoPath string) error {
+	oldVer, _ := m.Versions()
+
+	// This call performs some checks on the repo before we start.
+	fsrepo, err := repo.OpenFSRepo(newRepoPath, oldVer)
+	if err != nil {
+		return err
+	}
+	defer mustCloseRepo(fsrepo)
+
+	genesis, err := loadGenesisBlock(fsrepo)
+	if err != nil {
+		return err
+	}
+
+	val, err := cbor.DumpObject(types.NewTipSetKey(genesis.Cid()))
+	if err != nil {
+		return err
+	}
+	return fsrepo.ChainDatastore().Put(headKey, val)
+}
+
+// Versions returns the old and new versions that are valid for this migration
+func (m *MessageMethodIDs) Versions() (from, to uint) {
+	return 2, 3
+}
+
+// Validate checks that the head of the new chain store is the genesis tipset
+// and that its state root is present.
+func (m *MessageMethodIDs) Validate(oldRepoPath, newRepoPath string) error {
+	oldVer, _ := m.Versions()
+
+	// Version hasn't been updated yet.
+	fsrepo, err := repo.OpenFSRepo(newRepoPath, oldVer)
+	if err != nil {
+		return err
+	}
+	defer mustCloseRepo(fsrepo)
+
+	genesis, err := loadGenesisBlock(fsrepo)
+	if err != nil {
+		return err
+	}
+
+	bb, err := fsrepo.ChainDatastore().Get(headKey)
+	if err != nil {
+		return errors.Wrap(err, "failed to read headKey")
+	}
+	var head types.TipSetKey
+	if err := cbor.DecodeInto(bb, &head); err != nil {
+		return errors.Wrap(err, "failed to cast headCids")
+	}
+	if !head.Equals(types.NewTipSetKey(genesis.Cid())) {
+		return errors.Errorf("chain head %s is not the genesis tipset", head)
+	}
+
+	genesisTs, err := types.NewTipSet(genesis)
+	if err != nil {
+		return err
+	}
+	stateKey := datastore.NewKey(makeKey(genesisTs.String(), uint64(genesis.Height)))
+	has, err := fsrepo.ChainDatastore().Has(stateKey)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return errors.New("missing state root of the genesis tipset")
+	}
+	return nil
+}
+
+// loadGenesisBlock loads the genesis block recorded in the repo.
+func loadGenesisBlock(fsrepo *repo.FSRepo) (*types.Block, error) {
+	bb, err := fsrepo.Datastore().Get(genesisKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read genesis key")
+	}
+
+	var genesisCid cid.Cid
+	if err := json.Unmarshal(bb, &genesisCid); err != nil {
+		return nil, errors.Wrap(err, "failed to cast genesis cid")
+	}
+
+	// Block headers are stored in the repo datastore, not the chain datastore.
+	blk, err := bstore.NewBlockstore(fsrepo.Datastore()).Get(genesisCid)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get genesis block %s", genesisCid)
+	}
+	return types.DecodeBlock(blk.RawData())
+}
+
+func makeKey(pKey string, h uint64) string {
+	return fmt.Sprintf("p-%s h-%d", pKey, h)
+}
+
+func mustCloseRepo(fsRepo *repo.FSRepo) {
+	err := fsRepo.Close()
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,59 @@
+package migration23_test
+
+import (
+	"context"
+	"testing"
+
+	bserv "github.com/ipfs/go-blockservice"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-hamt-ipld"
+	bstore "github.com/ipfs/go-ipfs-blockstore"
+	offline "github.com/ipfs/go-ipfs-exchange-offline"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/chain"
+	"github.com/filecoin-project/go-filecoin/repo"
+	th "github.com/filecoin-project/go-filecoin/testhelpers"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/tools/migration/internal"
+	migration23 "github.com/filecoin-project/go-filecoin/tools/migration/migrations/repo-2-3"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+func TestMessageMethodIDsMigration(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	ctx := context.Background()
+	container, repoPath := internal.RequireInitRepo(t, 2)
+	defer repo.RequireRemoveAll(t, container)
+
+	// Initialize the repo with a genesis block and move the head away from it.
+	fsrepo, err := repo.OpenFSRepo(repoPath, 2)
+	require.NoError(t, err)
+	bs := bstore.NewBlockstore(fsrepo.Datastore())
+	cst := &hamt.CborIpldStore{Blocks: bserv.New(bs, offline.Exchange(bs))}
+	chainStore, err := chain.Init(ctx, fsrepo, bs, cst, th.DefaultGenesis)
+	require.NoError(t, err)
+	genesisKey := types.NewTipSetKey(chainStore.GenesisCid())
+
+	otherHead, err := cbor.DumpObject(types.NewTipSetKey(types.CidFromString(t, "other head")))
+	require.NoError(t, err)
+	require.NoError(t, fsrepo.ChainDatastore().Put(datastore.NewKey("/chain/heaviestTipSet"), otherHead))
+	require.NoError(t, fsrepo.Close())
+
+	migration := &migration23.MessageMethodIDs{}
+	assert.Error(t, migration.Validate(repoPath, repoPath))
+	require.NoError(t, migration.Migrate(repoPath))
+	require.NoError(t, migration.Validate(repoPath, repoPath))
+
+	fsrepo, err = repo.OpenFSRepo(repoPath, 2)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, fsrepo.Close()) }()
+	bb, err := fsrepo.ChainDatastore().Get(datastore.NewKey("/chain/heaviestTipSet"))
+	require.NoError(t, err)
+	var head types.TipSetKey
+	require.NoError(t, cbor.DecodeInto(bb, &head))
+	assert.True(t, genesisKey.Equals(head))
+}
@@ -46,6 +46,12 @@ var InitActorCodeObj ipld.Node
 // InitActorCodeCid is the cid of the above object
 var InitActorCodeCid cid.Cid
 
+// MultisigActorCodeObj is the code representation of the builtin multisig actor.
+var MultisigActorCodeObj ipld.Node
+
+// MultisigActorCodeCid is the cid of the above object
+var MultisigActorCodeCid cid.Cid
+
 // ActorCodeCidTypeNames maps Actor codeCid's to the name of the associated Actor type.
 var ActorCodeCidTypeNames = make(map[cid.Cid]string)
 
@@ -62,6 +68,8 @@ func init() {
 	BootstrapMinerActorCodeCid = BootstrapMinerActorCodeObj.Cid()
 	InitActorCodeObj = dag.NewRawNode([]byte("initactor"))
 	InitActorCodeCid = InitActorCodeObj.Cid()
+	MultisigActorCodeObj = dag.NewRawNode([]byte("multisigactor"))
+	MultisigActorCodeCid = MultisigActorCodeObj.Cid()
 
 	// New Actors need to be added here.
 	// TODO: Make this work with reflection -- but note that nasty import cycles lie on that path.
@@ -72,6 +80,7 @@ func init() {
 	ActorCodeCidTypeNames[MinerActorCodeCid] = "MinerActor"
 	ActorCodeCidTypeNames[BootstrapMinerActorCodeCid] = "MinerActor"
 	ActorCodeCidTypeNames[InitActorCodeCid] = "InitActor"
+	ActorCodeCidTypeNames[MultisigActorCodeCid] = "MultisigActor"
 }
 
 // ActorCodeTypeName returns the (string) name of the Go type of the actor with cid, code.
@@ -0,0 +1,22 @@
+package types
+
+// GasSchedule holds the gas the VM charges for the operations it performs on
+// behalf of actors, on top of what actors charge explicitly.
+type GasSchedule struct {
+	// StoragePutPerByte is charged for every byte of a chunk put in actor storage.
+	StoragePutPerByte GasUnits `json:"storagePutPerByte"`
+	// StorageGetPerBlock is charged for every chunk read from actor storage.
+	StorageGetPerBlock GasUnits `json:"storageGetPerBlock"`
+	// SendPerCall is charged for every message an actor sends to another actor.
+	SendPerCall GasUnits `json:"sendPerCall"`
+	// CreateActorPerCall is charged for every actor created by an actor.
+	CreateActorPerCall GasUnits `json:"createActorPerCall"`
+}
+
+// DefaultGasSchedule is the gas schedule applied to all messages.
+var DefaultGasSchedule = GasSchedule{
+	StoragePutPerByte:  NewGasUnits(1),
+	StorageGetPerBlock: NewGasUnits(10),
+	SendPerCall:        NewGasUnits(10),
+	CreateActorPerCall: NewGasUnits(100),
+}
@@ -42,13 +42,13 @@ type Message struct {
 
 	Value AttoFIL `json:"value"`
 
-	Method string `json:"method"`
-	Params []byte `json:"params"`
+	Method MethodID `json:"method"`
+	Params []byte   `json:"params"`
 	// Pay attention to Equals() if updating this struct.
 }
 
 // NewMessage creates a new message.
-func NewMessage(from, to address.Address, nonce uint64, value AttoFIL, method string, params []byte) *Message {
+func NewMessage(from, to address.Address, nonce uint64, value AttoFIL, method MethodID, params []byte) *Message {
 	return &Message{
 		From:   from,
 		To:     to,
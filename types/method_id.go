@@ -0,0 +1,18 @@
+package types
+
+import (
+	"strconv"
+)
+
+// MethodID identifies an exported method of an actor. IDs are assigned by each
+// actor and are stable across releases, so messages do not depend on the names
+// of the Go methods implementing them.
+type MethodID uint64
+
+// SendMethodID is the method of a message that only transfers value and does
+// not invoke any actor method.
+const SendMethodID = MethodID(0)
+
+func (id MethodID) String() string {
+	return strconv.FormatUint(uint64(id), 10)
+}
@@ -29,6 +29,10 @@ type MinerState struct {
 	// worker address for the miner.
 	Owner address.Address
 
+	// PendingOwner is the address the current owner has proposed to transfer
+	// ownership to. The transfer takes effect once the pending owner accepts it.
+	PendingOwner address.Address
+
 	// Worker is the address of the worker account for this miner.
 	// This will be the key that is used to sign blocks created by this miner, and
 	// sign messages sent on behalf of this miner to commit sectors, submit PoSts, and
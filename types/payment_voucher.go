@@ -13,6 +13,7 @@ import (
 func init() {
 	cbor.RegisterCborType(Predicate{})
 	cbor.RegisterCborType(PaymentVoucher{})
+	cbor.RegisterCborType(Merge{})
 }
 
 // Predicate is an optional message that is sent to another actor and must return true for the voucher to be valid.
@@ -33,6 +34,14 @@ type PaymentVoucher struct {
 	// Channel is the id of this voucher's payment channel.
 	Channel ChannelID `json:"channel"`
 
+	// Lane is the lane of the channel this voucher pays on. Vouchers on different
+	// lanes are redeemed independently of each other.
+	Lane uint64 `json:"lane"`
+
+	// Nonce orders vouchers within a lane. A voucher may not be redeemed once a
+	// voucher with a greater nonce has been redeemed on its lane.
+	Nonce uint64 `json:"nonce"`
+
 	// Payer is the address of the account that created the channel.
 	Payer address.Address `json:"payer"`
 
@@ -45,6 +54,10 @@ type PaymentVoucher struct {
 	// ValidAt is the earliest block height at which this voucher is valid.
 	ValidAt BlockHeight `json:"valid_at"`
 
+	// Merges are the lanes folded into this voucher's lane when it is redeemed.
+	// Amount then covers everything redeemed on the merged lanes as well.
+	Merges []Merge `json:"merges"`
+
 	// Condition defines a optional message that will be called and must return true before this voucher can be redeemed.
 	Condition *Predicate `json:"condition"`
 
@@ -52,6 +65,15 @@ type PaymentVoucher struct {
 	Signature Signature `json:"signature"`
 }
 
+// Merge identifies a lane merged into the lane of a voucher. Nonce must be
+// greater than that of any voucher redeemed on the merged lane. The merge covers
+// the vouchers of the merged lane up to and including Nonce, so they may not be
+// redeemed on it afterwards.
+type Merge struct {
+	Lane  uint64 `json:"lane"`
+	Nonce uint64 `json:"nonce"`
+}
+
 // DecodeVoucher creates a *PaymentVoucher from a base58, Cbor-encoded one
 func DecodeVoucher(voucherRaw string) (*PaymentVoucher, error) {
 	_, cborVoucher, err := multibase.Decode(voucherRaw)
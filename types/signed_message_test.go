@@ -100,7 +100,7 @@ func makeMessage(t *testing.T, signer MockSigner, nonce uint64) *SignedMessage {
 		newAddr,
 		nonce,
 		NewAttoFILFromFIL(2),
-		"method",
+		MethodID(1),
 		[]byte("params"))
 	smsg, err := NewSignedMessage(*msg, &signer, NewGasPrice(1000), NewGasUnits(100))
 	require.NoError(t, err)
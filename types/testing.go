@@ -137,7 +137,7 @@ func NewSignedMessageForTestGetter(ms MockSigner) func() *SignedMessage {
 			newAddr,
 			0,
 			ZeroAttoFIL,
-			s,
+			MethodID(i),
 			[]byte("params"))
 		smsg, err := NewSignedMessage(*msg, &ms, NewGasPrice(0), NewGasUnits(0))
 		if err != nil {
@@ -195,7 +195,7 @@ func NewMessageForTestGetter() func() *Message {
 			to,
 			0,
 			ZeroAttoFIL,
-			s,
+			MethodID(i),
 			nil)
 	}
 }
@@ -53,7 +53,7 @@ func (mm *MessageMaker) NewSignedMessage(from address.Address, nonce uint64) *Si
 		to,
 		nonce,
 		ZeroAttoFIL,
-		"method"+fmt.Sprintf("%d", seq),
+		MethodID(seq),
 		[]byte("params"))
 	signed, err := NewSignedMessage(*msg, mm.signer, mm.DefaultGasPrice, mm.DefaultGasUnits)
 	require.NoError(mm.t, err)
@@ -27,8 +27,10 @@ type Context struct {
 	state       *state.CachedTree
 	storageMap  StorageMap
 	gasTracker  *GasTracker
+	gasSchedule types.GasSchedule
 	blockHeight *types.BlockHeight
 	ancestors   []types.TipSet
+	trace       *ExecutionTrace
 
 	deps *deps // Inject external dependencies so we can unit test robustly.
 }
@@ -45,6 +47,13 @@ type NewContextParams struct {
 	GasTracker  *GasTracker
 	BlockHeight *types.BlockHeight
 	Ancestors   []types.TipSet
+
+	// GasSchedule is charged for the storage, send and actor creation
+	// operations of the actor. The zero value makes them free.
+	GasSchedule types.GasSchedule
+	// Trace, when set, records the execution of the message and of the
+	// messages it sends.
+	Trace *ExecutionTrace
 }
 
 // NewVMContext returns an initialized context.
@@ -56,8 +65,10 @@ func NewVMContext(params NewContextParams) *Context {
 		state:       params.State,
 		storageMap:  params.StorageMap,
 		gasTracker:  params.GasTracker,
+		gasSchedule: params.GasSchedule,
 		blockHeight: params.BlockHeight,
 		ancestors:   params.Ancestors,
+		trace:       params.Trace,
 		deps:        makeDeps(params.State),
 	}
 }
@@ -65,8 +76,14 @@ func NewVMContext(params NewContextParams) *Context {
 var _ exec.VMContext = (*Context)(nil)
 
 // Storage returns an implementation of the storage module for this context.
+// Reads and writes are charged according to the gas schedule.
 func (ctx *Context) Storage() exec.Storage {
-	return ctx.storageMap.NewStorage(ctx.message.To, ctx.to)
+	return meteredStorage{
+		Storage:    ctx.storageMap.NewStorage(ctx.message.To, ctx.to),
+		gasTracker: ctx.gasTracker,
+		schedule:   ctx.gasSchedule,
+		trace:      ctx.trace,
+	}
 }
 
 // Message retrieves the message associated with this context.
@@ -100,10 +117,15 @@ func (ctx *Context) IsFromAccountActor() bool {
 }
 
 // Send sends a message to another actor.
-// This method assumes to be called from inside the `to` actor.
+// This method assumes to be called from inside the `to` actor. The method is
+// given by name and resolved to its id using the exports of the receiving actor.
 func (ctx *Context) Send(to address.Address, method string, value types.AttoFIL, params []interface{}) ([][]byte, uint8, error) {
 	deps := ctx.deps
 
+	if err := ctx.Charge(ctx.gasSchedule.SendPerCall); err != nil {
+		return nil, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
 	// the message sender is the `to` actor, so this is what we set as `from` in the new message
 	from := ctx.Message().To
 	fromActor := ctx.to
@@ -118,18 +140,24 @@ func (ctx *Context) Send(to address.Address, method string, value types.AttoFIL,
 		return nil, 1, errors.RevertErrorWrap(err, "encoding params failed")
 	}
 
-	msg := types.NewMessage(from, to, 0, value, method, paramData)
-	if msg.From == msg.To {
+	if from == to {
 		// TODO: handle this
-		return nil, 1, errors.NewFaultErrorf("unhandled: sending to self (%s)", msg.From)
+		return nil, 1, errors.NewFaultErrorf("unhandled: sending to self (%s)", from)
 	}
 
-	toActor, err := deps.GetOrCreateActor(context.TODO(), msg.To, func() (*actor.Actor, error) {
+	toActor, err := deps.GetOrCreateActor(context.TODO(), to, func() (*actor.Actor, error) {
 		return &actor.Actor{}, nil
 	})
 	if err != nil {
-		return nil, 1, errors.FaultErrorWrapf(err, "failed to get or create To actor %s", msg.To)
+		return nil, 1, errors.FaultErrorWrapf(err, "failed to get or create To actor %s", to)
 	}
+
+	methodID, code, err := LookupMethod(ctx.state, toActor.Code, method)
+	if err != nil {
+		return nil, code, err
+	}
+
+	msg := types.NewMessage(from, to, 0, value, methodID, paramData)
 	// TODO(fritz) de-dup some of the logic between here and core.Send
 	innerParams := NewContextParams{
 		From:        fromActor,
@@ -138,9 +166,14 @@ func (ctx *Context) Send(to address.Address, method string, value types.AttoFIL,
 		State:       ctx.state,
 		StorageMap:  ctx.storageMap,
 		GasTracker:  ctx.gasTracker,
+		GasSchedule: ctx.gasSchedule,
 		BlockHeight: ctx.blockHeight,
 		Ancestors:   ctx.ancestors,
 	}
+	if ctx.trace != nil {
+		innerParams.Trace = &ExecutionTrace{}
+		ctx.trace.Subcalls = append(ctx.trace.Subcalls, innerParams.Trace)
+	}
 	innerCtx := NewVMContext(innerParams)
 
 	out, ret, err := deps.Send(context.Background(), innerCtx)
@@ -176,6 +209,10 @@ func computeActorAddress(creator address.Address, nonce uint64) (address.Address
 // CreateNewActor creates and initializes an actor at the given address.
 // If the address is occupied by a non-empty actor, this method will fail.
 func (ctx *Context) CreateNewActor(addr address.Address, code cid.Cid, initializerData interface{}) error {
+	if err := ctx.Charge(ctx.gasSchedule.CreateActorPerCall); err != nil {
+		return errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
 	// Check existing address. If nothing there, create empty actor.
 	newActor, err := ctx.state.GetOrCreateActor(context.TODO(), addr, func() (*actor.Actor, error) {
 		return &actor.Actor{}, nil
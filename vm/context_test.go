@@ -43,7 +43,7 @@ func TestVMContextStorage(t *testing.T) {
 	toAddr := addrGetter()
 
 	assert.NoError(t, st.SetActor(ctx, toAddr, toActor))
-	msg := types.NewMessage(addrGetter(), toAddr, 0, types.ZeroAttoFIL, "hello", nil)
+	msg := types.NewMessage(addrGetter(), toAddr, 0, types.ZeroAttoFIL, types.MethodID(1), nil)
 
 	to, err := cstate.GetActor(ctx, toAddr)
 	assert.NoError(t, err)
@@ -76,6 +76,64 @@ func TestVMContextStorage(t *testing.T) {
 	assert.Equal(t, storage, node.RawData())
 }
 
+func TestVMContextStorageChargesGas(t *testing.T) {
+	tf.UnitTest(t)
+
+	addrGetter := address.NewForTestGetter()
+
+	bs := blockstore.NewBlockstore(datastore.NewMapDatastore())
+	vms := NewStorageMap(bs)
+
+	toActor, err := account.NewActor(types.ZeroAttoFIL)
+	require.NoError(t, err)
+	msg := types.NewMessage(addrGetter(), addrGetter(), 0, types.ZeroAttoFIL, types.MethodID(1), nil)
+
+	node, err := cbor.WrapObject([]byte("hello"), types.DefaultHashFunction, -1)
+	require.NoError(t, err)
+	size := types.GasUnits(len(node.RawData()))
+
+	schedule := types.GasSchedule{
+		StoragePutPerByte:  types.NewGasUnits(2),
+		StorageGetPerBlock: types.NewGasUnits(7),
+	}
+
+	newCtx := func(limit types.GasUnits) *Context {
+		gasTracker := NewGasTracker()
+		gasTracker.MsgGasLimit = limit
+
+		return NewVMContext(NewContextParams{
+			To:          toActor,
+			Message:     msg,
+			StorageMap:  vms,
+			GasTracker:  gasTracker,
+			BlockHeight: types.NewBlockHeight(0),
+			GasSchedule: schedule,
+		})
+	}
+
+	t.Run("put charges per byte and get per block", func(t *testing.T) {
+		vmCtx := newCtx(types.NewGasUnits(1000))
+
+		c, err := vmCtx.Storage().Put(node.RawData())
+		require.NoError(t, err)
+		assert.Equal(t, 2*size, vmCtx.GasUnits())
+
+		_, err = vmCtx.Storage().Get(c)
+		require.NoError(t, err)
+		assert.Equal(t, 2*size+7, vmCtx.GasUnits())
+		assert.False(t, vmCtx.gasTracker.outOfGas)
+	})
+
+	t.Run("running out of gas does not fail the storage call", func(t *testing.T) {
+		vmCtx := newCtx(size)
+
+		_, err := vmCtx.Storage().Put(node.RawData())
+		require.NoError(t, err)
+		assert.Equal(t, size, vmCtx.GasUnits())
+		assert.True(t, vmCtx.gasTracker.outOfGas)
+	})
+}
+
 func TestVMContextSendFailures(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -232,7 +290,7 @@ func TestVMContextSendFailures(t *testing.T) {
 		ctx := NewVMContext(vmCtxParams)
 		ctx.deps = deps
 
-		_, code, err := ctx.Send(newAddress(), "foo", types.ZeroAttoFIL, []interface{}{})
+		_, code, err := ctx.Send(newAddress(), "", types.ZeroAttoFIL, []interface{}{})
 
 		assert.Error(t, err)
 		assert.Equal(t, 123, int(code))
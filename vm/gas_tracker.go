@@ -10,6 +10,8 @@ type GasTracker struct {
 	MsgGasLimit          types.GasUnits
 	gasConsumedByBlock   types.GasUnits
 	gasConsumedByMessage types.GasUnits
+	// outOfGas is set once a charge exceeded the limit of the current message.
+	outOfGas bool
 }
 
 // NewGasTracker initializes a new empty gas tracker
@@ -25,13 +27,15 @@ func NewGasTracker() *GasTracker {
 func (gasTracker *GasTracker) ResetForNewMessage(message types.MeteredMessage) {
 	gasTracker.MsgGasLimit = message.GasLimit
 	gasTracker.gasConsumedByMessage = types.NewGasUnits(0)
+	gasTracker.outOfGas = false
 }
 
 // Charge will add the gas charge to the current method gas context.
 func (gasTracker *GasTracker) Charge(cost types.GasUnits) error {
 	if gasTracker.gasConsumedByMessage+cost > gasTracker.MsgGasLimit {
+		gasTracker.gasConsumedByBlock += gasTracker.MsgGasLimit - gasTracker.gasConsumedByMessage
 		gasTracker.gasConsumedByMessage = gasTracker.MsgGasLimit
-		gasTracker.gasConsumedByBlock += gasTracker.MsgGasLimit
+		gasTracker.outOfGas = true
 		return errors.NewRevertError("gas cost exceeds gas limit")
 	}
 
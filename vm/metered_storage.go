@@ -0,0 +1,45 @@
+package vm
+
+import (
+	"github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/go-filecoin/exec"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+// meteredStorage charges the gas schedule for reads and writes to an actor's
+// storage. Running out of gas does not fail the read or write itself, actors
+// commonly wrap storage errors as faults. Instead the gas tracker remembers it
+// and the message is reverted when the actor method returns.
+// Writes are recorded in the execution trace, if there is one.
+type meteredStorage struct {
+	Storage
+	gasTracker *GasTracker
+	schedule   types.GasSchedule
+	trace      *ExecutionTrace
+}
+
+var _ exec.Storage = (*meteredStorage)(nil)
+
+// Put adds a node to temporary storage, charging for every byte of it.
+func (s meteredStorage) Put(v interface{}) (cid.Cid, error) {
+	c, err := s.Storage.Put(v)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	size := types.GasUnits(len(s.Storage.chunks[c].RawData()))
+	s.gasTracker.Charge(s.schedule.StoragePutPerByte * size) // nolint: errcheck
+	if s.trace != nil {
+		s.trace.StorageWrites = append(s.trace.StorageWrites, c)
+	}
+
+	return c, nil
+}
+
+// Get retrieves a chunk, charging for the block read.
+func (s meteredStorage) Get(c cid.Cid) ([]byte, error) {
+	s.gasTracker.Charge(s.schedule.StorageGetPerBlock) // nolint: errcheck
+
+	return s.Storage.Get(c)
+}
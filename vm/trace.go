@@ -0,0 +1,60 @@
+package vm
+
+import (
+	"github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+// ExecutionTrace records a message executed by the VM, what it did to the
+// storage of the receiving actor and the messages that actor sent in turn.
+type ExecutionTrace struct {
+	From       address.Address `json:"from"`
+	To         address.Address `json:"to"`
+	Value      types.AttoFIL   `json:"value"`
+	Method     types.MethodID  `json:"method"`
+	MethodName string          `json:"methodName,omitempty"`
+	Params     []byte          `json:"params"`
+
+	Return   [][]byte       `json:"return"`
+	ExitCode uint8          `json:"exitCode"`
+	Error    string         `json:"error,omitempty"`
+	GasUsed  types.GasUnits `json:"gasUsed"`
+
+	// StorageWrites are the cids of the chunks the receiving actor put in its storage.
+	StorageWrites []cid.Cid `json:"storageWrites"`
+	// Subcalls are the traces of the messages sent by the receiving actor, in order.
+	Subcalls []*ExecutionTrace `json:"subcalls"`
+}
+
+// begin records the message about to be executed in the given context.
+func (t *ExecutionTrace) begin(vmCtx *Context) {
+	msg := vmCtx.message
+	t.From = msg.From
+	t.To = msg.To
+	t.Value = msg.Value
+	t.Method = msg.Method
+	t.Params = msg.Params
+
+	if msg.Method == types.SendMethodID || vmCtx.state == nil {
+		return
+	}
+	executable, err := vmCtx.state.GetBuiltinActorCode(vmCtx.to.Code)
+	if err != nil {
+		return
+	}
+	if signature, ok := executable.Exports()[msg.Method]; ok {
+		t.MethodName = signature.Name
+	}
+}
+
+// end records the outcome of the execution.
+func (t *ExecutionTrace) end(ret [][]byte, exitCode uint8, err error, gasUsed types.GasUnits) {
+	t.Return = ret
+	t.ExitCode = exitCode
+	if err != nil {
+		t.Error = err.Error()
+	}
+	t.GasUsed = gasUsed
+}
@@ -5,9 +5,12 @@ package vm
 
 import (
 	"context"
+
+	"github.com/ipfs/go-cid"
 	cbor "github.com/ipfs/go-ipld-cbor"
 
 	"github.com/filecoin-project/go-filecoin/actor"
+	"github.com/filecoin-project/go-filecoin/exec"
 	"github.com/filecoin-project/go-filecoin/types"
 	"github.com/filecoin-project/go-filecoin/vm/errors"
 )
@@ -20,7 +23,16 @@ func Send(ctx context.Context, vmCtx *Context) ([][]byte, uint8, error) {
 	deps := sendDeps{
 		transfer: Transfer,
 	}
-	return send(ctx, deps, vmCtx)
+	if vmCtx.trace == nil {
+		return send(ctx, deps, vmCtx)
+	}
+
+	vmCtx.trace.begin(vmCtx)
+	gasBefore := vmCtx.gasTracker.gasConsumedByMessage
+	ret, code, err := send(ctx, deps, vmCtx)
+	vmCtx.trace.end(ret, code, err, vmCtx.gasTracker.gasConsumedByMessage-gasBefore)
+
+	return ret, code, err
 }
 
 type sendDeps struct {
@@ -41,7 +53,7 @@ func send(ctx context.Context, deps sendDeps, vmCtx *Context) ([][]byte, uint8,
 		}
 	}
 
-	if vmCtx.message.Method == "" {
+	if vmCtx.message.Method == types.SendMethodID {
 		// if only tokens are transferred there is no need for a method
 		// this means we can shortcircuit execution
 		return nil, 0, nil
@@ -57,6 +69,10 @@ func send(ctx context.Context, deps sendDeps, vmCtx *Context) ([][]byte, uint8,
 	}
 
 	r, code, err := actor.MakeTypedExport(toExecutable, vmCtx.message.Method)(vmCtx)
+	if err == nil && vmCtx.gasTracker.outOfGas {
+		// storage charges don't fail the actor, so catch running out of gas here
+		return nil, exec.ErrInsufficientGas, errors.NewCodedRevertError(exec.ErrInsufficientGas, "gas cost exceeds gas limit")
+	}
 	if r != nil {
 		var rv [][]byte
 		err = cbor.DecodeInto(r, &rv)
@@ -68,6 +84,32 @@ func send(ctx context.Context, deps sendDeps, vmCtx *Context) ([][]byte, uint8,
 	return nil, code, err
 }
 
+// BuiltinActorGetter finds the implementation of an actor's code.
+type BuiltinActorGetter interface {
+	GetBuiltinActorCode(c cid.Cid) (exec.ExecutableActor, error)
+}
+
+// LookupMethod resolves the name of a method exported by the actor with the
+// given code to the id messages address it by. The empty name resolves to
+// types.SendMethodID for any actor. If error is set it will always satisfy
+// ShouldRevert().
+func LookupMethod(actors BuiltinActorGetter, code cid.Cid, method string) (types.MethodID, uint8, error) {
+	if method == "" {
+		return types.SendMethodID, 0, nil
+	}
+
+	executable, err := actors.GetBuiltinActorCode(code)
+	if err != nil {
+		return 0, errors.ErrNoActorCode, errors.Errors[errors.ErrNoActorCode]
+	}
+
+	id, _, ok := executable.Exports().Lookup(method)
+	if !ok {
+		return 0, 1, errors.Errors[errors.ErrMissingExport]
+	}
+	return id, 0, nil
+}
+
 // Transfer transfers the given value between two actors.
 func Transfer(fromActor, toActor *actor.Actor, value types.AttoFIL) error {
 	if value.IsNegative() {
@@ -109,7 +109,7 @@ func TestSendErrorHandling(t *testing.T) {
 	t.Run("returns exit code 1 and a revert error if code doesn't export a matching method", func(t *testing.T) {
 		msg := newMsg()
 		msg.Value = types.ZeroAttoFIL // such that we don't transfer
-		msg.Method = "bar"
+		msg.Method = types.MethodID(1000)
 
 		assert.False(t, actor.FakeActorExports.Has(msg.Method))
 
@@ -136,3 +136,39 @@ func TestSendErrorHandling(t *testing.T) {
 		assert.True(t, errors.ShouldRevert(sendErr))
 	})
 }
+
+func TestLookupMethod(t *testing.T) {
+	tf.UnitTest(t)
+
+	fakeCode := types.CidFromString(t, "somecid")
+	tree := state.NewCachedStateTree(&state.MockStateTree{NoMocks: true, BuiltinActors: map[cid.Cid]exec.ExecutableActor{
+		fakeCode: &actor.FakeActor{},
+	}})
+
+	t.Run("resolves an exported method to its id", func(t *testing.T) {
+		id, code, err := LookupMethod(tree, fakeCode, "goodCall")
+		assert.NoError(t, err)
+		assert.Equal(t, 0, int(code))
+		assert.Equal(t, actor.FakeMethodGoodCall, id)
+	})
+
+	t.Run("resolves the empty name to a plain send for any actor", func(t *testing.T) {
+		id, _, err := LookupMethod(tree, cid.Undef, "")
+		assert.NoError(t, err)
+		assert.Equal(t, types.SendMethodID, id)
+	})
+
+	t.Run("returns a revert error if the method is not exported", func(t *testing.T) {
+		_, code, err := LookupMethod(tree, fakeCode, "bar")
+		assert.Error(t, err)
+		assert.Equal(t, 1, int(code))
+		assert.True(t, errors.ShouldRevert(err))
+	})
+
+	t.Run("returns a revert error if the code can't be loaded", func(t *testing.T) {
+		_, code, err := LookupMethod(tree, types.CidFromString(t, "othercid"), "goodCall")
+		assert.Error(t, err)
+		assert.Equal(t, errors.ErrNoActorCode, int(code))
+		assert.True(t, errors.ShouldRevert(err))
+	})
+}